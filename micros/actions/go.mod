@@ -3,6 +3,7 @@ module github.com/red-gold/telar-web/micros/actions
 go 1.18
 
 require (
+	github.com/alexellis/hmac v0.0.0-20180624211220-5c52ab81c0de
 	github.com/gofiber/adaptor/v2 v2.1.4
 	github.com/gofiber/fiber/v2 v2.10.0
 	github.com/gofrs/uuid v4.0.0+incompatible
@@ -11,7 +12,6 @@ require (
 )
 
 require (
-	github.com/alexellis/hmac v0.0.0-20180624211220-5c52ab81c0de // indirect
 	github.com/andybalholm/brotli v1.0.3 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible // indirect
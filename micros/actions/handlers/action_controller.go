@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofrs/uuid"
 	"github.com/red-gold/telar-core/config"
 	"github.com/red-gold/telar-core/pkg/log"
 	"github.com/red-gold/telar-core/types"
@@ -23,6 +24,7 @@ import (
 // @Param roomId path string true "ActionRoom ID"
 // @Success 200 {string} string "OK"
 // @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 403 {object} utils.TelarError "Sender is blocked or muted"
 // @Failure 500 {object} utils.TelarError "Internal server error"
 // @Router /dispatch/{roomId} [post]
 func DispatchHandle(c *fiber.Ctx) error {
@@ -37,6 +39,17 @@ func DispatchHandle(c *fiber.Ctx) error {
 		return c.Status(http.StatusInternalServerError).JSON(utils.Error("actionRoomIdRequired", "ActionRoom Id is required!"))
 	}
 
+	// Room of a user does not get actions from users they blocked, muted or were blocked by
+	currentUser, _ := c.Locals("user").(types.UserContext)
+	if receiverId, uuidErr := uuid.FromString(actionRoomId); uuidErr == nil && currentUser.UserID != uuid.Nil && currentUser.UserID != receiverId {
+		restrictions, restrictionsErr := getUserRestrictions(receiverId)
+		if restrictionsErr != nil {
+			log.Error("[DispatchHandle] getUserRestrictions %s", restrictionsErr.Error())
+		} else if restrictions.IsRestricted(currentUser.UserID) {
+			return c.Status(http.StatusForbidden).JSON(utils.Error("restrictedUser", "Action is not allowed for this user!"))
+		}
+	}
+
 	bodyReader := bytes.NewBuffer(c.Body())
 	URL := fmt.Sprintf("%s/api/dispatch/%s", actionConfig.WebsocketServerURL, actionRoomId)
 	log.Info(" Dispatch URL: %s", URL)
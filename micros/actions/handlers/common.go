@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/alexellis/hmac"
+	"github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/config"
+	"github.com/red-gold/telar-core/types"
+	"github.com/red-gold/telar-core/utils"
+	models "github.com/red-gold/telar-web/micros/actions/models"
+)
+
+// functionCall send request to another function/microservice using HMAC validation
+func functionCall(method string, bytesReq []byte, url string, header map[string][]string) ([]byte, error) {
+	prettyURL := utils.GetPrettyURLf(url)
+	bodyReader := bytes.NewBuffer(bytesReq)
+
+	httpReq, httpErr := http.NewRequest(method, *config.AppConfig.InternalGateway+prettyURL, bodyReader)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	payloadSecret := *config.AppConfig.PayloadSecret
+
+	digest := hmac.Sign(bytesReq, []byte(payloadSecret))
+	httpReq.Header.Set("Content-type", "application/json")
+	httpReq.Header.Add(types.HeaderHMACAuthenticate, "sha1="+hex.EncodeToString(digest))
+
+	for k, v := range header {
+		httpReq.Header[k] = v
+	}
+
+	c := http.Client{}
+	res, reqErr := c.Do(httpReq)
+	if reqErr != nil {
+		return nil, fmt.Errorf("Error while sending request to %s: %s", url, reqErr.Error())
+	}
+	if res.Body != nil {
+		defer res.Body.Close()
+	}
+
+	resData, readErr := ioutil.ReadAll(res.Body)
+	if resData == nil || readErr != nil {
+		return nil, fmt.Errorf("failed to read response from %s", url)
+	}
+
+	if res.StatusCode != http.StatusAccepted && res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to call %s, invalid status: %s", url, res.Status)
+	}
+
+	return resData, nil
+}
+
+// getUserRestrictions get users blocked or muted by the user and users who blocked the user from profile micro
+func getUserRestrictions(userId uuid.UUID) (*models.UserRestrictionListModel, error) {
+	url := fmt.Sprintf("/profile/dto/restrictions/%s", userId.String())
+
+	resData, callErr := functionCall(http.MethodGet, []byte(""), url, nil)
+	if callErr != nil {
+		return nil, callErr
+	}
+
+	var restrictions models.UserRestrictionListModel
+	if err := json.Unmarshal(resData, &restrictions); err != nil {
+		return nil, err
+	}
+	return &restrictions, nil
+}
@@ -0,0 +1,22 @@
+package models
+
+import uuid "github.com/gofrs/uuid"
+
+// UserRestrictionListModel is the users one user has blocked or muted and the users who blocked the user
+type UserRestrictionListModel struct {
+	Blocked   []uuid.UUID `json:"blocked"`
+	Muted     []uuid.UUID `json:"muted"`
+	BlockedBy []uuid.UUID `json:"blockedBy"`
+}
+
+// IsRestricted check the user is blocked or muted by, or has blocked, the owner of the list
+func (m UserRestrictionListModel) IsRestricted(userId uuid.UUID) bool {
+	for _, list := range [][]uuid.UUID{m.Blocked, m.Muted, m.BlockedBy} {
+		for _, restrictedId := range list {
+			if restrictedId == userId {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -135,3 +135,14 @@ func writeSessionOnCookie(c *fiber.Ctx, session string, config *ac.Configuration
 	c.Cookie(payloadCookie)
 	c.Cookie(signCookie)
 }
+
+// getAdminHeaders create user headers of current admin for http request
+func getAdminHeaders(currentUser types.UserContext) map[string][]string {
+	adminHeaders := make(map[string][]string)
+	adminHeaders["uid"] = []string{currentUser.UserID.String()}
+	adminHeaders["email"] = []string{currentUser.Username}
+	adminHeaders["avatar"] = []string{currentUser.Avatar}
+	adminHeaders["displayName"] = []string{currentUser.DisplayName}
+	adminHeaders["role"] = []string{currentUser.SystemRole}
+	return adminHeaders
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+)
+
+// ReconcileCountersHandler starts profile counter reconciliation on profile micro
+// @Summary Reconcile profile counters
+// @Description Recompute follow, follower, post, vote and share counters from their source collections.
+// @Description Set fix to save the recomputed counters, otherwise discrepancies are only reported.
+// @Tags counters
+// @Accept json
+// @Produce json
+// @Param body body object{fix=boolean} true "Reconcile options"
+// @Success 200 {object} object "Counter reconciliation"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /counters/reconcile [post]
+func ReconcileCountersHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[ReconcileCountersHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	reconcileURL := "/profile/dto/counters/reconcile"
+	counterReconciliation, callErr := functionCallByHeader(http.MethodPost, c.Body(), reconcileURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", reconcileURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/reconcileCounters", "Error happened while starting counter reconciliation!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(counterReconciliation)
+}
+
+// QueryCounterReconciliationsHandler gets counter reconciliation reports from profile micro
+// @Summary Query counter reconciliations
+// @Description Get counter reconciliation reports from newest to oldest
+// @Tags counters
+// @Produce json
+// @Param page query int false "Page number"
+// @Success 200 {array} object "Counter reconciliation list"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /counters/reconciliations [get]
+func QueryCounterReconciliationsHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[QueryCounterReconciliationsHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	query := url.Values{}
+	query.Set("page", c.Query("page", "1"))
+	reconciliationURL := "/profile/dto/counters/reconciliations?" + query.Encode()
+	counterReconciliationList, callErr := functionCallByHeader(http.MethodGet, []byte(""), reconciliationURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", reconciliationURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/queryCounterReconciliations", "Error happened while getting counter reconciliations!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(counterReconciliationList)
+}
+
+// ReadCounterReconciliationHandler gets a counter reconciliation report from profile micro
+// @Summary Get counter reconciliation
+// @Description Get counter reconciliation report with its discrepancies
+// @Tags counters
+// @Produce json
+// @Param reconciliationId path string true "Counter reconciliation ID"
+// @Success 200 {object} object "Counter reconciliation"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /counters/reconciliations/{reconciliationId} [get]
+func ReadCounterReconciliationHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[ReadCounterReconciliationHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	reconciliationURL := "/profile/dto/counters/reconciliations/" + url.PathEscape(c.Params("reconciliationId"))
+	counterReconciliation, callErr := functionCallByHeader(http.MethodGet, []byte(""), reconciliationURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", reconciliationURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/readCounterReconciliation", "Error happened while getting counter reconciliation!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(counterReconciliation)
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+)
+
+// CreateInvitationHandler creates an invitation code on auth micro
+// @Summary Create invitation code
+// @Description Create an invitation code for invite-only signup. Zero maxUses or expiresIn means unlimited.
+// @Tags invitation
+// @Accept json
+// @Produce json
+// @Param body body object{maxUses=int,expiresIn=int} true "Invitation options"
+// @Success 200 {object} object "Created invitation"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /invitations [post]
+func CreateInvitationHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[CreateInvitationHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	invitationURL := "/auth/admin/invitations"
+	invitation, callErr := functionCallByHeader(http.MethodPost, c.Body(), invitationURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", invitationURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/createInvitation", "Error happened while creating invitation!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(invitation)
+}
+
+// QueryInvitationsHandler gets invitation codes from auth micro
+// @Summary Query invitation codes
+// @Description Get all invitation codes with usage and invited users
+// @Tags invitation
+// @Produce json
+// @Param page query int false "Page number"
+// @Success 200 {array} object "Invitation list"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /invitations [get]
+func QueryInvitationsHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[QueryInvitationsHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	invitationURL := "/auth/admin/invitations?page=" + c.Query("page", "1")
+	invitationList, callErr := functionCallByHeader(http.MethodGet, []byte(""), invitationURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", invitationURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/queryInvitations", "Error happened while getting invitations!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(invitationList)
+}
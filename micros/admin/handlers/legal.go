@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+)
+
+// CreateLegalDocumentHandler publishes a legal document version on auth micro
+// @Summary Publish legal document
+// @Description Publish a new version of terms of service or privacy policy. Users must accept it on their next login.
+// @Tags legal
+// @Accept json
+// @Produce json
+// @Param body body object{type=string,version=string,title=string,url=string,content=string,publishedDate=int} true "Legal document"
+// @Success 200 {object} object "Published legal document"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /legal [post]
+func CreateLegalDocumentHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[CreateLegalDocumentHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	legalURL := "/auth/admin/legal"
+	legalDocument, callErr := functionCallByHeader(http.MethodPost, c.Body(), legalURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", legalURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/createLegalDocument", "Error happened while publishing legal document!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(legalDocument)
+}
+
+// QueryLegalDocumentsHandler gets legal document versions from auth micro
+// @Summary Query legal documents
+// @Description Get all published and scheduled versions of legal documents
+// @Tags legal
+// @Produce json
+// @Param type query string false "Document type (terms or privacy)"
+// @Param page query int false "Page number"
+// @Success 200 {array} object "Legal document list"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /legal [get]
+func QueryLegalDocumentsHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[QueryLegalDocumentsHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	query := url.Values{}
+	query.Set("type", c.Query("type"))
+	query.Set("page", c.Query("page", "1"))
+	legalURL := "/auth/admin/legal?" + query.Encode()
+	legalDocumentList, callErr := functionCallByHeader(http.MethodGet, []byte(""), legalURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", legalURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/queryLegalDocuments", "Error happened while getting legal documents!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(legalDocumentList)
+}
+
+// ExportLegalConsentsHandler exports user consents from auth micro for auditors
+// @Summary Export user consents
+// @Description Export which version of legal documents each user accepted and when
+// @Tags legal
+// @Produce text/csv
+// @Produce json
+// @Param type query string false "Document type (terms or privacy)"
+// @Param version query string false "Document version"
+// @Param format query string false "csv (default) or json"
+// @Success 200 {string} string "User consents"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /legal/consents [get]
+func ExportLegalConsentsHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[ExportLegalConsentsHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	query := url.Values{}
+	query.Set("type", c.Query("type"))
+	query.Set("version", c.Query("version"))
+	query.Set("format", c.Query("format"))
+	legalURL := "/auth/admin/legal/consents?" + query.Encode()
+	userConsents, callErr := functionCallByHeader(http.MethodGet, []byte(""), legalURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", legalURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/exportLegalConsents", "Error happened while exporting user consents!"))
+	}
+
+	if c.Query("format") == "json" {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		return c.Send(userConsents)
+	}
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="consents.csv"`)
+	return c.Send(userConsents)
+}
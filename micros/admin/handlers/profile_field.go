@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+)
+
+// CreateProfileFieldHandler defines a custom profile field on profile micro
+// @Summary Create custom profile field
+// @Description Define a field users fill in their profile with its type, validation, visibility and whether signup asks for it
+// @Tags profile-fields
+// @Accept json
+// @Produce json
+// @Param body body object true "Profile field"
+// @Success 200 {object} object "Profile field"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /profile-fields [post]
+func CreateProfileFieldHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[CreateProfileFieldHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	profileFieldURL := "/profile/dto/fields"
+	profileField, callErr := functionCallByHeader(http.MethodPost, c.Body(), profileFieldURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", profileFieldURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/createProfileField", "Error happened while creating profile field!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(profileField)
+}
+
+// QueryProfileFieldsHandler gets custom profile fields from profile micro
+// @Summary Query custom profile fields
+// @Description Get the custom profile fields by order
+// @Tags profile-fields
+// @Produce json
+// @Success 200 {array} object "Profile field list"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /profile-fields [get]
+func QueryProfileFieldsHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[QueryProfileFieldsHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	profileFieldURL := "/profile/fields"
+	profileFieldList, callErr := functionCallByHeader(http.MethodGet, []byte(""), profileFieldURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", profileFieldURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/queryProfileFields", "Error happened while getting profile fields!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(profileFieldList)
+}
+
+// UpdateProfileFieldHandler updates a custom profile field on profile micro
+// @Summary Update custom profile field
+// @Description Update label, validation, visibility and signup requirement of a profile field. Name and type are kept.
+// @Tags profile-fields
+// @Accept json
+// @Produce json
+// @Param name path string true "Field name"
+// @Param body body object true "Profile field"
+// @Success 200 {object} object "Profile field"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /profile-fields/{name} [put]
+func UpdateProfileFieldHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[UpdateProfileFieldHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	profileFieldURL := "/profile/dto/fields/" + url.PathEscape(c.Params("name"))
+	profileField, callErr := functionCallByHeader(http.MethodPut, c.Body(), profileFieldURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", profileFieldURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/updateProfileField", "Error happened while updating profile field!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(profileField)
+}
+
+// DeleteProfileFieldHandler deletes a custom profile field on profile micro
+// @Summary Delete custom profile field
+// @Description Delete a profile field and its value from every profile
+// @Tags profile-fields
+// @Produce json
+// @Param name path string true "Field name"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /profile-fields/{name} [delete]
+func DeleteProfileFieldHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[DeleteProfileFieldHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	profileFieldURL := "/profile/dto/fields/" + url.PathEscape(c.Params("name"))
+	_, callErr := functionCallByHeader(http.MethodDelete, []byte(""), profileFieldURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", profileFieldURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/deleteProfileField", "Error happened while deleting profile field!"))
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
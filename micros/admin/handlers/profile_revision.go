@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+)
+
+// QueryProfileRevisionsHandler gets the profile edit history of a user from profile micro
+// @Summary Query profile history
+// @Description Get the recorded profile changes of a user from newest to oldest
+// @Tags profile
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param page query int false "Page number"
+// @Success 200 {array} object "Profile revision list"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /profile-revisions/{userId} [get]
+func QueryProfileRevisionsHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[QueryProfileRevisionsHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	query := url.Values{}
+	query.Set("page", c.Query("page", "1"))
+	revisionURL := "/profile/dto/revisions/" + url.PathEscape(c.Params("userId")) + "?" + query.Encode()
+	revisionList, callErr := functionCallByHeader(http.MethodGet, []byte(""), revisionURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", revisionURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/queryProfileRevisions", "Error happened while getting profile history!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(revisionList)
+}
+
+// RollbackProfileRevisionHandler rolls a user profile back to before a revision on profile micro
+// @Summary Roll back user profile
+// @Description Undo a profile revision and every later change of the same user
+// @Tags profile
+// @Produce json
+// @Param revisionId path string true "Profile revision ID"
+// @Success 200 {object} object "Rollback revision"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /profile-revisions/{revisionId}/rollback [post]
+func RollbackProfileRevisionHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[RollbackProfileRevisionHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	rollbackURL := "/profile/dto/revisions/" + url.PathEscape(c.Params("revisionId")) + "/rollback"
+	revision, callErr := functionCallByHeader(http.MethodPost, []byte(""), rollbackURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", rollbackURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/rollbackProfileRevision", "Error happened while rolling back profile!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(revision)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+)
+
+// CreateReservedNameHandler reserves a social name on profile micro
+// @Summary Reserve social name
+// @Description Add a social name to the reserved list so users can not pick it at signup or on profile update
+// @Tags reserved-names
+// @Accept json
+// @Produce json
+// @Param body body object{name=string,reason=string} true "Reserved name"
+// @Success 200 {object} object "Reserved name"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /reserved-names [post]
+func CreateReservedNameHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[CreateReservedNameHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	reservedNameURL := "/profile/dto/reserved-names"
+	reservedName, callErr := functionCallByHeader(http.MethodPost, c.Body(), reservedNameURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", reservedNameURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/createReservedName", "Error happened while reserving social name!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(reservedName)
+}
+
+// QueryReservedNamesHandler gets reserved social names from profile micro
+// @Summary Query reserved social names
+// @Description Get the admin-managed reserved social names
+// @Tags reserved-names
+// @Produce json
+// @Param search query string false "Name prefix"
+// @Param page query int false "Page number"
+// @Success 200 {array} object "Reserved name list"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /reserved-names [get]
+func QueryReservedNamesHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[QueryReservedNamesHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	query := url.Values{}
+	query.Set("search", c.Query("search"))
+	query.Set("page", c.Query("page", "1"))
+	reservedNameURL := "/profile/dto/reserved-names?" + query.Encode()
+	reservedNameList, callErr := functionCallByHeader(http.MethodGet, []byte(""), reservedNameURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", reservedNameURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/queryReservedNames", "Error happened while getting reserved names!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(reservedNameList)
+}
+
+// DeleteReservedNameHandler releases a reserved social name on profile micro
+// @Summary Delete reserved social name
+// @Description Remove a social name from the reserved list
+// @Tags reserved-names
+// @Produce json
+// @Param name path string true "Reserved name"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /reserved-names/{name} [delete]
+func DeleteReservedNameHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[DeleteReservedNameHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	reservedNameURL := "/profile/dto/reserved-names/" + url.PathEscape(c.Params("name"))
+	_, callErr := functionCallByHeader(http.MethodDelete, []byte(""), reservedNameURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", reservedNameURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/deleteReservedName", "Error happened while deleting reserved name!"))
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+)
+
+// ImportUsersHandler sends a CSV or JSON file of users to auth micro for import
+// @Summary Import users
+// @Description Create users from a CSV or JSON file and invite them by email to set a password.
+// @Description CSV needs email and fullName columns, socialName and "cf.<name>" custom field columns are optional.
+// @Description Set dryRun to only check the rows. Progress and row errors are read from the returned import.
+// @Tags users
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or JSON file of users"
+// @Param format formData string false "csv or json, taken from the file extension when empty"
+// @Param dryRun formData boolean false "Only check the rows"
+// @Success 200 {object} object "User import"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /users/import [post]
+func ImportUsersHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[ImportUsersHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		log.Error("[ImportUsersHandler] FormFile %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("importFileRequired", "Import file is required!"))
+	}
+	format := strings.ToLower(c.FormValue("format"))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+	}
+	if format != "csv" && format != "json" {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("importFormatInvalid", "Import format must be csv or json!"))
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Error("[ImportUsersHandler] Open file %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/openImportFile", "Error happened while reading import file!"))
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		log.Error("[ImportUsersHandler] Read file %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/readImportFile", "Error happened while reading import file!"))
+	}
+
+	dryRun, _ := strconv.ParseBool(c.FormValue("dryRun"))
+	importModel := map[string]interface{}{
+		"fileName": fileHeader.Filename,
+		"format":   format,
+		"content":  string(content),
+		"dryRun":   dryRun,
+	}
+	importBytes, err := json.Marshal(importModel)
+	if err != nil {
+		log.Error("[ImportUsersHandler] Marshal import model %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/marshalImportModel", "Error happened while reading import file!"))
+	}
+
+	importURL := "/auth/admin/users/import"
+	userImport, callErr := functionCallByHeader(http.MethodPost, importBytes, importURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", importURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/importUsers", "Error happened while importing users!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(userImport)
+}
+
+// QueryUserImportsHandler gets user imports from auth micro
+// @Summary Query user imports
+// @Description Get user imports with progress and counts from newest to oldest
+// @Tags users
+// @Produce json
+// @Param page query int false "Page number"
+// @Success 200 {array} object "User import list"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /users/imports [get]
+func QueryUserImportsHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[QueryUserImportsHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	importURL := "/auth/admin/users/imports?page=" + url.QueryEscape(c.Query("page", "1"))
+	userImportList, callErr := functionCallByHeader(http.MethodGet, []byte(""), importURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", importURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/queryUserImports", "Error happened while getting user imports!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(userImportList)
+}
+
+// ReadUserImportHandler gets a user import from auth micro
+// @Summary Get user import
+// @Description Get progress and counts of a user import
+// @Tags users
+// @Produce json
+// @Param importId path string true "User import ID"
+// @Success 200 {object} object "User import"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /users/imports/{importId} [get]
+func ReadUserImportHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[ReadUserImportHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	importURL := "/auth/admin/users/imports/" + url.PathEscape(c.Params("importId"))
+	userImport, callErr := functionCallByHeader(http.MethodGet, []byte(""), importURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", importURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/readUserImport", "Error happened while getting user import!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(userImport)
+}
+
+// QueryUserImportRowsHandler gets rows of a user import from auth micro
+// @Summary Query user import rows
+// @Description Get rows of a user import with their status and error in file order
+// @Tags users
+// @Produce json
+// @Param importId path string true "User import ID"
+// @Param status query string false "Row status: pending, valid, created or failed"
+// @Param page query int false "Page number"
+// @Success 200 {array} object "User import row list"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /users/imports/{importId}/rows [get]
+func QueryUserImportRowsHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[QueryUserImportRowsHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	query := url.Values{}
+	query.Set("page", c.Query("page", "1"))
+	if status := c.Query("status"); status != "" {
+		query.Set("status", status)
+	}
+	importURL := "/auth/admin/users/imports/" + url.PathEscape(c.Params("importId")) + "/rows?" + query.Encode()
+	userImportRowList, callErr := functionCallByHeader(http.MethodGet, []byte(""), importURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", importURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/queryUserImportRows", "Error happened while getting user import rows!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(userImportRowList)
+}
+
+// ResumeUserImportHandler resumes a failed or stopped user import on auth micro
+// @Summary Resume user import
+// @Description Go on with a failed or stopped user import from the first row which is not processed
+// @Tags users
+// @Produce json
+// @Param importId path string true "User import ID"
+// @Success 200 {object} object "User import"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /users/imports/{importId}/resume [post]
+func ResumeUserImportHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[ResumeUserImportHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	importURL := "/auth/admin/users/imports/" + url.PathEscape(c.Params("importId")) + "/resume"
+	userImport, callErr := functionCallByHeader(http.MethodPost, []byte(""), importURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", importURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/resumeUserImport", "Error happened while resuming user import!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(userImport)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+)
+
+// GrantVerificationHandler marks a user as verified on profile micro
+// @Summary Grant verified badge
+// @Description Mark an official or notable account as verified
+// @Tags verification
+// @Accept json
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param body body object{type=string} true "Verification type: official, notable or organization"
+// @Success 200 {object} object "Verification"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /verification/{userId} [put]
+func GrantVerificationHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[GrantVerificationHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	verificationURL := "/profile/dto/verification/" + url.PathEscape(c.Params("userId"))
+	verification, callErr := functionCallByHeader(http.MethodPut, c.Body(), verificationURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", verificationURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/grantVerification", "Error happened while granting verification!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(verification)
+}
+
+// RevokeVerificationHandler removes verified badge of a user on profile micro
+// @Summary Revoke verified badge
+// @Description Remove verified badge of a user
+// @Tags verification
+// @Produce json
+// @Param userId path string true "User ID"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /verification/{userId} [delete]
+func RevokeVerificationHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[RevokeVerificationHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	verificationURL := "/profile/dto/verification/" + url.PathEscape(c.Params("userId"))
+	_, callErr := functionCallByHeader(http.MethodDelete, []byte(""), verificationURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", verificationURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/revokeVerification", "Error happened while revoking verification!"))
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
+
+// QueryVerificationRequestsHandler gets the verification review queue from profile micro
+// @Summary Query verification requests
+// @Description Get verification requests by status, oldest first
+// @Tags verification
+// @Produce json
+// @Param status query string false "pending, approved or rejected. Default is pending"
+// @Param page query int false "Page number"
+// @Success 200 {array} object "Verification request list"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /verification-requests [get]
+func QueryVerificationRequestsHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[QueryVerificationRequestsHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	query := url.Values{}
+	query.Set("status", c.Query("status", "pending"))
+	query.Set("page", c.Query("page", "1"))
+	verificationURL := "/profile/dto/verification-requests?" + query.Encode()
+	verificationRequestList, callErr := functionCallByHeader(http.MethodGet, []byte(""), verificationURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", verificationURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/queryVerificationRequests", "Error happened while getting verification requests!"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(verificationRequestList)
+}
+
+// ReviewVerificationRequestHandler approves or rejects a verification request on profile micro
+// @Summary Review verification request
+// @Description Approve or reject a pending verification request. Approving grants the verified badge.
+// @Tags verification
+// @Accept json
+// @Produce json
+// @Param requestId path string true "Verification request ID"
+// @Param body body object{status=string,type=string,note=string} true "Review"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /verification-requests/{requestId} [put]
+func ReviewVerificationRequestHandler(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok {
+		log.Error("[ReviewVerificationRequestHandler] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	verificationURL := "/profile/dto/verification-requests/" + url.PathEscape(c.Params("requestId"))
+	_, callErr := functionCallByHeader(http.MethodPut, c.Body(), verificationURL, getAdminHeaders(currentUser))
+	if callErr != nil {
+		log.Error("[functionCallByHeader] %s - %s", verificationURL, callErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/reviewVerificationRequest", "Error happened while reviewing verification request!"))
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
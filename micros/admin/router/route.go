@@ -34,6 +34,32 @@ func SetupRoutes(app *fiber.App) {
 	// Router
 	app.Post("/setup", authCookieMiddleware, authRoleMiddleware, handlers.SetupHandler)
 	app.Get("/setup", authCookieMiddleware, authRoleMiddleware, handlers.SetupPageHandler)
+	app.Post("/invitations", authCookieMiddleware, authRoleMiddleware, handlers.CreateInvitationHandler)
+	app.Get("/invitations", authCookieMiddleware, authRoleMiddleware, handlers.QueryInvitationsHandler)
+	app.Post("/legal", authCookieMiddleware, authRoleMiddleware, handlers.CreateLegalDocumentHandler)
+	app.Get("/legal", authCookieMiddleware, authRoleMiddleware, handlers.QueryLegalDocumentsHandler)
+	app.Get("/legal/consents", authCookieMiddleware, authRoleMiddleware, handlers.ExportLegalConsentsHandler)
+	app.Post("/reserved-names", authCookieMiddleware, authRoleMiddleware, handlers.CreateReservedNameHandler)
+	app.Get("/reserved-names", authCookieMiddleware, authRoleMiddleware, handlers.QueryReservedNamesHandler)
+	app.Delete("/reserved-names/:name", authCookieMiddleware, authRoleMiddleware, handlers.DeleteReservedNameHandler)
+	app.Put("/verification/:userId", authCookieMiddleware, authRoleMiddleware, handlers.GrantVerificationHandler)
+	app.Delete("/verification/:userId", authCookieMiddleware, authRoleMiddleware, handlers.RevokeVerificationHandler)
+	app.Get("/verification-requests", authCookieMiddleware, authRoleMiddleware, handlers.QueryVerificationRequestsHandler)
+	app.Put("/verification-requests/:requestId", authCookieMiddleware, authRoleMiddleware, handlers.ReviewVerificationRequestHandler)
+	app.Get("/profile-revisions/:userId", authCookieMiddleware, authRoleMiddleware, handlers.QueryProfileRevisionsHandler)
+	app.Post("/profile-revisions/:revisionId/rollback", authCookieMiddleware, authRoleMiddleware, handlers.RollbackProfileRevisionHandler)
+	app.Post("/counters/reconcile", authCookieMiddleware, authRoleMiddleware, handlers.ReconcileCountersHandler)
+	app.Get("/counters/reconciliations", authCookieMiddleware, authRoleMiddleware, handlers.QueryCounterReconciliationsHandler)
+	app.Get("/counters/reconciliations/:reconciliationId", authCookieMiddleware, authRoleMiddleware, handlers.ReadCounterReconciliationHandler)
+	app.Post("/profile-fields", authCookieMiddleware, authRoleMiddleware, handlers.CreateProfileFieldHandler)
+	app.Get("/profile-fields", authCookieMiddleware, authRoleMiddleware, handlers.QueryProfileFieldsHandler)
+	app.Put("/profile-fields/:name", authCookieMiddleware, authRoleMiddleware, handlers.UpdateProfileFieldHandler)
+	app.Delete("/profile-fields/:name", authCookieMiddleware, authRoleMiddleware, handlers.DeleteProfileFieldHandler)
+	app.Post("/users/import", authCookieMiddleware, authRoleMiddleware, handlers.ImportUsersHandler)
+	app.Get("/users/imports", authCookieMiddleware, authRoleMiddleware, handlers.QueryUserImportsHandler)
+	app.Get("/users/imports/:importId", authCookieMiddleware, authRoleMiddleware, handlers.ReadUserImportHandler)
+	app.Get("/users/imports/:importId/rows", authCookieMiddleware, authRoleMiddleware, handlers.QueryUserImportRowsHandler)
+	app.Post("/users/imports/:importId/resume", authCookieMiddleware, authRoleMiddleware, handlers.ResumeUserImportHandler)
 	app.Get("/login", handlers.LoginPageHandler)
 	app.Post("/login", handlers.LoginAdminHandler)
 }
@@ -15,12 +15,50 @@ type (
 		ExternalRedirectDomain string
 		AuthWebURI             string
 		WebURL                 string
+		PlaceholderImageURL    string
 		Scope                  string
 		CookieRootDomain       string
 		CookieExpiresIn        time.Duration
 		BaseRoute              string
 		VerifyType             string
 		QueryPrettyURL         bool
+		SignupMode             string
+		InviteMaxUses          int64
+		InviteExpiresIn        time.Duration
+		ImportInviteExpiresIn  time.Duration
+		SessionMaxAge          time.Duration
+		AccessTokenExpiresIn   time.Duration
+		EmailDomainAllowlist   []string
+		EmailDomainDenylist    []string
+		BlockDisposableEmail   bool
+		DisposableDomainsFile  string
+		StrictVerifyIP         bool
+		SAMLEnabled            bool
+		SAMLEntityID           string
+		SAMLIdPMetadataURL     string
+		SAMLIdPMetadataFile    string
+		SAMLSPKey              string
+		SAMLSPCertificate      string
+		SAMLAllowIDPInitiated  bool
+		SAMLEmailAttribute     string
+		SAMLNameAttribute      string
+		SAMLAvatarAttribute    string
+		SAMLGroupsAttribute    string
+		AllowedOrganizations   []string
+		OrganizationRoles      map[string]string
+		AuthBackends           []string
+		LDAPURL                string
+		LDAPStartTLS           bool
+		LDAPBindDN             string
+		LDAPBindPassword       string
+		LDAPBaseDN             string
+		LDAPUserFilter         string
+		LDAPEmailAttribute     string
+		LDAPNameAttribute      string
+		LDAPGroupAttribute     string
+		LDAPGroupRoles         map[string]string
+		DefaultLang            string
+		LocalesDir             string
 		Debug                  bool // Debug enables verbose logging of claims / cookies
 	}
 )
@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	coreUtils "github.com/red-gold/telar-core/utils"
@@ -16,9 +17,19 @@ const (
 	oauthClientSecretKey   = "ts-client-secret"
 	adminUsernameSecretKey = "admin-username"
 	adminPasswordSecretKey = "admin-password"
+	samlSPKeySecretKey     = "saml-sp-key"
+	samlSPCertSecretKey    = "saml-sp-cert"
+	ldapBindPassSecretKey  = "ldap-bind-password"
+
+	defaultDisposableDomainsFile = "./config/disposable_domains.txt"
+	defaultImportInviteExpiry    = 7 * 24 * time.Hour
+	defaultSessionMaxAge         = 30 * 24 * time.Hour
+	defaultAccessTokenExpiry     = 48 * time.Hour
+	defaultLang                  = "en"
+	defaultLocalesDir            = "./locales"
 )
 
-var secretKeys = []string{oauthClientSecretKey}
+var secretKeys = []string{oauthClientSecretKey, samlSPKeySecretKey, samlSPCertSecretKey, ldapBindPassSecretKey}
 
 // Initialize AppConfig
 func InitConfig() {
@@ -98,6 +109,14 @@ func loadAllConfig() {
 		log.Printf("[INFO]: Web URL information loaded from env [%s] ", WebURL)
 	}
 
+	// Default avatars and banners are drawn by storage micro behind the same gateway as web
+	AuthConfig.PlaceholderImageURL = strings.TrimSuffix(AuthConfig.WebURL, "/") + "/storage"
+	placeholderImageURL, ok := os.LookupEnv("placeholder_image_url")
+	if ok {
+		AuthConfig.PlaceholderImageURL = strings.TrimSuffix(placeholderImageURL, "/")
+		log.Printf("[INFO]: Placeholder image URL information loaded from env [%s] ", placeholderImageURL)
+	}
+
 	scope, ok := os.LookupEnv("oauth_scope")
 	if ok {
 		AuthConfig.Scope = scope
@@ -134,6 +153,270 @@ func loadAllConfig() {
 		log.Printf("[INFO]: Base route information loaded from env.")
 	}
 
+	signupMode, ok := os.LookupEnv("signup_mode")
+	if ok {
+		AuthConfig.SignupMode = signupMode
+		log.Printf("[INFO]: Signup mode information loaded from env [%s] ", signupMode)
+	}
+
+	inviteMaxUses, ok := os.LookupEnv("invite_max_uses")
+	if ok {
+		parsedInviteMaxUses, parseErr := strconv.ParseInt(inviteMaxUses, 10, 64)
+		if parseErr != nil {
+			log.Printf("[ERROR]: Invite max uses information loading error: %s", parseErr.Error())
+		} else {
+			AuthConfig.InviteMaxUses = parsedInviteMaxUses
+			log.Printf("[INFO]: Invite max uses information loaded from env.")
+		}
+	}
+
+	inviteExpiry, ok := os.LookupEnv("invite_expiry")
+	if ok {
+		expireTime, atoiErr := strconv.Atoi(inviteExpiry)
+		if atoiErr != nil {
+			log.Printf("[Error]: Invite expiry information loading error: %s.", atoiErr.Error())
+		} else {
+			AuthConfig.InviteExpiresIn = time.Hour * time.Duration(expireTime)
+			log.Printf("[INFO]: Invite expiry information loaded from env.")
+		}
+	}
+
+	AuthConfig.ImportInviteExpiresIn = defaultImportInviteExpiry
+	importInviteExpiry, ok := os.LookupEnv("import_invite_expiry")
+	if ok {
+		expireTime, atoiErr := strconv.Atoi(importInviteExpiry)
+		if atoiErr != nil {
+			log.Printf("[Error]: Import invite expiry information loading error: %s.", atoiErr.Error())
+		} else {
+			AuthConfig.ImportInviteExpiresIn = time.Hour * time.Duration(expireTime)
+			log.Printf("[INFO]: Import invite expiry information loaded from env.")
+		}
+	}
+
+	// Refreshed sessions can not outlive the sign in by more than max age
+	AuthConfig.SessionMaxAge = defaultSessionMaxAge
+	sessionMaxAge, ok := os.LookupEnv("session_max_age")
+	if ok {
+		maxAge, atoiErr := strconv.Atoi(sessionMaxAge)
+		if atoiErr != nil {
+			log.Printf("[Error]: Session max age information loading error: %s.", atoiErr.Error())
+		} else {
+			AuthConfig.SessionMaxAge = time.Hour * time.Duration(maxAge)
+			log.Printf("[INFO]: Session max age information loaded from env.")
+		}
+	}
+
+	// Shorter access token expiry is opt-in, revoked sessions end once the token expires and refresh is rejected
+	AuthConfig.AccessTokenExpiresIn = defaultAccessTokenExpiry
+	accessTokenExpiry, ok := os.LookupEnv("access_token_expiry")
+	if ok {
+		expiryMinutes, atoiErr := strconv.Atoi(accessTokenExpiry)
+		if atoiErr != nil {
+			log.Printf("[Error]: Access token expiry information loading error: %s.", atoiErr.Error())
+		} else {
+			AuthConfig.AccessTokenExpiresIn = time.Minute * time.Duration(expiryMinutes)
+			log.Printf("[INFO]: Access token expiry information loaded from env.")
+		}
+	}
+
+	emailDomainAllowlist, ok := os.LookupEnv("email_domain_allowlist")
+	if ok {
+		AuthConfig.EmailDomainAllowlist = splitConfigList(emailDomainAllowlist)
+		log.Printf("[INFO]: Email domain allowlist information loaded from env.")
+	}
+
+	emailDomainDenylist, ok := os.LookupEnv("email_domain_denylist")
+	if ok {
+		AuthConfig.EmailDomainDenylist = splitConfigList(emailDomainDenylist)
+		log.Printf("[INFO]: Email domain denylist information loaded from env.")
+	}
+
+	blockDisposableEmail, ok := os.LookupEnv("block_disposable_email")
+	if ok {
+		parsedBlockDisposableEmail, parseErr := strconv.ParseBool(blockDisposableEmail)
+		if parseErr != nil {
+			log.Printf("[ERROR]: Block disposable email information loading error: %s", parseErr.Error())
+		}
+		AuthConfig.BlockDisposableEmail = parsedBlockDisposableEmail
+		log.Printf("[INFO]: Block disposable email information loaded from env.")
+	}
+
+	AuthConfig.DisposableDomainsFile = defaultDisposableDomainsFile
+	disposableDomainsFile, ok := os.LookupEnv("disposable_domains_file")
+	if ok {
+		AuthConfig.DisposableDomainsFile = disposableDomainsFile
+		log.Printf("[INFO]: Disposable domains file information loaded from env.")
+	}
+
+	strictVerifyIP, ok := os.LookupEnv("strict_verify_ip")
+	if ok {
+		parsedStrictVerifyIP, parseErr := strconv.ParseBool(strictVerifyIP)
+		if parseErr != nil {
+			log.Printf("[ERROR]: Strict verify ip information loading error: %s", parseErr.Error())
+		}
+		AuthConfig.StrictVerifyIP = parsedStrictVerifyIP
+		log.Printf("[INFO]: Strict verify ip information loaded from env.")
+	}
+
+	samlEnabled, ok := os.LookupEnv("saml_enabled")
+	if ok {
+		parsedSAMLEnabled, parseErr := strconv.ParseBool(samlEnabled)
+		if parseErr != nil {
+			log.Printf("[ERROR]: SAML enabled information loading error: %s", parseErr.Error())
+		}
+		AuthConfig.SAMLEnabled = parsedSAMLEnabled
+		log.Printf("[INFO]: SAML enabled information loaded from env.")
+	}
+
+	samlEntityID, ok := os.LookupEnv("saml_entity_id")
+	if ok {
+		AuthConfig.SAMLEntityID = samlEntityID
+		log.Printf("[INFO]: SAML entity id information loaded from env.")
+	}
+
+	samlIdPMetadataURL, ok := os.LookupEnv("saml_idp_metadata_url")
+	if ok {
+		AuthConfig.SAMLIdPMetadataURL = samlIdPMetadataURL
+		log.Printf("[INFO]: SAML IdP metadata url information loaded from env.")
+	}
+
+	samlIdPMetadataFile, ok := os.LookupEnv("saml_idp_metadata_file")
+	if ok {
+		AuthConfig.SAMLIdPMetadataFile = samlIdPMetadataFile
+		log.Printf("[INFO]: SAML IdP metadata file information loaded from env.")
+	}
+
+	samlAllowIDPInitiated, ok := os.LookupEnv("saml_allow_idp_initiated")
+	if ok {
+		parsedAllowIDPInitiated, parseErr := strconv.ParseBool(samlAllowIDPInitiated)
+		if parseErr != nil {
+			log.Printf("[ERROR]: SAML allow IdP initiated information loading error: %s", parseErr.Error())
+		}
+		AuthConfig.SAMLAllowIDPInitiated = parsedAllowIDPInitiated
+		log.Printf("[INFO]: SAML allow IdP initiated information loaded from env.")
+	}
+
+	AuthConfig.SAMLEmailAttribute = "email"
+	samlEmailAttribute, ok := os.LookupEnv("saml_attr_email")
+	if ok {
+		AuthConfig.SAMLEmailAttribute = samlEmailAttribute
+		log.Printf("[INFO]: SAML email attribute information loaded from env.")
+	}
+
+	AuthConfig.SAMLNameAttribute = "displayName"
+	samlNameAttribute, ok := os.LookupEnv("saml_attr_name")
+	if ok {
+		AuthConfig.SAMLNameAttribute = samlNameAttribute
+		log.Printf("[INFO]: SAML name attribute information loaded from env.")
+	}
+
+	samlAvatarAttribute, ok := os.LookupEnv("saml_attr_avatar")
+	if ok {
+		AuthConfig.SAMLAvatarAttribute = samlAvatarAttribute
+		log.Printf("[INFO]: SAML avatar attribute information loaded from env.")
+	}
+
+	samlGroupsAttribute, ok := os.LookupEnv("saml_attr_groups")
+	if ok {
+		AuthConfig.SAMLGroupsAttribute = samlGroupsAttribute
+		log.Printf("[INFO]: SAML groups attribute information loaded from env.")
+	}
+
+	allowedOrganizations, ok := os.LookupEnv("oauth_allowed_orgs")
+	if ok {
+		AuthConfig.AllowedOrganizations = splitConfigList(allowedOrganizations)
+		log.Printf("[INFO]: OAuth allowed organizations information loaded from env.")
+	}
+
+	organizationRoles, ok := os.LookupEnv("oauth_org_roles")
+	if ok {
+		AuthConfig.OrganizationRoles = parseGroupRoles(organizationRoles)
+		log.Printf("[INFO]: OAuth organization roles information loaded from env.")
+	}
+
+	AuthConfig.AuthBackends = []string{"mongo"}
+	authBackends, ok := os.LookupEnv("auth_backends")
+	if ok {
+		AuthConfig.AuthBackends = splitConfigList(authBackends)
+		log.Printf("[INFO]: Auth backends information loaded from env [%s] ", authBackends)
+	}
+
+	ldapURL, ok := os.LookupEnv("ldap_url")
+	if ok {
+		AuthConfig.LDAPURL = ldapURL
+		log.Printf("[INFO]: LDAP url information loaded from env.")
+	}
+
+	ldapStartTLS, ok := os.LookupEnv("ldap_start_tls")
+	if ok {
+		parsedLDAPStartTLS, parseErr := strconv.ParseBool(ldapStartTLS)
+		if parseErr != nil {
+			log.Printf("[ERROR]: LDAP start TLS information loading error: %s", parseErr.Error())
+		}
+		AuthConfig.LDAPStartTLS = parsedLDAPStartTLS
+		log.Printf("[INFO]: LDAP start TLS information loaded from env.")
+	}
+
+	ldapBindDN, ok := os.LookupEnv("ldap_bind_dn")
+	if ok {
+		AuthConfig.LDAPBindDN = ldapBindDN
+		log.Printf("[INFO]: LDAP bind DN information loaded from env.")
+	}
+
+	ldapBaseDN, ok := os.LookupEnv("ldap_base_dn")
+	if ok {
+		AuthConfig.LDAPBaseDN = ldapBaseDN
+		log.Printf("[INFO]: LDAP base DN information loaded from env.")
+	}
+
+	AuthConfig.LDAPUserFilter = "(&(objectClass=person)(|(uid=%s)(mail=%s)))"
+	ldapUserFilter, ok := os.LookupEnv("ldap_user_filter")
+	if ok {
+		AuthConfig.LDAPUserFilter = ldapUserFilter
+		log.Printf("[INFO]: LDAP user filter information loaded from env.")
+	}
+
+	AuthConfig.LDAPEmailAttribute = "mail"
+	ldapEmailAttribute, ok := os.LookupEnv("ldap_attr_email")
+	if ok {
+		AuthConfig.LDAPEmailAttribute = ldapEmailAttribute
+		log.Printf("[INFO]: LDAP email attribute information loaded from env.")
+	}
+
+	AuthConfig.LDAPNameAttribute = "cn"
+	ldapNameAttribute, ok := os.LookupEnv("ldap_attr_name")
+	if ok {
+		AuthConfig.LDAPNameAttribute = ldapNameAttribute
+		log.Printf("[INFO]: LDAP name attribute information loaded from env.")
+	}
+
+	AuthConfig.LDAPGroupAttribute = "memberOf"
+	ldapGroupAttribute, ok := os.LookupEnv("ldap_attr_group")
+	if ok {
+		AuthConfig.LDAPGroupAttribute = ldapGroupAttribute
+		log.Printf("[INFO]: LDAP group attribute information loaded from env.")
+	}
+
+	ldapGroupRoles, ok := os.LookupEnv("ldap_group_roles")
+	if ok {
+		AuthConfig.LDAPGroupRoles = parseGroupRoles(ldapGroupRoles)
+		log.Printf("[INFO]: LDAP group roles information loaded from env.")
+	}
+
+	AuthConfig.DefaultLang = defaultLang
+	lang, ok := os.LookupEnv("default_lang")
+	if ok && lang != "" {
+		AuthConfig.DefaultLang = lang
+		log.Printf("[INFO]: Default language information loaded from env.")
+	}
+
+	AuthConfig.LocalesDir = defaultLocalesDir
+	localesDir, ok := os.LookupEnv("locales_dir")
+	if ok {
+		AuthConfig.LocalesDir = localesDir
+		log.Printf("[INFO]: Locales directory information loaded from env.")
+	}
+
 	debug, ok := os.LookupEnv("write_debug")
 	if ok {
 		parsedDebug, errParseDebug := strconv.ParseBool(debug)
@@ -145,6 +428,36 @@ func loadAllConfig() {
 	}
 }
 
+// splitConfigList split comma separated values to lower case list
+func splitConfigList(value string) []string {
+	var domains []string
+	for _, domain := range strings.Split(value, ",") {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+// parseGroupRoles parse `group:role` pairs separated by semicolon.
+// Group may be a DN so the role is taken after the last colon.
+func parseGroupRoles(value string) map[string]string {
+	groupRoles := make(map[string]string)
+	for _, pair := range strings.Split(value, ";") {
+		sep := strings.LastIndex(pair, ":")
+		if sep < 1 {
+			continue
+		}
+		group := strings.ToLower(strings.TrimSpace(pair[:sep]))
+		role := strings.TrimSpace(pair[sep+1:])
+		if group != "" && role != "" {
+			groupRoles[group] = role
+		}
+	}
+	return groupRoles
+}
+
 // loadSecretsFromFile Load secrets from file
 func loadSecretsFromFile() {
 	filesConfig := getAllConfigFromFile()
@@ -167,6 +480,21 @@ func loadSecretsFromFile() {
 		log.Printf("[INFO]: Admin password information loaded from env.")
 	}
 
+	if filesConfig[basePath+samlSPKeySecretKey] != nil {
+		AuthConfig.SAMLSPKey = string(filesConfig[basePath+samlSPKeySecretKey])
+		log.Printf("[INFO]: SAML SP key information loaded from file.")
+	}
+
+	if filesConfig[basePath+samlSPCertSecretKey] != nil {
+		AuthConfig.SAMLSPCertificate = string(filesConfig[basePath+samlSPCertSecretKey])
+		log.Printf("[INFO]: SAML SP certificate information loaded from file.")
+	}
+
+	if filesConfig[basePath+ldapBindPassSecretKey] != nil {
+		AuthConfig.LDAPBindPassword = strings.TrimSpace(string(filesConfig[basePath+ldapBindPassSecretKey]))
+		log.Printf("[INFO]: LDAP bind password information loaded from file.")
+	}
+
 }
 
 // loadSecretsFromEnv Load secrets from environment variables
@@ -192,6 +520,24 @@ func loadSecretsFromEnv() {
 		AuthConfig.AdminPassword = adminPassword
 		log.Printf("[INFO]: Admin password information loaded from env.")
 	}
+
+	samlSPKey, ok := os.LookupEnv("saml_sp_key")
+	if ok {
+		AuthConfig.SAMLSPKey = decodeBase64(samlSPKey)
+		log.Printf("[INFO]: SAML SP key information loaded from env.")
+	}
+
+	samlSPCert, ok := os.LookupEnv("saml_sp_cert")
+	if ok {
+		AuthConfig.SAMLSPCertificate = decodeBase64(samlSPCert)
+		log.Printf("[INFO]: SAML SP certificate information loaded from env.")
+	}
+
+	ldapBindPassword, ok := os.LookupEnv("ldap_bind_password")
+	if ok {
+		AuthConfig.LDAPBindPassword = decodeBase64(ldapBindPassword)
+		log.Printf("[INFO]: LDAP bind password information loaded from env.")
+	}
 }
 
 // decodeBase64 Decode base64 string
@@ -0,0 +1,18 @@
+package dto
+
+import (
+	uuid "github.com/gofrs/uuid"
+)
+
+type Invitation struct {
+	ObjectId    uuid.UUID   `json:"objectId" bson:"objectId"`
+	Code        string      `json:"code" bson:"code"`
+	OwnerUserId uuid.UUID   `json:"ownerUserId" bson:"ownerUserId"`
+	IsAdmin     bool        `json:"isAdmin" bson:"isAdmin"`
+	MaxUses     int64       `json:"maxUses" bson:"maxUses"`
+	UsedCount   int64       `json:"usedCount" bson:"usedCount"`
+	UsedBy      []uuid.UUID `json:"usedBy" bson:"usedBy"`
+	ExpiresAt   int64       `json:"expiresAt" bson:"expiresAt"`
+	CreatedDate int64       `json:"created_date" bson:"created_date"`
+	LastUpdated int64       `json:"last_updated" bson:"last_updated"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+import (
+	uuid "github.com/gofrs/uuid"
+)
+
+type LegalDocument struct {
+	ObjectId      uuid.UUID `json:"objectId" bson:"objectId"`
+	Type          string    `json:"type" bson:"type"`
+	Version       string    `json:"version" bson:"version"`
+	Title         string    `json:"title" bson:"title"`
+	URL           string    `json:"url" bson:"url"`
+	Content       string    `json:"content" bson:"content"`
+	PublishedDate int64     `json:"publishedDate" bson:"publishedDate"`
+	CreatedBy     uuid.UUID `json:"createdBy" bson:"createdBy"`
+	CreatedDate   int64     `json:"created_date" bson:"created_date"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import (
+	uuid "github.com/gofrs/uuid"
+)
+
+// PendingLogin keeps a login which waits for the user to accept new legal documents
+type PendingLogin struct {
+	ObjectId         uuid.UUID `json:"objectId" bson:"objectId"`
+	TokenHash        string    `json:"-" bson:"tokenHash"`
+	UserId           uuid.UUID `json:"userId" bson:"userId"`
+	ProviderName     string    `json:"providerName" bson:"providerName"`
+	AccessToken      string    `json:"-" bson:"accessToken"`
+	Organizations    string    `json:"organizations" bson:"organizations"`
+	OrganizationRole string    `json:"organizationRole" bson:"organizationRole"`
+	Login            string    `json:"login" bson:"login"`
+	State            string    `json:"state" bson:"state"`
+	Redirect         string    `json:"redirect" bson:"redirect"`
+	Lang             string    `json:"lang" bson:"lang"`
+	ExpiresAt        int64     `json:"expiresAt" bson:"expiresAt"`
+	CreatedDate      int64     `json:"created_date" bson:"created_date"`
+}
@@ -13,6 +13,7 @@ type UserAuth struct {
 	Role          string    `json:"role" bson:"role"`
 	PhoneVerified bool      `json:"phoneVerified" bson:"phoneVerified"`
 	TokenExpires  int64     `json:"token_expires" bson:"token_expires"`
+	RevokedAt     int64     `json:"revokedAt" bson:"revokedAt"`
 	CreatedDate   int64     `json:"created_date" bson:"created_date"`
 	LastUpdated   int64     `json:"last_updated" bson:"last_updated"`
 }
@@ -0,0 +1,16 @@
+package dto
+
+import (
+	uuid "github.com/gofrs/uuid"
+)
+
+type UserConsent struct {
+	ObjectId        uuid.UUID `json:"objectId" bson:"objectId"`
+	UserId          uuid.UUID `json:"userId" bson:"userId"`
+	DocumentId      uuid.UUID `json:"documentId" bson:"documentId"`
+	DocumentType    string    `json:"documentType" bson:"documentType"`
+	Version         string    `json:"version" bson:"version"`
+	AcceptedDate    int64     `json:"acceptedDate" bson:"acceptedDate"`
+	RemoteIpAddress string    `json:"remoteIpAddress" bson:"remoteIpAddress"`
+	UserAgent       string    `json:"userAgent" bson:"userAgent"`
+}
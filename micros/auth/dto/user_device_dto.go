@@ -0,0 +1,19 @@
+package dto
+
+import (
+	uuid "github.com/gofrs/uuid"
+)
+
+type UserDevice struct {
+	ObjectId      uuid.UUID `json:"objectId" bson:"objectId"`
+	UserId        uuid.UUID `json:"userId" bson:"userId"`
+	Fingerprint   string    `json:"fingerprint" bson:"fingerprint"`
+	UserAgent     string    `json:"userAgent" bson:"userAgent"`
+	IPRanges      []string  `json:"ipRanges" bson:"ipRanges"`
+	LastIPAddress string    `json:"lastIpAddress" bson:"lastIpAddress"`
+	LastSeen      int64     `json:"lastSeen" bson:"lastSeen"`
+	CreatedDate   int64     `json:"created_date" bson:"created_date"`
+	// AlertTokenHash is the hash of the "this wasn't me" token sent in the last alert about this device
+	AlertTokenHash string `json:"-" bson:"alertTokenHash,omitempty"`
+	AlertExpiresAt int64  `json:"-" bson:"alertExpiresAt,omitempty"`
+}
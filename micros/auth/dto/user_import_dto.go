@@ -0,0 +1,47 @@
+package dto
+
+import (
+	uuid "github.com/gofrs/uuid"
+)
+
+// UserImport is an admin import of users from a CSV or JSON file
+type UserImport struct {
+	ObjectId   uuid.UUID `json:"objectId" bson:"objectId"`
+	ImportedBy uuid.UUID `json:"importedBy" bson:"importedBy"`
+	FileName   string    `json:"fileName" bson:"fileName"`
+	Format     string    `json:"format" bson:"format"`
+	// DryRun only checks the rows. No user is created and no email is sent.
+	DryRun bool   `json:"dryRun" bson:"dryRun"`
+	Status string `json:"status" bson:"status"`
+	// Lang of the invitation emails
+	Lang          string `json:"lang" bson:"lang"`
+	TotalRows     int64  `json:"totalRows" bson:"totalRows"`
+	ProcessedRows int64  `json:"processedRows" bson:"processedRows"`
+	ValidCount    int64  `json:"validCount" bson:"validCount"`
+	CreatedCount  int64  `json:"createdCount" bson:"createdCount"`
+	FailedCount   int64  `json:"failedCount" bson:"failedCount"`
+	Error         string `json:"error,omitempty" bson:"error,omitempty"`
+	CreatedDate   int64  `json:"created_date" bson:"created_date"`
+	LastUpdated   int64  `json:"last_updated" bson:"last_updated"`
+	FinishedDate  int64  `json:"finishedDate,omitempty" bson:"finishedDate,omitempty"`
+}
+
+// UserImportRow is a user of an import file with its result
+type UserImportRow struct {
+	ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	ImportId uuid.UUID `json:"importId" bson:"importId"`
+	// Row is the position of the user in the file starting from one, CSV header is not counted
+	Row          int64                  `json:"row" bson:"row"`
+	Email        string                 `json:"email" bson:"email"`
+	FullName     string                 `json:"fullName" bson:"fullName"`
+	SocialName   string                 `json:"socialName,omitempty" bson:"socialName,omitempty"`
+	CustomFields map[string]interface{} `json:"customFields,omitempty" bson:"customFields,omitempty"`
+	Status       string                 `json:"status" bson:"status"`
+	// Step is the last finished step of creating the user so a resumed import goes on from there
+	Step         int       `json:"step" bson:"step"`
+	UserId       uuid.UUID `json:"userId" bson:"userId"`
+	ErrorCode    string    `json:"errorCode,omitempty" bson:"errorCode,omitempty"`
+	ErrorMessage string    `json:"errorMessage,omitempty" bson:"errorMessage,omitempty"`
+	CreatedDate  int64     `json:"created_date" bson:"created_date"`
+	LastUpdated  int64     `json:"last_updated" bson:"last_updated"`
+}
@@ -13,6 +13,7 @@ type UserVerification struct {
 	Counter         int64                 `json:"counter" bson:"counter"`
 	CreatedDate     int64                 `json:"created_date" bson:"created_date"`
 	RemoteIpAddress string                `json:"remoteIpAddress" bson:"remoteIpAddress"`
+	DeviceNonceHash string                `json:"deviceNonceHash" bson:"deviceNonceHash"`
 	UserId          uuid.UUID             `json:"userId" bson:"userId"`
 	IsVerified      bool                  `json:"isVerified" bson:"isVerified"`
 	LastUpdated     int64                 `json:"last_updated" bson:"last_updated"`
@@ -16,6 +16,7 @@ import (
 	micros "github.com/red-gold/telar-web/micros"
 	authConfig "github.com/red-gold/telar-web/micros/auth/config"
 	"github.com/red-gold/telar-web/micros/auth/database"
+	"github.com/red-gold/telar-web/micros/auth/i18n"
 	"github.com/red-gold/telar-web/micros/auth/router"
 )
 
@@ -29,9 +30,15 @@ func init() {
 	micros.InitConfig()
 	authConfig.InitConfig()
 
+	// Load message catalogs
+	if err := i18n.Load(authConfig.AuthConfig.LocalesDir, authConfig.AuthConfig.DefaultLang); err != nil {
+		log.Error("Error loading message catalogs: %s", err.Error())
+	}
+
 	// Initialize app
 	app = fiber.New(fiber.Config{
-		Views: html.New("./views", ".html"),
+		Views:             html.New("./views", ".html").AddFunc("T", i18n.T),
+		PassLocalsToViews: true,
 	})
 	app.Use(recover.New())
 	app.Use(requestid.New())
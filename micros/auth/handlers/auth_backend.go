@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/red-gold/telar-core/pkg/log"
+	utils "github.com/red-gold/telar-core/utils"
+	ac "github.com/red-gold/telar-web/micros/auth/config"
+	"github.com/red-gold/telar-web/micros/auth/database"
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+	models "github.com/red-gold/telar-web/micros/auth/models"
+	service "github.com/red-gold/telar-web/micros/auth/services"
+)
+
+const (
+	mongoAuthBackendName = "mongo"
+	ldapAuthBackendName  = "ldap"
+)
+
+// authBackend verifies user credentials and returns the user authentication record
+type authBackend interface {
+	name() string
+	authenticate(username, password string) (*dto.UserAuth, error)
+}
+
+// mongoAuthBackend checks the password stored in userAuth collection
+type mongoAuthBackend struct {
+	userAuthService service.UserAuthService
+}
+
+func (b mongoAuthBackend) name() string {
+	return mongoAuthBackendName
+}
+
+func (b mongoAuthBackend) authenticate(username, password string) (*dto.UserAuth, error) {
+	foundUser, err := b.userAuthService.FindByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	// Users provisioned by external providers have no local password
+	if foundUser == nil || len(foundUser.Password) == 0 {
+		return nil, models.UserAuthError{Code: models.UserAuthErrorUserNotFound}
+	}
+
+	if !foundUser.EmailVerified && !foundUser.PhoneVerified {
+		return nil, models.UserAuthError{Code: models.UserAuthErrorUserNotVerified}
+	}
+
+	compareErr := utils.CompareHash(foundUser.Password, []byte(password))
+	if compareErr != nil {
+		log.Error("Password doesn't match %s", compareErr.Error())
+		return nil, models.UserAuthError{Code: models.UserAuthErrorPasswordNotMatch}
+	}
+	return foundUser, nil
+}
+
+// getAuthBackends create authentication backends in the configured order
+func getAuthBackends() ([]authBackend, error) {
+	userAuthService, serviceErr := service.NewUserAuthService(database.Db)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	var backends []authBackend
+	for _, backendName := range ac.AuthConfig.AuthBackends {
+		switch backendName {
+		case mongoAuthBackendName:
+			backends = append(backends, mongoAuthBackend{userAuthService: userAuthService})
+		case ldapAuthBackendName:
+			backends = append(backends, ldapAuthBackend{config: &ac.AuthConfig, userAuthService: userAuthService})
+		default:
+			return nil, fmt.Errorf("authentication backend %s is not supported", backendName)
+		}
+	}
+	if len(backends) == 0 {
+		backends = append(backends, mongoAuthBackend{userAuthService: userAuthService})
+	}
+	return backends, nil
+}
+
+// authenticateUser try backends in order. The next backend is only tried when
+// the user is unknown to the current one, so a wrong password never falls through.
+func authenticateUser(username, password string) (*dto.UserAuth, error) {
+	backends, backendsErr := getAuthBackends()
+	if backendsErr != nil {
+		return nil, backendsErr
+	}
+
+	var lastErr error = models.UserAuthError{Code: models.UserAuthErrorUserNotFound}
+	for _, backend := range backends {
+		foundUser, authErr := backend.authenticate(username, password)
+		if authErr == nil {
+			return foundUser, nil
+		}
+		log.Error("[authenticateUser] %s backend: %s", backend.name(), authErr.Error())
+		lastErr = authErr
+		if userAuthErr, ok := authErr.(models.UserAuthError); !ok || userAuthErr.Code != models.UserAuthErrorUserNotFound {
+			return nil, authErr
+		}
+	}
+	return nil, lastErr
+}
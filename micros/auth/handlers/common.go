@@ -2,12 +2,12 @@ package handlers
 
 import (
 	"bytes"
+	"crypto/sha256"
 	b64 "encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"math/rand"
 	"net/http"
 	"net/url"
 	"strings"
@@ -57,6 +57,8 @@ type UserClaim struct {
 	Email         string `json:"email"`
 	CreatedDate   int64  `json:"createdDate"`
 	Role          string `json:"role"`
+	// VerificationType is the type of verified badge, empty when the user is not verified
+	VerificationType string `json:"verificationType,omitempty"`
 }
 
 type TokenModel struct {
@@ -66,6 +68,8 @@ type TokenModel struct {
 	organizationList string
 	profile          *provider.Profile
 	claim            UserClaim
+	// authTime is the sign in time of a refreshed session, zero for a new sign in
+	authTime int64
 }
 
 type CreateActionRoomModel struct {
@@ -91,6 +95,9 @@ type TelarSocailClaims struct {
 	// User information
 	Claim UserClaim `json:"claim"`
 
+	// AuthTime is when the user signed in. Refreshed sessions keep it.
+	AuthTime int64 `json:"auth_time,omitempty"`
+
 	// Inherit from standard claims
 	jwt.StandardClaims
 }
@@ -198,14 +205,19 @@ func decodeResetPasswordToken(token string) (*ResetPasswordClaims, error) {
 
 // generateResetPasswordToken Generate reset password token
 func generateResetPasswordToken(verifyId string) (string, error) {
+	// here, we have kept it as 5 minutes
+	return generateSetPasswordToken(verifyId, 5*time.Minute)
+}
+
+// generateSetPasswordToken generate token of reset password page which expires after the duration
+func generateSetPasswordToken(verifyId string, expiresIn time.Duration) (string, error) {
 
 	// Create the JWT key used to create the signature
 	privateKeyEnc := b64.StdEncoding.EncodeToString([]byte(*coreConfig.AppConfig.PrivateKey))
 	var jwtKey = []byte(privateKeyEnc[:20])
 
 	// Declare the expiration time of the token
-	// here, we have kept it as 5 minutes
-	expirationTime := time.Now().Add(5 * time.Minute)
+	expirationTime := time.Now().Add(expiresIn)
 	// Create the JWT claims, which includes the username and expiry time
 	claims := &ResetPasswordClaims{
 		VerifyId: verifyId,
@@ -278,18 +290,19 @@ func combineURL(a, b string) string {
 	return a + b
 }
 
-func createOAuthSession(model *TokenModel) (string, error) {
+// createOAuthSession create session for OAuth user with the role mapped from organizations
+func createOAuthSession(model *TokenModel, organizationRole string) (string, error) {
 	fmt.Printf("\nToken Model: %v\n", model)
 
-	model.organizationList = ""
-
-	if model.providerName == "github" {
-		fmt.Printf("\nGithub Access Token: %s\n", model.token.AccessToken)
-		organizations, organizationsErr := getUserOrganizations(model.profile.Login, model.token.AccessToken)
-		if organizationsErr != nil {
-			return "", organizationsErr
+	if organizationRole != "" && organizationRole != model.claim.Role {
+		userId, uuidErr := uuid.FromString(model.claim.UserId)
+		if uuidErr != nil {
+			return "", uuidErr
+		}
+		if roleErr := updateUserRole(userId, organizationRole); roleErr != nil {
+			return "", roleErr
 		}
-		model.organizationList = organizations
+		model.claim.Role = organizationRole
 	}
 	return createToken(model)
 }
@@ -334,7 +347,7 @@ func writeSessionOnCookie(c *fiber.Ctx, session string, config *authConfig.Confi
 func writeUserLangOnCookie(c *fiber.Ctx, lang string) {
 	langCookie := &fiber.Cookie{
 		HTTPOnly: false,
-		Name:     "social-lang",
+		Name:     langCookie,
 		Value:    lang,
 		Path:     "/",
 		Domain:   authConfig.AuthConfig.CookieRootDomain,
@@ -353,12 +366,17 @@ func createToken(model *TokenModel) (string, error) {
 		return "", fmt.Errorf("unable to parse private key: %s", keyErr.Error())
 	}
 
+	authTime := model.authTime
+	if authTime == 0 {
+		authTime = time.Now().Unix()
+	}
+
 	method := jwt.GetSigningMethod(jwt.SigningMethodES256.Name)
 	claims := TelarSocailClaims{
 		StandardClaims: jwt.StandardClaims{
 			Id:        fmt.Sprintf("%s", model.profile.ID),
 			Issuer:    fmt.Sprintf("telar-social@%s", model.providerName),
-			ExpiresAt: time.Now().Add(48 * time.Hour).Unix(),
+			ExpiresAt: time.Now().Add(authConfig.AuthConfig.AccessTokenExpiresIn).Unix(),
 			IssuedAt:  time.Now().Unix(),
 			Subject:   model.profile.Login,
 			Audience:  authConfig.AuthConfig.CookieRootDomain,
@@ -367,6 +385,7 @@ func createToken(model *TokenModel) (string, error) {
 		Name:          model.profile.Name,
 		AccessToken:   model.token.AccessToken,
 		Claim:         model.claim,
+		AuthTime:      authTime,
 	}
 
 	session, err = jwt.NewWithClaims(method, claims).SignedString(privateKey)
@@ -484,13 +503,6 @@ func initUserSetup(userId uuid.UUID, email string, avatar string, displayName st
 	adminHeaders["displayName"] = []string{displayName}
 	adminHeaders["role"] = []string{role}
 
-	circleURL := fmt.Sprintf("/circles/following/%s", userId)
-	_, circleErr := functionCall(http.MethodPost, []byte(""), circleURL, adminHeaders)
-
-	if circleErr != nil {
-		return circleErr
-	}
-
 	// Create default setting for user
 	settingModel := models.CreateMultipleSettingsModel{
 		List: []models.CreateSettingGroupModel{
@@ -513,6 +525,10 @@ func initUserSetup(userId uuid.UUID, email string, avatar string, displayName st
 						Name:  "send_email_app_news",
 						Value: "true",
 					},
+					{
+						Name:  loginAlertSettingKey,
+						Value: loginAlertSettingDefault,
+					},
 				},
 			},
 			{
@@ -524,6 +540,15 @@ func initUserSetup(userId uuid.UUID, email string, avatar string, displayName st
 					},
 				},
 			},
+			{
+				Type: "privacy",
+				List: []models.SettingGroupItemModel{
+					{
+						Name:  "hide_online_status",
+						Value: "false",
+					},
+				},
+			},
 		},
 	}
 
@@ -591,6 +616,20 @@ func getUserProfileByID(userID uuid.UUID) (*models.UserProfileModel, error) {
 	return &foundProfile, nil
 }
 
+// verificationTypeOf get type of verified badge of the profile
+func verificationTypeOf(profile *models.UserProfileModel) string {
+	if profile == nil || profile.Verification == nil {
+		return ""
+	}
+	return profile.Verification.Type
+}
+
+// hashToken hash a random token to keep in database, so the database alone can not be used to act with it
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
 // saveUserProfile Save user profile
 func saveUserProfile(model *models.UserProfileModel) error {
 	profileURL := "/profile/dto"
@@ -619,10 +658,14 @@ func updateUserProfile(data []byte, updateType int, userInfoInReq *UserInfoInReq
 	return nil
 }
 
-// generateRandomNumber
-func generateRandomNumber(min int, max int) int {
-	rand.Seed(time.Now().UnixNano())
-	return (rand.Intn(max-min+1) + min)
+// defaultAvatarURL get the avatar drawn by storage micro for a new user
+func defaultAvatarURL(userId uuid.UUID) string {
+	return fmt.Sprintf("%s/avatars/%s", authConfig.AuthConfig.PlaceholderImageURL, userId.String())
+}
+
+// defaultBannerURL get the banner drawn by storage micro for a new user
+func defaultBannerURL(userId uuid.UUID) string {
+	return fmt.Sprintf("%s/banners/%s", authConfig.AuthConfig.PlaceholderImageURL, userId.String())
 }
 
 // readProfileAsync Read profile async
@@ -2,8 +2,16 @@ package handlers
 
 const (
 	cookieName      = "telar_social_token"
+	inviteCookie    = "telar_invite_code"
+	legalCookie     = "telar_legal_accepted"
+	langCookie      = "social-lang"
+	langLocalName   = "Lang"
+	verifyDevice    = "telar_verify_device"
+	deviceCookie    = "telar_device"
 	gitlabName      = "gitlab"
 	githubName      = "github"
+	samlName        = "saml"
+	samlRequestID   = "telar_saml_request"
 	SPAResponseType = "spa"
 	SSRResponseType = "ssr"
 )
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/red-gold/telar-core/pkg/log"
+	utils "github.com/red-gold/telar-core/utils"
+	models "github.com/red-gold/telar-web/micros/auth/models"
+)
+
+// checkSignupCustomFields ask profile micro to validate the custom profile fields filled at signup.
+// It returns the values as JSON to keep in the signup token.
+func checkSignupCustomFields(rawCustomFields string) (string, error) {
+	var customFields map[string]interface{}
+	if rawCustomFields != "" {
+		if err := json.Unmarshal([]byte(rawCustomFields), &customFields); err != nil {
+			return "", models.CustomFieldError{Code: models.CustomFieldErrorInvalid}
+		}
+	}
+
+	checkedCustomFields, err := checkCustomFields(customFields, true)
+	if err != nil {
+		return "", err
+	}
+	if len(checkedCustomFields) == 0 {
+		return "", nil
+	}
+
+	checkedData, err := json.Marshal(checkedCustomFields)
+	if err != nil {
+		return "", err
+	}
+	return string(checkedData), nil
+}
+
+// checkCustomFields ask profile micro to validate custom profile fields and return the normalized values.
+// Required fields are only asked at signup.
+func checkCustomFields(customFields map[string]interface{}, signup bool) (map[string]interface{}, error) {
+	data, err := json.Marshal(models.CheckCustomFieldsModel{CustomFields: customFields, Signup: signup})
+	if err != nil {
+		return nil, err
+	}
+	profileURL := "/profile/dto/fields/check"
+	resData, err := functionCall(http.MethodPost, data, profileURL, nil)
+	if err != nil {
+		log.Error("functionCall (%s) -  %s", profileURL, err.Error())
+		return nil, fmt.Errorf("checkCustomFields/functionCall")
+	}
+
+	var checkResult models.CustomFieldsCheckResultModel
+	if err = json.Unmarshal(resData, &checkResult); err != nil {
+		log.Error("Unmarshal CustomFieldsCheckResultModel -  %s", err.Error())
+		return nil, fmt.Errorf("checkCustomFields/unmarshal")
+	}
+	if !checkResult.Valid {
+		return nil, models.CustomFieldError{Code: checkResult.Code, Field: checkResult.Field, Message: checkResult.Message}
+	}
+	return checkResult.CustomFields, nil
+}
+
+// parseCustomFieldsClaim get the custom profile fields kept in the signup token
+func parseCustomFieldsClaim(claim string) map[string]interface{} {
+	if claim == "" {
+		return nil
+	}
+	var customFields map[string]interface{}
+	if err := json.Unmarshal([]byte(claim), &customFields); err != nil {
+		log.Error("Unmarshal custom fields claim %s", err.Error())
+		return nil
+	}
+	return customFields
+}
+
+// customFieldErrorResponse write custom field error on response
+func customFieldErrorResponse(c *fiber.Ctx, err error) error {
+	if customFieldErr, ok := err.(models.CustomFieldError); ok {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error(customFieldErr.Code, translateErrorOf(getLang(c), customFieldErr)))
+	}
+	log.Error("Error happened while checking custom fields: %s", err.Error())
+	return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/checkCustomFields", "Error happened while checking custom fields!"))
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"bufio"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/red-gold/telar-core/pkg/log"
+	utils "github.com/red-gold/telar-core/utils"
+	ac "github.com/red-gold/telar-web/micros/auth/config"
+	models "github.com/red-gold/telar-web/micros/auth/models"
+)
+
+// disposableDomains caches the disposable domain list file and reloads it on change
+var disposableDomains = struct {
+	sync.RWMutex
+	modTime time.Time
+	domains map[string]struct{}
+}{}
+
+// checkEmailDomain check the email domain against allowlist, denylist and disposable domain list.
+// It should be called wherever a new email is bound to a user.
+func checkEmailDomain(email string) error {
+	authConfig := &ac.AuthConfig
+
+	at := strings.LastIndex(email, "@")
+	if at < 1 || at == len(email)-1 {
+		return models.EmailDomainError{Code: models.EmailDomainErrorInvalidEmail}
+	}
+	domain := strings.ToLower(strings.TrimSpace(email[at+1:]))
+
+	if len(authConfig.EmailDomainAllowlist) > 0 && !matchDomain(domain, authConfig.EmailDomainAllowlist) {
+		return models.EmailDomainError{Code: models.EmailDomainErrorNotAllowed}
+	}
+
+	if matchDomain(domain, authConfig.EmailDomainDenylist) {
+		return models.EmailDomainError{Code: models.EmailDomainErrorDenied}
+	}
+
+	if authConfig.BlockDisposableEmail && isDisposableDomain(domain) {
+		return models.EmailDomainError{Code: models.EmailDomainErrorDisposableMail}
+	}
+	return nil
+}
+
+// matchDomain check whether domain or one of its parents is in the list
+func matchDomain(domain string, list []string) bool {
+	for _, item := range list {
+		if domain == item || strings.HasSuffix(domain, "."+item) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDisposableDomain check whether domain or one of its parents is in disposable domain list
+func isDisposableDomain(domain string) bool {
+	domains := loadDisposableDomains()
+	for {
+		if _, ok := domains[domain]; ok {
+			return true
+		}
+		dot := strings.Index(domain, ".")
+		if dot < 0 {
+			return false
+		}
+		domain = domain[dot+1:]
+	}
+}
+
+// loadDisposableDomains read disposable domain list file if it is changed since last read
+func loadDisposableDomains() map[string]struct{} {
+	filePath := ac.AuthConfig.DisposableDomainsFile
+
+	fileInfo, statErr := os.Stat(filePath)
+	if statErr != nil {
+		log.Error("[loadDisposableDomains] Can not read disposable domains file %s: %s", filePath, statErr.Error())
+		disposableDomains.RLock()
+		defer disposableDomains.RUnlock()
+		return disposableDomains.domains
+	}
+
+	disposableDomains.RLock()
+	if disposableDomains.domains != nil && fileInfo.ModTime().Equal(disposableDomains.modTime) {
+		defer disposableDomains.RUnlock()
+		return disposableDomains.domains
+	}
+	disposableDomains.RUnlock()
+
+	file, openErr := os.Open(filePath)
+	if openErr != nil {
+		log.Error("[loadDisposableDomains] Can not open disposable domains file %s: %s", filePath, openErr.Error())
+		return nil
+	}
+	defer file.Close()
+
+	domains := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		domains[line] = struct{}{}
+	}
+	if scanErr := scanner.Err(); scanErr != nil {
+		log.Error("[loadDisposableDomains] Can not scan disposable domains file %s: %s", filePath, scanErr.Error())
+	}
+
+	disposableDomains.Lock()
+	disposableDomains.domains = domains
+	disposableDomains.modTime = fileInfo.ModTime()
+	disposableDomains.Unlock()
+	return domains
+}
+
+// emailDomainErrorResponse write email domain error as api response
+func emailDomainErrorResponse(c *fiber.Ctx, err error) error {
+	if domainErr, ok := err.(models.EmailDomainError); ok {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error(domainErr.Code, domainErr.Error()))
+	}
+	log.Error("Error happened while checking email domain: %s", err.Error())
+	return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/checkEmailDomain", "Error happened while checking email domain!"))
+}
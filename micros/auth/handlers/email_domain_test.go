@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	ac "github.com/red-gold/telar-web/micros/auth/config"
+	models "github.com/red-gold/telar-web/micros/auth/models"
+)
+
+func TestCheckEmailDomain(t *testing.T) {
+	disposableFile := filepath.Join(t.TempDir(), "disposable_domains.txt")
+	if err := ioutil.WriteFile(disposableFile, []byte("# disposable domains\nmailinator.com\n\nTempMail.org\n"), 0600); err != nil {
+		t.Fatalf("write disposable domains: %s", err)
+	}
+
+	previousConfig := ac.AuthConfig
+	defer func() {
+		ac.AuthConfig = previousConfig
+		disposableDomains.domains = nil
+	}()
+	disposableDomains.domains = nil
+
+	tests := []struct {
+		name       string
+		allowlist  []string
+		denylist   []string
+		disposable bool
+		email      string
+		wantCode   string
+	}{
+		{"no lists", nil, nil, false, "jane@example.com", ""},
+		{"missing at", nil, nil, false, "example.com", models.EmailDomainErrorInvalidEmail},
+		{"missing local part", nil, nil, false, "@example.com", models.EmailDomainErrorInvalidEmail},
+		{"missing domain", nil, nil, false, "jane@", models.EmailDomainErrorInvalidEmail},
+		{"allowed domain", []string{"example.com"}, nil, false, "jane@Example.COM", ""},
+		{"allowed parent domain", []string{"example.com"}, nil, false, "jane@mail.example.com", ""},
+		{"not allowed domain", []string{"example.com"}, nil, false, "jane@other.com", models.EmailDomainErrorNotAllowed},
+		{"suffix is not a parent domain", []string{"example.com"}, nil, false, "jane@badexample.com", models.EmailDomainErrorNotAllowed},
+		{"denied domain", nil, []string{"spam.com"}, false, "jane@spam.com", models.EmailDomainErrorDenied},
+		{"denied parent domain", nil, []string{"spam.com"}, false, "jane@a.spam.com", models.EmailDomainErrorDenied},
+		{"denylist wins over allowlist", []string{"spam.com"}, []string{"spam.com"}, false, "jane@spam.com", models.EmailDomainErrorDenied},
+		{"disposable domain", nil, nil, true, "jane@mailinator.com", models.EmailDomainErrorDisposableMail},
+		{"disposable parent domain", nil, nil, true, "jane@x.tempmail.org", models.EmailDomainErrorDisposableMail},
+		{"disposable domain not blocked", nil, nil, false, "jane@mailinator.com", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ac.AuthConfig.EmailDomainAllowlist = tt.allowlist
+			ac.AuthConfig.EmailDomainDenylist = tt.denylist
+			ac.AuthConfig.BlockDisposableEmail = tt.disposable
+			ac.AuthConfig.DisposableDomainsFile = disposableFile
+
+			err := checkEmailDomain(tt.email)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Errorf("checkEmailDomain(%q) = %v, want nil", tt.email, err)
+				}
+				return
+			}
+			domainErr, ok := err.(models.EmailDomainError)
+			if !ok || domainErr.Code != tt.wantCode {
+				t.Errorf("checkEmailDomain(%q) = %v, want %s", tt.email, err, tt.wantCode)
+			}
+		})
+	}
+}
@@ -3,3 +3,5 @@ package handlers
 import "errors"
 
 var NotFoundHTTPStatusError = errors.New("NotFoundHTTPStatusError")
+
+var OrganizationNotAllowedError = errors.New("OrganizationNotAllowedError")
@@ -0,0 +1,354 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/pkg/parser"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+	ac "github.com/red-gold/telar-web/micros/auth/config"
+	"github.com/red-gold/telar-web/micros/auth/database"
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+	models "github.com/red-gold/telar-web/micros/auth/models"
+	service "github.com/red-gold/telar-web/micros/auth/services"
+)
+
+type InvitationQueryModel struct {
+	Page int64 `query:"page"`
+}
+
+// CreateInvitationHandle godoc
+// @Summary Create an invitation code
+// @Description Create an invitation code for the current user. Usage limit and expiry are capped by the auth config.
+// @Tags Invitation
+// @Accept  json
+// @Produce  json
+// @Param   body  body  models.CreateInvitationModel  true  "Invitation options"
+// @Success 200 {object} dto.Invitation
+// @Failure 400 {object} utils.TelarError
+// @Failure 500 {object} utils.TelarError
+// @Router /invitations [post]
+func CreateInvitationHandle(c *fiber.Ctx) error {
+	authConfig := &ac.AuthConfig
+
+	model := new(models.CreateInvitationModel)
+	if err := c.BodyParser(model); err != nil {
+		log.Error("[CreateInvitationHandle] Parse CreateInvitationModel %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseModel", "Error happened while parsing model!"))
+	}
+
+	currentUser, ok := c.Locals(types.UserCtxName).(types.UserContext)
+	if !ok {
+		log.Error("[CreateInvitationHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	if models.SignupModeConst(authConfig.SignupMode) == models.ClosedSignupModeConst {
+		return c.Status(http.StatusForbidden).JSON(utils.Error(models.InvitationErrorSignupClosed, "Signup is closed!"))
+	}
+
+	// Users can not go beyond the configured limits
+	maxUses := model.MaxUses
+	if maxUses <= 0 || (authConfig.InviteMaxUses > 0 && maxUses > authConfig.InviteMaxUses) {
+		maxUses = authConfig.InviteMaxUses
+	}
+	expiresIn := time.Duration(model.ExpiresIn) * time.Hour
+	if expiresIn <= 0 || (authConfig.InviteExpiresIn > 0 && expiresIn > authConfig.InviteExpiresIn) {
+		expiresIn = authConfig.InviteExpiresIn
+	}
+
+	invitation, err := createInvitation(currentUser.UserID, false, maxUses, expiresIn)
+	if err != nil {
+		log.Error("[CreateInvitationHandle] Create invitation %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/createInvitation", "Error happened while creating invitation!"))
+	}
+	return c.JSON(invitation)
+}
+
+// CreateAdminInvitationHandle godoc
+// @Summary Create an invitation code by admin
+// @Description Create an invitation code without the user limits. Zero maxUses or expiresIn means unlimited.
+// @Tags Admin
+// @Accept  json
+// @Produce  json
+// @Security HMAC
+// @Param X-Cloud-Signature header string true "HMAC signature"
+// @Param   body  body  models.CreateInvitationModel  true  "Invitation options"
+// @Success 200 {object} dto.Invitation
+// @Failure 400 {object} utils.TelarError
+// @Failure 500 {object} utils.TelarError
+// @Router /admin/invitations [post]
+func CreateAdminInvitationHandle(c *fiber.Ctx) error {
+
+	model := new(models.CreateInvitationModel)
+	if err := c.BodyParser(model); err != nil {
+		log.Error("[CreateAdminInvitationHandle] Parse CreateInvitationModel %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseModel", "Error happened while parsing model!"))
+	}
+
+	currentUser, ok := c.Locals(types.UserCtxName).(types.UserContext)
+	if !ok {
+		log.Error("[CreateAdminInvitationHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	invitation, err := createInvitation(currentUser.UserID, true, model.MaxUses, time.Duration(model.ExpiresIn)*time.Hour)
+	if err != nil {
+		log.Error("[CreateAdminInvitationHandle] Create invitation %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/createInvitation", "Error happened while creating invitation!"))
+	}
+	return c.JSON(invitation)
+}
+
+// GetMyInvitationsHandle godoc
+// @Summary Get current user invitations
+// @Description Get invitation codes created by the current user
+// @Tags Invitation
+// @Produce  json
+// @Param page query int false "Page number"
+// @Success 200 {array} dto.Invitation
+// @Failure 400 {object} utils.TelarError
+// @Failure 500 {object} utils.TelarError
+// @Router /invitations [get]
+func GetMyInvitationsHandle(c *fiber.Ctx) error {
+
+	query := new(InvitationQueryModel)
+	if err := parser.QueryParser(c, query); err != nil {
+		log.Error("[GetMyInvitationsHandle] QueryParser %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("queryParser", "Error happened while parsing query!"))
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+
+	currentUser, ok := c.Locals(types.UserCtxName).(types.UserContext)
+	if !ok {
+		log.Error("[GetMyInvitationsHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	invitationService, serviceErr := service.NewInvitationService(database.Db)
+	if serviceErr != nil {
+		log.Error("[GetMyInvitationsHandle] %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/invitationService", "Error happened while creating invitation service!"))
+	}
+
+	invitationList, err := invitationService.FindByOwnerUserId(currentUser.UserID, query.Page)
+	if err != nil {
+		log.Error("[GetMyInvitationsHandle] FindByOwnerUserId %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findInvitations", "Error happened while reading invitations!"))
+	}
+	return c.JSON(invitationList)
+}
+
+// QueryInvitationsHandle godoc
+// @Summary Query all invitations
+// @Description Get all invitation codes with their usage so admins can see how invites spread
+// @Tags Admin
+// @Produce  json
+// @Security HMAC
+// @Param X-Cloud-Signature header string true "HMAC signature"
+// @Param page query int false "Page number"
+// @Success 200 {array} dto.Invitation
+// @Failure 400 {object} utils.TelarError
+// @Failure 500 {object} utils.TelarError
+// @Router /admin/invitations [get]
+func QueryInvitationsHandle(c *fiber.Ctx) error {
+
+	query := new(InvitationQueryModel)
+	if err := parser.QueryParser(c, query); err != nil {
+		log.Error("[QueryInvitationsHandle] QueryParser %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("queryParser", "Error happened while parsing query!"))
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+
+	invitationService, serviceErr := service.NewInvitationService(database.Db)
+	if serviceErr != nil {
+		log.Error("[QueryInvitationsHandle] %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/invitationService", "Error happened while creating invitation service!"))
+	}
+
+	invitationList, err := invitationService.QueryInvitation(query.Page)
+	if err != nil {
+		log.Error("[QueryInvitationsHandle] QueryInvitation %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findInvitations", "Error happened while reading invitations!"))
+	}
+	return c.JSON(invitationList)
+}
+
+// DeleteInvitationHandle godoc
+// @Summary Delete an invitation code
+// @Description Delete an invitation code owned by the current user
+// @Tags Invitation
+// @Param invitationId path string true "Invitation ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} utils.TelarError
+// @Failure 500 {object} utils.TelarError
+// @Router /invitations/{invitationId} [delete]
+func DeleteInvitationHandle(c *fiber.Ctx) error {
+
+	invitationId, uuidErr := uuid.FromString(c.Params("invitationId"))
+	if uuidErr != nil {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invitationIdRequired", "Invitation id is required!"))
+	}
+
+	currentUser, ok := c.Locals(types.UserCtxName).(types.UserContext)
+	if !ok {
+		log.Error("[DeleteInvitationHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	invitationService, serviceErr := service.NewInvitationService(database.Db)
+	if serviceErr != nil {
+		log.Error("[DeleteInvitationHandle] %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/invitationService", "Error happened while creating invitation service!"))
+	}
+
+	if err := invitationService.DeleteInvitation(invitationId, currentUser.UserID); err != nil {
+		log.Error("[DeleteInvitationHandle] DeleteInvitation %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/deleteInvitation", "Error happened while deleting invitation!"))
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// createInvitation generate a new invitation code and save it
+func createInvitation(ownerUserId uuid.UUID, isAdmin bool, maxUses int64, expiresIn time.Duration) (*dto.Invitation, error) {
+
+	invitationService, serviceErr := service.NewInvitationService(database.Db)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	code, codeErr := generateInviteCode()
+	if codeErr != nil {
+		return nil, codeErr
+	}
+
+	var expiresAt int64
+	if expiresIn > 0 {
+		expiresAt = utils.UTCNowUnix() + expiresIn.Milliseconds()
+	}
+
+	invitation := &dto.Invitation{
+		Code:        code,
+		OwnerUserId: ownerUserId,
+		IsAdmin:     isAdmin,
+		MaxUses:     maxUses,
+		UsedBy:      []uuid.UUID{},
+		ExpiresAt:   expiresAt,
+		LastUpdated: utils.UTCNowUnix(),
+	}
+	if err := invitationService.SaveInvitation(invitation); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// generateInviteCode generate a random human friendly invitation code
+func generateInviteCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(b)), nil
+}
+
+// checkSignupInvitation check whether a new user can signup with the invitation code in current signup mode.
+// In open mode an invalid code is ignored and a valid one is only used to track the inviter.
+func checkSignupInvitation(inviteCode string) (*dto.Invitation, error) {
+	signupMode := models.SignupModeConst(ac.AuthConfig.SignupMode)
+	if signupMode == models.ClosedSignupModeConst {
+		return nil, models.InvitationError{Code: models.InvitationErrorSignupClosed}
+	}
+
+	inviteCode = strings.ToUpper(strings.TrimSpace(inviteCode))
+	if inviteCode == "" {
+		if signupMode == models.InviteSignupModeConst {
+			return nil, models.InvitationError{Code: models.InvitationErrorCodeRequired}
+		}
+		return nil, nil
+	}
+
+	invitation, err := findValidInvitation(inviteCode)
+	if err != nil && signupMode != models.InviteSignupModeConst {
+		if _, ok := err.(models.InvitationError); ok {
+			return nil, nil
+		}
+	}
+	return invitation, err
+}
+
+// findValidInvitation find invitation by code and check expiry and usage limit
+func findValidInvitation(inviteCode string) (*dto.Invitation, error) {
+	invitationService, serviceErr := service.NewInvitationService(database.Db)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	invitation, findErr := invitationService.FindByCode(inviteCode)
+	if findErr != nil {
+		return nil, findErr
+	}
+	if invitation == nil {
+		return nil, models.InvitationError{Code: models.InvitationErrorInvalidCode}
+	}
+	if invitation.ExpiresAt > 0 && utils.UTCNowUnix() > invitation.ExpiresAt {
+		return nil, models.InvitationError{Code: models.InvitationErrorCodeExpired}
+	}
+	if invitation.MaxUses > 0 && invitation.UsedCount >= invitation.MaxUses {
+		return nil, models.InvitationError{Code: models.InvitationErrorCodeUsedUp}
+	}
+	return invitation, nil
+}
+
+// useSignupInvitation claim a use of the invitation for the new user.
+// It fails when the usage limit is reached by another signup since the check.
+func useSignupInvitation(invitation *dto.Invitation, userId uuid.UUID) error {
+	if invitation == nil {
+		return nil
+	}
+	invitationService, serviceErr := service.NewInvitationService(database.Db)
+	if serviceErr != nil {
+		return serviceErr
+	}
+	if err := invitationService.UseInvitation(invitation, userId); err != nil {
+		log.Error(fmt.Sprintf("[useSignupInvitation] Can not use invitation %s error: %s", invitation.Code, err.Error()))
+		return err
+	}
+	return nil
+}
+
+// invitationErrorResponse write invitation error as api response
+func invitationErrorResponse(c *fiber.Ctx, err error) error {
+	if invitationErr, ok := err.(models.InvitationError); ok {
+		status := http.StatusBadRequest
+		if invitationErr.Code == models.InvitationErrorSignupClosed {
+			status = http.StatusForbidden
+		}
+		return c.Status(status).JSON(utils.Error(invitationErr.Code, invitationErr.Error()))
+	}
+	log.Error("Error happened while checking invitation: %s", err.Error())
+	return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/checkInvitation", "Error happened while checking invitation!"))
+}
+
+// getInvitationOwner get the inviter of an invitation
+func getInvitationOwner(invitation *dto.Invitation) uuid.UUID {
+	if invitation == nil {
+		return uuid.Nil
+	}
+	return invitation.OwnerUserId
+}
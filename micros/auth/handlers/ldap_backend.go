@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/pkg/log"
+	utils "github.com/red-gold/telar-core/utils"
+	"github.com/red-gold/telar-web/constants"
+	cf "github.com/red-gold/telar-web/micros/auth/config"
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+	models "github.com/red-gold/telar-web/micros/auth/models"
+	service "github.com/red-gold/telar-web/micros/auth/services"
+)
+
+const defaultUserRole = "user"
+
+// ldapAuthBackend binds with service account, searches the user entry and binds as the user
+type ldapAuthBackend struct {
+	config          *cf.Configuration
+	userAuthService service.UserAuthService
+}
+
+func (b ldapAuthBackend) name() string {
+	return ldapAuthBackendName
+}
+
+func (b ldapAuthBackend) authenticate(username, password string) (*dto.UserAuth, error) {
+	// An empty password is an unauthenticated bind which most servers accept
+	if password == "" {
+		return nil, models.UserAuthError{Code: models.UserAuthErrorPasswordNotMatch}
+	}
+
+	conn, dialErr := ldap.DialURL(b.config.LDAPURL)
+	if dialErr != nil {
+		return nil, fmt.Errorf("unable to connect to LDAP server: %s", dialErr.Error())
+	}
+	defer conn.Close()
+
+	if b.config.LDAPStartTLS {
+		ldapURL, _ := url.Parse(b.config.LDAPURL)
+		if tlsErr := conn.StartTLS(&tls.Config{ServerName: ldapURL.Hostname()}); tlsErr != nil {
+			return nil, fmt.Errorf("unable to start TLS with LDAP server: %s", tlsErr.Error())
+		}
+	}
+
+	if b.config.LDAPBindDN != "" {
+		if bindErr := conn.Bind(b.config.LDAPBindDN, b.config.LDAPBindPassword); bindErr != nil {
+			return nil, fmt.Errorf("unable to bind LDAP service account: %s", bindErr.Error())
+		}
+	}
+
+	filter := strings.ReplaceAll(b.config.LDAPUserFilter, "%s", ldap.EscapeFilter(username))
+	searchRequest := ldap.NewSearchRequest(
+		b.config.LDAPBaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+		filter,
+		[]string{"dn", b.config.LDAPEmailAttribute, b.config.LDAPNameAttribute, b.config.LDAPGroupAttribute},
+		nil,
+	)
+	searchResult, searchErr := conn.Search(searchRequest)
+	if searchErr != nil {
+		return nil, fmt.Errorf("unable to search LDAP user: %s", searchErr.Error())
+	}
+	if len(searchResult.Entries) == 0 {
+		return nil, models.UserAuthError{Code: models.UserAuthErrorUserNotFound}
+	}
+	if len(searchResult.Entries) > 1 {
+		return nil, fmt.Errorf("LDAP user filter matched more than one entry for %s", username)
+	}
+	entry := searchResult.Entries[0]
+
+	if bindErr := conn.Bind(entry.DN, password); bindErr != nil {
+		if ldap.IsErrorWithCode(bindErr, ldap.LDAPResultInvalidCredentials) {
+			return nil, models.UserAuthError{Code: models.UserAuthErrorPasswordNotMatch}
+		}
+		return nil, fmt.Errorf("unable to bind LDAP user: %s", bindErr.Error())
+	}
+
+	email := strings.ToLower(entry.GetAttributeValue(b.config.LDAPEmailAttribute))
+	if email == "" {
+		email = strings.ToLower(username)
+	}
+	fullName := entry.GetAttributeValue(b.config.LDAPNameAttribute)
+	if fullName == "" {
+		fullName = strings.Split(email, "@")[0]
+	}
+	role := mapGroupsToRole(entry.GetAttributeValues(b.config.LDAPGroupAttribute), b.config.LDAPGroupRoles)
+
+	return b.findOrProvisionUser(email, fullName, role)
+}
+
+// findOrProvisionUser keep userAuth in sync with directory and create the user on first login
+func (b ldapAuthBackend) findOrProvisionUser(email, fullName, role string) (*dto.UserAuth, error) {
+	foundUser, findErr := b.userAuthService.FindByUsername(email)
+	if findErr != nil {
+		return nil, findErr
+	}
+
+	if foundUser == nil {
+		return provisionDirectoryUser(b.userAuthService, email, fullName, role)
+	}
+
+	// Directory is the source of truth for roles when group mapping is configured
+	if len(b.config.LDAPGroupRoles) > 0 && foundUser.Role != role {
+		if updateErr := updateUserRole(foundUser.ObjectId, role); updateErr != nil {
+			return nil, updateErr
+		}
+		foundUser.Role = role
+	}
+	return foundUser, nil
+}
+
+// provisionDirectoryUser create user auth, profile and default settings for a directory user
+func provisionDirectoryUser(userAuthService service.UserAuthService, email, fullName, role string) (*dto.UserAuth, error) {
+	newUserId, uuidErr := uuid.NewV4()
+	if uuidErr != nil {
+		return nil, uuidErr
+	}
+	createdDate := utils.UTCNowUnix()
+
+	newUserAuth := &dto.UserAuth{
+		ObjectId:      newUserId,
+		Username:      email,
+		Password:      []byte(""),
+		EmailVerified: true,
+		Role:          role,
+		CreatedDate:   createdDate,
+		LastUpdated:   createdDate,
+	}
+	if userAuthErr := userAuthService.SaveUserAuth(newUserAuth); userAuthErr != nil {
+		return nil, userAuthErr
+	}
+
+	newUserProfile := &models.UserProfileModel{
+		ObjectId:    newUserId,
+		FullName:    fullName,
+		SocialName:  generateSocialName(fullName, newUserId.String()),
+		CreatedDate: createdDate,
+		LastUpdated: createdDate,
+		Email:       email,
+		Avatar:      defaultAvatarURL(newUserId),
+		Banner:      defaultBannerURL(newUserId),
+		Permission:  constants.Public,
+	}
+	if userProfileErr := saveUserProfile(newUserProfile); userProfileErr != nil {
+		return nil, fmt.Errorf("Cannot save user profile! error: %s", userProfileErr.Error())
+	}
+
+	if setupErr := initUserSetup(newUserAuth.ObjectId, newUserAuth.Username, newUserProfile.Avatar, newUserProfile.FullName, newUserAuth.Role); setupErr != nil {
+		return nil, fmt.Errorf("Cannot initialize user setup! error: %s", setupErr.Error())
+	}
+	log.Info("[provisionDirectoryUser] User %s is provisioned from directory", email)
+	return newUserAuth, nil
+}
+
+// mapGroupsToRole map directory groups to a role, admin role wins over others.
+// Groups are matched by full DN or by the value of their first RDN.
+func mapGroupsToRole(groups []string, groupRoles map[string]string) string {
+	role := defaultUserRole
+	for _, group := range groups {
+		group = strings.ToLower(group)
+		mappedRole, ok := groupRoles[group]
+		if !ok {
+			rdn := strings.SplitN(group, ",", 2)[0]
+			if sep := strings.Index(rdn, "="); sep > -1 {
+				mappedRole, ok = groupRoles[rdn[sep+1:]]
+			}
+		}
+		if !ok {
+			continue
+		}
+		if mappedRole == "admin" {
+			return mappedRole
+		}
+		if role == defaultUserRole {
+			role = mappedRole
+		}
+	}
+	return role
+}
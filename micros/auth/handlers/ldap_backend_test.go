@@ -0,0 +1,34 @@
+package handlers
+
+import "testing"
+
+func TestMapGroupsToRole(t *testing.T) {
+	groupRoles := map[string]string{
+		"cn=admins,ou=groups,dc=example,dc=com": "admin",
+		"editors":                               "editor",
+		"moderators":                            "moderator",
+	}
+
+	tests := []struct {
+		name   string
+		groups []string
+		want   string
+	}{
+		{"no groups", nil, defaultUserRole},
+		{"unmapped group", []string{"cn=staff,ou=groups,dc=example,dc=com"}, defaultUserRole},
+		{"match by full DN", []string{"CN=Admins,OU=Groups,DC=example,DC=com"}, "admin"},
+		{"match by first RDN value", []string{"cn=editors,ou=groups,dc=example,dc=com"}, "editor"},
+		{"match by plain group name", []string{"Editors"}, "editor"},
+		{"first mapped role wins", []string{"cn=moderators,dc=example", "cn=editors,dc=example"}, "moderator"},
+		{"admin wins over others", []string{"cn=editors,dc=example", "cn=admins,ou=groups,dc=example,dc=com"}, "admin"},
+		{"group without RDN value", []string{"cn"}, defaultUserRole},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mapGroupsToRole(tt.groups, groupRoles); got != tt.want {
+				t.Errorf("mapGroupsToRole(%v) = %q, want %q", tt.groups, got, tt.want)
+			}
+		})
+	}
+}
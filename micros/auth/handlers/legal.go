@@ -0,0 +1,587 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	uuid "github.com/gofrs/uuid"
+	coreConfig "github.com/red-gold/telar-core/config"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/pkg/parser"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+	authConfig "github.com/red-gold/telar-web/micros/auth/config"
+	"github.com/red-gold/telar-web/micros/auth/database"
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+	"github.com/red-gold/telar-web/micros/auth/i18n"
+	models "github.com/red-gold/telar-web/micros/auth/models"
+	"github.com/red-gold/telar-web/micros/auth/provider"
+	service "github.com/red-gold/telar-web/micros/auth/services"
+)
+
+const (
+	legalConsentExpiresIn       = 30 * time.Minute
+	consentExportBatch    int64 = 1000
+)
+
+type LegalDocumentQueryModel struct {
+	Type string `query:"type"`
+	Page int64  `query:"page"`
+}
+
+type LegalConsentQueryModel struct {
+	Type    string `query:"type"`
+	Version string `query:"version"`
+	Format  string `query:"format"`
+}
+
+// legalAcceptance legal documents accepted by a new user and where the acceptance came from
+type legalAcceptance struct {
+	documentIds     string
+	remoteIpAddress string
+	userAgent       string
+}
+
+// pendingLogin login which waits for user consent before the session is created
+type pendingLogin struct {
+	model            *TokenModel
+	organizationRole string
+	state            string
+	redirect         string
+	lang             string
+	responseType     string
+}
+
+// CreateLegalDocumentHandle godoc
+// @Summary Publish a legal document version
+// @Description Publish a new version of terms of service or privacy policy. Users must accept it on their next login. A future publishedDate schedules the version.
+// @Tags Admin
+// @Accept  json
+// @Produce  json
+// @Security HMAC
+// @Param X-Cloud-Signature header string true "HMAC signature"
+// @Param   body  body  models.CreateLegalDocumentModel  true  "Legal document"
+// @Success 200 {object} dto.LegalDocument
+// @Failure 400 {object} utils.TelarError
+// @Failure 500 {object} utils.TelarError
+// @Router /admin/legal [post]
+func CreateLegalDocumentHandle(c *fiber.Ctx) error {
+
+	model := new(models.CreateLegalDocumentModel)
+	if err := c.BodyParser(model); err != nil {
+		log.Error("[CreateLegalDocumentHandle] Parse CreateLegalDocumentModel %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseModel", "Error happened while parsing model!"))
+	}
+
+	currentUser, ok := c.Locals(types.UserCtxName).(types.UserContext)
+	if !ok {
+		log.Error("[CreateLegalDocumentHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	model.Type = strings.ToLower(strings.TrimSpace(model.Type))
+	model.Version = strings.TrimSpace(model.Version)
+	if !contains(models.LegalDocumentTypes, model.Type) {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidLegalDocumentType",
+			fmt.Sprintf("Legal document type must be one of %s!", strings.Join(models.LegalDocumentTypes, ", "))))
+	}
+	if model.Version == "" {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("legalVersionRequired", "Legal document version is required!"))
+	}
+	if model.URL == "" {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("legalUrlRequired", "Legal document url is required!"))
+	}
+
+	legalDocumentService, serviceErr := service.NewLegalDocumentService(database.Db)
+	if serviceErr != nil {
+		log.Error("[CreateLegalDocumentHandle] %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/legalDocumentService", "Error happened while creating legal document service!"))
+	}
+
+	existDocument, findErr := legalDocumentService.FindByTypeVersion(model.Type, model.Version)
+	if findErr != nil {
+		log.Error("[CreateLegalDocumentHandle] FindByTypeVersion %s", findErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findLegalDocument", "Error happened while reading legal documents!"))
+	}
+	if existDocument != nil {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("legalVersionExists", "Legal document version already exists!"))
+	}
+
+	publishedDate := model.PublishedDate
+	if publishedDate <= 0 {
+		publishedDate = utils.UTCNowUnix()
+	}
+
+	legalDocument := &dto.LegalDocument{
+		Type:          model.Type,
+		Version:       model.Version,
+		Title:         model.Title,
+		URL:           model.URL,
+		Content:       model.Content,
+		PublishedDate: publishedDate,
+		CreatedBy:     currentUser.UserID,
+	}
+	if err := legalDocumentService.SaveLegalDocument(legalDocument); err != nil {
+		log.Error("[CreateLegalDocumentHandle] SaveLegalDocument %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/saveLegalDocument", "Error happened while saving legal document!"))
+	}
+	return c.JSON(legalDocument)
+}
+
+// QueryLegalDocumentsHandle godoc
+// @Summary Query legal document versions
+// @Description Get all published and scheduled versions of legal documents
+// @Tags Admin
+// @Produce  json
+// @Security HMAC
+// @Param X-Cloud-Signature header string true "HMAC signature"
+// @Param type query string false "Document type (terms or privacy)"
+// @Param page query int false "Page number"
+// @Success 200 {array} dto.LegalDocument
+// @Failure 400 {object} utils.TelarError
+// @Failure 500 {object} utils.TelarError
+// @Router /admin/legal [get]
+func QueryLegalDocumentsHandle(c *fiber.Ctx) error {
+
+	query := new(LegalDocumentQueryModel)
+	if err := parser.QueryParser(c, query); err != nil {
+		log.Error("[QueryLegalDocumentsHandle] QueryParser %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("queryParser", "Error happened while parsing query!"))
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+
+	legalDocumentService, serviceErr := service.NewLegalDocumentService(database.Db)
+	if serviceErr != nil {
+		log.Error("[QueryLegalDocumentsHandle] %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/legalDocumentService", "Error happened while creating legal document service!"))
+	}
+
+	legalDocumentList, err := legalDocumentService.QueryLegalDocument(query.Type, query.Page)
+	if err != nil {
+		log.Error("[QueryLegalDocumentsHandle] QueryLegalDocument %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findLegalDocument", "Error happened while reading legal documents!"))
+	}
+	return c.JSON(legalDocumentList)
+}
+
+// ExportLegalConsentsHandle godoc
+// @Summary Export user consents
+// @Description Export which version of legal documents each user accepted and when, for auditors
+// @Tags Admin
+// @Produce  text/csv
+// @Produce  json
+// @Security HMAC
+// @Param X-Cloud-Signature header string true "HMAC signature"
+// @Param type query string false "Document type (terms or privacy)"
+// @Param version query string false "Document version"
+// @Param format query string false "csv (default) or json"
+// @Success 200 {array} dto.UserConsent
+// @Failure 400 {object} utils.TelarError
+// @Failure 500 {object} utils.TelarError
+// @Router /admin/legal/consents [get]
+func ExportLegalConsentsHandle(c *fiber.Ctx) error {
+
+	query := new(LegalConsentQueryModel)
+	if err := parser.QueryParser(c, query); err != nil {
+		log.Error("[ExportLegalConsentsHandle] QueryParser %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("queryParser", "Error happened while parsing query!"))
+	}
+
+	userConsentService, serviceErr := service.NewUserConsentService(database.Db)
+	if serviceErr != nil {
+		log.Error("[ExportLegalConsentsHandle] %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userConsentService", "Error happened while creating user consent service!"))
+	}
+
+	userConsentList := []dto.UserConsent{}
+	for skip := int64(0); ; skip += consentExportBatch {
+		batch, err := userConsentService.QueryUserConsent(query.Type, query.Version, consentExportBatch, skip)
+		if err != nil {
+			log.Error("[ExportLegalConsentsHandle] QueryUserConsent %s", err.Error())
+			return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findUserConsent", "Error happened while reading user consents!"))
+		}
+		userConsentList = append(userConsentList, batch...)
+		if int64(len(batch)) < consentExportBatch {
+			break
+		}
+	}
+
+	if query.Format == "json" {
+		return c.JSON(userConsentList)
+	}
+
+	buf := new(bytes.Buffer)
+	writer := csv.NewWriter(buf)
+	writer.Write([]string{"userId", "documentType", "version", "documentId", "acceptedDate", "remoteIpAddress", "userAgent"})
+	for _, userConsent := range userConsentList {
+		acceptedDate := time.Unix(0, userConsent.AcceptedDate*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+		writer.Write([]string{
+			userConsent.UserId.String(),
+			userConsent.DocumentType,
+			userConsent.Version,
+			userConsent.DocumentId.String(),
+			acceptedDate,
+			userConsent.RemoteIpAddress,
+			userConsent.UserAgent,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Error("[ExportLegalConsentsHandle] Write csv %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/exportUserConsent", "Error happened while exporting user consents!"))
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="consents.csv"`)
+	return c.Send(buf.Bytes())
+}
+
+// GetLegalDocumentsHandle godoc
+// @Summary Get current legal documents
+// @Description Get the latest published version of each legal document that users must accept
+// @Tags Legal
+// @Produce  json
+// @Success 200 {array} dto.LegalDocument
+// @Failure 500 {object} utils.TelarError
+// @Router /legal [get]
+func GetLegalDocumentsHandle(c *fiber.Ctx) error {
+
+	legalDocuments, err := findLatestLegalDocuments()
+	if err != nil {
+		log.Error("[GetLegalDocumentsHandle] %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findLegalDocument", "Error happened while reading legal documents!"))
+	}
+	return c.JSON(legalDocuments)
+}
+
+// LegalConsentHandle godoc
+// @Summary Accept new legal documents
+// @Description Record consent of the user to the latest legal documents and continue the login which was waiting for it
+// @Tags Legal
+// @Accept  x-www-form-urlencoded
+// @Produce  json
+// @Param consentToken formData string true "Consent token of the login response"
+// @Param acceptedLegal formData string true "Comma separated ids of accepted legal documents"
+// @Param responseType formData string false "Response type (spa or ssr)"
+// @Success 200 {object} object{accessToken=string,redirect=string} "Access token"
+// @Failure 400 {object} utils.TelarError
+// @Failure 401 {object} utils.TelarError
+// @Failure 500 {object} utils.TelarError
+// @Router /legal/consent [post]
+func LegalConsentHandle(c *fiber.Ctx) error {
+
+	responseType := c.FormValue("responseType")
+	consentToken := c.FormValue("consentToken")
+	acceptedLegal := c.FormValue("acceptedLegal")
+
+	pendingLoginService, serviceErr := service.NewPendingLoginService(database.Db)
+	if serviceErr != nil {
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/pendingLoginService", serviceErr.Error()))
+	}
+
+	login, findErr := pendingLoginService.FindByTokenHash(hashToken(consentToken))
+	if findErr != nil {
+		log.Error("[LegalConsentHandle] Find pending login %s", findErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findPendingLogin", "Error happened while reading pending login!"))
+	}
+	if login == nil {
+		return c.Status(http.StatusUnauthorized).JSON(utils.Error("invalidToken", "Consent token is not valid or expired!"))
+	}
+	lang := setUserLang(c, login.Lang)
+	userId := login.UserId
+
+	pendingDocuments, pendingErr := findPendingLegalDocuments(userId)
+	if pendingErr != nil {
+		log.Error("[LegalConsentHandle] Find pending legal documents %s", pendingErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findLegalDocument", "Error happened while reading legal documents!"))
+	}
+
+	if acceptErr := checkAcceptedDocuments(pendingDocuments, acceptedLegal); acceptErr != nil {
+		if responseType == SPAResponseType {
+			return legalConsentErrorResponse(c, acceptErr)
+		}
+		return legalConsentPageResponse(c, consentToken, pendingDocuments, translateErrorOf(lang, acceptErr))
+	}
+
+	// The token continues one login only
+	consumed, consumeErr := pendingLoginService.ConsumePendingLogin(login)
+	if consumeErr != nil {
+		log.Error("[LegalConsentHandle] Consume pending login %s", consumeErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/consumePendingLogin", "Error happened while reading pending login!"))
+	}
+	if !consumed {
+		return c.Status(http.StatusUnauthorized).JSON(utils.Error("invalidToken", "Consent token is not valid or expired!"))
+	}
+
+	acceptance := getLegalAcceptance(c, acceptedLegal)
+	if recordErr := recordUserConsents(userId, pendingDocuments, acceptance); recordErr != nil {
+		log.Error("[LegalConsentHandle] Record user consents %s", recordErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/saveUserConsent", "Error happened while saving user consent!"))
+	}
+
+	// Identity and role are read again from database
+	userAuthService, serviceErr := service.NewUserAuthService(database.Db)
+	if serviceErr != nil {
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userAuthService", serviceErr.Error()))
+	}
+	foundUserAuth, userAuthErr := userAuthService.FindByUserId(userId)
+	if userAuthErr != nil {
+		log.Error("[LegalConsentHandle] Find user auth %s", userAuthErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findUserAuth", "Error happened while finding user!"))
+	}
+	if foundUserAuth == nil {
+		return c.Status(http.StatusUnauthorized).JSON(utils.Error("userAuthNotFound", "User auth not found"))
+	}
+	foundProfile, profileErr := getUserProfileByID(userId)
+	if profileErr != nil || foundProfile == nil {
+		if profileErr != nil {
+			log.Error("[LegalConsentHandle] Read user profile %s", profileErr.Error())
+		}
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/getUserProfile", "Can not find user profile!"))
+	}
+
+	model := &TokenModel{
+		token:            ProviderAccessToken{AccessToken: login.AccessToken},
+		providerName:     login.ProviderName,
+		organizationList: login.Organizations,
+		profile:          &provider.Profile{Name: foundProfile.FullName, ID: userId.String(), Login: login.Login, Email: foundProfile.Email},
+		claim:            userClaimOf(foundUserAuth, foundProfile),
+	}
+	session, err := createOAuthSession(model, login.OrganizationRole)
+	if err != nil {
+		log.Error("Error creating session: %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/createToken", "Internal server error creating token"))
+	}
+	checkClaimLoginDevice(c, model.claim, login.Lang)
+
+	if responseType != SPAResponseType {
+		return sessionRedirectResponse(c, session, login.State, login.Redirect, login.Lang)
+	}
+
+	writeSessionOnCookie(c, session, &authConfig.AuthConfig)
+	writeUserLangOnCookie(c, login.Lang)
+
+	webURL := authConfig.AuthConfig.ExternalRedirectDomain
+	if len(login.Redirect) > 0 {
+		sessionQuery := "access_token=" + session + "&state=" + login.State + "&expires_in=0"
+		webURL = getURLSchemaHost(login.Redirect) + "/auth/session?" + sessionQuery + "&r=" + login.Redirect
+	}
+	return c.JSON(fiber.Map{
+		"accessToken": session,
+		"redirect":    webURL,
+	})
+}
+
+// legalConsentResponse stop the login and ask user to accept the new legal documents before the session is created
+func legalConsentResponse(c *fiber.Ctx, login pendingLogin, pendingDocuments []dto.LegalDocument) error {
+	consentToken, tokenErr := savePendingLogin(login)
+	if tokenErr != nil {
+		log.Error("[legalConsentResponse] Generate consent token %s", tokenErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/createToken", "Internal server error creating token"))
+	}
+
+	if login.responseType == SPAResponseType {
+		return c.JSON(fiber.Map{
+			"consentRequired": true,
+			"consentToken":    consentToken,
+			"documents":       pendingDocuments,
+		})
+	}
+	return legalConsentPageResponse(c, consentToken, pendingDocuments, "")
+}
+
+// legalConsentPageResponse render the interstitial page of new legal documents
+func legalConsentPageResponse(c *fiber.Ctx, consentToken string, pendingDocuments []dto.LegalDocument, message string) error {
+	appConfig := coreConfig.AppConfig
+	prettyURL := utils.GetPrettyURLf(authConfig.AuthConfig.BaseRoute)
+	return c.Render("legal_consent", fiber.Map{
+		"Title":         i18n.T(getLang(c), "title.legalConsent", *appConfig.AppName),
+		"OrgName":       *appConfig.OrgName,
+		"OrgAvatar":     *appConfig.OrgAvatar,
+		"AppName":       *appConfig.AppName,
+		"ActionForm":    prettyURL + "/legal/consent",
+		"ConsentToken":  consentToken,
+		"Documents":     pendingDocuments,
+		"AcceptedLegal": joinLegalDocumentIds(pendingDocuments),
+		"Message":       message,
+	})
+}
+
+// legalConsentErrorResponse write legal consent error as api response
+func legalConsentErrorResponse(c *fiber.Ctx, err error) error {
+	if legalErr, ok := err.(models.LegalConsentError); ok {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error(legalErr.Code, legalErr.Error()))
+	}
+	log.Error("Error happened while checking legal consent: %s", err.Error())
+	return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findLegalDocument", "Error happened while reading legal documents!"))
+}
+
+// findLatestLegalDocuments get the latest published version of each legal document type
+func findLatestLegalDocuments() ([]dto.LegalDocument, error) {
+	legalDocumentService, serviceErr := service.NewLegalDocumentService(database.Db)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	legalDocuments := []dto.LegalDocument{}
+	for _, documentType := range models.LegalDocumentTypes {
+		legalDocument, err := legalDocumentService.FindLatestByType(documentType)
+		if err != nil {
+			return nil, err
+		}
+		if legalDocument != nil {
+			legalDocuments = append(legalDocuments, *legalDocument)
+		}
+	}
+	return legalDocuments, nil
+}
+
+// findPendingLegalDocuments get the latest legal documents which user has not accepted yet
+func findPendingLegalDocuments(userId uuid.UUID) ([]dto.LegalDocument, error) {
+	latestDocuments, latestErr := findLatestLegalDocuments()
+	if latestErr != nil || len(latestDocuments) == 0 {
+		return nil, latestErr
+	}
+
+	userConsentService, serviceErr := service.NewUserConsentService(database.Db)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	var pendingDocuments []dto.LegalDocument
+	for _, legalDocument := range latestDocuments {
+		accepted, err := userConsentService.HasUserConsent(userId, legalDocument.ObjectId)
+		if err != nil {
+			return nil, err
+		}
+		if !accepted {
+			pendingDocuments = append(pendingDocuments, legalDocument)
+		}
+	}
+	return pendingDocuments, nil
+}
+
+// checkLegalAcceptance check the new user accepted the latest version of all legal documents
+func checkLegalAcceptance(documentIds string) ([]dto.LegalDocument, error) {
+	latestDocuments, latestErr := findLatestLegalDocuments()
+	if latestErr != nil {
+		return nil, latestErr
+	}
+	if acceptErr := checkAcceptedDocuments(latestDocuments, documentIds); acceptErr != nil {
+		return nil, acceptErr
+	}
+	return latestDocuments, nil
+}
+
+// checkAcceptedDocuments check all required documents are in comma separated accepted document ids
+func checkAcceptedDocuments(requiredDocuments []dto.LegalDocument, documentIds string) error {
+	acceptedIds := []string{}
+	for _, documentId := range strings.Split(documentIds, ",") {
+		if documentId = strings.TrimSpace(documentId); documentId != "" {
+			acceptedIds = append(acceptedIds, documentId)
+		}
+	}
+
+	for _, legalDocument := range requiredDocuments {
+		if contains(acceptedIds, legalDocument.ObjectId.String()) {
+			continue
+		}
+		// User accepted documents which are replaced by a new version in the meantime
+		if len(acceptedIds) > 0 {
+			return models.LegalConsentError{Code: models.LegalConsentErrorVersionOutdated}
+		}
+		return models.LegalConsentError{Code: models.LegalConsentErrorRequired}
+	}
+	return nil
+}
+
+// getLegalAcceptance read where legal documents are accepted from request
+func getLegalAcceptance(c *fiber.Ctx, documentIds string) legalAcceptance {
+	return legalAcceptance{
+		documentIds:     documentIds,
+		remoteIpAddress: c.IP(),
+		userAgent:       c.Get(fiber.HeaderUserAgent),
+	}
+}
+
+// recordUserConsents save user acceptance of legal documents
+func recordUserConsents(userId uuid.UUID, legalDocuments []dto.LegalDocument, acceptance legalAcceptance) error {
+	if len(legalDocuments) == 0 {
+		return nil
+	}
+
+	userConsentService, serviceErr := service.NewUserConsentService(database.Db)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	acceptedDate := utils.UTCNowUnix()
+	for _, legalDocument := range legalDocuments {
+		userConsent := &dto.UserConsent{
+			UserId:          userId,
+			DocumentId:      legalDocument.ObjectId,
+			DocumentType:    legalDocument.Type,
+			Version:         legalDocument.Version,
+			AcceptedDate:    acceptedDate,
+			RemoteIpAddress: acceptance.remoteIpAddress,
+			UserAgent:       acceptance.userAgent,
+		}
+		if err := userConsentService.SaveUserConsent(userConsent); err != nil {
+			return fmt.Errorf("save consent of %s version %s: %s", legalDocument.Type, legalDocument.Version, err.Error())
+		}
+	}
+	return nil
+}
+
+// joinLegalDocumentIds join document ids to be accepted in one form value
+func joinLegalDocumentIds(legalDocuments []dto.LegalDocument) string {
+	documentIds := make([]string, 0, len(legalDocuments))
+	for _, legalDocument := range legalDocuments {
+		documentIds = append(documentIds, legalDocument.ObjectId.String())
+	}
+	return strings.Join(documentIds, ",")
+}
+
+// savePendingLogin keep the login on server until user consent and return the random token which continues it
+func savePendingLogin(login pendingLogin) (string, error) {
+	userId, uuidErr := uuid.FromString(login.model.claim.UserId)
+	if uuidErr != nil {
+		return "", uuidErr
+	}
+
+	token, tokenErr := generateDeviceNonce()
+	if tokenErr != nil {
+		return "", tokenErr
+	}
+
+	pendingLoginService, serviceErr := service.NewPendingLoginService(database.Db)
+	if serviceErr != nil {
+		return "", serviceErr
+	}
+
+	saveErr := pendingLoginService.SavePendingLogin(&dto.PendingLogin{
+		TokenHash:        hashToken(token),
+		UserId:           userId,
+		ProviderName:     login.model.providerName,
+		AccessToken:      login.model.token.AccessToken,
+		Organizations:    login.model.organizationList,
+		OrganizationRole: login.organizationRole,
+		Login:            login.model.profile.Login,
+		State:            login.state,
+		Redirect:         login.redirect,
+		Lang:             login.lang,
+		ExpiresAt:        utils.UTCNowUnix() + legalConsentExpiresIn.Milliseconds(),
+	})
+	if saveErr != nil {
+		return "", saveErr
+	}
+	return token, nil
+}
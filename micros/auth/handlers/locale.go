@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	utils "github.com/red-gold/telar-core/utils"
+	"github.com/red-gold/telar-web/micros/auth/i18n"
+	"github.com/red-gold/telar-web/micros/auth/models"
+)
+
+// LocaleMiddleware negotiate request language for views and translate error messages of the response
+func LocaleMiddleware(c *fiber.Ctx) error {
+	c.Locals(langLocalName, negotiateLang(c, ""))
+
+	err := c.Next()
+
+	if c.Response().StatusCode() >= http.StatusBadRequest &&
+		strings.HasPrefix(string(c.Response().Header.ContentType()), fiber.MIMEApplicationJSON) {
+		translateErrorResponse(c)
+	}
+	return err
+}
+
+// negotiateLang choose language from user setting, language cookie and Accept-Language header
+func negotiateLang(c *fiber.Ctx, settingLang string) string {
+	candidates := []string{settingLang, c.Cookies(langCookie)}
+	candidates = append(candidates, i18n.ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage))...)
+	return i18n.Negotiate(candidates...)
+}
+
+// getLang get negotiated language of request
+func getLang(c *fiber.Ctx) string {
+	if lang, ok := c.Locals(langLocalName).(string); ok && lang != "" {
+		return lang
+	}
+	return negotiateLang(c, "")
+}
+
+// setUserLang use language of user setting for the rest of request
+func setUserLang(c *fiber.Ctx, settingLang string) string {
+	lang := negotiateLang(c, settingLang)
+	c.Locals(langLocalName, lang)
+	return lang
+}
+
+// translateError translate message of error code. Messages of default language are kept as they may carry details.
+func translateError(lang, code, message string) string {
+	if i18n.Match(lang) == i18n.DefaultLang() {
+		return message
+	}
+	if translated, ok := i18n.Lookup(lang, "error."+code); ok {
+		return translated
+	}
+	return message
+}
+
+// translateErrorOf translate message of typed auth errors
+func translateErrorOf(lang string, err error) string {
+	switch typedErr := err.(type) {
+	case models.UserAuthError:
+		return translateError(lang, typedErr.Code, typedErr.Error())
+	case models.InvitationError:
+		return translateError(lang, typedErr.Code, typedErr.Error())
+	case models.EmailDomainError:
+		return translateError(lang, typedErr.Code, typedErr.Error())
+	case models.LegalConsentError:
+		return translateError(lang, typedErr.Code, typedErr.Error())
+	case models.SocialNameError:
+		return translateError(lang, typedErr.Code, typedErr.Error())
+	case models.CustomFieldError:
+		return translateError(lang, typedErr.Code, typedErr.Error())
+	case models.UserImportError:
+		return translateError(lang, typedErr.Code, typedErr.Error())
+	}
+	return err.Error()
+}
+
+// translateErrorResponse replace message of telar error in response body
+func translateErrorResponse(c *fiber.Ctx) {
+	var telarError utils.TelarError
+	if json.Unmarshal(c.Response().Body(), &telarError) != nil || telarError.Error.Code == "" {
+		return
+	}
+
+	message := translateError(getLang(c), telarError.Error.Code, telarError.Error.Message)
+	if message == telarError.Error.Message {
+		return
+	}
+	telarError.Error.Message = message
+	c.JSON(telarError)
+}
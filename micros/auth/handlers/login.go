@@ -17,6 +17,7 @@ import (
 	utils "github.com/red-gold/telar-core/utils"
 	authConfig "github.com/red-gold/telar-web/micros/auth/config"
 	"github.com/red-gold/telar-web/micros/auth/database"
+	"github.com/red-gold/telar-web/micros/auth/i18n"
 	models "github.com/red-gold/telar-web/micros/auth/models"
 	"github.com/red-gold/telar-web/micros/auth/provider"
 	service "github.com/red-gold/telar-web/micros/auth/services"
@@ -53,6 +54,8 @@ const oauthGoogleUrlAPI = "https://www.googleapis.com/oauth2/v2/userinfo?access_
 // @Description Redirects the user to GitHub for authentication
 // @Tags Login
 // @Produce  json
+// @Param invite query string false "Invitation code for new users"
+// @Param acceptedLegal query string false "Comma separated ids of legal documents accepted by new users"
 // @Success 307 {string} string "Redirect to GitHub"
 // @Router /login/github [get]
 func LoginGithubHandler(c *fiber.Ctx) error {
@@ -66,6 +69,14 @@ func LoginGithubHandler(c *fiber.Ctx) error {
 		resource = val
 	}
 
+	// Keep invitation code and accepted legal documents for the new user signup after OAuth callback
+	if inviteCode := c.Query("invite"); len(inviteCode) > 0 {
+		c.Cookie(&fiber.Cookie{Name: inviteCookie, Value: inviteCode, Expires: time.Now().Add(time.Hour), HTTPOnly: true})
+	}
+	if acceptedLegal := c.Query("acceptedLegal"); len(acceptedLegal) > 0 {
+		c.Cookie(&fiber.Cookie{Name: legalCookie, Value: acceptedLegal, Expires: time.Now().Add(time.Hour), HTTPOnly: true})
+	}
+
 	u := buildGitHubURL(&config, resource, "read:org,read:user,user:email")
 	return c.Redirect(u.String(), http.StatusTemporaryRedirect)
 
@@ -105,7 +116,7 @@ func LoginPageHandler(c *fiber.Ctx) error {
 	authConfig := &authConfig.AuthConfig
 	prettyURL := utils.GetPrettyURLf(authConfig.BaseRoute)
 	loginData := &loginPageData{
-		title:         "Login - Telar Social",
+		title:         i18n.T(getLang(c), "title.login", *appConfig.AppName),
 		orgName:       *appConfig.OrgName,
 		orgAvatar:     *appConfig.OrgAvatar,
 		appName:       *appConfig.AppName,
@@ -149,12 +160,6 @@ func LoginTelarHandler(c *fiber.Ctx) error {
 
 func LoginTelarHandlerSPA(c *fiber.Ctx, model *models.LoginModel) error {
 
-	// Create service
-	userAuthService, serviceErr := service.NewUserAuthService(database.Db)
-	if serviceErr != nil {
-		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userAuthService", serviceErr.Error()))
-	}
-
 	if model.Username == "" {
 		log.Error("Username is required!")
 		return c.Status(http.StatusBadRequest).JSON(utils.Error("usernameIsRequired", "Username is required!"))
@@ -166,27 +171,13 @@ func LoginTelarHandlerSPA(c *fiber.Ctx, model *models.LoginModel) error {
 		return c.Status(http.StatusBadRequest).JSON(utils.Error("passwordIsRequired", "Password is required!"))
 	}
 
-	foundUser, err := userAuthService.FindByUsername(model.Username)
-	if err != nil || foundUser == nil {
-		if err != nil {
-			log.Error(" User not found %s", err.Error())
+	foundUser, authErr := authenticateUser(model.Username, model.Password)
+	if authErr != nil {
+		if userAuthErr, ok := authErr.(models.UserAuthError); ok {
+			return c.Status(http.StatusBadRequest).JSON(utils.Error(userAuthErr.Code, userAuthErr.Error()))
 		}
-		log.Error("User not found!")
-		return c.Status(http.StatusBadRequest).JSON(utils.Error("findUserByUserName", "User not found!"))
-
-	}
-
-	if !foundUser.EmailVerified && !foundUser.PhoneVerified {
-
-		log.Error("User is not verified!")
-		return c.Status(http.StatusBadRequest).JSON(utils.Error("userNotVerified", "User is not verified!"))
-	}
-
-	log.Info(" foundUser.Password: %s  , model.Password: %s", foundUser.Password, model.Password)
-	compareErr := utils.CompareHash(foundUser.Password, []byte(model.Password))
-	if compareErr != nil {
-		log.Error("Password doesn't match %s", compareErr.Error())
-		return c.Status(http.StatusBadRequest).JSON(utils.Error("passwordNotMatch", "Password doesn't match!"))
+		log.Error("Authenticate user %s", authErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/authenticateUser", "Error happened while authenticating user!"))
 	}
 
 	profileChannel := readProfileAsync(foundUser.ObjectId)
@@ -206,6 +197,7 @@ func LoginTelarHandlerSPA(c *fiber.Ctx, model *models.LoginModel) error {
 	langSettigPath := getSettingPath(foundUser.ObjectId, "lang", "current")
 	if val, ok := langResult.settings[langSettigPath]; ok && val != "" {
 		currentUserLang = val
+		setUserLang(c, currentUserLang)
 	} else {
 		go func() {
 			userInfoReq := &UserInfoInReq{
@@ -226,22 +218,46 @@ func LoginTelarHandlerSPA(c *fiber.Ctx, model *models.LoginModel) error {
 		profile:          &provider.Profile{Name: foundUser.Username, ID: foundUser.ObjectId.String(), Login: foundUser.Username},
 		organizationList: "Red Gold",
 		claim: UserClaim{
-			DisplayName: profileResult.Profile.FullName,
-			SocialName:  profileResult.Profile.SocialName,
-			Email:       profileResult.Profile.Email,
-			Avatar:      profileResult.Profile.Avatar,
-			Banner:      profileResult.Profile.Banner,
-			TagLine:     profileResult.Profile.TagLine,
-			UserId:      foundUser.ObjectId.String(),
-			Role:        foundUser.Role,
-			CreatedDate: foundUser.CreatedDate,
+			DisplayName:      profileResult.Profile.FullName,
+			SocialName:       profileResult.Profile.SocialName,
+			Email:            profileResult.Profile.Email,
+			Avatar:           profileResult.Profile.Avatar,
+			Banner:           profileResult.Profile.Banner,
+			TagLine:          profileResult.Profile.TagLine,
+			VerificationType: verificationTypeOf(profileResult.Profile),
+			UserId:           foundUser.ObjectId.String(),
+			Role:             foundUser.Role,
+			CreatedDate:      foundUser.CreatedDate,
 		},
 	}
+
+	pendingDocuments, pendingErr := findPendingLegalDocuments(foundUser.ObjectId)
+	if pendingErr != nil {
+		log.Error("Find pending legal documents %s", pendingErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findLegalDocument", "Error happened while reading legal documents!"))
+	}
+	if len(pendingDocuments) > 0 {
+		return legalConsentResponse(c, pendingLogin{
+			model:        tokenModel,
+			state:        model.State,
+			redirect:     c.Query("r"),
+			lang:         currentUserLang,
+			responseType: SPAResponseType,
+		}, pendingDocuments)
+	}
+
 	session, err := createToken(tokenModel)
 	if err != nil {
 		log.Error("Error creating session: %s", err.Error())
 		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/createToken", "Internal server error creating token"))
 	}
+	checkLoginDevice(c, &UserInfoInReq{
+		UserId:      foundUser.ObjectId,
+		Username:    foundUser.Username,
+		Avatar:      profileResult.Profile.Avatar,
+		DisplayName: profileResult.Profile.FullName,
+		SystemRole:  foundUser.Role,
+	}, profileResult.Profile.Email, getLang(c))
 
 	// Write session on cookie
 	writeSessionOnCookie(c, session, &authConfig.AuthConfig)
@@ -281,8 +297,9 @@ func LoginTelarHandlerSPA(c *fiber.Ctx, model *models.LoginModel) error {
 // LoginTelarHandlerSSR creates a handler for logging in telar social
 func LoginTelarHandlerSSR(c *fiber.Ctx, model *models.LoginModel) error {
 
+	lang := getLang(c)
 	loginData := &loginPageData{
-		title:         "Login - " + *coreConfig.AppConfig.AppName,
+		title:         i18n.T(lang, "title.login", *coreConfig.AppConfig.AppName),
 		orgName:       *coreConfig.AppConfig.OrgName,
 		orgAvatar:     *coreConfig.AppConfig.OrgAvatar,
 		appName:       *coreConfig.AppConfig.AppName,
@@ -292,44 +309,25 @@ func LoginTelarHandlerSSR(c *fiber.Ctx, model *models.LoginModel) error {
 		message:       "",
 	}
 
-	// Create service
-	userAuthService, serviceErr := service.NewUserAuthService(database.Db)
-	if serviceErr != nil {
-		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userAuthService", serviceErr.Error()))
-	}
-
 	if model.Username == "" {
 		log.Error(" Username is empty")
-		loginData.message = "Username is required!"
+		loginData.message = i18n.T(lang, "error.usernameIsRequired")
 		return loginPageResponse(c, loginData)
 	}
 
 	if model.Password == "" {
 		log.Error(" Password is empty")
-		loginData.message = "Password is required!"
+		loginData.message = i18n.T(lang, "error.passwordIsRequired")
 		return loginPageResponse(c, loginData)
 	}
 
-	foundUser, err := userAuthService.FindByUsername(model.Username)
-	if err != nil || foundUser == nil {
-		if err != nil {
-			log.Error(" User not found %s", err.Error())
+	foundUser, authErr := authenticateUser(model.Username, model.Password)
+	if authErr != nil {
+		log.Error("Authenticate user %s", authErr.Error())
+		loginData.message = i18n.T(lang, "error.internal/authenticateUser")
+		if _, ok := authErr.(models.UserAuthError); ok {
+			loginData.message = translateErrorOf(lang, authErr)
 		}
-		loginData.message = "User not found!"
-		return loginPageResponse(c, loginData)
-	}
-
-	log.Error("[INFO] Found user auth: %v", foundUser)
-	if !foundUser.EmailVerified && !foundUser.PhoneVerified {
-
-		loginData.message = "User is not verified!"
-		return loginPageResponse(c, loginData)
-	}
-	log.Info(" foundUser.Password: %s  , model.Password: %s", foundUser.Password, model.Password)
-	compareErr := utils.CompareHash(foundUser.Password, []byte(model.Password))
-	if compareErr != nil {
-		log.Error("Password doesn't match %s", compareErr.Error())
-		loginData.message = "Password doesn't match!"
 		return loginPageResponse(c, loginData)
 	}
 
@@ -342,7 +340,7 @@ func LoginTelarHandlerSSR(c *fiber.Ctx, model *models.LoginModel) error {
 		if profileResult.Error != nil {
 			log.Error(" User profile  %s", profileResult.Error.Error())
 		}
-		loginData.message = "User Profile error!"
+		loginData.message = i18n.T(lang, "error.internal/getUserProfile")
 		return loginPageResponse(c, loginData)
 	}
 
@@ -351,6 +349,7 @@ func LoginTelarHandlerSSR(c *fiber.Ctx, model *models.LoginModel) error {
 	langSettigPath := getSettingPath(foundUser.ObjectId, "lang", "current")
 	if val, ok := langResult.settings[langSettigPath]; ok && val != "" {
 		currentUserLang = val
+		setUserLang(c, currentUserLang)
 	} else {
 		go func() {
 			userInfoReq := &UserInfoInReq{
@@ -371,22 +370,48 @@ func LoginTelarHandlerSSR(c *fiber.Ctx, model *models.LoginModel) error {
 		profile:          &provider.Profile{Name: foundUser.Username, ID: foundUser.ObjectId.String(), Login: foundUser.Username},
 		organizationList: "Red Gold",
 		claim: UserClaim{
-			DisplayName: profileResult.Profile.FullName,
-			SocialName:  profileResult.Profile.SocialName,
-			Email:       profileResult.Profile.Email,
-			Avatar:      profileResult.Profile.Avatar,
-			Banner:      profileResult.Profile.Banner,
-			TagLine:     profileResult.Profile.TagLine,
-			UserId:      foundUser.ObjectId.String(),
-			Role:        foundUser.Role,
-			CreatedDate: foundUser.CreatedDate,
+			DisplayName:      profileResult.Profile.FullName,
+			SocialName:       profileResult.Profile.SocialName,
+			Email:            profileResult.Profile.Email,
+			Avatar:           profileResult.Profile.Avatar,
+			Banner:           profileResult.Profile.Banner,
+			TagLine:          profileResult.Profile.TagLine,
+			VerificationType: verificationTypeOf(profileResult.Profile),
+			UserId:           foundUser.ObjectId.String(),
+			Role:             foundUser.Role,
+			CreatedDate:      foundUser.CreatedDate,
 		},
 	}
+
+	// Ask for consent before the session when a new version of legal documents is published
+	pendingDocuments, pendingErr := findPendingLegalDocuments(foundUser.ObjectId)
+	if pendingErr != nil {
+		log.Error("Find pending legal documents %s", pendingErr.Error())
+		loginData.message = i18n.T(lang, "error.internal/findLegalDocument")
+		return loginPageResponse(c, loginData)
+	}
+	if len(pendingDocuments) > 0 {
+		return legalConsentResponse(c, pendingLogin{
+			model:        tokenModel,
+			state:        model.State,
+			redirect:     c.Query("r"),
+			lang:         currentUserLang,
+			responseType: SSRResponseType,
+		}, pendingDocuments)
+	}
+
 	session, err := createToken(tokenModel)
 	if err != nil {
 		log.Error("Error creating session: %s", err.Error())
 		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/createToken", "Internal server error creating token"))
 	}
+	checkLoginDevice(c, &UserInfoInReq{
+		UserId:      foundUser.ObjectId,
+		Username:    foundUser.Username,
+		Avatar:      profileResult.Profile.Avatar,
+		DisplayName: profileResult.Profile.FullName,
+		SystemRole:  foundUser.Role,
+	}, profileResult.Profile.Email, getLang(c))
 
 	// Write session on cookie
 	writeSessionOnCookie(c, session, &authConfig.AuthConfig)
@@ -497,15 +522,16 @@ func LoginAdminHandler(c *fiber.Ctx) error {
 		profile:          &provider.Profile{Name: foundUser.Username, ID: foundUser.ObjectId.String(), Login: foundUser.Username},
 		organizationList: *coreConfig.AppConfig.OrgName,
 		claim: UserClaim{
-			DisplayName: foundUserProfile.FullName,
-			SocialName:  foundUserProfile.SocialName,
-			Email:       foundUserProfile.Email,
-			Avatar:      foundUserProfile.Avatar,
-			Banner:      foundUserProfile.Banner,
-			TagLine:     foundUserProfile.TagLine,
-			UserId:      foundUser.ObjectId.String(),
-			Role:        foundUser.Role,
-			CreatedDate: foundUser.CreatedDate,
+			DisplayName:      foundUserProfile.FullName,
+			SocialName:       foundUserProfile.SocialName,
+			Email:            foundUserProfile.Email,
+			Avatar:           foundUserProfile.Avatar,
+			Banner:           foundUserProfile.Banner,
+			TagLine:          foundUserProfile.TagLine,
+			VerificationType: verificationTypeOf(foundUserProfile),
+			UserId:           foundUser.ObjectId.String(),
+			Role:             foundUser.Role,
+			CreatedDate:      foundUser.CreatedDate,
 		},
 	}
 	session, err := createToken(tokenModel)
@@ -0,0 +1,425 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	uuid "github.com/gofrs/uuid"
+	coreConfig "github.com/red-gold/telar-core/config"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/utils"
+	"github.com/red-gold/telar-web/constants"
+	authConfig "github.com/red-gold/telar-web/micros/auth/config"
+	"github.com/red-gold/telar-web/micros/auth/database"
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+	"github.com/red-gold/telar-web/micros/auth/i18n"
+	"github.com/red-gold/telar-web/micros/auth/models"
+	service "github.com/red-gold/telar-web/micros/auth/services"
+	"github.com/valyala/bytebufferpool"
+)
+
+const (
+	loginAlertSettingKey = "send_email_on_new_login"
+	// loginAlertSettingDefault is set by initUserSetup and used for users who have no such setting
+	loginAlertSettingDefault = "true"
+	loginAlertExpiresIn      = 7 * 24 * time.Hour
+	deviceCookieExpiresIn    = 365 * 24 * time.Hour
+)
+
+// loginDevice device information of a login request
+type loginDevice struct {
+	userAgent   string
+	ipAddress   string
+	ipRange     string
+	fingerprint string
+	// legacyFingerprint is the user agent hash which identified devices before the device cookie
+	legacyFingerprint string
+}
+
+// getLoginDevice read device information from request.
+// The fingerprint includes a random device id kept on cookie, so a copied user agent is not enough to look like a known device.
+func getLoginDevice(c *fiber.Ctx) loginDevice {
+	userAgent := strings.TrimSpace(c.Get(fiber.HeaderUserAgent))
+
+	deviceId := c.Cookies(deviceCookie)
+	if deviceId == "" {
+		var nonceErr error
+		deviceId, nonceErr = generateDeviceNonce()
+		if nonceErr != nil {
+			log.Error("[getLoginDevice] Generate device id %s", nonceErr.Error())
+		}
+	}
+	c.Cookie(&fiber.Cookie{
+		HTTPOnly: true,
+		Name:     deviceCookie,
+		Value:    deviceId,
+		Path:     "/",
+		Expires:  time.Now().Add(deviceCookieExpiresIn),
+		Domain:   authConfig.AuthConfig.CookieRootDomain,
+	})
+
+	hash := sha256.Sum256([]byte(deviceId + "\n" + strings.ToLower(userAgent)))
+	legacyHash := sha256.Sum256([]byte(strings.ToLower(userAgent)))
+	return loginDevice{
+		userAgent:         userAgent,
+		ipAddress:         c.IP(),
+		ipRange:           getIPRange(c.IP()),
+		fingerprint:       hex.EncodeToString(hash[:]),
+		legacyFingerprint: hex.EncodeToString(legacyHash[:]),
+	}
+}
+
+// getIPRange return /24 network for IPv4 and /48 network for IPv6 address
+func getIPRange(ipAddress string) string {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return ipAddress
+	}
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return (&net.IPNet{IP: ipv4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
+
+// checkLoginDevice remember the login device and alert user when it is a new device or IP range
+func checkLoginDevice(c *fiber.Ctx, userInfo *UserInfoInReq, email string, lang string) {
+	device := getLoginDevice(c)
+	app := c.App()
+	go func() {
+		if err := notifyLoginDevice(app, device, userInfo, email, lang); err != nil {
+			log.Error("[checkLoginDevice] %s", err.Error())
+		}
+	}()
+}
+
+// checkClaimLoginDevice check login device of user in session claim
+func checkClaimLoginDevice(c *fiber.Ctx, claim UserClaim, settingLang string) {
+	userId, uuidErr := uuid.FromString(claim.UserId)
+	if uuidErr != nil {
+		log.Error("[checkClaimLoginDevice] Parse user id %s", uuidErr.Error())
+		return
+	}
+	checkLoginDevice(c, &UserInfoInReq{
+		UserId:      userId,
+		Username:    claim.Email,
+		Avatar:      claim.Avatar,
+		DisplayName: claim.DisplayName,
+		SystemRole:  claim.Role,
+	}, claim.Email, negotiateLang(c, settingLang))
+}
+
+// notifyLoginDevice store device fingerprint and send security alert for unknown devices
+func notifyLoginDevice(app *fiber.App, device loginDevice, userInfo *UserInfoInReq, email string, lang string) error {
+
+	userDeviceService, serviceErr := service.NewUserDeviceService(database.Db)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	knownDevices, findErr := userDeviceService.FindByUserId(userInfo.UserId)
+	if findErr != nil {
+		return fmt.Errorf("find user devices %s", findErr.Error())
+	}
+
+	var currentDevice *dto.UserDevice
+	knownRange := false
+	var legacyDevice *dto.UserDevice
+	for index, knownDevice := range knownDevices {
+		if knownDevice.Fingerprint == device.fingerprint {
+			currentDevice = &knownDevices[index]
+		}
+		if knownDevice.Fingerprint == device.legacyFingerprint {
+			legacyDevice = &knownDevices[index]
+		}
+		if contains(knownDevice.IPRanges, device.ipRange) {
+			knownRange = true
+		}
+	}
+
+	// A device known before the device cookie is bound to the cookie on its next login
+	if currentDevice == nil && legacyDevice != nil {
+		if bindErr := userDeviceService.BindUserDevice(legacyDevice.ObjectId, device.fingerprint); bindErr != nil {
+			return fmt.Errorf("bind user device %s", bindErr.Error())
+		}
+		currentDevice = legacyDevice
+	}
+
+	deviceKnown := currentDevice != nil
+	if deviceKnown {
+		if touchErr := userDeviceService.TouchUserDevice(currentDevice.ObjectId, device.ipRange, device.ipAddress); touchErr != nil {
+			return fmt.Errorf("touch user device %s", touchErr.Error())
+		}
+	} else {
+		currentDevice = &dto.UserDevice{
+			UserId:        userInfo.UserId,
+			Fingerprint:   device.fingerprint,
+			UserAgent:     device.userAgent,
+			IPRanges:      []string{device.ipRange},
+			LastIPAddress: device.ipAddress,
+		}
+		if saveErr := userDeviceService.SaveUserDevice(currentDevice); saveErr != nil {
+			return fmt.Errorf("save user device %s", saveErr.Error())
+		}
+	}
+
+	// The first login of account only introduces the device
+	if len(knownDevices) == 0 || (deviceKnown && knownRange) {
+		return nil
+	}
+
+	if !isLoginAlertEnabled(userInfo) {
+		return nil
+	}
+
+	token, tokenErr := generateDeviceNonce()
+	if tokenErr != nil {
+		return fmt.Errorf("generate login alert token %s", tokenErr.Error())
+	}
+	expiresAt := utils.UTCNowUnix() + loginAlertExpiresIn.Milliseconds()
+	if setErr := userDeviceService.SetAlertToken(currentDevice.ObjectId, hashToken(token), expiresAt); setErr != nil {
+		return fmt.Errorf("save login alert token %s", setErr.Error())
+	}
+
+	appConfig := coreConfig.AppConfig
+	config := authConfig.AuthConfig
+	prettyURL := utils.GetPrettyURLf(config.BaseRoute)
+
+	deviceName := device.userAgent
+	if deviceName == "" {
+		deviceName = i18n.T(lang, "email.loginAlert.unknownDevice")
+	}
+
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+	emailData := fiber.Map{
+		"Lang":      lang,
+		"Name":      userInfo.DisplayName,
+		"AppName":   *appConfig.AppName,
+		"AppURL":    config.WebURL,
+		"Device":    deviceName,
+		"IPAddress": device.ipAddress,
+		"LoginTime": time.Now().UTC().Format(time.RFC1123),
+		"Link":      fmt.Sprintf("%s%s/login/alert/%s", config.AuthWebURI, prettyURL, token),
+		"OrgName":   *appConfig.OrgName,
+		"OrgAvatar": *appConfig.OrgAvatar,
+	}
+	app.Config().Views.Render(buf, "email_login_alert", emailData, app.Config().ViewsLayout)
+
+	emailClient := utils.NewEmail(*appConfig.RefEmail, *appConfig.RefEmailPass, *appConfig.SmtpEmail)
+	emailReq := utils.NewEmailRequest([]string{email}, i18n.T(lang, "email.loginAlert.subject"), buf.String())
+	emailResStatus, emailResErr := emailClient.SendEmail(emailReq)
+	if emailResErr != nil {
+		return fmt.Errorf("send login alert email %s", emailResErr.Error())
+	}
+	if !emailResStatus {
+		return fmt.Errorf("login alert email response status is false")
+	}
+	return nil
+}
+
+// isLoginAlertEnabled read login alert option from user notification settings
+func isLoginAlertEnabled(userInfo *UserInfoInReq) bool {
+	model := models.GetSettingsModel{
+		UserIds: []uuid.UUID{userInfo.UserId},
+		Type:    "notification",
+	}
+	payload, marshalErr := json.Marshal(model)
+	if marshalErr != nil {
+		return true
+	}
+
+	resData, callErr := functionCall(http.MethodPost, payload, "/setting/dto/ids", getHeadersFromUserInfoReq(userInfo))
+	if callErr != nil {
+		log.Error("[isLoginAlertEnabled] Get notification settings %s", callErr.Error())
+		return true
+	}
+
+	var settings map[string]string
+	json.Unmarshal(resData, &settings)
+
+	value, ok := settings[getSettingPath(userInfo.UserId, "notification", loginAlertSettingKey)]
+	if !ok {
+		value = loginAlertSettingDefault
+	}
+	return value != "false"
+}
+
+// findLoginAlertDevice find the reported device by token of "this wasn't me" link
+func findLoginAlertDevice(c *fiber.Ctx) (*dto.UserDevice, error) {
+	userDeviceService, serviceErr := service.NewUserDeviceService(database.Db)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+	return userDeviceService.FindByAlertToken(hashToken(c.Params("token")))
+}
+
+// LoginAlertHandler godoc
+// @Summary Login alert confirm page
+// @Description "This wasn't me" link of new sign-in alert. Shows a page to confirm securing the account, so opening the link alone changes nothing.
+// @Tags Login
+// @Produce  html
+// @Param token path string true "Login alert token"
+// @Success 200 {string} string "Confirm page HTML"
+// @Failure 401 {object} utils.TelarError
+// @Failure 500 {object} utils.TelarError
+// @Router /login/alert/{token} [get]
+func LoginAlertHandler(c *fiber.Ctx) error {
+	appConfig := coreConfig.AppConfig
+	config := authConfig.AuthConfig
+	lang := getLang(c)
+
+	foundDevice, findErr := findLoginAlertDevice(c)
+	if findErr != nil {
+		log.Error("[LoginAlertHandler] Find user device %s", findErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findUserDevice", "Error happened while reading login alert!"))
+	}
+	if foundDevice == nil {
+		return c.Status(http.StatusUnauthorized).JSON(utils.Error("invalidToken", "Login alert link is not valid or expired!"))
+	}
+
+	deviceName := foundDevice.UserAgent
+	if deviceName == "" {
+		deviceName = i18n.T(lang, "email.loginAlert.unknownDevice")
+	}
+
+	prettyURL := utils.GetPrettyURLf(config.BaseRoute)
+	return c.Render("login_alert", fiber.Map{
+		"Title":      i18n.T(lang, "title.secureAccount", *appConfig.AppName),
+		"OrgAvatar":  *appConfig.OrgAvatar,
+		"AppName":    *appConfig.AppName,
+		"Device":     deviceName,
+		"ActionForm": fmt.Sprintf("%s/login/alert/%s", prettyURL, c.Params("token")),
+	})
+}
+
+// ConfirmLoginAlertHandler godoc
+// @Summary Report unknown sign-in
+// @Description Confirm of "this wasn't me" link. Revokes the user sessions, forgets the device and sends a reset password link. The link can be used once.
+// @Tags Login
+// @Accept  x-www-form-urlencoded
+// @Produce  html
+// @Param token path string true "Login alert token"
+// @Success 200 {string} string "Message page HTML"
+// @Failure 401 {object} utils.TelarError
+// @Failure 404 {object} utils.TelarError
+// @Failure 500 {object} utils.TelarError
+// @Router /login/alert/{token} [post]
+func ConfirmLoginAlertHandler(c *fiber.Ctx) error {
+	appConfig := coreConfig.AppConfig
+	lang := getLang(c)
+
+	foundDevice, findErr := findLoginAlertDevice(c)
+	if findErr != nil {
+		log.Error("[ConfirmLoginAlertHandler] Find user device %s", findErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findUserDevice", "Error happened while reading login alert!"))
+	}
+	if foundDevice == nil {
+		return c.Status(http.StatusUnauthorized).JSON(utils.Error("invalidToken", "Login alert link is not valid or expired!"))
+	}
+
+	// Create service
+	userAuthService, serviceErr := service.NewUserAuthService(database.Db)
+	if serviceErr != nil {
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userAuthService", serviceErr.Error()))
+	}
+
+	userDeviceService, serviceErr := service.NewUserDeviceService(database.Db)
+	if serviceErr != nil {
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userDeviceService", serviceErr.Error()))
+	}
+
+	foundUserAuth, userAuthErr := userAuthService.FindByUserId(foundDevice.UserId)
+	if userAuthErr != nil {
+		log.Error("[ConfirmLoginAlertHandler] Find user auth %s", userAuthErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findUserAuth", "Error happened while finding user!"))
+	}
+	if foundUserAuth == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("userAuthNotFound", "User auth not found"))
+	}
+
+	// Forgetting the device also drops its token
+	if deleteErr := userDeviceService.DeleteUserDevice(foundDevice.ObjectId, foundDevice.UserId); deleteErr != nil {
+		log.Error("[ConfirmLoginAlertHandler] Delete user device %s", deleteErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/deleteUserDevice", "Error happened while removing device!"))
+	}
+
+	if revokeErr := userAuthService.RevokeSessions(foundDevice.UserId); revokeErr != nil {
+		log.Error("[ConfirmLoginAlertHandler] Revoke sessions %s", revokeErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/revokeSessions", "Error happened while revoking sessions!"))
+	}
+	clearSessionCookies(c)
+
+	if resetErr := sendResetPasswordLink(c, foundUserAuth); resetErr != nil {
+		log.Error("[ConfirmLoginAlertHandler] Send reset password link %s", resetErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("sendEmailError", "Unable to send reset password email!"))
+	}
+
+	return c.Render("message", fiber.Map{
+		"Title":     i18n.T(lang, "title.secureAccount", *appConfig.AppName),
+		"OrgAvatar": *appConfig.OrgAvatar,
+		"Message":   i18n.T(lang, "message.sessionsRevoked", foundUserAuth.Username),
+	})
+}
+
+// sendResetPasswordLink create reset password verification and email the link to user
+func sendResetPasswordLink(c *fiber.Ctx, userAuth *dto.UserAuth) error {
+	appConfig := coreConfig.AppConfig
+	lang := getLang(c)
+	config := authConfig.AuthConfig
+
+	userVerificationService, serviceErr := service.NewUserVerificationService(database.Db)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	verifyId := uuid.Must(uuid.NewV4())
+	saveErr := userVerificationService.SaveUserVerification(&dto.UserVerification{
+		ObjectId:        verifyId,
+		UserId:          userAuth.ObjectId,
+		Code:            "0",
+		Target:          userAuth.Username,
+		TargetType:      constants.EmailVerifyConst,
+		Counter:         1,
+		RemoteIpAddress: c.IP(),
+	})
+	if saveErr != nil {
+		return fmt.Errorf("save user verification %s", saveErr.Error())
+	}
+
+	token, tokenErr := generateResetPasswordToken(verifyId.String())
+	if tokenErr != nil {
+		return fmt.Errorf("generate reset password token %s", tokenErr.Error())
+	}
+
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+	prettyURL := utils.GetPrettyURLf(config.BaseRoute)
+	emailData := fiber.Map{
+		"Lang":      lang,
+		"Name":      userAuth.Username,
+		"AppName":   *appConfig.AppName,
+		"AppURL":    config.WebURL,
+		"Link":      fmt.Sprintf("%s%s/password/reset/%s", config.AuthWebURI, prettyURL, token),
+		"Email":     userAuth.Username,
+		"OrgName":   *appConfig.OrgName,
+		"OrgAvatar": *appConfig.OrgAvatar,
+	}
+	c.App().Config().Views.Render(buf, "email_link_verify_reset_pass", emailData, c.App().Config().ViewsLayout)
+
+	emailClient := utils.NewEmail(*appConfig.RefEmail, *appConfig.RefEmailPass, *appConfig.SmtpEmail)
+	emailResStatus, emailResErr := emailClient.SendEmail(utils.NewEmailRequest([]string{userAuth.Username}, i18n.T(lang, "email.resetPassword.subject"), buf.String()))
+	if emailResErr != nil {
+		return emailResErr
+	}
+	if !emailResStatus {
+		return fmt.Errorf("email response status is false")
+	}
+	return nil
+}
@@ -25,8 +25,9 @@ import (
 
 const profileFetchTimeout = time.Second * 5
 
-// checkOAuthSignup check for user oauth signup in the case user does not exist in user auth
-func checkOAuthSignup(accessToken string, model *TokenModel, currentUserLang *string, db interface{}) error {
+// checkOAuthSignup check for user oauth signup in the case user does not exist in user auth.
+// New users who did not accept legal documents before leaving to the provider are asked on login.
+func checkOAuthSignup(accessToken string, model *TokenModel, currentUserLang *string, inviteCode string, legal legalAcceptance, db interface{}) error {
 
 	if model.profile.Name == "" {
 		log.Error("[ERROR]: OAuth provide - name can not be empty")
@@ -55,12 +56,33 @@ func checkOAuthSignup(accessToken string, model *TokenModel, currentUserLang *st
 	fmt.Printf("[INFO]: Oauth check signup - user auth object %v", userAuth)
 
 	if userAuth == nil {
+		if domainErr := checkEmailDomain(model.profile.Email); domainErr != nil {
+			return domainErr
+		}
+
+		invitation, invitationErr := checkSignupInvitation(inviteCode)
+		if invitationErr != nil {
+			return invitationErr
+		}
+
+		var legalDocuments []dto.LegalDocument
+		if legal.documentIds != "" {
+			var legalErr error
+			legalDocuments, legalErr = checkLegalAcceptance(legal.documentIds)
+			if legalErr != nil {
+				return legalErr
+			}
+		}
+
 		// Create signup token
 		newUserId, uuidErr := uuid.NewV4()
 		if uuidErr != nil {
 			fmt.Printf("[Error]: uuid.NewV4 error: %s", uuidErr.Error())
 			return uuidErr
 		}
+		if useErr := useSignupInvitation(invitation, newUserId); useErr != nil {
+			return useErr
+		}
 		createdDate := utils.UTCNowUnix()
 
 		newUserAuth := &dto.UserAuth{
@@ -80,6 +102,9 @@ func checkOAuthSignup(accessToken string, model *TokenModel, currentUserLang *st
 			return userAuthErr
 		}
 		model.profile.ID = newUserId.String()
+		if model.profile.Avatar == "" {
+			model.profile.Avatar = defaultAvatarURL(newUserId)
+		}
 		newUserProfile := &models.UserProfileModel{
 			ObjectId:    newUserId,
 			FullName:    model.profile.Name,
@@ -88,8 +113,9 @@ func checkOAuthSignup(accessToken string, model *TokenModel, currentUserLang *st
 			LastUpdated: createdDate,
 			Email:       model.profile.Email,
 			Avatar:      model.profile.Avatar,
-			Banner:      fmt.Sprintf("https://picsum.photos/id/%d/900/300/?blur", generateRandomNumber(1, 1000)),
+			Banner:      defaultBannerURL(newUserId),
 			Permission:  constants.Public,
+			InvitedBy:   getInvitationOwner(invitation),
 		}
 		userProfileErr := saveUserProfile(newUserProfile)
 		if userProfileErr != nil {
@@ -100,6 +126,9 @@ func checkOAuthSignup(accessToken string, model *TokenModel, currentUserLang *st
 		if setupErr != nil {
 			return fmt.Errorf("Cannot initialize user setup! error: %s", setupErr.Error())
 		}
+		if consentErr := recordUserConsents(newUserId, legalDocuments, legal); consentErr != nil {
+			log.Error("[checkOAuthSignup] Record user consents %s", consentErr.Error())
+		}
 		model.profile.ID = newUserAuth.ObjectId.String()
 		model.claim = UserClaim{
 			DisplayName: newUserProfile.FullName,
@@ -153,14 +182,15 @@ func checkOAuthSignup(accessToken string, model *TokenModel, currentUserLang *st
 		model.profile.Name = profileResult.Profile.FullName
 		model.profile.Avatar = profileResult.Profile.Avatar
 		model.claim = UserClaim{
-			DisplayName: profileResult.Profile.FullName,
-			Email:       profileResult.Profile.Email,
-			UserId:      userAuth.ObjectId.String(),
-			Role:        userAuth.Role,
-			Avatar:      profileResult.Profile.Avatar,
-			Banner:      profileResult.Profile.Banner,
-			TagLine:     profileResult.Profile.TagLine,
-			CreatedDate: userAuth.CreatedDate,
+			DisplayName:      profileResult.Profile.FullName,
+			Email:            profileResult.Profile.Email,
+			UserId:           userAuth.ObjectId.String(),
+			Role:             userAuth.Role,
+			Avatar:           profileResult.Profile.Avatar,
+			Banner:           profileResult.Profile.Banner,
+			TagLine:          profileResult.Profile.TagLine,
+			VerificationType: verificationTypeOf(profileResult.Profile),
+			CreatedDate:      userAuth.CreatedDate,
 		}
 
 	}
@@ -278,18 +308,62 @@ func OAuth2Handler(c *fiber.Ctx) error {
 		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/getOAuthProfile", "Get oath profile error!"))
 	}
 	model.profile = profile
+
+	// Refuse non-members before the user is provisioned
+	organizationRole, organizationErr := checkOAuthOrganizations(&model)
+	if organizationErr != nil {
+		return organizationErrorResponse(c, organizationErr)
+	}
+
 	var currentUserLang string
-	signupErr := checkOAuthSignup(token.AccessToken, &model, &currentUserLang, database.Db)
+	signupErr := checkOAuthSignup(token.AccessToken, &model, &currentUserLang, c.Cookies(inviteCookie), getLegalAcceptance(c, c.Cookies(legalCookie)), database.Db)
 	if signupErr != nil {
-		log.Error("Error signup: %s", signupErr.Error())
-		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/signupCheck", "Internal server error signup check!"))
+		return signupCheckErrorResponse(c, signupErr)
 	}
 
-	session, err := createOAuthSession(&model)
+	pendingDocuments, pendingErr := findPendingLegalDocuments(uuid.FromStringOrNil(model.claim.UserId))
+	if pendingErr != nil {
+		log.Error("Find pending legal documents %s", pendingErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findLegalDocument", "Error happened while reading legal documents!"))
+	}
+	if len(pendingDocuments) > 0 {
+		return legalConsentResponse(c, pendingLogin{
+			model:            &model,
+			organizationRole: organizationRole,
+			state:            state,
+			redirect:         c.Query("r"),
+			lang:             currentUserLang,
+		}, pendingDocuments)
+	}
+
+	session, err := createOAuthSession(&model, organizationRole)
 	if err != nil {
 		log.Error("Error creating session: %s", err.Error())
 		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/createToken", "Internal server error creating token!"))
 	}
+	checkClaimLoginDevice(c, model.claim, currentUserLang)
+
+	return sessionRedirectResponse(c, session, state, c.Query("r"), currentUserLang)
+}
+
+// signupCheckErrorResponse write error of external provider signup check as api response
+func signupCheckErrorResponse(c *fiber.Ctx, signupErr error) error {
+	if _, ok := signupErr.(models.InvitationError); ok {
+		return invitationErrorResponse(c, signupErr)
+	}
+	if _, ok := signupErr.(models.EmailDomainError); ok {
+		return emailDomainErrorResponse(c, signupErr)
+	}
+	if _, ok := signupErr.(models.LegalConsentError); ok {
+		return legalConsentErrorResponse(c, signupErr)
+	}
+	log.Error("Error signup: %s", signupErr.Error())
+	return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/signupCheck", "Internal server error signup check!"))
+}
+
+// sessionRedirectResponse write session on cookie and render redirect page to web app
+func sessionRedirectResponse(c *fiber.Ctx, session, state, redirect, currentUserLang string) error {
+	config := &cf.AuthConfig
 
 	// We don't expire token because it's complicating things
 	// Also Google recommend it. https://developers.google.com/actions/identity/oauth2-implicit-flow
@@ -304,7 +378,6 @@ func OAuth2Handler(c *fiber.Ctx) error {
 
 	webURL := config.ExternalRedirectDomain + "/auth/session?" + sessionQuery
 
-	redirect := c.Query("r")
 	log.Info("SetCookie done, redirect to: %s", redirect)
 
 	// Redirect to original requested resource (if specified in r=)
@@ -327,10 +400,12 @@ func OAuth2Handler(c *fiber.Ctx) error {
 
 }
 
-func getUserOrganizations(username, accessToken string) (string, error) {
+// getUserOrganizations get comma separated logins of organizations the authenticated user is a member of.
+// The authenticated endpoint also returns private memberships with the read:org scope.
+func getUserOrganizations(accessToken string) (string, error) {
 
 	organizations := []Organization{}
-	apiURL := fmt.Sprintf("https://api.github.com/users/%s/orgs", username)
+	apiURL := "https://api.github.com/user/orgs?per_page=100"
 
 	req, reqErr := http.NewRequest(http.MethodGet, apiURL, nil)
 	if reqErr != nil {
@@ -372,3 +447,46 @@ func getUserOrganizations(username, accessToken string) (string, error) {
 type Organization struct {
 	Login string `json:"login"`
 }
+
+type GitLabGroup struct {
+	FullPath string `json:"full_path"`
+}
+
+// getGitLabUserGroups get comma separated full path of groups the user is a member of
+func getGitLabUserGroups(accessToken string) (string, error) {
+
+	groups := []GitLabGroup{}
+	apiURL := fmt.Sprintf("%s/api/v4/groups?min_access_level=10&per_page=100", cf.AuthConfig.OAuthProviderBaseURL)
+
+	req, reqErr := http.NewRequest(http.MethodGet, apiURL, nil)
+	if reqErr != nil {
+		return "", fmt.Errorf("error while making request to `%s` groups: %s", apiURL, reqErr.Error())
+	}
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+
+	client := &http.Client{Timeout: profileFetchTimeout}
+	resp, respErr := client.Do(req)
+	if respErr != nil {
+		return "", fmt.Errorf("error while requesting groups: %s", respErr.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("bad status code from request to GitLab groups: %d", resp.StatusCode)
+	}
+
+	body, bodyErr := ioutil.ReadAll(resp.Body)
+	if bodyErr != nil {
+		return "", fmt.Errorf("error while reading body from GitLab groups: %s", bodyErr.Error())
+	}
+
+	unmarshallErr := json.Unmarshal(body, &groups)
+	if unmarshallErr != nil {
+		return "", fmt.Errorf("error while un-marshaling groups: %s", unmarshallErr.Error())
+	}
+
+	var allGroups []string
+	for _, group := range groups {
+		allGroups = append(allGroups, group.FullPath)
+	}
+	return strings.Join(allGroups, ","), nil
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/pkg/log"
+	utils "github.com/red-gold/telar-core/utils"
+	cf "github.com/red-gold/telar-web/micros/auth/config"
+	"github.com/red-gold/telar-web/micros/auth/database"
+	service "github.com/red-gold/telar-web/micros/auth/services"
+)
+
+// checkOAuthOrganizations fetch organizations of OAuth user into token model and check the membership.
+// It returns the role mapped from organizations or empty string when no role is mapped.
+func checkOAuthOrganizations(model *TokenModel) (string, error) {
+	model.organizationList = ""
+
+	var organizations string
+	var organizationsErr error
+	switch model.providerName {
+	case githubName:
+		organizations, organizationsErr = getUserOrganizations(model.token.AccessToken)
+	case gitlabName:
+		if len(cf.AuthConfig.AllowedOrganizations) == 0 && len(cf.AuthConfig.OrganizationRoles) == 0 {
+			return "", nil
+		}
+		organizations, organizationsErr = getGitLabUserGroups(model.token.AccessToken)
+	default:
+		return "", nil
+	}
+	if organizationsErr != nil {
+		return "", organizationsErr
+	}
+	model.organizationList = organizations
+
+	return checkOrganizationMembership(organizations)
+}
+
+// checkOrganizationMembership check organizations against allowed list and map them to a role
+func checkOrganizationMembership(organizations string) (string, error) {
+	authConfig := &cf.AuthConfig
+
+	var userOrganizations []string
+	for _, organization := range strings.Split(organizations, ",") {
+		organization = strings.ToLower(strings.TrimSpace(organization))
+		if organization != "" {
+			userOrganizations = append(userOrganizations, organization)
+		}
+	}
+
+	if len(authConfig.AllowedOrganizations) > 0 {
+		allowed := false
+		for _, organization := range userOrganizations {
+			if contains(authConfig.AllowedOrganizations, organization) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return "", OrganizationNotAllowedError
+		}
+	}
+
+	role := ""
+	for _, organization := range userOrganizations {
+		if mappedRole, ok := authConfig.OrganizationRoles[organization]; ok {
+			if mappedRole == "admin" {
+				return mappedRole, nil
+			}
+			if role == "" {
+				role = mappedRole
+			}
+		}
+	}
+	if role == "" && len(authConfig.OrganizationRoles) > 0 {
+		role = defaultUserRole
+	}
+	return role, nil
+}
+
+// updateUserRole update role of user in user auth
+func updateUserRole(userId uuid.UUID, role string) error {
+	userAuthService, serviceErr := service.NewUserAuthService(database.Db)
+	if serviceErr != nil {
+		return serviceErr
+	}
+	filter := struct {
+		ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	}{
+		ObjectId: userId,
+	}
+	return userAuthService.UpdateUserAuth(filter, map[string]interface{}{
+		"$set": map[string]interface{}{"role": role, "last_updated": utils.UTCNowUnix()},
+	})
+}
+
+// organizationErrorResponse write organization check error as api response
+func organizationErrorResponse(c *fiber.Ctx, err error) error {
+	if err == OrganizationNotAllowedError {
+		log.Error("Login refused, user is not a member of allowed organizations")
+		return c.Status(http.StatusForbidden).JSON(utils.Error("organizationNotAllowed", "You are not a member of an allowed organization!"))
+	}
+	log.Error("Error happened while checking organizations: %s", err.Error())
+	return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/checkOrganizations", "Error happened while checking organizations!"))
+}
+
+// contains check whether the list contains the value
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
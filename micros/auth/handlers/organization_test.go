@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"testing"
+
+	cf "github.com/red-gold/telar-web/micros/auth/config"
+)
+
+func TestCheckOrganizationMembership(t *testing.T) {
+	tests := []struct {
+		name          string
+		allowed       []string
+		roles         map[string]string
+		organizations string
+		wantRole      string
+		wantErr       error
+	}{
+		{"no restriction and no roles", nil, nil, "red-gold", "", nil},
+		{"no organizations and no restriction", nil, nil, "", "", nil},
+		{"member of allowed organization", []string{"red-gold"}, nil, "other, Red-Gold", "", nil},
+		{"not member of allowed organization", []string{"red-gold"}, nil, "other", "", OrganizationNotAllowedError},
+		{"no organizations with restriction", []string{"red-gold"}, nil, "", "", OrganizationNotAllowedError},
+		{"mapped role", nil, map[string]string{"editors": "editor"}, "editors", "editor", nil},
+		{"default role when roles are mapped", nil, map[string]string{"editors": "editor"}, "other", defaultUserRole, nil},
+		{"admin wins over others", nil, map[string]string{"editors": "editor", "owners": "admin"}, "editors,owners", "admin", nil},
+		{"first mapped role wins", nil, map[string]string{"editors": "editor", "moderators": "moderator"}, "moderators,editors", "moderator", nil},
+		{"allowed and mapped", []string{"red-gold"}, map[string]string{"red-gold": "editor"}, " RED-GOLD ", "editor", nil},
+	}
+
+	previousAllowed := cf.AuthConfig.AllowedOrganizations
+	previousRoles := cf.AuthConfig.OrganizationRoles
+	defer func() {
+		cf.AuthConfig.AllowedOrganizations = previousAllowed
+		cf.AuthConfig.OrganizationRoles = previousRoles
+	}()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf.AuthConfig.AllowedOrganizations = tt.allowed
+			cf.AuthConfig.OrganizationRoles = tt.roles
+
+			role, err := checkOrganizationMembership(tt.organizations)
+			if err != tt.wantErr {
+				t.Fatalf("checkOrganizationMembership(%q) error = %v, want %v", tt.organizations, err, tt.wantErr)
+			}
+			if role != tt.wantRole {
+				t.Errorf("checkOrganizationMembership(%q) = %q, want %q", tt.organizations, role, tt.wantRole)
+			}
+		})
+	}
+}
@@ -54,6 +54,12 @@ func UpdateProfileHandle(c *fiber.Ctx) error {
 
 	}
 
+	// Verified badge is not part of the update so it is read from the saved profile
+	foundProfile, profileErr := getUserProfileByID(currentUser.UserID)
+	if profileErr != nil {
+		log.Error("[UpdateProfileHandle] getUserProfileByID %s", profileErr.Error())
+	}
+
 	tokenModel := &TokenModel{
 		token:            ProviderAccessToken{},
 		oauthProvider:    nil,
@@ -61,14 +67,15 @@ func UpdateProfileHandle(c *fiber.Ctx) error {
 		profile:          &provider.Profile{Name: model.FullName, ID: currentUser.UserID.String(), Login: currentUser.Username},
 		organizationList: *config.AppConfig.OrgName,
 		claim: UserClaim{
-			DisplayName: model.FullName,
-			SocialName:  model.SocialName,
-			Email:       currentUser.Username,
-			Avatar:      model.Avatar,
-			Banner:      model.Banner,
-			TagLine:     model.TagLine,
-			UserId:      currentUser.UserID.String(),
-			CreatedDate: currentUser.CreatedDate,
+			DisplayName:      model.FullName,
+			SocialName:       model.SocialName,
+			Email:            currentUser.Username,
+			Avatar:           model.Avatar,
+			Banner:           model.Banner,
+			TagLine:          model.TagLine,
+			UserId:           currentUser.UserID.String(),
+			CreatedDate:      currentUser.CreatedDate,
+			VerificationType: verificationTypeOf(foundProfile),
 		},
 	}
 	session, err := createToken(tokenModel)
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	uuid "github.com/gofrs/uuid"
+	gopass "github.com/nbutton23/zxcvbn-go"
 	tsconfig "github.com/red-gold/telar-core/config"
 	"github.com/red-gold/telar-core/pkg/log"
 	"github.com/red-gold/telar-core/types"
@@ -14,6 +15,7 @@ import (
 	cf "github.com/red-gold/telar-web/micros/auth/config"
 	"github.com/red-gold/telar-web/micros/auth/database"
 	dto "github.com/red-gold/telar-web/micros/auth/dto"
+	"github.com/red-gold/telar-web/micros/auth/i18n"
 	"github.com/red-gold/telar-web/micros/auth/models"
 	service "github.com/red-gold/telar-web/micros/auth/services"
 	"github.com/valyala/bytebufferpool"
@@ -53,6 +55,9 @@ func ResetPasswordPageHandler(c *fiber.Ctx) error {
 	if findErr != nil {
 		return c.Status(http.StatusBadRequest).JSON(utils.Error("findVerification", findErr.Error()))
 	}
+	if foundVerification == nil || foundVerification.IsVerified {
+		return c.Status(http.StatusUnauthorized).JSON(utils.Error("invalidToken", "Reset password link is expired or already used!"))
+	}
 
 	foundUserAuth, userAuthErr := userAuthService.FindByUserId(foundVerification.UserId)
 	if userAuthErr != nil {
@@ -66,7 +71,7 @@ func ResetPasswordPageHandler(c *fiber.Ctx) error {
 	prettyURL := utils.GetPrettyURLf(authConfig.BaseRoute)
 
 	return c.Render("reset_password", fiber.Map{
-		"Title":         "Login - " + *appConfig.AppName,
+		"Title":         i18n.T(getLang(c), "title.resetPassword", *appConfig.AppName),
 		"OrgName":       *appConfig.OrgName,
 		"OrgAvatar":     *appConfig.OrgAvatar,
 		"AppName":       *appConfig.AppName,
@@ -90,7 +95,7 @@ func ForgetPasswordPageHandler(c *fiber.Ctx) error {
 	loginURL := utils.GetPrettyURLf(authConfig.BaseRoute + "/login")
 
 	return c.Render("reset_password", fiber.Map{
-		"Title":      "Login - " + *appConfig.AppName,
+		"Title":      i18n.T(getLang(c), "title.resetPassword", *appConfig.AppName),
 		"OrgName":    *appConfig.OrgName,
 		"OrgAvatar":  *appConfig.OrgAvatar,
 		"AppName":    *appConfig.AppName,
@@ -177,6 +182,7 @@ func ForgetPasswordFormHandler(c *fiber.Ctx) error {
 	// Generate reset password token
 	token, err := generateResetPasswordToken(verifyId.String())
 	emailData := fiber.Map{
+		"Lang":      getLang(c),
 		"Name":      foundUserAuth.Username,
 		"AppName":   *appConfig.AppName,
 		"AppURL":    authConfig.WebURL,
@@ -186,7 +192,7 @@ func ForgetPasswordFormHandler(c *fiber.Ctx) error {
 		"OrgAvatar": *appConfig.OrgAvatar,
 	}
 	c.App().Config().Views.Render(buf, "email_link_verify_reset_pass", emailData, c.App().Config().ViewsLayout)
-	emailReq := utils.NewEmailRequest([]string{foundUserAuth.Username}, "Reset Password", buf.String())
+	emailReq := utils.NewEmailRequest([]string{foundUserAuth.Username}, i18n.T(getLang(c), "email.resetPassword.subject"), buf.String())
 	if err != nil {
 		log.Error("Generate reset password token: %s", err.Error())
 		return c.Status(http.StatusInternalServerError).JSON(utils.Error("generateToken", "Error in generating token!"))
@@ -211,9 +217,9 @@ func ForgetPasswordFormHandler(c *fiber.Ctx) error {
 	}
 
 	return c.Render("message", fiber.Map{
-		"Title":     "Reset Password - " + *appConfig.AppName,
+		"Title":     i18n.T(getLang(c), "title.resetPassword", *appConfig.AppName),
 		"OrgAvatar": *appConfig.OrgAvatar,
-		"Message":   fmt.Sprintf("Reset password link has been sent to %s. It may takes up to 30 minutes to receive the email.", userEmail),
+		"Message":   i18n.T(getLang(c), "message.resetLinkSent", userEmail),
 	})
 
 }
@@ -226,6 +232,7 @@ func ForgetPasswordFormHandler(c *fiber.Ctx) error {
 // @Param verifyId path string true "The verify id that sent to user"
 // @Success 200 {string} string "OK"
 // @Failure 400 {object} utils.TelarError
+// @Failure 401 {object} utils.TelarError
 // @Failure 404 {object} utils.TelarError
 // @Failure 500 {object} utils.TelarError
 // @Router /password/reset/{verifyId} [post]
@@ -249,6 +256,11 @@ func ResetPasswordFormHandler(c *fiber.Ctx) error {
 		return c.Status(http.StatusBadRequest).JSON(utils.Error("passwordNotMatchError", "Confirm password didn't match"))
 	}
 
+	passStrength := gopass.PasswordStrength(newPassword, nil)
+	if passStrength.Score < 3 || passStrength.Entropy < 37 {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("needStrongerPassword", "Password is not strong enough!"))
+	}
+
 	// Create service
 	userAuthService, serviceErr := service.NewUserAuthService(database.Db)
 	if serviceErr != nil {
@@ -269,6 +281,9 @@ func ResetPasswordFormHandler(c *fiber.Ctx) error {
 	if findErr != nil {
 		return c.Status(http.StatusBadRequest).JSON(utils.Error("findVerification", findErr.Error()))
 	}
+	if foundVerification == nil || foundVerification.IsVerified {
+		return c.Status(http.StatusUnauthorized).JSON(utils.Error("invalidToken", "Reset password link is expired or already used!"))
+	}
 
 	foundUserAuth, userAuthErr := userAuthService.FindByUserId(foundVerification.UserId)
 	if userAuthErr != nil {
@@ -287,6 +302,16 @@ func ResetPasswordFormHandler(c *fiber.Ctx) error {
 
 	}
 
+	// Mark the link used before updating the password so concurrent requests can not reuse it
+	used, useErr := userVerificationService.UseUserVerification(foundVerification.ObjectId)
+	if useErr != nil {
+		log.Error("Use user verification %s", useErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/useVerification", "Can not update password!"))
+	}
+	if !used {
+		return c.Status(http.StatusUnauthorized).JSON(utils.Error("invalidToken", "Reset password link is expired or already used!"))
+	}
+
 	updateErr := userAuthService.UpdatePassword(foundUserAuth.ObjectId, hashPassword)
 	if updateErr != nil {
 		log.Error("Update user password %s", updateErr.Error())
@@ -298,9 +323,9 @@ func ResetPasswordFormHandler(c *fiber.Ctx) error {
 	}
 
 	return c.Render("message", fiber.Map{
-		"Title":     "Reset Password - " + *appConfig.AppName,
+		"Title":     i18n.T(getLang(c), "title.resetPassword", *appConfig.AppName),
 		"OrgAvatar": *appConfig.OrgAvatar,
-		"Message":   fmt.Sprintf("Your password has been updated. You can login with new password."),
+		"Message":   i18n.T(getLang(c), "message.passwordUpdated"),
 	})
 
 }
@@ -0,0 +1,329 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/crewjam/saml/samlsp"
+	"github.com/gofiber/fiber/v2"
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/utils"
+	cf "github.com/red-gold/telar-web/micros/auth/config"
+	"github.com/red-gold/telar-web/micros/auth/database"
+	"github.com/red-gold/telar-web/micros/auth/provider"
+)
+
+const samlMetadataFetchTimeout = time.Second * 10
+
+// samlSignup find or provision the user of SAML assertion just in time
+var samlSignup = checkOAuthSignup
+
+// samlSP keeps the service provider after IdP metadata is loaded once
+var samlSP = struct {
+	sync.Mutex
+	sp *saml.ServiceProvider
+}{}
+
+// getSAMLServiceProvider create SAML service provider from deployment config
+func getSAMLServiceProvider() (*saml.ServiceProvider, error) {
+	samlSP.Lock()
+	defer samlSP.Unlock()
+	if samlSP.sp != nil {
+		return samlSP.sp, nil
+	}
+
+	config := &cf.AuthConfig
+	if !config.SAMLEnabled {
+		return nil, fmt.Errorf("SAML login is not enabled")
+	}
+
+	keyPair, keyPairErr := tls.X509KeyPair([]byte(config.SAMLSPCertificate), []byte(config.SAMLSPKey))
+	if keyPairErr != nil {
+		return nil, fmt.Errorf("unable to load SAML SP key pair: %s", keyPairErr.Error())
+	}
+	privateKey, ok := keyPair.PrivateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("SAML SP key must be an RSA private key")
+	}
+	certificate, certErr := x509.ParseCertificate(keyPair.Certificate[0])
+	if certErr != nil {
+		return nil, fmt.Errorf("unable to parse SAML SP certificate: %s", certErr.Error())
+	}
+
+	idpMetadata, metadataErr := loadSAMLIdPMetadata(config)
+	if metadataErr != nil {
+		return nil, metadataErr
+	}
+
+	baseURL := combineURL(config.AuthWebURI, utils.GetPrettyURLf(config.BaseRoute))
+	metadataURL, _ := url.Parse(baseURL + "/saml/metadata")
+	acsURL, _ := url.Parse(baseURL + "/saml/acs")
+
+	entityID := config.SAMLEntityID
+	if entityID == "" {
+		entityID = metadataURL.String()
+	}
+
+	samlSP.sp = &saml.ServiceProvider{
+		EntityID:          entityID,
+		Key:               privateKey,
+		Certificate:       certificate,
+		MetadataURL:       *metadataURL,
+		AcsURL:            *acsURL,
+		IDPMetadata:       idpMetadata,
+		AllowIDPInitiated: config.SAMLAllowIDPInitiated,
+	}
+	return samlSP.sp, nil
+}
+
+// loadSAMLIdPMetadata read IdP metadata from file or fetch it from metadata url
+func loadSAMLIdPMetadata(config *cf.Configuration) (*saml.EntityDescriptor, error) {
+	if config.SAMLIdPMetadataFile != "" {
+		data, readErr := ioutil.ReadFile(config.SAMLIdPMetadataFile)
+		if readErr != nil {
+			return nil, fmt.Errorf("unable to read SAML IdP metadata file: %s", readErr.Error())
+		}
+		return samlsp.ParseMetadata(data)
+	}
+
+	if config.SAMLIdPMetadataURL == "" {
+		return nil, fmt.Errorf("SAML IdP metadata is not configured")
+	}
+	metadataURL, urlErr := url.Parse(config.SAMLIdPMetadataURL)
+	if urlErr != nil {
+		return nil, fmt.Errorf("unable to parse SAML IdP metadata url: %s", urlErr.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), samlMetadataFetchTimeout)
+	defer cancel()
+	return samlsp.FetchMetadata(ctx, &http.Client{Timeout: samlMetadataFetchTimeout}, *metadataURL)
+}
+
+// SAMLMetadataHandler godoc
+// @Summary SAML service provider metadata
+// @Description Returns the SAML SP metadata to register this deployment on the identity provider
+// @Tags Login
+// @Produce  xml
+// @Success 200 {string} string "SP metadata"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /saml/metadata [get]
+func SAMLMetadataHandler(c *fiber.Ctx) error {
+	sp, spErr := getSAMLServiceProvider()
+	if spErr != nil {
+		log.Error("[SAMLMetadataHandler] %s", spErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/samlServiceProvider", "SAML service provider is not available!"))
+	}
+
+	metadata, marshalErr := xml.MarshalIndent(sp.Metadata(), "", "  ")
+	if marshalErr != nil {
+		log.Error("[SAMLMetadataHandler] Marshal metadata %s", marshalErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/samlMetadata", "Error happened while creating SAML metadata!"))
+	}
+
+	c.Set(fiber.HeaderContentType, "application/samlmetadata+xml")
+	return c.Send(metadata)
+}
+
+// LoginSAMLHandler godoc
+// @Summary Login with SAML
+// @Description Redirects the user to the configured SAML identity provider
+// @Tags Login
+// @Produce  json
+// @Param r query string false "Redirect URL after login"
+// @Param invite query string false "Invitation code for new users"
+// @Param acceptedLegal query string false "Comma separated ids of legal documents accepted by new users"
+// @Success 307 {string} string "Redirect to identity provider"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /login/saml [get]
+func LoginSAMLHandler(c *fiber.Ctx) error {
+	sp, spErr := getSAMLServiceProvider()
+	if spErr != nil {
+		log.Error("[LoginSAMLHandler] %s", spErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/samlServiceProvider", "SAML service provider is not available!"))
+	}
+
+	authnRequest, requestErr := sp.MakeAuthenticationRequest(sp.GetSSOBindingLocation(saml.HTTPRedirectBinding), saml.HTTPRedirectBinding, saml.HTTPPostBinding)
+	if requestErr != nil {
+		log.Error("[LoginSAMLHandler] Make authentication request %s", requestErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/samlRequest", "Error happened while creating SAML request!"))
+	}
+
+	redirectURL, redirectErr := authnRequest.Redirect(c.Query("r"), sp)
+	if redirectErr != nil {
+		log.Error("[LoginSAMLHandler] Redirect url %s", redirectErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/samlRequest", "Error happened while creating SAML request!"))
+	}
+
+	// Keep request id to validate InResponseTo of the assertion
+	c.Cookie(samlStateCookie(samlRequestID, authnRequest.ID, 10*time.Minute))
+	if inviteCode := c.Query("invite"); len(inviteCode) > 0 {
+		c.Cookie(samlStateCookie(inviteCookie, inviteCode, time.Hour))
+	}
+	if acceptedLegal := c.Query("acceptedLegal"); len(acceptedLegal) > 0 {
+		c.Cookie(samlStateCookie(legalCookie, acceptedLegal, time.Hour))
+	}
+
+	return c.Redirect(redirectURL.String(), http.StatusTemporaryRedirect)
+}
+
+// SAMLACSHandler godoc
+// @Summary SAML assertion consumer service
+// @Description Accepts the signed SAML response from identity provider, provisions the user just in time and creates the session
+// @Tags Login
+// @Accept  x-www-form-urlencoded
+// @Produce  html
+// @Param SAMLResponse formData string true "Base64 encoded SAML response"
+// @Param RelayState formData string false "Redirect URL after login"
+// @Success 200 {object} object{URL=string} "Redirect page"
+// @Failure 401 {object} utils.TelarError "Invalid SAML response"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /saml/acs [post]
+func SAMLACSHandler(c *fiber.Ctx) error {
+	config := &cf.AuthConfig
+
+	sp, spErr := getSAMLServiceProvider()
+	if spErr != nil {
+		log.Error("[SAMLACSHandler] %s", spErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/samlServiceProvider", "SAML service provider is not available!"))
+	}
+
+	var possibleRequestIDs []string
+	if requestID := c.Cookies(samlRequestID); requestID != "" {
+		possibleRequestIDs = append(possibleRequestIDs, requestID)
+	}
+
+	relayState := c.FormValue("RelayState")
+	acsURL := sp.AcsURL
+	httpReq := &http.Request{
+		Method: http.MethodPost,
+		URL:    &acsURL,
+		PostForm: url.Values{
+			"SAMLResponse": []string{c.FormValue("SAMLResponse")},
+			"RelayState":   []string{relayState},
+		},
+	}
+
+	assertion, parseErr := sp.ParseResponse(httpReq, possibleRequestIDs)
+	if parseErr != nil {
+		if invalidErr, ok := parseErr.(*saml.InvalidResponseError); ok {
+			log.Error("[SAMLACSHandler] Invalid SAML response %s", invalidErr.PrivateErr.Error())
+		} else {
+			log.Error("[SAMLACSHandler] Parse SAML response %s", parseErr.Error())
+		}
+		return c.Status(http.StatusUnauthorized).JSON(utils.Error("invalidSAMLResponse", "SAML response is not valid!"))
+	}
+	c.ClearCookie(samlRequestID)
+
+	profile, organizations := getSAMLProfile(assertion, config)
+	if profile.Email == "" {
+		log.Error("[SAMLACSHandler] SAML assertion has no email attribute %s", config.SAMLEmailAttribute)
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("missingEmail", "SAML assertion does not contain email!"))
+	}
+
+	model := TokenModel{
+		token:            ProviderAccessToken{},
+		providerName:     samlName,
+		profile:          profile,
+		organizationList: organizations,
+	}
+	var currentUserLang string
+	signupErr := samlSignup("", &model, &currentUserLang, c.Cookies(inviteCookie), getLegalAcceptance(c, c.Cookies(legalCookie)), database.Db)
+	if signupErr != nil {
+		return signupCheckErrorResponse(c, signupErr)
+	}
+	model.claim.Organizations = organizations
+
+	pendingDocuments, pendingErr := findPendingLegalDocuments(uuid.FromStringOrNil(model.claim.UserId))
+	if pendingErr != nil {
+		log.Error("[SAMLACSHandler] Find pending legal documents %s", pendingErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findLegalDocument", "Error happened while reading legal documents!"))
+	}
+	if len(pendingDocuments) > 0 {
+		return legalConsentResponse(c, pendingLogin{
+			model:    &model,
+			state:    assertion.ID,
+			redirect: relayState,
+			lang:     currentUserLang,
+		}, pendingDocuments)
+	}
+
+	session, err := createToken(&model)
+	if err != nil {
+		log.Error("Error creating session: %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/createToken", "Internal server error creating token!"))
+	}
+	checkClaimLoginDevice(c, model.claim, currentUserLang)
+
+	return sessionRedirectResponse(c, session, assertion.ID, relayState, currentUserLang)
+}
+
+// getSAMLProfile map SAML assertion attributes to provider profile and organizations
+func getSAMLProfile(assertion *saml.Assertion, config *cf.Configuration) (*provider.Profile, string) {
+	attributes := make(map[string][]string)
+	for _, statement := range assertion.AttributeStatements {
+		for _, attribute := range statement.Attributes {
+			values := []string{}
+			for _, value := range attribute.Values {
+				values = append(values, value.Value)
+			}
+			attributes[attribute.Name] = append(attributes[attribute.Name], values...)
+			if attribute.FriendlyName != "" {
+				attributes[attribute.FriendlyName] = append(attributes[attribute.FriendlyName], values...)
+			}
+		}
+	}
+
+	firstValue := func(name string) string {
+		if name == "" || len(attributes[name]) == 0 {
+			return ""
+		}
+		return strings.TrimSpace(attributes[name][0])
+	}
+
+	profile := &provider.Profile{
+		Email:  firstValue(config.SAMLEmailAttribute),
+		Name:   firstValue(config.SAMLNameAttribute),
+		Avatar: firstValue(config.SAMLAvatarAttribute),
+	}
+
+	// Fallback to NameID when IdP sends email as subject
+	if profile.Email == "" && assertion.Subject != nil && assertion.Subject.NameID != nil &&
+		strings.Contains(assertion.Subject.NameID.Value, "@") {
+		profile.Email = assertion.Subject.NameID.Value
+	}
+	if profile.Name == "" && profile.Email != "" {
+		profile.Name = strings.Split(profile.Email, "@")[0]
+	}
+	profile.Login = profile.Email
+
+	organizations := ""
+	if config.SAMLGroupsAttribute != "" {
+		organizations = strings.Join(attributes[config.SAMLGroupsAttribute], ",")
+	}
+	return profile, organizations
+}
+
+// samlStateCookie create a cookie to keep login state until the assertion is posted back.
+// The identity provider posts to ACS from another site, so the cookie must be SameSite=None which requires Secure.
+func samlStateCookie(name, value string, expiresIn time.Duration) *fiber.Cookie {
+	return &fiber.Cookie{
+		Name:     name,
+		Value:    value,
+		Expires:  time.Now().Add(expiresIn),
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: "None",
+	}
+}
@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"encoding/xml"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/gofiber/fiber/v2"
+	coreConfig "github.com/red-gold/telar-core/config"
+	cf "github.com/red-gold/telar-web/micros/auth/config"
+	"github.com/red-gold/telar-web/micros/auth/models"
+)
+
+// samlTestSPProvider serve metadata of the service provider under test to the test IdP
+type samlTestSPProvider struct {
+	metadata *saml.EntityDescriptor
+}
+
+func (p samlTestSPProvider) GetServiceProvider(r *http.Request, serviceProviderID string) (*saml.EntityDescriptor, error) {
+	return p.metadata, nil
+}
+
+// newSAMLTestKeyPair generate a RSA key and self signed certificate
+func newSAMLTestKeyPair(t *testing.T, commonName string) (*rsa.PrivateKey, *x509.Certificate, string, string) {
+	t.Helper()
+	key, keyErr := rsa.GenerateKey(rand.Reader, 2048)
+	if keyErr != nil {
+		t.Fatalf("generate key: %s", keyErr)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-24 * time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+	}
+	der, certErr := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if certErr != nil {
+		t.Fatalf("create certificate: %s", certErr)
+	}
+	certificate, parseErr := x509.ParseCertificate(der)
+	if parseErr != nil {
+		t.Fatalf("parse certificate: %s", parseErr)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return key, certificate, string(keyPEM), string(certPEM)
+}
+
+// newSAMLTestIdP create an identity provider with a locally generated key pair
+func newSAMLTestIdP(t *testing.T) *saml.IdentityProvider {
+	key, certificate, _, _ := newSAMLTestKeyPair(t, "idp.example.com")
+	metadataURL, _ := url.Parse("https://idp.example.com/metadata")
+	ssoURL, _ := url.Parse("https://idp.example.com/sso")
+	return &saml.IdentityProvider{
+		Key:         key,
+		Certificate: certificate,
+		MetadataURL: *metadataURL,
+		SSOURL:      *ssoURL,
+	}
+}
+
+// setupSAMLTest configure SAML login against the test IdP and return the service provider
+func setupSAMLTest(t *testing.T, idp *saml.IdentityProvider) *saml.ServiceProvider {
+	t.Helper()
+
+	previousConfig := cf.AuthConfig
+	previousBaseRoute := coreConfig.AppConfig.BaseRoute
+	previousSignup := samlSignup
+	t.Cleanup(func() {
+		cf.AuthConfig = previousConfig
+		coreConfig.AppConfig.BaseRoute = previousBaseRoute
+		samlSignup = previousSignup
+		samlSP.sp = nil
+	})
+
+	metadata, marshalErr := xml.Marshal(idp.Metadata())
+	if marshalErr != nil {
+		t.Fatalf("marshal IdP metadata: %s", marshalErr)
+	}
+	metadataFile := filepath.Join(t.TempDir(), "idp_metadata.xml")
+	if writeErr := ioutil.WriteFile(metadataFile, metadata, 0600); writeErr != nil {
+		t.Fatalf("write IdP metadata: %s", writeErr)
+	}
+
+	_, _, spKeyPEM, spCertPEM := newSAMLTestKeyPair(t, "auth.example.com")
+	cf.AuthConfig.SAMLEnabled = true
+	cf.AuthConfig.SAMLSPKey = spKeyPEM
+	cf.AuthConfig.SAMLSPCertificate = spCertPEM
+	cf.AuthConfig.SAMLIdPMetadataFile = metadataFile
+	cf.AuthConfig.SAMLAllowIDPInitiated = false
+	cf.AuthConfig.SAMLEmailAttribute = "email"
+	cf.AuthConfig.SAMLNameAttribute = "displayName"
+	cf.AuthConfig.SAMLGroupsAttribute = "groups"
+	cf.AuthConfig.AuthWebURI = "https://auth.example.com"
+	cf.AuthConfig.BaseRoute = ""
+	baseRoute := ""
+	coreConfig.AppConfig.BaseRoute = &baseRoute
+	samlSP.sp = nil
+
+	sp, spErr := getSAMLServiceProvider()
+	if spErr != nil {
+		t.Fatalf("create service provider: %s", spErr)
+	}
+	idp.ServiceProviderProvider = samlTestSPProvider{metadata: sp.Metadata()}
+	return sp
+}
+
+// makeSAMLTestResponse run the SP initiated flow on the IdP and return the request id and posted SAML response
+func makeSAMLTestResponse(t *testing.T, idp *saml.IdentityProvider, sp *saml.ServiceProvider) (string, string) {
+	t.Helper()
+
+	authnRequest, requestErr := sp.MakeAuthenticationRequest(idp.SSOURL.String(), saml.HTTPRedirectBinding, saml.HTTPPostBinding)
+	if requestErr != nil {
+		t.Fatalf("make authentication request: %s", requestErr)
+	}
+	redirectURL, redirectErr := authnRequest.Redirect("", sp)
+	if redirectErr != nil {
+		t.Fatalf("redirect url: %s", redirectErr)
+	}
+
+	idpRequest, idpRequestErr := saml.NewIdpAuthnRequest(idp, httptest.NewRequest(http.MethodGet, redirectURL.String(), nil))
+	if idpRequestErr != nil {
+		t.Fatalf("read authentication request: %s", idpRequestErr)
+	}
+	if validateErr := idpRequest.Validate(); validateErr != nil {
+		t.Fatalf("validate authentication request: %s", validateErr)
+	}
+
+	session := &saml.Session{
+		ID:         "session-1",
+		CreateTime: saml.TimeNow(),
+		ExpireTime: saml.TimeNow().Add(time.Hour),
+		Index:      "1",
+		NameID:     "jane",
+		CustomAttributes: []saml.Attribute{
+			{Name: "email", Values: []saml.AttributeValue{{Type: "xs:string", Value: "jane@example.com"}}},
+			{Name: "displayName", Values: []saml.AttributeValue{{Type: "xs:string", Value: "Jane Doe"}}},
+			{Name: "groups", Values: []saml.AttributeValue{{Type: "xs:string", Value: "staff"}, {Type: "xs:string", Value: "admins"}}},
+		},
+	}
+	if assertionErr := (saml.DefaultAssertionMaker{}).MakeAssertion(idpRequest, session); assertionErr != nil {
+		t.Fatalf("make assertion: %s", assertionErr)
+	}
+	if assertionElErr := idpRequest.MakeAssertionEl(); assertionElErr != nil {
+		t.Fatalf("sign assertion: %s", assertionElErr)
+	}
+	form, formErr := idpRequest.PostBinding()
+	if formErr != nil {
+		t.Fatalf("make response: %s", formErr)
+	}
+	return authnRequest.ID, form.SAMLResponse
+}
+
+// postSAMLTestACS post the SAML response to ACS handler with the request id cookie
+func postSAMLTestACS(t *testing.T, samlResponse string, requestID string) *http.Response {
+	t.Helper()
+
+	app := fiber.New()
+	app.Post("/saml/acs", SAMLACSHandler)
+
+	form := url.Values{"SAMLResponse": []string{samlResponse}}
+	req := httptest.NewRequest(http.MethodPost, "/saml/acs", strings.NewReader(form.Encode()))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationForm)
+	if requestID != "" {
+		req.AddCookie(&http.Cookie{Name: samlRequestID, Value: requestID})
+	}
+	req.AddCookie(&http.Cookie{Name: inviteCookie, Value: "INVITE1"})
+
+	res, testErr := app.Test(req, -1)
+	if testErr != nil {
+		t.Fatalf("post ACS: %s", testErr)
+	}
+	return res
+}
+
+func TestSAMLACSHandlerValidAssertion(t *testing.T) {
+	idp := newSAMLTestIdP(t)
+	sp := setupSAMLTest(t, idp)
+
+	// Stop after provisioning check, the rest of login needs database
+	var signupModel *TokenModel
+	var signupInviteCode string
+	samlSignup = func(accessToken string, model *TokenModel, currentUserLang *string, inviteCode string, legal legalAcceptance, db interface{}) error {
+		signupModel = model
+		signupInviteCode = inviteCode
+		return models.InvitationError{Code: models.InvitationErrorSignupClosed}
+	}
+
+	requestID, samlResponse := makeSAMLTestResponse(t, idp, sp)
+	res := postSAMLTestACS(t, samlResponse, requestID)
+
+	if signupModel == nil {
+		t.Fatalf("signup was not checked, status %d", res.StatusCode)
+	}
+	if res.StatusCode != http.StatusForbidden {
+		t.Errorf("status = %d, want %d from signup check", res.StatusCode, http.StatusForbidden)
+	}
+	if signupModel.providerName != samlName {
+		t.Errorf("provider = %q, want %q", signupModel.providerName, samlName)
+	}
+	if signupModel.profile.Email != "jane@example.com" || signupModel.profile.Login != "jane@example.com" {
+		t.Errorf("email = %q, login = %q, want jane@example.com", signupModel.profile.Email, signupModel.profile.Login)
+	}
+	if signupModel.profile.Name != "Jane Doe" {
+		t.Errorf("name = %q, want %q", signupModel.profile.Name, "Jane Doe")
+	}
+	if signupModel.organizationList != "staff,admins" {
+		t.Errorf("organizations = %q, want %q", signupModel.organizationList, "staff,admins")
+	}
+	if signupInviteCode != "INVITE1" {
+		t.Errorf("invite code = %q, want %q", signupInviteCode, "INVITE1")
+	}
+}
+
+func TestSAMLACSHandlerRejectedResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		// post returns the SAML response and request id cookie to post
+		post func(t *testing.T, idp *saml.IdentityProvider, sp *saml.ServiceProvider) (string, string)
+	}{
+		{
+			name: "bad signature",
+			post: func(t *testing.T, idp *saml.IdentityProvider, sp *saml.ServiceProvider) (string, string) {
+				// Sign with a key which is not in the IdP metadata
+				rogueKey, rogueCertificate, _, _ := newSAMLTestKeyPair(t, "idp.example.com")
+				rogueIdP := *idp
+				rogueIdP.Key = rogueKey
+				rogueIdP.Certificate = rogueCertificate
+				requestID, samlResponse := makeSAMLTestResponse(t, &rogueIdP, sp)
+				return samlResponse, requestID
+			},
+		},
+		{
+			name: "unknown request id",
+			post: func(t *testing.T, idp *saml.IdentityProvider, sp *saml.ServiceProvider) (string, string) {
+				_, samlResponse := makeSAMLTestResponse(t, idp, sp)
+				otherRequestID, _ := makeSAMLTestResponse(t, idp, sp)
+				return samlResponse, otherRequestID
+			},
+		},
+		{
+			name: "missing request id cookie",
+			post: func(t *testing.T, idp *saml.IdentityProvider, sp *saml.ServiceProvider) (string, string) {
+				_, samlResponse := makeSAMLTestResponse(t, idp, sp)
+				return samlResponse, ""
+			},
+		},
+		{
+			name: "expired assertion",
+			post: func(t *testing.T, idp *saml.IdentityProvider, sp *saml.ServiceProvider) (string, string) {
+				previousTimeNow := saml.TimeNow
+				defer func() { saml.TimeNow = previousTimeNow }()
+				issuedAt := time.Now().Add(-time.Hour)
+				saml.TimeNow = func() time.Time { return issuedAt }
+				requestID, samlResponse := makeSAMLTestResponse(t, idp, sp)
+				return samlResponse, requestID
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newSAMLTestIdP(t)
+			sp := setupSAMLTest(t, idp)
+			samlSignup = func(accessToken string, model *TokenModel, currentUserLang *string, inviteCode string, legal legalAcceptance, db interface{}) error {
+				t.Errorf("signup must not be checked for rejected response")
+				return nil
+			}
+
+			samlResponse, requestID := tt.post(t, idp, sp)
+			res := postSAMLTestACS(t, samlResponse, requestID)
+			if res.StatusCode != http.StatusUnauthorized {
+				t.Errorf("status = %d, want %d", res.StatusCode, http.StatusUnauthorized)
+			}
+		})
+	}
+}
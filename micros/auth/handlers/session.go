@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/gofiber/fiber/v2"
+	uuid "github.com/gofrs/uuid"
+	coreConfig "github.com/red-gold/telar-core/config"
+	"github.com/red-gold/telar-core/pkg/log"
+	utils "github.com/red-gold/telar-core/utils"
+	cf "github.com/red-gold/telar-web/micros/auth/config"
+	"github.com/red-gold/telar-web/micros/auth/database"
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+	models "github.com/red-gold/telar-web/micros/auth/models"
+	"github.com/red-gold/telar-web/micros/auth/provider"
+	service "github.com/red-gold/telar-web/micros/auth/services"
+)
+
+// RefreshSessionHandle godoc
+// @Summary Refresh session
+// @Description Reissue the session token, also after the short lived access token is expired, with the role and profile read again from database. Sessions revoked by the user, sessions of deleted users and sessions older than the max session age are rejected. Organization membership of GitHub/GitLab users is checked again and the session is revoked for non-members.
+// @Tags Login
+// @Produce  json
+// @Success 200 {object} object{accessToken=string}
+// @Failure 401 {object} utils.TelarError
+// @Failure 403 {object} utils.TelarError
+// @Failure 500 {object} utils.TelarError
+// @Router /session/refresh [post]
+func RefreshSessionHandle(c *fiber.Ctx) error {
+
+	claims, claimsErr := readSessionClaims(c)
+	if claimsErr != nil {
+		log.Error("[RefreshSessionHandle] Read session %s", claimsErr.Error())
+		return c.Status(http.StatusUnauthorized).JSON(utils.Error("invalidSession", "Session is not valid!"))
+	}
+
+	authTime := claims.AuthTime
+	if authTime == 0 {
+		authTime = claims.IssuedAt
+	}
+	if time.Since(time.Unix(authTime, 0)) > cf.AuthConfig.SessionMaxAge {
+		clearSessionCookies(c)
+		return c.Status(http.StatusUnauthorized).JSON(utils.Error("sessionExpired", "Session has expired, please login again!"))
+	}
+
+	userAuth, userAuthErr := findSessionUserAuth(claims)
+	if userAuthErr != nil {
+		log.Error("[RefreshSessionHandle] Check session revocation %s", userAuthErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findUserAuth", "Error happened while checking session!"))
+	}
+	if userAuth == nil {
+		clearSessionCookies(c)
+		return c.Status(http.StatusUnauthorized).JSON(utils.Error("sessionRevoked", "Session has been revoked!"))
+	}
+
+	foundProfile, profileErr := getUserProfileByID(userAuth.ObjectId)
+	if profileErr != nil || foundProfile == nil {
+		if profileErr != nil {
+			log.Error("[RefreshSessionHandle] Read user profile %s", profileErr.Error())
+		}
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/getUserProfile", "Can not find user profile!"))
+	}
+
+	model := &TokenModel{
+		token:            ProviderAccessToken{AccessToken: claims.AccessToken},
+		providerName:     strings.TrimPrefix(claims.Issuer, "telar-social@"),
+		profile:          &provider.Profile{Name: claims.Name, ID: claims.Id, Login: claims.Subject},
+		organizationList: claims.Organizations,
+		claim:            userClaimOf(userAuth, foundProfile),
+		authTime:         authTime,
+	}
+
+	organizationRole, organizationErr := checkOAuthOrganizations(model)
+	if organizationErr != nil {
+		if organizationErr == OrganizationNotAllowedError {
+			clearSessionCookies(c)
+		}
+		return organizationErrorResponse(c, organizationErr)
+	}
+	if model.organizationList == "" {
+		model.organizationList = claims.Organizations
+	}
+
+	session, sessionErr := createOAuthSession(model, organizationRole)
+	if sessionErr != nil {
+		log.Error("[RefreshSessionHandle] Error creating session: %s", sessionErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/createToken", "Internal server error creating token!"))
+	}
+
+	writeSessionOnCookie(c, session, &cf.AuthConfig)
+	return c.JSON(fiber.Map{
+		"accessToken": session,
+	})
+}
+
+// userClaimOf build session claim of user from database records
+func userClaimOf(userAuth *dto.UserAuth, profile *models.UserProfileModel) UserClaim {
+	return UserClaim{
+		DisplayName:      profile.FullName,
+		SocialName:       profile.SocialName,
+		Email:            profile.Email,
+		Avatar:           profile.Avatar,
+		Banner:           profile.Banner,
+		TagLine:          profile.TagLine,
+		VerificationType: verificationTypeOf(profile),
+		UserId:           userAuth.ObjectId.String(),
+		Role:             userAuth.Role,
+		CreatedDate:      userAuth.CreatedDate,
+	}
+}
+
+// readSessionClaims read and validate session token from cookies
+func readSessionClaims(c *fiber.Ctx) (*TelarSocailClaims, error) {
+	appConfig := coreConfig.AppConfig
+	session := c.Cookies(*appConfig.HeaderCookieName) + "." + c.Cookies(*appConfig.PayloadCookieName) + "." + c.Cookies(*appConfig.SignatureCookieName)
+
+	claims := &TelarSocailClaims{}
+	_, parseErr := jwt.ParseWithClaims(session, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return jwt.ParseECPublicKeyFromPEM([]byte(*appConfig.PublicKey))
+	})
+	if parseErr != nil {
+		// An expired access token with valid signature can still be refreshed
+		validationErr, ok := parseErr.(*jwt.ValidationError)
+		if !ok || validationErr.Errors != jwt.ValidationErrorExpired {
+			return nil, parseErr
+		}
+	}
+	return claims, nil
+}
+
+// findSessionUserAuth find user auth of the session.
+// It returns nil when the user is deleted or revoked sessions after the token was issued.
+func findSessionUserAuth(claims *TelarSocailClaims) (*dto.UserAuth, error) {
+	userId, uuidErr := uuid.FromString(claims.Claim.UserId)
+	if uuidErr != nil {
+		return nil, uuidErr
+	}
+
+	userAuthService, serviceErr := service.NewUserAuthService(database.Db)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	foundUserAuth, findErr := userAuthService.FindByUserId(userId)
+	if findErr != nil {
+		return nil, findErr
+	}
+	if foundUserAuth == nil || isSessionRevoked(foundUserAuth, claims.IssuedAt) {
+		return nil, nil
+	}
+	return foundUserAuth, nil
+}
+
+// isSessionRevoked check whether the token issued at (seconds) is not after the user revoked sessions.
+// RevokedAt is stored in milliseconds like the other dates of user auth.
+func isSessionRevoked(userAuth *dto.UserAuth, issuedAt int64) bool {
+	return userAuth.RevokedAt > 0 && issuedAt*1000 <= userAuth.RevokedAt
+}
+
+// clearSessionCookies expire session cookies
+func clearSessionCookies(c *fiber.Ctx) {
+	appConfig := coreConfig.AppConfig
+	for _, name := range []string{*appConfig.HeaderCookieName, *appConfig.PayloadCookieName, *appConfig.SignatureCookieName} {
+		c.Cookie(&fiber.Cookie{
+			Name:    name,
+			Value:   "",
+			Path:    "/",
+			Expires: time.Now().Add(-time.Hour),
+			Domain:  cf.AuthConfig.CookieRootDomain,
+		})
+	}
+}
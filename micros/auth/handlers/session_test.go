@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/red-gold/telar-core/utils"
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+)
+
+func TestIsSessionRevoked(t *testing.T) {
+	// Revoke the same way RevokeSessions does
+	revokedAt := utils.UTCNowUnix()
+	revokeTime := time.Unix(0, revokedAt*int64(time.Millisecond))
+
+	tests := []struct {
+		name      string
+		revokedAt int64
+		issuedAt  int64
+		want      bool
+	}{
+		{"never revoked", 0, revokeTime.Unix(), false},
+		{"issued before revoke", revokedAt, revokeTime.Add(-time.Minute).Unix(), true},
+		{"issued in the second of revoke", revokedAt, revokeTime.Unix(), true},
+		{"login again after revoke", revokedAt, revokeTime.Add(2 * time.Second).Unix(), false},
+		{"refresh a day after revoke", revokedAt, revokeTime.Add(24 * time.Hour).Unix(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userAuth := &dto.UserAuth{RevokedAt: tt.revokedAt}
+			if got := isSessionRevoked(userAuth, tt.issuedAt); got != tt.want {
+				t.Errorf("isSessionRevoked(%d, %d) = %v, want %v", tt.revokedAt, tt.issuedAt, got, tt.want)
+			}
+		})
+	}
+}
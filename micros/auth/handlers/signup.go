@@ -14,6 +14,7 @@ import (
 	ac "github.com/red-gold/telar-web/micros/auth/config"
 	"github.com/red-gold/telar-web/micros/auth/database"
 	dto "github.com/red-gold/telar-web/micros/auth/dto"
+	"github.com/red-gold/telar-web/micros/auth/i18n"
 	models "github.com/red-gold/telar-web/micros/auth/models"
 	"github.com/red-gold/telar-web/micros/auth/provider"
 	service "github.com/red-gold/telar-web/micros/auth/services"
@@ -32,16 +33,35 @@ func SignupPageHandler(c *fiber.Ctx) error {
 	appConfig := coreConfig.AppConfig
 	authConfig := &ac.AuthConfig
 	prettyURL := utils.GetPrettyURLf(authConfig.BaseRoute)
+	lang := getLang(c)
+
+	if models.SignupModeConst(authConfig.SignupMode) == models.ClosedSignupModeConst {
+		return c.Render("message", fiber.Map{
+			"Title":     i18n.T(lang, "title.signup", *appConfig.AppName),
+			"OrgAvatar": *appConfig.OrgAvatar,
+			"Message":   i18n.T(lang, "message.signupClosed"),
+		})
+	}
+
+	legalDocuments, legalErr := findLatestLegalDocuments()
+	if legalErr != nil {
+		log.Error("[SignupPageHandler] Find legal documents %s", legalErr.Error())
+	}
 
 	return c.Render("signup", fiber.Map{
-		"Title":        "Signup - Telar Social",
-		"OrgName":      *appConfig.OrgName,
-		"OrgAvatar":    *appConfig.OrgAvatar,
-		"AppName":      *appConfig.AppName,
-		"ActionForm":   "",
-		"LoginLink":    prettyURL + "/login",
-		"RecaptchaKey": *appConfig.RecaptchaSiteKey,
-		"VerifyType":   authConfig.VerifyType,
+		"Title":          i18n.T(lang, "title.signup", *appConfig.AppName),
+		"OrgName":        *appConfig.OrgName,
+		"OrgAvatar":      *appConfig.OrgAvatar,
+		"AppName":        *appConfig.AppName,
+		"ActionForm":     "",
+		"LoginLink":      prettyURL + "/login",
+		"RecaptchaKey":   *appConfig.RecaptchaSiteKey,
+		"VerifyType":     authConfig.VerifyType,
+		"InviteOnly":     models.SignupModeConst(authConfig.SignupMode) == models.InviteSignupModeConst,
+		"InviteCode":     c.Query("invite"),
+		"LegalDocuments": legalDocuments,
+		"AcceptedLegal":  joinLegalDocumentIds(legalDocuments),
+		"SocialNameURL":  prettyURL + "/signup/social-name/",
 	})
 }
 
@@ -57,6 +77,10 @@ func SignupPageHandler(c *fiber.Ctx) error {
 // @Param verifyType formData string true "Type of verification (email or phone)"
 // @Param g-recaptcha-response formData string true "Google reCAPTCHA response token"
 // @Param responseType formData string false "Response type indicating the desired response format (default or spa)"
+// @Param inviteCode formData string false "Invitation code, required when signup mode is invite"
+// @Param acceptedLegal formData string false "Comma separated ids of accepted legal documents, required when legal documents are published"
+// @Param socialName formData string false "Social name chosen by the user, generated from the full name when empty"
+// @Param customFields formData string false "JSON object of custom profile field values, required fields must be filled"
 // @Success 200 {object} utils.TelarError "Returns a JSON object containing the generated token if responseType is 'spa', or renders a verification page otherwise."
 // @Failure 400 {object} utils.TelarError "Returns a JSON object describing the missing or invalid parameters."
 // @Failure 500 {object} utils.TelarError "Returns a JSON object indicating an internal server error, such as failure to create a user or verify captcha."
@@ -71,9 +95,13 @@ func SignupTokenHandle(c *fiber.Ctx) error {
 			Email:    c.FormValue("email"),
 			Password: c.FormValue("newPassword"),
 		},
-		VerifyType:   c.FormValue("verifyType"),
-		Recaptcha:    c.FormValue("g-recaptcha-response"),
-		ResponseType: c.FormValue("responseType"),
+		VerifyType:    c.FormValue("verifyType"),
+		Recaptcha:     c.FormValue("g-recaptcha-response"),
+		ResponseType:  c.FormValue("responseType"),
+		InviteCode:    c.FormValue("inviteCode"),
+		AcceptedLegal: c.FormValue("acceptedLegal"),
+		SocialName:    c.FormValue("socialName"),
+		CustomFields:  c.FormValue("customFields"),
 	}
 
 	if model.User.Fullname == "" {
@@ -89,13 +117,6 @@ func SignupTokenHandle(c *fiber.Ctx) error {
 		return c.Status(http.StatusBadRequest).JSON(utils.Error("missingPassword", "Missing password"))
 	}
 
-	passStrength := gopass.PasswordStrength(model.User.Password, nil)
-	if passStrength.Score < 3 || passStrength.Entropy < 37 {
-		log.Error("Password Strength - Score (%v)", passStrength.Score)
-		log.Error("Password Strength - Entropy (%v)", passStrength.Entropy)
-		return c.Status(http.StatusBadRequest).JSON(utils.Error("needStrongerPassword", "Password is not strong enough!"))
-	}
-
 	// Verify Captha
 	recaptcha := utils.NewRecaptha(*config.RecaptchaKey)
 	remoteIpAddress := c.IP()
@@ -111,6 +132,38 @@ func SignupTokenHandle(c *fiber.Ctx) error {
 
 	}
 
+	if domainErr := checkEmailDomain(model.User.Email); domainErr != nil {
+		log.Error("SignupTokenHandle: email domain rejected %s", domainErr.Error())
+		return emailDomainErrorResponse(c, domainErr)
+	}
+
+	if _, invitationErr := checkSignupInvitation(model.InviteCode); invitationErr != nil {
+		return invitationErrorResponse(c, invitationErr)
+	}
+
+	if _, legalErr := checkLegalAcceptance(model.AcceptedLegal); legalErr != nil {
+		return legalConsentErrorResponse(c, legalErr)
+	}
+
+	socialName, socialNameErr := checkSocialNameChoice(model.SocialName)
+	if socialNameErr != nil {
+		return socialNameErrorResponse(c, socialNameErr)
+	}
+	model.SocialName = socialName
+
+	customFields, customFieldErr := checkSignupCustomFields(model.CustomFields)
+	if customFieldErr != nil {
+		return customFieldErrorResponse(c, customFieldErr)
+	}
+	model.CustomFields = customFields
+
+	passStrength := gopass.PasswordStrength(model.User.Password, nil)
+	if passStrength.Score < 3 || passStrength.Entropy < 37 {
+		log.Error("Password Strength - Score (%v)", passStrength.Score)
+		log.Error("Password Strength - Entropy (%v)", passStrength.Entropy)
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("needStrongerPassword", "Password is not strong enough!"))
+	}
+
 	// Create service
 	userAuthService, serviceErr := service.NewUserAuthService(database.Db)
 	if serviceErr != nil {
@@ -140,6 +193,13 @@ func SignupTokenHandle(c *fiber.Ctx) error {
 	// Create signup token
 	newUserId := uuid.Must(uuid.NewV4())
 
+	// Bind verification to this device instead of the remote address
+	deviceNonce, nonceErr := generateDeviceNonce()
+	if nonceErr != nil {
+		log.Error("Error on creating device nonce: %s", nonceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/deviceNonce", "Error happened in creating token!"))
+	}
+
 	token := ""
 	var tokenErr error
 	if model.VerifyType == constants.EmailVerifyConst.String() {
@@ -147,6 +207,7 @@ func SignupTokenHandle(c *fiber.Ctx) error {
 		defer bytebufferpool.Put(buf)
 		code := utils.GenerateDigits(6)
 		emailData := fiber.Map{
+			"Lang":      getLang(c),
 			"Name":      model.User.Fullname,
 			"AppName":   *coreConfig.AppConfig.AppName,
 			"AppURL":    authConfig.WebURL,
@@ -161,10 +222,15 @@ func SignupTokenHandle(c *fiber.Ctx) error {
 			Code:            code,
 			Username:        model.User.Email,
 			EmailTo:         model.User.Email,
-			EmailSubject:    "Your verification code",
+			EmailSubject:    i18n.T(getLang(c), "email.verifyCode.subject"),
 			RemoteIpAddress: remoteIpAddress,
 			FullName:        model.User.Fullname,
 			UserPassword:    model.User.Password,
+			InviteCode:      model.InviteCode,
+			AcceptedLegal:   model.AcceptedLegal,
+			SocialName:      model.SocialName,
+			CustomFields:    model.CustomFields,
+			DeviceNonce:     deviceNonce,
 		}, &config)
 	} else if model.VerifyType == constants.PhoneVerifyConst.String() {
 		token, tokenErr = userVerificationService.CreatePhoneVerficationToken(service.PhoneVerificationToken{
@@ -174,6 +240,11 @@ func SignupTokenHandle(c *fiber.Ctx) error {
 			RemoteIpAddress: remoteIpAddress,
 			FullName:        model.User.Fullname,
 			UserPassword:    model.User.Password,
+			InviteCode:      model.InviteCode,
+			AcceptedLegal:   model.AcceptedLegal,
+			SocialName:      model.SocialName,
+			CustomFields:    model.CustomFields,
+			DeviceNonce:     deviceNonce,
 		}, &config)
 	}
 	if tokenErr != nil {
@@ -185,14 +256,17 @@ func SignupTokenHandle(c *fiber.Ctx) error {
 	appConfig := coreConfig.AppConfig
 	prettyURL := utils.GetPrettyURLf(authConfig.BaseRoute)
 
+	writeVerifyDeviceOnCookie(c, deviceNonce)
+
 	if model.ResponseType == "spa" {
 		return c.JSON(fiber.Map{
-			"token": token,
+			"token":       token,
+			"deviceToken": deviceNonce,
 		})
 	}
 
 	signupVerifyData := &signupVerifyPageData{
-		title:      "Login - Telar Social",
+		title:      i18n.T(getLang(c), "title.verify", *appConfig.AppName),
 		orgName:    *appConfig.OrgName,
 		orgAvatar:  *appConfig.OrgAvatar,
 		appName:    *appConfig.AppName,
@@ -266,8 +340,8 @@ func AdminSignupHandle(c *fiber.Ctx) error {
 		CreatedDate: createdDate,
 		LastUpdated: createdDate,
 		Email:       email,
-		Avatar:      "https://util.telar.dev/api/avatars/" + userUUID.String(),
-		Banner:      fmt.Sprintf("https://picsum.photos/id/%d/900/300/?blur", generateRandomNumber(1, 1000)),
+		Avatar:      defaultAvatarURL(userUUID),
+		Banner:      defaultBannerURL(userUUID),
 		Permission:  constants.Public,
 	}
 	userProfileErr := saveUserProfile(newUserProfile)
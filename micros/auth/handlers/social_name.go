@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/red-gold/telar-core/pkg/log"
+	utils "github.com/red-gold/telar-core/utils"
+	models "github.com/red-gold/telar-web/micros/auth/models"
+)
+
+// CheckSocialNameHandle godoc
+// @Summary Check social name availability
+// @Description Check whether a social name can be chosen at signup. Unavailable names carry the reason code and a translated message.
+// @Tags Signup
+// @Produce  json
+// @Param name path string true "Social name"
+// @Success 200 {object} models.SocialNameAvailabilityModel
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /signup/social-name/{name} [get]
+func CheckSocialNameHandle(c *fiber.Ctx) error {
+
+	availability, err := getSocialNameAvailability(c.Params("name"))
+	if err != nil {
+		log.Error("[CheckSocialNameHandle] %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/checkSocialName", "Error happened while checking social name!"))
+	}
+
+	if !availability.Available {
+		availability.Message = translateError(getLang(c), availability.Code, availability.Message)
+	}
+	return c.JSON(availability)
+}
+
+// getSocialNameAvailability ask profile micro whether the social name is valid and free
+func getSocialNameAvailability(socialName string) (*models.SocialNameAvailabilityModel, error) {
+	profileURL := "/profile/social/available/" + url.PathEscape(socialName)
+	resData, err := functionCall(http.MethodGet, []byte(""), profileURL, nil)
+	if err != nil {
+		log.Error("functionCall (%s) -  %s", profileURL, err.Error())
+		return nil, fmt.Errorf("getSocialNameAvailability/functionCall")
+	}
+
+	var availability models.SocialNameAvailabilityModel
+	if err = json.Unmarshal(resData, &availability); err != nil {
+		log.Error("Unmarshal SocialNameAvailabilityModel -  %s", err.Error())
+		return nil, fmt.Errorf("getSocialNameAvailability/unmarshal")
+	}
+	return &availability, nil
+}
+
+// checkSocialNameChoice check the social name chosen at signup and return it normalized.
+// Empty choice is allowed and the social name is generated from the full name.
+func checkSocialNameChoice(socialName string) (string, error) {
+	if socialName == "" {
+		return "", nil
+	}
+
+	availability, err := getSocialNameAvailability(socialName)
+	if err != nil {
+		return "", err
+	}
+	if !availability.Available {
+		return "", models.SocialNameError{Code: availability.Code}
+	}
+	return availability.SocialName, nil
+}
+
+// socialNameErrorResponse write social name error on response
+func socialNameErrorResponse(c *fiber.Ctx, err error) error {
+	if socialNameErr, ok := err.(models.SocialNameError); ok {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error(socialNameErr.Code, socialNameErr.Error()))
+	}
+	log.Error("Error happened while checking social name: %s", err.Error())
+	return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/checkSocialName", "Error happened while checking social name!"))
+}
@@ -0,0 +1,805 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	uuid "github.com/gofrs/uuid"
+	coreConfig "github.com/red-gold/telar-core/config"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/pkg/parser"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+	"github.com/red-gold/telar-web/constants"
+	ac "github.com/red-gold/telar-web/micros/auth/config"
+	"github.com/red-gold/telar-web/micros/auth/database"
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+	"github.com/red-gold/telar-web/micros/auth/i18n"
+	models "github.com/red-gold/telar-web/micros/auth/models"
+	service "github.com/red-gold/telar-web/micros/auth/services"
+	"github.com/valyala/bytebufferpool"
+)
+
+const (
+	maxUserImportRows         = 5000
+	userImportBatchSize int64 = 100
+	// A running import which has not saved progress for ten minutes is taken as stopped and can be resumed
+	userImportStaleAfter int64 = 10 * 60 * 1000
+	// customFieldColumnPrefix is the CSV column prefix of custom profile fields
+	customFieldColumnPrefix = "cf."
+	// csvListSeparator separates the items of list custom fields in a CSV cell
+	csvListSeparator = ";"
+)
+
+// ImportUsersHandle godoc
+// @Summary Import users
+// @Description Create users from a CSV or JSON file. CSV needs email and fullName columns, socialName and "cf.<name>" custom field columns are optional.
+// @Description The import runs in background and invites each user by email to set a password. Dry run only checks the rows.
+// @Tags Admin
+// @Accept  json
+// @Produce  json
+// @Security HMAC
+// @Param X-Cloud-Signature header string true "HMAC signature"
+// @Param   body  body  models.UserImportModel  true  "Import file"
+// @Success 200 {object} dto.UserImport
+// @Failure 400 {object} utils.TelarError
+// @Failure 500 {object} utils.TelarError
+// @Router /admin/users/import [post]
+func ImportUsersHandle(c *fiber.Ctx) error {
+
+	model := new(models.UserImportModel)
+	if err := c.BodyParser(model); err != nil {
+		log.Error("[ImportUsersHandle] Parse UserImportModel %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseModel", "Error happened while parsing model!"))
+	}
+
+	currentUser, ok := c.Locals(types.UserCtxName).(types.UserContext)
+	if !ok {
+		log.Error("[ImportUsersHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser",
+			"Can not get current user"))
+	}
+
+	format := strings.ToLower(strings.TrimSpace(model.Format))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(model.FileName)), ".")
+	}
+	userImportRows, err := parseUserImportRows(format, model.Content, getLang(c))
+	if err != nil {
+		return userImportErrorResponse(c, err)
+	}
+
+	userImport := &dto.UserImport{
+		ImportedBy: currentUser.UserID,
+		FileName:   model.FileName,
+		Format:     format,
+		DryRun:     model.DryRun,
+		Status:     models.UserImportStatusRunning,
+		Lang:       getLang(c),
+		TotalRows:  int64(len(userImportRows)),
+	}
+	if err := startUserImport(userImport, userImportRows); err != nil {
+		log.Error("[ImportUsersHandle] startUserImport %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/startUserImport", "Error happened while starting user import!"))
+	}
+
+	go runUserImport(c.App(), userImport)
+
+	return c.JSON(userImport)
+}
+
+// ResumeUserImportHandle godoc
+// @Summary Resume user import
+// @Description Go on with a failed or stopped user import from the first row which is not processed
+// @Tags Admin
+// @Produce  json
+// @Security HMAC
+// @Param X-Cloud-Signature header string true "HMAC signature"
+// @Param importId path string true "User import ID"
+// @Success 200 {object} dto.UserImport
+// @Failure 400 {object} utils.TelarError
+// @Failure 404 {object} utils.TelarError
+// @Failure 409 {object} utils.TelarError "User import is running or completed"
+// @Failure 500 {object} utils.TelarError
+// @Router /admin/users/imports/{importId}/resume [post]
+func ResumeUserImportHandle(c *fiber.Ctx) error {
+
+	importId, uuidErr := uuid.FromString(c.Params("importId"))
+	if uuidErr != nil {
+		log.Error("[ResumeUserImportHandle] Parse UUID %s ", uuidErr.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseUUIDError", "Can not parse import id!"))
+	}
+
+	// Create service
+	userImportService, serviceErr := service.NewUserImportService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserImportService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userImportService", "Error happened while creating userImportService!"))
+	}
+
+	userImport, err := userImportService.FindById(importId)
+	if err != nil {
+		log.Error("[ResumeUserImportHandle] FindById %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findUserImport", "Error happened while finding user import!"))
+	}
+	if userImport == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUserImport", "User import not found!"))
+	}
+
+	stopped := userImport.Status == models.UserImportStatusRunning && userImport.LastUpdated < utils.UTCNowUnix()-userImportStaleAfter
+	if userImport.Status != models.UserImportStatusFailed && !stopped {
+		importErr := models.UserImportError{Code: models.UserImportErrorNotResumable}
+		return c.Status(http.StatusConflict).JSON(utils.Error(importErr.Code, translateErrorOf(getLang(c), importErr)))
+	}
+
+	userImport.Status = models.UserImportStatusRunning
+	userImport.Error = ""
+	userImport.FinishedDate = 0
+	if err := userImportService.UpdateUserImport(userImport); err != nil {
+		log.Error("[ResumeUserImportHandle] UpdateUserImport %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/updateUserImport", "Error happened while resuming user import!"))
+	}
+
+	go runUserImport(c.App(), userImport)
+
+	return c.JSON(userImport)
+}
+
+// QueryUserImportsHandle godoc
+// @Summary Get user imports
+// @Description Get user imports by page from newest to oldest
+// @Tags Admin
+// @Produce  json
+// @Security HMAC
+// @Param X-Cloud-Signature header string true "HMAC signature"
+// @Param page query int false "Page number"
+// @Success 200 {array} dto.UserImport
+// @Failure 400 {object} utils.TelarError
+// @Failure 500 {object} utils.TelarError
+// @Router /admin/users/imports [get]
+func QueryUserImportsHandle(c *fiber.Ctx) error {
+
+	query := new(models.UserImportQueryModel)
+	if err := parser.QueryParser(c, query); err != nil {
+		log.Error("[QueryUserImportsHandle] QueryParser %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("queryParser", "Error happened while parsing query!"))
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+
+	// Create service
+	userImportService, serviceErr := service.NewUserImportService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserImportService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userImportService", "Error happened while creating userImportService!"))
+	}
+
+	userImportList, err := userImportService.QueryUserImport(query.Page)
+	if err != nil {
+		log.Error("[QueryUserImportsHandle] QueryUserImport %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/queryUserImport", "Error happened while querying user imports!"))
+	}
+
+	return c.JSON(userImportList)
+}
+
+// ReadUserImportHandle godoc
+// @Summary Get user import
+// @Description Get progress and counts of a user import
+// @Tags Admin
+// @Produce  json
+// @Security HMAC
+// @Param X-Cloud-Signature header string true "HMAC signature"
+// @Param importId path string true "User import ID"
+// @Success 200 {object} dto.UserImport
+// @Failure 400 {object} utils.TelarError
+// @Failure 404 {object} utils.TelarError
+// @Failure 500 {object} utils.TelarError
+// @Router /admin/users/imports/{importId} [get]
+func ReadUserImportHandle(c *fiber.Ctx) error {
+
+	importId, uuidErr := uuid.FromString(c.Params("importId"))
+	if uuidErr != nil {
+		log.Error("[ReadUserImportHandle] Parse UUID %s ", uuidErr.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseUUIDError", "Can not parse import id!"))
+	}
+
+	// Create service
+	userImportService, serviceErr := service.NewUserImportService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserImportService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userImportService", "Error happened while creating userImportService!"))
+	}
+
+	userImport, err := userImportService.FindById(importId)
+	if err != nil {
+		log.Error("[ReadUserImportHandle] FindById %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findUserImport", "Error happened while finding user import!"))
+	}
+	if userImport == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUserImport", "User import not found!"))
+	}
+
+	return c.JSON(userImport)
+}
+
+// QueryUserImportRowsHandle godoc
+// @Summary Get user import rows
+// @Description Get rows of a user import with their status and errors in file order
+// @Tags Admin
+// @Produce  json
+// @Security HMAC
+// @Param X-Cloud-Signature header string true "HMAC signature"
+// @Param importId path string true "User import ID"
+// @Param status query string false "Row status: pending, valid, created or failed"
+// @Param page query int false "Page number"
+// @Success 200 {array} dto.UserImportRow
+// @Failure 400 {object} utils.TelarError
+// @Failure 500 {object} utils.TelarError
+// @Router /admin/users/imports/{importId}/rows [get]
+func QueryUserImportRowsHandle(c *fiber.Ctx) error {
+
+	importId, uuidErr := uuid.FromString(c.Params("importId"))
+	if uuidErr != nil {
+		log.Error("[QueryUserImportRowsHandle] Parse UUID %s ", uuidErr.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseUUIDError", "Can not parse import id!"))
+	}
+
+	query := new(models.UserImportRowQueryModel)
+	if err := parser.QueryParser(c, query); err != nil {
+		log.Error("[QueryUserImportRowsHandle] QueryParser %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("queryParser", "Error happened while parsing query!"))
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+
+	// Create service
+	userImportService, serviceErr := service.NewUserImportService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserImportService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userImportService", "Error happened while creating userImportService!"))
+	}
+
+	userImportRowList, err := userImportService.QueryUserImportRow(importId, query.Status, query.Page)
+	if err != nil {
+		log.Error("[QueryUserImportRowsHandle] QueryUserImportRow %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/queryUserImportRow", "Error happened while querying user import rows!"))
+	}
+
+	return c.JSON(userImportRowList)
+}
+
+// parseUserImportRows read users of the import file. Rows repeating an email or social name are failed here.
+func parseUserImportRows(format string, content string, lang string) ([]dto.UserImportRow, error) {
+	var items []models.UserImportItemModel
+	var err error
+	switch format {
+	case models.UserImportFormatCSV:
+		items, err = parseUserImportCSV(content)
+	case models.UserImportFormatJSON:
+		if jsonErr := json.Unmarshal([]byte(content), &items); jsonErr != nil {
+			log.Error("[parseUserImportRows] Unmarshal %s", jsonErr.Error())
+			err = models.UserImportError{Code: models.UserImportErrorFile}
+		}
+	default:
+		err = models.UserImportError{Code: models.UserImportErrorFormat}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, models.UserImportError{Code: models.UserImportErrorEmpty}
+	}
+	if len(items) > maxUserImportRows {
+		return nil, models.UserImportError{Code: models.UserImportErrorTooManyRows}
+	}
+
+	emails := make(map[string]bool)
+	socialNames := make(map[string]bool)
+	userImportRows := []dto.UserImportRow{}
+	for index, item := range items {
+		userImportRow := dto.UserImportRow{
+			Row:          int64(index + 1),
+			Email:        strings.TrimSpace(item.Email),
+			FullName:     strings.TrimSpace(item.FullName),
+			SocialName:   strings.TrimSpace(item.SocialName),
+			CustomFields: item.CustomFields,
+			Status:       models.UserImportRowPending,
+			UserId:       uuid.Must(uuid.NewV4()),
+		}
+
+		email := strings.ToLower(userImportRow.Email)
+		socialName := strings.ToLower(userImportRow.SocialName)
+		if email != "" && emails[email] {
+			failUserImportRow(&userImportRow, models.UserImportError{Code: models.UserImportErrorDuplicateEmail}, lang)
+		} else if socialName != "" && socialNames[socialName] {
+			failUserImportRow(&userImportRow, models.UserImportError{Code: models.UserImportErrorDuplicateSocial}, lang)
+		}
+		emails[email] = true
+		if socialName != "" {
+			socialNames[socialName] = true
+		}
+		userImportRows = append(userImportRows, userImportRow)
+	}
+	return userImportRows, nil
+}
+
+// parseUserImportCSV read users of CSV file with a header row. Custom field cells are converted by the field type.
+func parseUserImportCSV(content string) ([]models.UserImportItemModel, error) {
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(content, "\ufeff")))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, models.UserImportError{Code: models.UserImportErrorEmpty}
+	}
+	if err != nil {
+		log.Error("[parseUserImportCSV] Read header %s", err.Error())
+		return nil, models.UserImportError{Code: models.UserImportErrorFile}
+	}
+
+	columns := make(map[string]int)
+	customFieldColumns := make(map[string]int)
+	for index, column := range header {
+		column = strings.TrimSpace(column)
+		if strings.HasPrefix(column, customFieldColumnPrefix) {
+			customFieldColumns[strings.TrimPrefix(column, customFieldColumnPrefix)] = index
+			continue
+		}
+		columns[strings.ToLower(column)] = index
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, models.UserImportError{Code: models.UserImportErrorEmailColumn}
+	}
+
+	fieldTypes := make(map[string]string)
+	if len(customFieldColumns) > 0 {
+		if fieldTypes, err = getProfileFieldTypes(); err != nil {
+			return nil, err
+		}
+	}
+
+	cell := func(record []string, index int) string {
+		if index < 0 || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+	columnOf := func(name string) int {
+		if index, ok := columns[strings.ToLower(name)]; ok {
+			return index
+		}
+		return -1
+	}
+
+	items := []models.UserImportItemModel{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Error("[parseUserImportCSV] Read record %s", err.Error())
+			return nil, models.UserImportError{Code: models.UserImportErrorFile}
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		if len(items) == maxUserImportRows {
+			return nil, models.UserImportError{Code: models.UserImportErrorTooManyRows}
+		}
+
+		item := models.UserImportItemModel{
+			Email:      cell(record, columnOf("email")),
+			FullName:   cell(record, columnOf("fullName")),
+			SocialName: cell(record, columnOf("socialName")),
+		}
+		for name, index := range customFieldColumns {
+			value := cell(record, index)
+			if value == "" {
+				continue
+			}
+			if item.CustomFields == nil {
+				item.CustomFields = make(map[string]interface{})
+			}
+			item.CustomFields[name] = csvCustomFieldValue(fieldTypes[name], value)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// csvCustomFieldValue convert text of CSV cell to the value type of custom field.
+// Values which can not be converted are left as text so profile micro reports the row.
+func csvCustomFieldValue(fieldType string, value string) interface{} {
+	switch fieldType {
+	case "number":
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number
+		}
+	case "boolean":
+		if boolean, err := strconv.ParseBool(value); err == nil {
+			return boolean
+		}
+	case "list":
+		list := []interface{}{}
+		for _, item := range strings.Split(value, csvListSeparator) {
+			list = append(list, strings.TrimSpace(item))
+		}
+		return list
+	}
+	return value
+}
+
+// getProfileFieldTypes get type of custom profile fields from profile micro by field name
+func getProfileFieldTypes() (map[string]string, error) {
+	profileURL := "/profile/fields"
+	resData, err := functionCall(http.MethodGet, []byte(""), profileURL, nil)
+	if err != nil {
+		log.Error("functionCall (%s) -  %s", profileURL, err.Error())
+		return nil, fmt.Errorf("getProfileFieldTypes/functionCall")
+	}
+
+	var profileFields []models.ProfileFieldTypeModel
+	if err = json.Unmarshal(resData, &profileFields); err != nil {
+		log.Error("Unmarshal ProfileFieldTypeModel -  %s", err.Error())
+		return nil, fmt.Errorf("getProfileFieldTypes/unmarshal")
+	}
+
+	fieldTypes := make(map[string]string)
+	for _, profileField := range profileFields {
+		fieldTypes[profileField.Name] = profileField.Type
+	}
+	return fieldTypes, nil
+}
+
+// startUserImport save the user import with its rows
+func startUserImport(userImport *dto.UserImport, userImportRows []dto.UserImportRow) error {
+	userImportService, serviceErr := service.NewUserImportService(database.Db)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	for _, userImportRow := range userImportRows {
+		if userImportRow.Status == models.UserImportRowFailed {
+			userImport.FailedCount++
+			userImport.ProcessedRows++
+		}
+	}
+	if err := userImportService.SaveUserImport(userImport); err != nil {
+		return err
+	}
+
+	for index := range userImportRows {
+		userImportRows[index].ImportId = userImport.ObjectId
+	}
+	return userImportService.SaveManyUserImportRow(userImportRows)
+}
+
+// runUserImport process the pending rows of user import and save the result
+func runUserImport(app *fiber.App, userImport *dto.UserImport) {
+	userImportService, serviceErr := service.NewUserImportService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserImportService %s", serviceErr.Error())
+		return
+	}
+
+	if err := importUserRows(app, userImport); err != nil {
+		log.Error("[runUserImport] importUserRows %s", err.Error())
+		userImport.Status = models.UserImportStatusFailed
+		userImport.Error = err.Error()
+	} else {
+		userImport.Status = models.UserImportStatusCompleted
+	}
+	userImport.FinishedDate = utils.UTCNowUnix()
+
+	if err := userImportService.UpdateUserImport(userImport); err != nil {
+		log.Error("[runUserImport] UpdateUserImport %s", err.Error())
+	}
+}
+
+// importUserRows check or create the user of each pending row and save progress after every row.
+// Rows with invalid data are failed and reported. Other errors stop the import so it can be resumed.
+func importUserRows(app *fiber.App, userImport *dto.UserImport) error {
+	userImportService, serviceErr := service.NewUserImportService(database.Db)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	for {
+		userImportRows, err := userImportService.FindPendingRows(userImport.ObjectId, userImportBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(userImportRows) == 0 {
+			return nil
+		}
+
+		for index := range userImportRows {
+			userImportRow := &userImportRows[index]
+			if err := importUserRow(app, userImport, userImportRow); err != nil {
+				if !failUserImportRow(userImportRow, err, userImport.Lang) {
+					return fmt.Errorf("row %d: %s", userImportRow.Row, err.Error())
+				}
+			}
+
+			switch userImportRow.Status {
+			case models.UserImportRowValid:
+				userImport.ValidCount++
+			case models.UserImportRowCreated:
+				userImport.CreatedCount++
+			case models.UserImportRowFailed:
+				userImport.FailedCount++
+			}
+			userImport.ProcessedRows++
+
+			if err := userImportService.UpdateUserImportRow(userImportRow); err != nil {
+				return err
+			}
+			if err := userImportService.UpdateUserImport(userImport); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// importUserRow check the row and create the user with profile, default settings and invitation email.
+// Each finished step is saved so a resumed import does not repeat it.
+func importUserRow(app *fiber.App, userImport *dto.UserImport, userImportRow *dto.UserImportRow) error {
+	userImportService, serviceErr := service.NewUserImportService(database.Db)
+	if serviceErr != nil {
+		return serviceErr
+	}
+	saveStep := func(step int) error {
+		userImportRow.Step = step
+		return userImportService.UpdateUserImportRow(userImportRow)
+	}
+
+	if userImportRow.Step == models.UserImportStepNone {
+		if err := checkUserImportRow(userImportRow); err != nil {
+			return err
+		}
+		if userImport.DryRun {
+			userImportRow.Status = models.UserImportRowValid
+			return nil
+		}
+	}
+
+	if userImportRow.Step < models.UserImportStepAuth {
+		if err := saveImportedUserAuth(userImportRow); err != nil {
+			return err
+		}
+		if err := saveStep(models.UserImportStepAuth); err != nil {
+			return err
+		}
+	}
+
+	if userImportRow.Step < models.UserImportStepProfile {
+		if err := saveImportedUserProfile(userImportRow); err != nil {
+			return err
+		}
+		if err := saveStep(models.UserImportStepProfile); err != nil {
+			return err
+		}
+	}
+
+	if userImportRow.Step < models.UserImportStepSetup {
+		if err := initUserSetup(userImportRow.UserId, userImportRow.Email, "", userImportRow.FullName, "user"); err != nil {
+			return err
+		}
+		if err := saveStep(models.UserImportStepSetup); err != nil {
+			return err
+		}
+	}
+
+	if userImportRow.Step < models.UserImportStepInvite {
+		if err := sendUserImportInvite(app, userImportRow, userImport.Lang); err != nil {
+			return err
+		}
+		userImportRow.Step = models.UserImportStepInvite
+	}
+
+	userImportRow.Status = models.UserImportRowCreated
+	return nil
+}
+
+// checkUserImportRow check the row as signup does and normalize social name and custom fields.
+// Custom fields which are required at signup are not asked because imported users do not fill the signup form.
+func checkUserImportRow(userImportRow *dto.UserImportRow) error {
+	if userImportRow.FullName == "" {
+		return models.UserImportError{Code: models.UserImportErrorFullName}
+	}
+	if err := checkEmailDomain(userImportRow.Email); err != nil {
+		return err
+	}
+
+	userAuthService, serviceErr := service.NewUserAuthService(database.Db)
+	if serviceErr != nil {
+		return serviceErr
+	}
+	foundUserAuth, err := userAuthService.FindByUsername(userImportRow.Email)
+	if err != nil {
+		return err
+	}
+	// The user may be saved by this row before the import stopped
+	if foundUserAuth != nil && foundUserAuth.ObjectId != userImportRow.UserId {
+		return models.UserImportError{Code: models.UserImportErrorUserExist}
+	}
+
+	socialName, err := checkSocialNameChoice(userImportRow.SocialName)
+	if err != nil {
+		return err
+	}
+	userImportRow.SocialName = socialName
+
+	if len(userImportRow.CustomFields) > 0 {
+		customFields, err := checkCustomFields(userImportRow.CustomFields, false)
+		if err != nil {
+			return err
+		}
+		userImportRow.CustomFields = customFields
+	}
+	return nil
+}
+
+// saveImportedUserAuth save user auth of the row with an unknown password. The user sets a password from the invitation email.
+func saveImportedUserAuth(userImportRow *dto.UserImportRow) error {
+	userAuthService, serviceErr := service.NewUserAuthService(database.Db)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	foundUserAuth, err := userAuthService.FindByUserId(userImportRow.UserId)
+	if err != nil {
+		return err
+	}
+	if foundUserAuth != nil {
+		return nil
+	}
+
+	hashPassword, hashErr := utils.Hash(uuid.Must(uuid.NewV4()).String())
+	if hashErr != nil {
+		return fmt.Errorf("hash password %s", hashErr.Error())
+	}
+
+	createdDate := utils.UTCNowUnix()
+	// The email is trusted by admin and the invitation link proves the user owns it
+	return userAuthService.SaveUserAuth(&dto.UserAuth{
+		ObjectId:      userImportRow.UserId,
+		Username:      userImportRow.Email,
+		Password:      hashPassword,
+		Role:          "user",
+		EmailVerified: true,
+		CreatedDate:   createdDate,
+		LastUpdated:   createdDate,
+	})
+}
+
+// saveImportedUserProfile create profile of the row on profile micro
+func saveImportedUserProfile(userImportRow *dto.UserImportRow) error {
+	foundProfile, err := getUserProfileByID(userImportRow.UserId)
+	if err != nil {
+		return err
+	}
+	if foundProfile != nil {
+		return nil
+	}
+
+	socialName := userImportRow.SocialName
+	if socialName == "" {
+		socialName = generateSocialName(userImportRow.FullName, userImportRow.UserId.String())
+	}
+	createdDate := utils.UTCNowUnix()
+	return saveUserProfile(&models.UserProfileModel{
+		ObjectId:     userImportRow.UserId,
+		FullName:     userImportRow.FullName,
+		SocialName:   socialName,
+		CreatedDate:  createdDate,
+		LastUpdated:  createdDate,
+		Email:        userImportRow.Email,
+		Avatar:       defaultAvatarURL(userImportRow.UserId),
+		Banner:       defaultBannerURL(userImportRow.UserId),
+		Permission:   constants.Public,
+		CustomFields: userImportRow.CustomFields,
+	})
+}
+
+// sendUserImportInvite email the imported user a link to set password
+func sendUserImportInvite(app *fiber.App, userImportRow *dto.UserImportRow, lang string) error {
+	appConfig := coreConfig.AppConfig
+	authConfig := ac.AuthConfig
+
+	userVerificationService, serviceErr := service.NewUserVerificationService(database.Db)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	verifyId := uuid.Must(uuid.NewV4())
+	saveErr := userVerificationService.SaveUserVerification(&dto.UserVerification{
+		ObjectId:   verifyId,
+		UserId:     userImportRow.UserId,
+		Code:       "0",
+		Target:     userImportRow.Email,
+		TargetType: constants.EmailVerifyConst,
+		Counter:    1,
+	})
+	if saveErr != nil {
+		return fmt.Errorf("save user verification %s", saveErr.Error())
+	}
+
+	token, tokenErr := generateSetPasswordToken(verifyId.String(), authConfig.ImportInviteExpiresIn)
+	if tokenErr != nil {
+		return fmt.Errorf("generate set password token %s", tokenErr.Error())
+	}
+
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+	prettyURL := utils.GetPrettyURLf(authConfig.BaseRoute)
+	emailData := fiber.Map{
+		"Lang":      lang,
+		"Name":      userImportRow.FullName,
+		"AppName":   *appConfig.AppName,
+		"AppURL":    authConfig.WebURL,
+		"Link":      fmt.Sprintf("%s%s/password/reset/%s", authConfig.AuthWebURI, prettyURL, token),
+		"Email":     userImportRow.Email,
+		"ExpiresAt": time.Now().Add(authConfig.ImportInviteExpiresIn).UTC().Format(time.RFC1123),
+		"OrgName":   *appConfig.OrgName,
+		"OrgAvatar": *appConfig.OrgAvatar,
+	}
+	if err := app.Config().Views.Render(buf, "email_import_invite", emailData, app.Config().ViewsLayout); err != nil {
+		return fmt.Errorf("render import invite email %s", err.Error())
+	}
+
+	emailClient := utils.NewEmail(*appConfig.RefEmail, *appConfig.RefEmailPass, *appConfig.SmtpEmail)
+	emailReq := utils.NewEmailRequest([]string{userImportRow.Email}, i18n.T(lang, "email.importInvite.subject", *appConfig.AppName), buf.String())
+	emailResStatus, emailResErr := emailClient.SendEmail(emailReq)
+	if emailResErr != nil {
+		return fmt.Errorf("send import invite email %s", emailResErr.Error())
+	}
+	if !emailResStatus {
+		return fmt.Errorf("import invite email response status is false")
+	}
+	return nil
+}
+
+// failUserImportRow fail the row with the code of a data error. It returns false for other errors.
+func failUserImportRow(userImportRow *dto.UserImportRow, err error, lang string) bool {
+	var code string
+	switch typedErr := err.(type) {
+	case models.UserImportError:
+		code = typedErr.Code
+	case models.EmailDomainError:
+		code = typedErr.Code
+	case models.SocialNameError:
+		code = typedErr.Code
+	case models.CustomFieldError:
+		code = typedErr.Code
+	default:
+		return false
+	}
+
+	userImportRow.Status = models.UserImportRowFailed
+	userImportRow.ErrorCode = code
+	userImportRow.ErrorMessage = translateErrorOf(lang, err)
+	return true
+}
+
+// userImportErrorResponse write user import error on response
+func userImportErrorResponse(c *fiber.Ctx, err error) error {
+	if importErr, ok := err.(models.UserImportError); ok {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error(importErr.Code, translateErrorOf(getLang(c), importErr)))
+	}
+	log.Error("Error happened while reading import file: %s", err.Error())
+	return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/readImportFile", "Error happened while reading import file!"))
+}
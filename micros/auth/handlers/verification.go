@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	uuid "github.com/gofrs/uuid"
@@ -13,6 +16,7 @@ import (
 	authConfig "github.com/red-gold/telar-web/micros/auth/config"
 	"github.com/red-gold/telar-web/micros/auth/database"
 	dto "github.com/red-gold/telar-web/micros/auth/dto"
+	"github.com/red-gold/telar-web/micros/auth/i18n"
 	models "github.com/red-gold/telar-web/micros/auth/models"
 	"github.com/red-gold/telar-web/micros/auth/provider"
 	service "github.com/red-gold/telar-web/micros/auth/services"
@@ -38,6 +42,7 @@ type signupVerifyPageData struct {
 // @Param code formData string true "6 digits code" minLength(6) maxLength(6)
 // @Param verificaitonSecret formData string true "JWT token"
 // @Param responseType formData string true "Type of response for SPA/SSR" Enums(spa,ssr)
+// @Param deviceToken formData string false "Device token from signup response, used when the device cookie is not sent"
 // @Success 200 {object} object{}
 // @Failure 400 {object} utils.TelarError
 // @Failure 404 {object} utils.TelarError
@@ -79,6 +84,10 @@ func VerifySignupSPA(c *fiber.Ctx, model *models.VerifySignupModel) error {
 	email, _ := claimMap["email"].(string)
 	phoneNumber, _ := claimMap["phoneNumber"].(string)
 	password, _ := claimMap["password"].(string)
+	inviteCode, _ := claimMap["inviteCode"].(string)
+	acceptedLegal, _ := claimMap["acceptedLegal"].(string)
+	chosenSocialName, _ := claimMap["socialName"].(string)
+	customFieldsClaim, _ := claimMap["customFields"].(string)
 	verifyTarget := ""
 	fmt.Printf("\nuserId: %s, fullName: %s, email: %s, password: %s, userRemoteIp: %s, verifyType: %v, verifyMode: %v, verifyId: %s\n",
 		userId, fullName, email, password, userRemoteIp, verifyType, verifyMode, verifyId)
@@ -92,7 +101,7 @@ func VerifySignupSPA(c *fiber.Ctx, model *models.VerifySignupModel) error {
 		verifyTarget = phoneNumber
 		phoneVerified = true
 	}
-	if remoteIpAddress != userRemoteIp {
+	if remoteIpAddress != userRemoteIp && authConfig.AuthConfig.StrictVerifyIP {
 
 		log.Error("[VerifySignupSPA] The request is from different remote ip address!")
 		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidToken", "Error happened in validating token!"))
@@ -122,7 +131,7 @@ func VerifySignupSPA(c *fiber.Ctx, model *models.VerifySignupModel) error {
 		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseVerifyUUIDError", "Can not parse verify id!"))
 	}
 
-	verifyStatus, verifyErr := userVerificationService.VerifyUserByCode(userUUID, verifyUUID, remoteIpAddress, model.Code, verifyTarget)
+	verifyStatus, verifyErr := userVerificationService.VerifyUserByCode(userUUID, verifyUUID, remoteIpAddress, getVerifyDeviceNonce(c), model.Code, verifyTarget, authConfig.AuthConfig.StrictVerifyIP)
 	if verifyErr != nil {
 		errorMessage := fmt.Sprintf("Cannot verify user by provided code! error: %s", verifyErr.Error())
 		log.Error(errorMessage)
@@ -135,6 +144,33 @@ func VerifySignupSPA(c *fiber.Ctx, model *models.VerifySignupModel) error {
 		log.Error(errorMessage)
 		return c.Status(http.StatusBadRequest).JSON(utils.Error("wrongCode", "The code is wrong!"))
 	}
+
+	// The invitation may be used up while the user was verifying the code
+	invitation, invitationErr := checkSignupInvitation(inviteCode)
+	if invitationErr != nil {
+		return invitationErrorResponse(c, invitationErr)
+	}
+
+	// A new version may be published while the user was verifying the code
+	legalDocuments, legalErr := checkLegalAcceptance(acceptedLegal)
+	if legalErr != nil {
+		return legalConsentErrorResponse(c, legalErr)
+	}
+
+	// The social name may be taken while the user was verifying the code
+	socialName, socialNameErr := checkSocialNameChoice(chosenSocialName)
+	if socialNameErr != nil {
+		return socialNameErrorResponse(c, socialNameErr)
+	}
+	if socialName == "" {
+		socialName = generateSocialName(fullName, userId)
+	}
+
+	// Claim the invitation before creating the user so its usage limit holds for concurrent signups
+	if useErr := useSignupInvitation(invitation, userUUID); useErr != nil {
+		return invitationErrorResponse(c, useErr)
+	}
+
 	createdDate := utils.UTCNowUnix()
 	hashPassword, hashErr := utils.Hash(password)
 	if hashErr != nil {
@@ -161,17 +197,18 @@ func VerifySignupSPA(c *fiber.Ctx, model *models.VerifySignupModel) error {
 		log.Error(errorMessage)
 		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal", "Error happened during verification!"))
 	}
-	socialName := generateSocialName(fullName, userId)
 	newUserProfile := &models.UserProfileModel{
-		ObjectId:    userUUID,
-		FullName:    fullName,
-		SocialName:  socialName,
-		CreatedDate: createdDate,
-		LastUpdated: createdDate,
-		Email:       email,
-		Avatar:      "https://util.telar.dev/api/avatars/" + userUUID.String(),
-		Banner:      fmt.Sprintf("https://picsum.photos/id/%d/900/300/?blur", generateRandomNumber(1, 1000)),
-		Permission:  constants.Public,
+		ObjectId:     userUUID,
+		FullName:     fullName,
+		SocialName:   socialName,
+		CreatedDate:  createdDate,
+		LastUpdated:  createdDate,
+		Email:        email,
+		Avatar:       defaultAvatarURL(userUUID),
+		Banner:       defaultBannerURL(userUUID),
+		Permission:   constants.Public,
+		InvitedBy:    getInvitationOwner(invitation),
+		CustomFields: parseCustomFieldsClaim(customFieldsClaim),
 	}
 	userProfileErr := saveUserProfile(newUserProfile)
 	if userProfileErr != nil {
@@ -184,15 +221,21 @@ func VerifySignupSPA(c *fiber.Ctx, model *models.VerifySignupModel) error {
 		return c.Status(http.StatusInternalServerError).JSON(utils.Error("initUserSetupError", fmt.Sprintf("Cannot initialize user setup! error: %s", setupErr.Error())))
 	}
 
+	// Missing consent records are asked again on the next login
+	if consentErr := recordUserConsents(userUUID, legalDocuments, getLegalAcceptance(c, acceptedLegal)); consentErr != nil {
+		log.Error("Record user consents %s", consentErr.Error())
+	}
+
 	return c.SendStatus(http.StatusOK)
 }
 
 func VerifySignupSSR(c *fiber.Ctx, model *models.VerifySignupModel) error {
 
 	prettyURL := utils.GetPrettyURLf(authConfig.AuthConfig.BaseRoute)
+	lang := getLang(c)
 
 	signupVerifyData := &signupVerifyPageData{
-		title:      "Login - Telar Social",
+		title:      i18n.T(lang, "title.verify", *coreConfig.AppConfig.AppName),
 		orgName:    *coreConfig.AppConfig.OrgName,
 		orgAvatar:  *coreConfig.AppConfig.OrgAvatar,
 		appName:    *coreConfig.AppConfig.AppName,
@@ -207,7 +250,7 @@ func VerifySignupSSR(c *fiber.Ctx, model *models.VerifySignupModel) error {
 	if errToken != nil {
 		errorMessage := fmt.Sprintf("Can not parse token : %s",
 			errToken.Error())
-		signupVerifyData.message = errorMessage
+		signupVerifyData.message = translateError(lang, "invalidToken", errorMessage)
 		return renderCodeVerify(c, signupVerifyData)
 	}
 	claimMap, _ := claims["claim"].(map[string]interface{})
@@ -220,6 +263,10 @@ func VerifySignupSSR(c *fiber.Ctx, model *models.VerifySignupModel) error {
 	email, _ := claimMap["email"].(string)
 	phoneNumber, _ := claimMap["phoneNumber"].(string)
 	password, _ := claimMap["password"].(string)
+	inviteCode, _ := claimMap["inviteCode"].(string)
+	acceptedLegal, _ := claimMap["acceptedLegal"].(string)
+	chosenSocialName, _ := claimMap["socialName"].(string)
+	customFieldsClaim, _ := claimMap["customFields"].(string)
 	verifyTarget := ""
 	fmt.Printf("\nuserId: %s, fullName: %s, email: %s, password: %s, userRemoteIp: %s, verifyType: %v, verifyMode: %v, verifyId: %s\n",
 		userId, fullName, email, password, userRemoteIp, verifyType, verifyMode, verifyId)
@@ -233,10 +280,10 @@ func VerifySignupSSR(c *fiber.Ctx, model *models.VerifySignupModel) error {
 		verifyTarget = phoneNumber
 		phoneVerified = true
 	}
-	if remoteIpAddress != userRemoteIp {
+	if remoteIpAddress != userRemoteIp && authConfig.AuthConfig.StrictVerifyIP {
 
 		errorMessage := "The request is from different remote ip address!"
-		signupVerifyData.message = errorMessage
+		signupVerifyData.message = translateError(lang, "differentRemoteAddress", errorMessage)
 		return renderCodeVerify(c, signupVerifyData)
 	}
 
@@ -261,24 +308,56 @@ func VerifySignupSSR(c *fiber.Ctx, model *models.VerifySignupModel) error {
 		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseVerifyUUIDError", fmt.Sprintf("Can not parse verify id! error: %s", verifyUuidErr.Error())))
 	}
 
-	verifyStatus, verifyErr := userVerificationService.VerifyUserByCode(userUUID, verifyUUID, remoteIpAddress, model.Code, verifyTarget)
+	verifyStatus, verifyErr := userVerificationService.VerifyUserByCode(userUUID, verifyUUID, remoteIpAddress, getVerifyDeviceNonce(c), model.Code, verifyTarget, authConfig.AuthConfig.StrictVerifyIP)
 	if verifyErr != nil {
 		errorMessage := fmt.Sprintf("Cannot verify user by provided code! error: %s", verifyErr.Error())
-		signupVerifyData.message = errorMessage
+		signupVerifyData.message = translateError(lang, "invalidCode", errorMessage)
 		return renderCodeVerify(c, signupVerifyData)
 	}
 
 	if !verifyStatus {
 
 		errorMessage := "The code is wrong!"
-		signupVerifyData.message = errorMessage
+		signupVerifyData.message = translateError(lang, "wrongCode", errorMessage)
+		return renderCodeVerify(c, signupVerifyData)
+	}
+
+	invitation, invitationErr := checkSignupInvitation(inviteCode)
+	if invitationErr != nil {
+		signupVerifyData.message = translateErrorOf(lang, invitationErr)
 		return renderCodeVerify(c, signupVerifyData)
 	}
+
+	legalDocuments, legalErr := checkLegalAcceptance(acceptedLegal)
+	if legalErr != nil {
+		signupVerifyData.message = translateErrorOf(lang, legalErr)
+		return renderCodeVerify(c, signupVerifyData)
+	}
+
+	socialName, socialNameErr := checkSocialNameChoice(chosenSocialName)
+	if socialNameErr != nil {
+		signupVerifyData.message = translateErrorOf(lang, socialNameErr)
+		return renderCodeVerify(c, signupVerifyData)
+	}
+	if socialName == "" {
+		socialName = generateSocialName(fullName, userId)
+	}
+
+	// Claim the invitation before creating the user so its usage limit holds for concurrent signups
+	if useErr := useSignupInvitation(invitation, userUUID); useErr != nil {
+		if _, ok := useErr.(models.InvitationError); !ok {
+			log.Error("Error happened while using invitation: %s", useErr.Error())
+			useErr = fmt.Errorf("Error happened while checking invitation!")
+		}
+		signupVerifyData.message = translateErrorOf(lang, useErr)
+		return renderCodeVerify(c, signupVerifyData)
+	}
+
 	createdDate := utils.UTCNowUnix()
 	hashPassword, hashErr := utils.Hash(password)
 	if hashErr != nil {
 		errorMessage := fmt.Sprintf("Cannot hash the password! error: %s", hashErr.Error())
-		signupVerifyData.message = errorMessage
+		signupVerifyData.message = translateError(lang, "internal", errorMessage)
 		return renderCodeVerify(c, signupVerifyData)
 	}
 	newUserAuth := &dto.UserAuth{
@@ -296,21 +375,22 @@ func VerifySignupSSR(c *fiber.Ctx, model *models.VerifySignupModel) error {
 	if userAuthErr != nil {
 
 		errorMessage := fmt.Sprintf("Cannot save user authentication! error: %s", userAuthErr.Error())
-		signupVerifyData.message = errorMessage
+		signupVerifyData.message = translateError(lang, "internal", errorMessage)
 		return renderCodeVerify(c, signupVerifyData)
 	}
 
-	socialName := generateSocialName(fullName, userId)
 	newUserProfile := &models.UserProfileModel{
-		ObjectId:    userUUID,
-		FullName:    fullName,
-		SocialName:  socialName,
-		CreatedDate: createdDate,
-		LastUpdated: createdDate,
-		Email:       email,
-		Avatar:      "https://util.telar.dev/api/avatars/" + userUUID.String(),
-		Banner:      fmt.Sprintf("https://picsum.photos/id/%d/900/300/?blur", generateRandomNumber(1, 1000)),
-		Permission:  constants.Public,
+		ObjectId:     userUUID,
+		FullName:     fullName,
+		SocialName:   socialName,
+		CreatedDate:  createdDate,
+		LastUpdated:  createdDate,
+		Email:        email,
+		Avatar:       defaultAvatarURL(userUUID),
+		Banner:       defaultBannerURL(userUUID),
+		Permission:   constants.Public,
+		InvitedBy:    getInvitationOwner(invitation),
+		CustomFields: parseCustomFieldsClaim(customFieldsClaim),
 	}
 	userProfileErr := saveUserProfile(newUserProfile)
 	if userProfileErr != nil {
@@ -323,6 +403,11 @@ func VerifySignupSSR(c *fiber.Ctx, model *models.VerifySignupModel) error {
 		return c.Status(http.StatusInternalServerError).JSON(utils.Error("initUserSetupError", fmt.Sprintf("Cannot initialize user setup! error: %s", setupErr.Error())))
 	}
 
+	// Missing consent records are asked again on the next login
+	if consentErr := recordUserConsents(userUUID, legalDocuments, getLegalAcceptance(c, acceptedLegal)); consentErr != nil {
+		log.Error("Record user consents %s", consentErr.Error())
+	}
+
 	tokenModel := &TokenModel{
 		token:            ProviderAccessToken{},
 		oauthProvider:    nil,
@@ -391,6 +476,35 @@ func CheckAdminHandler(c *fiber.Ctx) error {
 
 }
 
+// generateDeviceNonce generate a random nonce to bind verification to the signup device
+func generateDeviceNonce() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// writeVerifyDeviceOnCookie write device nonce on cookie for the verification request
+func writeVerifyDeviceOnCookie(c *fiber.Ctx, deviceNonce string) {
+	c.Cookie(&fiber.Cookie{
+		HTTPOnly: true,
+		Name:     verifyDevice,
+		Value:    deviceNonce,
+		Path:     "/",
+		Expires:  time.Now().Add(time.Hour),
+		Domain:   authConfig.AuthConfig.CookieRootDomain,
+	})
+}
+
+// getVerifyDeviceNonce read device nonce from cookie or from form for clients without cookie
+func getVerifyDeviceNonce(c *fiber.Ctx) string {
+	if deviceNonce := c.Cookies(verifyDevice); deviceNonce != "" {
+		return deviceNonce
+	}
+	return c.FormValue("deviceToken")
+}
+
 // renderCodeVerify return signup verify page
 func renderCodeVerify(c *fiber.Ctx, data *signupVerifyPageData) error {
 	return c.Render("code_verification", fiber.Map{
@@ -0,0 +1,183 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Catalog messages of one locale by message key
+type Catalog map[string]string
+
+var store = struct {
+	sync.RWMutex
+	catalogs    map[string]Catalog
+	defaultLang string
+}{
+	catalogs:    map[string]Catalog{},
+	defaultLang: "en",
+}
+
+// Load read all <locale>.json catalogs from directory. The default language is the last step of fallback chain.
+func Load(dir string, defaultLang string) error {
+	files, globErr := filepath.Glob(filepath.Join(dir, "*.json"))
+	if globErr != nil {
+		return globErr
+	}
+
+	catalogs := make(map[string]Catalog)
+	for _, file := range files {
+		data, readErr := ioutil.ReadFile(file)
+		if readErr != nil {
+			return fmt.Errorf("read catalog %s: %s", file, readErr.Error())
+		}
+		catalog := Catalog{}
+		if unmarshalErr := json.Unmarshal(data, &catalog); unmarshalErr != nil {
+			return fmt.Errorf("parse catalog %s: %s", file, unmarshalErr.Error())
+		}
+		locale := normalize(strings.TrimSuffix(filepath.Base(file), ".json"))
+		catalogs[locale] = catalog
+	}
+
+	defaultLang = normalize(defaultLang)
+	if _, ok := catalogs[defaultLang]; !ok {
+		return fmt.Errorf("catalog of default language %s is not found in %s", defaultLang, dir)
+	}
+
+	store.Lock()
+	defer store.Unlock()
+	store.catalogs = catalogs
+	store.defaultLang = defaultLang
+	return nil
+}
+
+// DefaultLang return default language of catalogs
+func DefaultLang() string {
+	store.RLock()
+	defer store.RUnlock()
+	return store.defaultLang
+}
+
+// Locales return all loaded locales
+func Locales() []string {
+	store.RLock()
+	defer store.RUnlock()
+	locales := make([]string, 0, len(store.catalogs))
+	for locale := range store.catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Match return the loaded locale for language tag, trying the base language of a regional tag. Empty if none is loaded.
+func Match(lang string) string {
+	store.RLock()
+	defer store.RUnlock()
+	for _, locale := range chain(lang) {
+		if _, ok := store.catalogs[locale]; ok {
+			return locale
+		}
+	}
+	return ""
+}
+
+// Negotiate return the first candidate language that has a catalog, or the default language
+func Negotiate(candidates ...string) string {
+	for _, candidate := range candidates {
+		if locale := Match(candidate); locale != "" {
+			return locale
+		}
+	}
+	return DefaultLang()
+}
+
+// Lookup find message of key in locale or its base language, without falling back to default language
+func Lookup(lang, key string) (string, bool) {
+	store.RLock()
+	defer store.RUnlock()
+	for _, locale := range chain(lang) {
+		if message, ok := store.catalogs[locale][key]; ok {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// T translate message key for language. Fallback chain is locale, base language, default language and the key itself.
+func T(lang, key string, args ...interface{}) string {
+	message, ok := Lookup(lang, key)
+	if !ok {
+		message, ok = Lookup(DefaultLang(), key)
+	}
+	if !ok {
+		message = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// ParseAcceptLanguage return language tags of Accept-Language header ordered by quality
+func ParseAcceptLanguage(header string) []string {
+	type weightedTag struct {
+		tag     string
+		quality float64
+	}
+
+	var tags []weightedTag
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		tag, quality := part, 1.0
+		if index := strings.Index(part, ";"); index != -1 {
+			tag = strings.TrimSpace(part[:index])
+			params := strings.TrimSpace(part[index+1:])
+			if strings.HasPrefix(params, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if tag == "*" || quality <= 0 {
+			continue
+		}
+		tags = append(tags, weightedTag{tag: tag, quality: quality})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].quality > tags[j].quality
+	})
+
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, tag.tag)
+	}
+	return result
+}
+
+// chain return the locale and its base language
+func chain(lang string) []string {
+	lang = normalize(lang)
+	if lang == "" {
+		return nil
+	}
+	locales := []string{lang}
+	if index := strings.Index(lang, "-"); index > 0 {
+		locales = append(locales, lang[:index])
+	}
+	return locales
+}
+
+// normalize lower case language tag with dash separator
+func normalize(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+}
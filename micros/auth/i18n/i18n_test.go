@@ -0,0 +1,67 @@
+package i18n
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   []string
+	}{
+		{"empty header", "", []string{}},
+		{"single tag", "es", []string{"es"}},
+		{"ordered by quality", "en;q=0.5, es-MX, fr;q=0.8", []string{"es-MX", "fr", "en"}},
+		{"equal quality keeps order", "de, fr, en", []string{"de", "fr", "en"}},
+		{"wildcard and zero quality are skipped", "*, es;q=0, en;q=0.1", []string{"en"}},
+		{"invalid quality counts as one", "en;q=0.5, es;q=abc", []string{"es", "en"}},
+		{"empty parts are skipped", " , en ,, ", []string{"en"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseAcceptLanguage(tt.header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAcceptLanguage(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	dir := t.TempDir()
+	for _, locale := range []string{"en", "es", "pt_BR"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, locale+".json"), []byte(`{"hello":"`+locale+`"}`), 0600); err != nil {
+			t.Fatalf("write catalog: %s", err)
+		}
+	}
+	if err := Load(dir, "en"); err != nil {
+		t.Fatalf("load catalogs: %s", err)
+	}
+
+	tests := []struct {
+		name       string
+		candidates []string
+		want       string
+	}{
+		{"no candidates", nil, "en"},
+		{"exact locale", []string{"es"}, "es"},
+		{"regional tag falls back to base language", []string{"es-MX"}, "es"},
+		{"regional catalog with underscore", []string{"pt_br"}, "pt-br"},
+		{"case insensitive", []string{"PT-BR"}, "pt-br"},
+		{"base language without regional catalog", []string{"pt"}, "en"},
+		{"first supported candidate wins", []string{"fr", "de", "es", "en"}, "es"},
+		{"unsupported falls back to default", []string{"fr", ""}, "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Negotiate(tt.candidates...); got != tt.want {
+				t.Errorf("Negotiate(%v) = %q, want %q", tt.candidates, got, tt.want)
+			}
+		})
+	}
+}
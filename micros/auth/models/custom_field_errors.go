@@ -0,0 +1,22 @@
+package models
+
+// CustomFieldError is a custom error for rejected custom profile field values, the codes are shared with profile micro
+type CustomFieldError struct {
+	Code  string
+	Field string
+	// Message is given by profile micro which owns the field definitions
+	Message string
+}
+
+const CustomFieldErrorInvalid = "customFieldsInvalid"
+
+// Error get message of the error
+func (e CustomFieldError) Error() string {
+	if e.Code == CustomFieldErrorInvalid {
+		return "Custom fields must be a JSON object!"
+	}
+	if e.Message != "" {
+		return e.Message
+	}
+	return "Unrecognized custom field error code"
+}
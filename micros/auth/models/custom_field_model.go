@@ -0,0 +1,14 @@
+package models
+
+type CheckCustomFieldsModel struct {
+	CustomFields map[string]interface{} `json:"customFields"`
+	Signup       bool                   `json:"signup"`
+}
+
+type CustomFieldsCheckResultModel struct {
+	Valid        bool                   `json:"valid"`
+	CustomFields map[string]interface{} `json:"customFields,omitempty"`
+	Field        string                 `json:"field,omitempty"`
+	Code         string                 `json:"code,omitempty"`
+	Message      string                 `json:"message,omitempty"`
+}
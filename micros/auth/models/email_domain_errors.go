@@ -0,0 +1,29 @@
+package models
+
+// EmailDomainError is a custom error for rejected email domains
+type EmailDomainError struct {
+	Code string
+}
+
+const (
+	EmailDomainErrorInvalidEmail   = "invalidEmail"
+	EmailDomainErrorNotAllowed     = "emailDomainNotAllowed"
+	EmailDomainErrorDenied         = "emailDomainDenied"
+	EmailDomainErrorDisposableMail = "disposableEmailNotAllowed"
+)
+
+// Error get message by error code
+func (e EmailDomainError) Error() string {
+	switch e.Code {
+	case EmailDomainErrorInvalidEmail:
+		return "Email address is not valid!"
+	case EmailDomainErrorNotAllowed:
+		return "Email domain is not allowed!"
+	case EmailDomainErrorDenied:
+		return "Email domain is blocked!"
+	case EmailDomainErrorDisposableMail:
+		return "Disposable email addresses are not allowed!"
+	default:
+		return "Unrecognized email domain error code"
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+// InvitationError is a custom error for signup invitation checks
+type InvitationError struct {
+	Code string
+}
+
+const (
+	InvitationErrorSignupClosed = "signupClosed"
+	InvitationErrorCodeRequired = "inviteCodeRequired"
+	InvitationErrorInvalidCode  = "invalidInviteCode"
+	InvitationErrorCodeExpired  = "inviteCodeExpired"
+	InvitationErrorCodeUsedUp   = "inviteCodeUsedUp"
+)
+
+// Error get message by error code
+func (e InvitationError) Error() string {
+	switch e.Code {
+	case InvitationErrorSignupClosed:
+		return "Signup is closed!"
+	case InvitationErrorCodeRequired:
+		return "Invitation code is required!"
+	case InvitationErrorInvalidCode:
+		return "Invitation code is not valid!"
+	case InvitationErrorCodeExpired:
+		return "Invitation code is expired!"
+	case InvitationErrorCodeUsedUp:
+		return "Invitation code has reached its usage limit!"
+	default:
+		return "Unrecognized invitation error code"
+	}
+}
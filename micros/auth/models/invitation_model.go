@@ -0,0 +1,6 @@
+package models
+
+type CreateInvitationModel struct {
+	MaxUses   int64 `json:"maxUses"`
+	ExpiresIn int64 `json:"expiresIn"`
+}
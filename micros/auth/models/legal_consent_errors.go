@@ -0,0 +1,23 @@
+package models
+
+// LegalConsentError is a custom error for terms and privacy policy acceptance
+type LegalConsentError struct {
+	Code string
+}
+
+const (
+	LegalConsentErrorRequired        = "legalConsentRequired"
+	LegalConsentErrorVersionOutdated = "legalVersionOutdated"
+)
+
+// Error get message by error code
+func (e LegalConsentError) Error() string {
+	switch e.Code {
+	case LegalConsentErrorRequired:
+		return "You must accept the terms of service and privacy policy!"
+	case LegalConsentErrorVersionOutdated:
+		return "A new version of the terms or privacy policy is published, please review it again!"
+	default:
+		return "Unrecognized legal consent error code"
+	}
+}
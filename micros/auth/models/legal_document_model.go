@@ -0,0 +1,18 @@
+package models
+
+const (
+	LegalDocumentTypeTerms   = "terms"
+	LegalDocumentTypePrivacy = "privacy"
+)
+
+// LegalDocumentTypes all document types a user has to accept
+var LegalDocumentTypes = []string{LegalDocumentTypeTerms, LegalDocumentTypePrivacy}
+
+type CreateLegalDocumentModel struct {
+	Type          string `json:"type"`
+	Version       string `json:"version"`
+	Title         string `json:"title"`
+	URL           string `json:"url"`
+	Content       string `json:"content"`
+	PublishedDate int64  `json:"publishedDate"`
+}
@@ -0,0 +1,13 @@
+package models
+
+type SignupModeConst string
+
+const (
+	OpenSignupModeConst   SignupModeConst = "open"
+	InviteSignupModeConst SignupModeConst = "invite"
+	ClosedSignupModeConst SignupModeConst = "closed"
+)
+
+func (s SignupModeConst) String() string {
+	return string(s)
+}
@@ -1,10 +1,15 @@
 package models
 
 type SignupTokenModel struct {
-	User         UserSignupTokenModel `json:"user"`
-	VerifyType   string               `json:"verifyType"`
-	Recaptcha    string               `json:"g-recaptcha-response"`
-	ResponseType string               `json:"responseType"`
+	User          UserSignupTokenModel `json:"user"`
+	VerifyType    string               `json:"verifyType"`
+	Recaptcha     string               `json:"g-recaptcha-response"`
+	ResponseType  string               `json:"responseType"`
+	InviteCode    string               `json:"inviteCode"`
+	AcceptedLegal string               `json:"acceptedLegal"`
+	SocialName    string               `json:"socialName"`
+	// CustomFields is a JSON object of custom profile field values
+	CustomFields string `json:"customFields"`
 }
 
 type UserSignupTokenModel struct {
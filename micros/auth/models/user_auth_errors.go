@@ -8,7 +8,9 @@ type UserAuthError struct {
 }
 
 const (
-	UserAuthErrorUserNotVerified = "Auth/usersNotVerifide"
+	UserAuthErrorUserNotVerified  = "userNotVerified"
+	UserAuthErrorUserNotFound     = "findUserByUserName"
+	UserAuthErrorPasswordNotMatch = "passwordNotMatch"
 )
 
 // Error get message by error code
@@ -16,6 +18,10 @@ func (e UserAuthError) Error() string {
 	switch e.Code {
 	case UserAuthErrorUserNotVerified:
 		return "User is not verified!"
+	case UserAuthErrorUserNotFound:
+		return "User not found!"
+	case UserAuthErrorPasswordNotMatch:
+		return "Password doesn't match!"
 	default:
 		return "Unrecognized user error code"
 	}
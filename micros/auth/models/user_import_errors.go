@@ -0,0 +1,47 @@
+package models
+
+// UserImportError is a custom error for rejected import files and rows
+type UserImportError struct {
+	Code string
+}
+
+const (
+	UserImportErrorFormat          = "importFormatInvalid"
+	UserImportErrorFile            = "importFileInvalid"
+	UserImportErrorEmpty           = "importFileEmpty"
+	UserImportErrorTooManyRows     = "importTooManyRows"
+	UserImportErrorEmailColumn     = "importEmailColumnMissing"
+	UserImportErrorFullName        = "importFullNameRequired"
+	UserImportErrorDuplicateEmail  = "importDuplicateEmail"
+	UserImportErrorDuplicateSocial = "importDuplicateSocialName"
+	UserImportErrorUserExist       = "importUserAlreadyExist"
+	UserImportErrorNotResumable    = "importNotResumable"
+)
+
+// Error get message by error code
+func (e UserImportError) Error() string {
+	switch e.Code {
+	case UserImportErrorFormat:
+		return "Import format must be csv or json!"
+	case UserImportErrorFile:
+		return "Import file can not be parsed!"
+	case UserImportErrorEmpty:
+		return "Import file has no users!"
+	case UserImportErrorTooManyRows:
+		return "Import file has too many users!"
+	case UserImportErrorEmailColumn:
+		return "Import file must have an email column!"
+	case UserImportErrorFullName:
+		return "Full name is required!"
+	case UserImportErrorDuplicateEmail:
+		return "Email is repeated in the import file!"
+	case UserImportErrorDuplicateSocial:
+		return "Social name is repeated in the import file!"
+	case UserImportErrorUserExist:
+		return "User already exists!"
+	case UserImportErrorNotResumable:
+		return "Only failed or stopped imports can be resumed!"
+	default:
+		return "Unrecognized user import error code"
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+// Format of a user import file
+const (
+	UserImportFormatCSV  = "csv"
+	UserImportFormatJSON = "json"
+)
+
+// Status of a user import
+const (
+	UserImportStatusRunning   = "running"
+	UserImportStatusCompleted = "completed"
+	UserImportStatusFailed    = "failed"
+)
+
+// Status of a user import row
+const (
+	UserImportRowPending = "pending"
+	UserImportRowValid   = "valid"
+	UserImportRowCreated = "created"
+	UserImportRowFailed  = "failed"
+)
+
+// Steps of creating an imported user in order
+const (
+	UserImportStepNone = iota
+	UserImportStepAuth
+	UserImportStepProfile
+	UserImportStepSetup
+	UserImportStepInvite
+)
+
+type UserImportModel struct {
+	FileName string `json:"fileName"`
+	// Format is csv or json. It is taken from the file name extension when empty.
+	Format  string `json:"format"`
+	Content string `json:"content"`
+	DryRun  bool   `json:"dryRun"`
+}
+
+// UserImportItemModel is a user in JSON import file. CSV files have the same columns
+// and custom profile fields as "cf.<name>" columns.
+type UserImportItemModel struct {
+	Email        string                 `json:"email"`
+	FullName     string                 `json:"fullName"`
+	SocialName   string                 `json:"socialName"`
+	CustomFields map[string]interface{} `json:"customFields"`
+}
+
+type UserImportQueryModel struct {
+	Page int64 `query:"page"`
+}
+
+type UserImportRowQueryModel struct {
+	Status string `query:"status"`
+	Page   int64  `query:"page"`
+}
+
+// ProfileFieldTypeModel is the type of a custom profile field defined on profile micro
+type ProfileFieldTypeModel struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
@@ -28,4 +28,7 @@ type UserProfileModel struct {
 	TwitterId      string                        `json:"twitterId" bson:"twitterId"`
 	AccessUserList []string                      `json:"accessUserList" bson:"accessUserList"`
 	Permission     constants.UserPermissionConst `json:"permission" bson:"permission"`
+	InvitedBy      uuid.UUID                     `json:"invitedBy" bson:"invitedBy"`
+	Verification   *VerificationModel            `json:"verification,omitempty" bson:"verification,omitempty"`
+	CustomFields   map[string]interface{}        `json:"customFields,omitempty" bson:"customFields,omitempty"`
 }
@@ -0,0 +1,10 @@
+package models
+
+import uuid "github.com/gofrs/uuid"
+
+// VerificationModel is the verified badge an admin granted to the user
+type VerificationModel struct {
+	Type      string    `json:"type" bson:"type"`
+	GrantedBy uuid.UUID `json:"grantedBy" bson:"grantedBy"`
+	GrantedAt int64     `json:"grantedAt" bson:"grantedAt"`
+}
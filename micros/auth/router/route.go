@@ -34,6 +34,7 @@ func SetupRoutes(app *fiber.App) {
 	app.Get("/swagger/*", swagger.HandlerDefault)
 
 	// Middleware
+	app.Use(handlers.LocaleMiddleware)
 	authHMACMiddleware := authhmac.New(authhmac.Config{
 		PayloadSecret: *config.AppConfig.PayloadSecret,
 	})
@@ -48,11 +49,31 @@ func SetupRoutes(app *fiber.App) {
 	admin.Post("/check", handlers.CheckAdminHandler)
 	admin.Post("/signup", handlers.AdminSignupHandle)
 	admin.Post("/login", handlers.LoginAdminHandler)
+	admin.Post("/invitations", handlers.CreateAdminInvitationHandle)
+	admin.Get("/invitations", handlers.QueryInvitationsHandle)
+	admin.Post("/legal", handlers.CreateLegalDocumentHandle)
+	admin.Get("/legal", handlers.QueryLegalDocumentsHandle)
+	admin.Get("/legal/consents", handlers.ExportLegalConsentsHandle)
+	admin.Post("/users/import", handlers.ImportUsersHandle)
+	admin.Get("/users/imports", handlers.QueryUserImportsHandle)
+	admin.Get("/users/imports/:importId", handlers.ReadUserImportHandle)
+	admin.Get("/users/imports/:importId/rows", handlers.QueryUserImportRowsHandle)
+	admin.Post("/users/imports/:importId/resume", handlers.ResumeUserImportHandle)
 
 	// Signup
 	app.Post("/signup/verify", handlers.VerifySignupHandle)
 	app.Post("/signup", handlers.SignupTokenHandle)
 	app.Get("/signup", handlers.SignupPageHandler)
+	app.Get("/signup/social-name/:name", handlers.CheckSocialNameHandle)
+
+	// Invitation
+	app.Post("/invitations", authCookieMiddleware, handlers.CreateInvitationHandle)
+	app.Get("/invitations", authCookieMiddleware, handlers.GetMyInvitationsHandle)
+	app.Delete("/invitations/:invitationId", authCookieMiddleware, handlers.DeleteInvitationHandle)
+
+	// Legal
+	app.Get("/legal", handlers.GetLegalDocumentsHandle)
+	app.Post("/legal/consent", handlers.LegalConsentHandle)
 
 	// Password
 	app.Get("/password/reset/:verifyId", handlers.ResetPasswordPageHandler)
@@ -66,7 +87,15 @@ func SetupRoutes(app *fiber.App) {
 	login.Post("/", handlers.LoginTelarHandler)
 	login.Get("/github", handlers.LoginGithubHandler)
 	login.Get("/google", handlers.LoginGoogleHandler)
+	login.Get("/saml", handlers.LoginSAMLHandler)
+	login.Get("/alert/:token", handlers.LoginAlertHandler)
+	login.Post("/alert/:token", handlers.ConfirmLoginAlertHandler)
 	app.Get("/oauth2/authorized", handlers.OAuth2Handler)
+	app.Get("/saml/metadata", handlers.SAMLMetadataHandler)
+	app.Post("/saml/acs", handlers.SAMLACSHandler)
+
+	// Session
+	app.Post("/session/refresh", handlers.RefreshSessionHandle)
 
 	// Profile
 	app.Put("/profile", authCookieMiddleware, handlers.UpdateProfileHandle)
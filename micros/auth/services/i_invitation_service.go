@@ -0,0 +1,17 @@
+package service
+
+import (
+	uuid "github.com/gofrs/uuid"
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+)
+
+type InvitationService interface {
+	SaveInvitation(invitation *dto.Invitation) error
+	FindOneInvitation(filter interface{}) (*dto.Invitation, error)
+	FindInvitationList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.Invitation, error)
+	FindByCode(code string) (*dto.Invitation, error)
+	FindByOwnerUserId(ownerUserId uuid.UUID, page int64) ([]dto.Invitation, error)
+	QueryInvitation(page int64) ([]dto.Invitation, error)
+	UseInvitation(invitation *dto.Invitation, userId uuid.UUID) error
+	DeleteInvitation(objectId uuid.UUID, ownerUserId uuid.UUID) error
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+)
+
+type LegalDocumentService interface {
+	SaveLegalDocument(legalDocument *dto.LegalDocument) error
+	FindOneLegalDocument(filter interface{}) (*dto.LegalDocument, error)
+	FindLegalDocumentList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.LegalDocument, error)
+	FindByTypeVersion(documentType string, version string) (*dto.LegalDocument, error)
+	FindLatestByType(documentType string) (*dto.LegalDocument, error)
+	QueryLegalDocument(documentType string, page int64) ([]dto.LegalDocument, error)
+}
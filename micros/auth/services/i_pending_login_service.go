@@ -0,0 +1,11 @@
+package service
+
+import (
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+)
+
+type PendingLoginService interface {
+	SavePendingLogin(pendingLogin *dto.PendingLogin) error
+	FindByTokenHash(tokenHash string) (*dto.PendingLogin, error)
+	ConsumePendingLogin(pendingLogin *dto.PendingLogin) (bool, error)
+}
@@ -12,6 +12,7 @@ type UserAuthService interface {
 	FindByUserId(userId uuid.UUID) (*dto.UserAuth, error)
 	UpdateUserAuth(filter interface{}, data interface{}) error
 	UpdatePassword(userId uuid.UUID, newPassword []byte) error
+	RevokeSessions(userId uuid.UUID) error
 	DeleteUserAuth(filter interface{}) error
 	DeleteManyUserAuth(filter interface{}) error
 	FindByUsername(username string) (*dto.UserAuth, error)
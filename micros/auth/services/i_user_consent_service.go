@@ -0,0 +1,15 @@
+package service
+
+import (
+	uuid "github.com/gofrs/uuid"
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+)
+
+type UserConsentService interface {
+	SaveUserConsent(userConsent *dto.UserConsent) error
+	FindOneUserConsent(filter interface{}) (*dto.UserConsent, error)
+	FindUserConsentList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.UserConsent, error)
+	HasUserConsent(userId uuid.UUID, documentId uuid.UUID) (bool, error)
+	FindByUserId(userId uuid.UUID) ([]dto.UserConsent, error)
+	QueryUserConsent(documentType string, version string, limit int64, skip int64) ([]dto.UserConsent, error)
+}
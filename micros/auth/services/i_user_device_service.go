@@ -0,0 +1,19 @@
+package service
+
+import (
+	uuid "github.com/gofrs/uuid"
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+)
+
+type UserDeviceService interface {
+	SaveUserDevice(userDevice *dto.UserDevice) error
+	FindOneUserDevice(filter interface{}) (*dto.UserDevice, error)
+	FindUserDeviceList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.UserDevice, error)
+	FindByUserId(userId uuid.UUID) ([]dto.UserDevice, error)
+	FindByFingerprint(userId uuid.UUID, fingerprint string) (*dto.UserDevice, error)
+	TouchUserDevice(objectId uuid.UUID, ipRange string, ipAddress string) error
+	BindUserDevice(objectId uuid.UUID, fingerprint string) error
+	SetAlertToken(objectId uuid.UUID, tokenHash string, expiresAt int64) error
+	FindByAlertToken(tokenHash string) (*dto.UserDevice, error)
+	DeleteUserDevice(objectId uuid.UUID, userId uuid.UUID) error
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	uuid "github.com/gofrs/uuid"
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+)
+
+type UserImportService interface {
+	SaveUserImport(userImport *dto.UserImport) error
+	UpdateUserImport(userImport *dto.UserImport) error
+	FindOneUserImport(filter interface{}) (*dto.UserImport, error)
+	FindUserImportList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.UserImport, error)
+	FindById(objectId uuid.UUID) (*dto.UserImport, error)
+	QueryUserImport(page int64) ([]dto.UserImport, error)
+	SaveManyUserImportRow(userImportRows []dto.UserImportRow) error
+	UpdateUserImportRow(userImportRow *dto.UserImportRow) error
+	FindUserImportRowList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.UserImportRow, error)
+	FindPendingRows(importId uuid.UUID, limit int64) ([]dto.UserImportRow, error)
+	QueryUserImportRow(importId uuid.UUID, status string, page int64) ([]dto.UserImportRow, error)
+}
@@ -13,9 +13,10 @@ type UserVerificationService interface {
 	FindByUserId(userId uuid.UUID) (*dto.UserVerification, error)
 	FindByVerifyId(verifyId uuid.UUID) (*dto.UserVerification, error)
 	UpdateUserVerification(filter interface{}, data interface{}) error
+	UseUserVerification(verifyId uuid.UUID) (bool, error)
 	DeleteUserVerification(filter interface{}) error
 	DeleteManyUserVerification(filter interface{}) error
-	VerifyUserByCode(userId uuid.UUID, verifyId uuid.UUID, remoteIpAddress string, code string, target string) (bool, error)
+	VerifyUserByCode(userId uuid.UUID, verifyId uuid.UUID, remoteIpAddress string, deviceNonce string, code string, target string, strictRemoteAddress bool) (bool, error)
 	CreateEmailVerficationToken(input EmailVerificationToken,
 		coreConfig *tsconfig.Configuration) (string, error)
 	CreatePhoneVerficationToken(input PhoneVerificationToken,
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"fmt"
+
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/config"
+	repo "github.com/red-gold/telar-core/data"
+	"github.com/red-gold/telar-core/data/mongodb"
+	mongoRepo "github.com/red-gold/telar-core/data/mongodb"
+	"github.com/red-gold/telar-core/utils"
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+	models "github.com/red-gold/telar-web/micros/auth/models"
+)
+
+// InvitationService handlers with injected dependencies
+type InvitationServiceImpl struct {
+	InvitationRepo repo.Repository
+}
+
+// NewInvitationService initializes InvitationService's dependencies and create new InvitationService struct
+func NewInvitationService(db interface{}) (InvitationService, error) {
+
+	invitationService := &InvitationServiceImpl{}
+
+	switch *config.AppConfig.DBType {
+	case config.DB_MONGO:
+
+		mongodb := db.(mongodb.MongoDatabase)
+		invitationService.InvitationRepo = mongoRepo.NewDataRepositoryMongo(mongodb)
+
+	}
+	if invitationService.InvitationRepo == nil {
+		fmt.Printf("invitationService.InvitationRepo is nil! \n")
+	}
+	return invitationService, nil
+}
+
+// SaveInvitation save invitation information
+func (s InvitationServiceImpl) SaveInvitation(invitation *dto.Invitation) error {
+
+	if invitation.ObjectId == uuid.Nil {
+		var uuidErr error
+		invitation.ObjectId, uuidErr = uuid.NewV4()
+		if uuidErr != nil {
+			return uuidErr
+		}
+	}
+
+	if invitation.CreatedDate == 0 {
+		invitation.CreatedDate = utils.UTCNowUnix()
+	}
+
+	result := <-s.InvitationRepo.Save(invitationCollectionName, invitation)
+
+	return result.Error
+}
+
+// FindOneInvitation get one invitation
+func (s InvitationServiceImpl) FindOneInvitation(filter interface{}) (*dto.Invitation, error) {
+
+	result := <-s.InvitationRepo.FindOne(invitationCollectionName, filter)
+	if result.Error() != nil {
+		if result.Error() == repo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, result.Error()
+	}
+
+	var invitationResult dto.Invitation
+	errDecode := result.Decode(&invitationResult)
+	if errDecode != nil {
+		return nil, fmt.Errorf("Error docoding on dto.Invitation")
+	}
+	return &invitationResult, nil
+}
+
+// FindInvitationList get all invitations by filter
+func (s InvitationServiceImpl) FindInvitationList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.Invitation, error) {
+
+	result := <-s.InvitationRepo.Find(invitationCollectionName, filter, limit, skip, sort)
+	defer result.Close()
+	if result.Error() != nil {
+		return nil, result.Error()
+	}
+	var invitationList []dto.Invitation
+	for result.Next() {
+		var invitation dto.Invitation
+		errDecode := result.Decode(&invitation)
+		if errDecode != nil {
+			return nil, fmt.Errorf("Error docoding on dto.Invitation")
+		}
+		invitationList = append(invitationList, invitation)
+	}
+
+	return invitationList, nil
+}
+
+// FindByCode find invitation by code
+func (s InvitationServiceImpl) FindByCode(code string) (*dto.Invitation, error) {
+
+	filter := struct {
+		Code string `json:"code" bson:"code"`
+	}{
+		Code: code,
+	}
+	return s.FindOneInvitation(filter)
+}
+
+// FindByOwnerUserId find invitations created by a user
+func (s InvitationServiceImpl) FindByOwnerUserId(ownerUserId uuid.UUID, page int64) ([]dto.Invitation, error) {
+
+	sortMap := make(map[string]int)
+	sortMap["created_date"] = -1
+	skip := numberOfItems * (page - 1)
+	filter := struct {
+		OwnerUserId uuid.UUID `json:"ownerUserId" bson:"ownerUserId"`
+	}{
+		OwnerUserId: ownerUserId,
+	}
+	return s.FindInvitationList(filter, numberOfItems, skip, sortMap)
+}
+
+// QueryInvitation get all invitations by page
+func (s InvitationServiceImpl) QueryInvitation(page int64) ([]dto.Invitation, error) {
+
+	sortMap := make(map[string]int)
+	sortMap["created_date"] = -1
+	skip := numberOfItems * (page - 1)
+	filter := make(map[string]interface{})
+	return s.FindInvitationList(filter, numberOfItems, skip, sortMap)
+}
+
+// UseInvitation increase used count of invitation and record the invited user
+func (s InvitationServiceImpl) UseInvitation(invitation *dto.Invitation, userId uuid.UUID) error {
+
+	filter := make(map[string]interface{})
+	filter["objectId"] = invitation.ObjectId
+	if invitation.MaxUses > 0 {
+		filter["usedCount"] = map[string]interface{}{"$lt": invitation.MaxUses}
+	}
+
+	updateData := make(map[string]interface{})
+	updateData["$inc"] = map[string]interface{}{"usedCount": 1}
+	updateData["$push"] = map[string]interface{}{"usedBy": userId}
+	updateData["$set"] = map[string]interface{}{"last_updated": utils.UTCNowUnix()}
+
+	result := <-s.InvitationRepo.Update(invitationCollectionName, filter, updateData)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// Nothing is updated when the last use is taken by another signup
+	modifiedCount, _ := result.Result.(int64)
+	if modifiedCount == 0 {
+		return models.InvitationError{Code: models.InvitationErrorCodeUsedUp}
+	}
+	return nil
+}
+
+// DeleteInvitation delete invitation by owner
+func (s InvitationServiceImpl) DeleteInvitation(objectId uuid.UUID, ownerUserId uuid.UUID) error {
+
+	filter := struct {
+		ObjectId    uuid.UUID `json:"objectId" bson:"objectId"`
+		OwnerUserId uuid.UUID `json:"ownerUserId" bson:"ownerUserId"`
+	}{
+		ObjectId:    objectId,
+		OwnerUserId: ownerUserId,
+	}
+
+	result := <-s.InvitationRepo.Delete(invitationCollectionName, filter, true)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"fmt"
+
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/config"
+	repo "github.com/red-gold/telar-core/data"
+	"github.com/red-gold/telar-core/data/mongodb"
+	mongoRepo "github.com/red-gold/telar-core/data/mongodb"
+	"github.com/red-gold/telar-core/utils"
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+)
+
+// LegalDocumentService handlers with injected dependencies
+type LegalDocumentServiceImpl struct {
+	LegalDocumentRepo repo.Repository
+}
+
+// NewLegalDocumentService initializes LegalDocumentService's dependencies and create new LegalDocumentService struct
+func NewLegalDocumentService(db interface{}) (LegalDocumentService, error) {
+
+	legalDocumentService := &LegalDocumentServiceImpl{}
+
+	switch *config.AppConfig.DBType {
+	case config.DB_MONGO:
+
+		mongodb := db.(mongodb.MongoDatabase)
+		legalDocumentService.LegalDocumentRepo = mongoRepo.NewDataRepositoryMongo(mongodb)
+
+	}
+	if legalDocumentService.LegalDocumentRepo == nil {
+		fmt.Printf("legalDocumentService.LegalDocumentRepo is nil! \n")
+	}
+	return legalDocumentService, nil
+}
+
+// SaveLegalDocument save legal document information
+func (s LegalDocumentServiceImpl) SaveLegalDocument(legalDocument *dto.LegalDocument) error {
+
+	if legalDocument.ObjectId == uuid.Nil {
+		var uuidErr error
+		legalDocument.ObjectId, uuidErr = uuid.NewV4()
+		if uuidErr != nil {
+			return uuidErr
+		}
+	}
+
+	if legalDocument.CreatedDate == 0 {
+		legalDocument.CreatedDate = utils.UTCNowUnix()
+	}
+
+	result := <-s.LegalDocumentRepo.Save(legalDocumentCollectionName, legalDocument)
+
+	return result.Error
+}
+
+// FindOneLegalDocument get one legal document
+func (s LegalDocumentServiceImpl) FindOneLegalDocument(filter interface{}) (*dto.LegalDocument, error) {
+
+	result := <-s.LegalDocumentRepo.FindOne(legalDocumentCollectionName, filter)
+	if result.Error() != nil {
+		if result.Error() == repo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, result.Error()
+	}
+
+	var legalDocumentResult dto.LegalDocument
+	errDecode := result.Decode(&legalDocumentResult)
+	if errDecode != nil {
+		return nil, fmt.Errorf("Error docoding on dto.LegalDocument")
+	}
+	return &legalDocumentResult, nil
+}
+
+// FindLegalDocumentList get all legal documents by filter
+func (s LegalDocumentServiceImpl) FindLegalDocumentList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.LegalDocument, error) {
+
+	result := <-s.LegalDocumentRepo.Find(legalDocumentCollectionName, filter, limit, skip, sort)
+	defer result.Close()
+	if result.Error() != nil {
+		return nil, result.Error()
+	}
+	var legalDocumentList []dto.LegalDocument
+	for result.Next() {
+		var legalDocument dto.LegalDocument
+		errDecode := result.Decode(&legalDocument)
+		if errDecode != nil {
+			return nil, fmt.Errorf("Error docoding on dto.LegalDocument")
+		}
+		legalDocumentList = append(legalDocumentList, legalDocument)
+	}
+
+	return legalDocumentList, nil
+}
+
+// FindByTypeVersion find legal document by type and version
+func (s LegalDocumentServiceImpl) FindByTypeVersion(documentType string, version string) (*dto.LegalDocument, error) {
+
+	filter := struct {
+		Type    string `json:"type" bson:"type"`
+		Version string `json:"version" bson:"version"`
+	}{
+		Type:    documentType,
+		Version: version,
+	}
+	return s.FindOneLegalDocument(filter)
+}
+
+// FindLatestByType find the latest published version of a legal document type.
+// Documents with a future published date are scheduled and not returned yet.
+func (s LegalDocumentServiceImpl) FindLatestByType(documentType string) (*dto.LegalDocument, error) {
+
+	sortMap := make(map[string]int)
+	sortMap["publishedDate"] = -1
+	filter := make(map[string]interface{})
+	filter["type"] = documentType
+	filter["publishedDate"] = map[string]interface{}{"$lte": utils.UTCNowUnix()}
+
+	legalDocumentList, err := s.FindLegalDocumentList(filter, 1, 0, sortMap)
+	if err != nil {
+		return nil, err
+	}
+	if len(legalDocumentList) == 0 {
+		return nil, nil
+	}
+	return &legalDocumentList[0], nil
+}
+
+// QueryLegalDocument get all versions of legal documents by page. Empty type returns all types.
+func (s LegalDocumentServiceImpl) QueryLegalDocument(documentType string, page int64) ([]dto.LegalDocument, error) {
+
+	sortMap := make(map[string]int)
+	sortMap["publishedDate"] = -1
+	skip := numberOfItems * (page - 1)
+	filter := make(map[string]interface{})
+	if documentType != "" {
+		filter["type"] = documentType
+	}
+	return s.FindLegalDocumentList(filter, numberOfItems, skip, sortMap)
+}
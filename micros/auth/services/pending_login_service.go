@@ -0,0 +1,97 @@
+package service
+
+import (
+	"fmt"
+
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/config"
+	repo "github.com/red-gold/telar-core/data"
+	"github.com/red-gold/telar-core/data/mongodb"
+	mongoRepo "github.com/red-gold/telar-core/data/mongodb"
+	"github.com/red-gold/telar-core/utils"
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+)
+
+// PendingLoginService handlers with injected dependencies
+type PendingLoginServiceImpl struct {
+	PendingLoginRepo repo.Repository
+}
+
+// NewPendingLoginService initializes PendingLoginService's dependencies and create new PendingLoginService struct
+func NewPendingLoginService(db interface{}) (PendingLoginService, error) {
+
+	pendingLoginService := &PendingLoginServiceImpl{}
+
+	switch *config.AppConfig.DBType {
+	case config.DB_MONGO:
+
+		mongodb := db.(mongodb.MongoDatabase)
+		pendingLoginService.PendingLoginRepo = mongoRepo.NewDataRepositoryMongo(mongodb)
+
+	}
+	if pendingLoginService.PendingLoginRepo == nil {
+		fmt.Printf("pendingLoginService.PendingLoginRepo is nil! \n")
+	}
+	return pendingLoginService, nil
+}
+
+// SavePendingLogin save login which waits for user consent
+func (s PendingLoginServiceImpl) SavePendingLogin(pendingLogin *dto.PendingLogin) error {
+
+	if pendingLogin.ObjectId == uuid.Nil {
+		var uuidErr error
+		pendingLogin.ObjectId, uuidErr = uuid.NewV4()
+		if uuidErr != nil {
+			return uuidErr
+		}
+	}
+
+	if pendingLogin.CreatedDate == 0 {
+		pendingLogin.CreatedDate = utils.UTCNowUnix()
+	}
+
+	result := <-s.PendingLoginRepo.Save(pendingLoginCollectionName, pendingLogin)
+
+	return result.Error
+}
+
+// FindByTokenHash find pending login by hash of its token which is not expired
+func (s PendingLoginServiceImpl) FindByTokenHash(tokenHash string) (*dto.PendingLogin, error) {
+
+	filter := make(map[string]interface{})
+	filter["tokenHash"] = tokenHash
+	filter["expiresAt"] = map[string]interface{}{"$gt": utils.UTCNowUnix()}
+
+	result := <-s.PendingLoginRepo.FindOne(pendingLoginCollectionName, filter)
+	if result.Error() != nil {
+		if result.Error() == repo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, result.Error()
+	}
+
+	var pendingLoginResult dto.PendingLogin
+	errDecode := result.Decode(&pendingLoginResult)
+	if errDecode != nil {
+		return nil, fmt.Errorf("Error docoding on dto.PendingLogin")
+	}
+	return &pendingLoginResult, nil
+}
+
+// ConsumePendingLogin delete the pending login so its token can be used once.
+// It returns false when the login was already consumed by another request.
+func (s PendingLoginServiceImpl) ConsumePendingLogin(pendingLogin *dto.PendingLogin) (bool, error) {
+
+	filter := struct {
+		ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	}{
+		ObjectId: pendingLogin.ObjectId,
+	}
+
+	result := <-s.PendingLoginRepo.Delete(pendingLoginCollectionName, filter, true)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	deletedCount, _ := result.Result.(int64)
+	return deletedCount > 0, nil
+}
@@ -3,6 +3,13 @@ package service
 const (
 	userAuthCollectionName         = "userAuth"
 	userVerificationCollectionName = "userVerification"
+	invitationCollectionName       = "invitation"
+	userDeviceCollectionName       = "userDevice"
+	legalDocumentCollectionName    = "legalDocument"
+	userConsentCollectionName      = "userConsent"
+	userImportCollectionName       = "userImport"
+	userImportRowCollectionName    = "userImportRow"
+	pendingLoginCollectionName     = "pendingLogin"
 )
 
 const (
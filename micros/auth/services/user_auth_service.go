@@ -152,6 +152,27 @@ func (s UserAuthServiceImpl) UpdatePassword(userId uuid.UUID, newPassword []byte
 	return nil
 }
 
+// RevokeSessions invalidate all sessions of user issued before now
+func (s UserAuthServiceImpl) RevokeSessions(userId uuid.UUID) error {
+
+	updateData := struct {
+		Set interface{} `json:"$set" bson:"$set"`
+	}{
+		Set: struct {
+			RevokedAt int64 `json:"revokedAt" bson:"revokedAt"`
+		}{
+			RevokedAt: utils.UTCNowUnix(),
+		},
+	}
+
+	filter := struct {
+		ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	}{
+		ObjectId: userId,
+	}
+	return s.UpdateUserAuth(filter, &updateData)
+}
+
 // DeleteUserAuth get all user authentication informaition
 func (s UserAuthServiceImpl) DeleteUserAuth(filter interface{}) error {
 
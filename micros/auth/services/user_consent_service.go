@@ -0,0 +1,141 @@
+package service
+
+import (
+	"fmt"
+
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/config"
+	repo "github.com/red-gold/telar-core/data"
+	"github.com/red-gold/telar-core/data/mongodb"
+	mongoRepo "github.com/red-gold/telar-core/data/mongodb"
+	"github.com/red-gold/telar-core/utils"
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+)
+
+// UserConsentService handlers with injected dependencies
+type UserConsentServiceImpl struct {
+	UserConsentRepo repo.Repository
+}
+
+// NewUserConsentService initializes UserConsentService's dependencies and create new UserConsentService struct
+func NewUserConsentService(db interface{}) (UserConsentService, error) {
+
+	userConsentService := &UserConsentServiceImpl{}
+
+	switch *config.AppConfig.DBType {
+	case config.DB_MONGO:
+
+		mongodb := db.(mongodb.MongoDatabase)
+		userConsentService.UserConsentRepo = mongoRepo.NewDataRepositoryMongo(mongodb)
+
+	}
+	if userConsentService.UserConsentRepo == nil {
+		fmt.Printf("userConsentService.UserConsentRepo is nil! \n")
+	}
+	return userConsentService, nil
+}
+
+// SaveUserConsent save user consent information
+func (s UserConsentServiceImpl) SaveUserConsent(userConsent *dto.UserConsent) error {
+
+	if userConsent.ObjectId == uuid.Nil {
+		var uuidErr error
+		userConsent.ObjectId, uuidErr = uuid.NewV4()
+		if uuidErr != nil {
+			return uuidErr
+		}
+	}
+
+	if userConsent.AcceptedDate == 0 {
+		userConsent.AcceptedDate = utils.UTCNowUnix()
+	}
+
+	result := <-s.UserConsentRepo.Save(userConsentCollectionName, userConsent)
+
+	return result.Error
+}
+
+// FindOneUserConsent get one user consent
+func (s UserConsentServiceImpl) FindOneUserConsent(filter interface{}) (*dto.UserConsent, error) {
+
+	result := <-s.UserConsentRepo.FindOne(userConsentCollectionName, filter)
+	if result.Error() != nil {
+		if result.Error() == repo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, result.Error()
+	}
+
+	var userConsentResult dto.UserConsent
+	errDecode := result.Decode(&userConsentResult)
+	if errDecode != nil {
+		return nil, fmt.Errorf("Error docoding on dto.UserConsent")
+	}
+	return &userConsentResult, nil
+}
+
+// FindUserConsentList get all user consents by filter
+func (s UserConsentServiceImpl) FindUserConsentList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.UserConsent, error) {
+
+	result := <-s.UserConsentRepo.Find(userConsentCollectionName, filter, limit, skip, sort)
+	defer result.Close()
+	if result.Error() != nil {
+		return nil, result.Error()
+	}
+	var userConsentList []dto.UserConsent
+	for result.Next() {
+		var userConsent dto.UserConsent
+		errDecode := result.Decode(&userConsent)
+		if errDecode != nil {
+			return nil, fmt.Errorf("Error docoding on dto.UserConsent")
+		}
+		userConsentList = append(userConsentList, userConsent)
+	}
+
+	return userConsentList, nil
+}
+
+// HasUserConsent check whether user has accepted the legal document
+func (s UserConsentServiceImpl) HasUserConsent(userId uuid.UUID, documentId uuid.UUID) (bool, error) {
+
+	filter := struct {
+		UserId     uuid.UUID `json:"userId" bson:"userId"`
+		DocumentId uuid.UUID `json:"documentId" bson:"documentId"`
+	}{
+		UserId:     userId,
+		DocumentId: documentId,
+	}
+	userConsent, err := s.FindOneUserConsent(filter)
+	if err != nil {
+		return false, err
+	}
+	return userConsent != nil, nil
+}
+
+// FindByUserId find consent history of a user
+func (s UserConsentServiceImpl) FindByUserId(userId uuid.UUID) ([]dto.UserConsent, error) {
+
+	sortMap := make(map[string]int)
+	sortMap["acceptedDate"] = -1
+	filter := struct {
+		UserId uuid.UUID `json:"userId" bson:"userId"`
+	}{
+		UserId: userId,
+	}
+	return s.FindUserConsentList(filter, 0, 0, sortMap)
+}
+
+// QueryUserConsent get user consents in order of acceptance. Empty type or version returns all of them.
+func (s UserConsentServiceImpl) QueryUserConsent(documentType string, version string, limit int64, skip int64) ([]dto.UserConsent, error) {
+
+	sortMap := make(map[string]int)
+	sortMap["acceptedDate"] = 1
+	filter := make(map[string]interface{})
+	if documentType != "" {
+		filter["documentType"] = documentType
+	}
+	if version != "" {
+		filter["version"] = version
+	}
+	return s.FindUserConsentList(filter, limit, skip, sortMap)
+}
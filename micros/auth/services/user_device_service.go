@@ -0,0 +1,213 @@
+package service
+
+import (
+	"fmt"
+
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/config"
+	repo "github.com/red-gold/telar-core/data"
+	"github.com/red-gold/telar-core/data/mongodb"
+	mongoRepo "github.com/red-gold/telar-core/data/mongodb"
+	"github.com/red-gold/telar-core/utils"
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+)
+
+// maxKnownDevices is the upper bound of devices loaded for one user
+const maxKnownDevices = 100
+
+// UserDeviceService handlers with injected dependencies
+type UserDeviceServiceImpl struct {
+	UserDeviceRepo repo.Repository
+}
+
+// NewUserDeviceService initializes UserDeviceService's dependencies and create new UserDeviceService struct
+func NewUserDeviceService(db interface{}) (UserDeviceService, error) {
+
+	userDeviceService := &UserDeviceServiceImpl{}
+
+	switch *config.AppConfig.DBType {
+	case config.DB_MONGO:
+
+		mongodb := db.(mongodb.MongoDatabase)
+		userDeviceService.UserDeviceRepo = mongoRepo.NewDataRepositoryMongo(mongodb)
+
+	}
+	if userDeviceService.UserDeviceRepo == nil {
+		fmt.Printf("userDeviceService.UserDeviceRepo is nil! \n")
+	}
+	return userDeviceService, nil
+}
+
+// SaveUserDevice save known device of user
+func (s UserDeviceServiceImpl) SaveUserDevice(userDevice *dto.UserDevice) error {
+
+	if userDevice.ObjectId == uuid.Nil {
+		var uuidErr error
+		userDevice.ObjectId, uuidErr = uuid.NewV4()
+		if uuidErr != nil {
+			return uuidErr
+		}
+	}
+
+	if userDevice.CreatedDate == 0 {
+		userDevice.CreatedDate = utils.UTCNowUnix()
+	}
+	if userDevice.LastSeen == 0 {
+		userDevice.LastSeen = userDevice.CreatedDate
+	}
+
+	result := <-s.UserDeviceRepo.Save(userDeviceCollectionName, userDevice)
+
+	return result.Error
+}
+
+// FindOneUserDevice get one user device
+func (s UserDeviceServiceImpl) FindOneUserDevice(filter interface{}) (*dto.UserDevice, error) {
+
+	result := <-s.UserDeviceRepo.FindOne(userDeviceCollectionName, filter)
+	if result.Error() != nil {
+		if result.Error() == repo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, result.Error()
+	}
+
+	var userDeviceResult dto.UserDevice
+	errDecode := result.Decode(&userDeviceResult)
+	if errDecode != nil {
+		return nil, fmt.Errorf("Error docoding on dto.UserDevice")
+	}
+	return &userDeviceResult, nil
+}
+
+// FindUserDeviceList get all user devices by filter
+func (s UserDeviceServiceImpl) FindUserDeviceList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.UserDevice, error) {
+
+	result := <-s.UserDeviceRepo.Find(userDeviceCollectionName, filter, limit, skip, sort)
+	defer result.Close()
+	if result.Error() != nil {
+		return nil, result.Error()
+	}
+	var userDeviceList []dto.UserDevice
+	for result.Next() {
+		var userDevice dto.UserDevice
+		errDecode := result.Decode(&userDevice)
+		if errDecode != nil {
+			return nil, fmt.Errorf("Error docoding on dto.UserDevice")
+		}
+		userDeviceList = append(userDeviceList, userDevice)
+	}
+
+	return userDeviceList, nil
+}
+
+// FindByUserId find known devices of a user
+func (s UserDeviceServiceImpl) FindByUserId(userId uuid.UUID) ([]dto.UserDevice, error) {
+
+	sortMap := make(map[string]int)
+	sortMap["lastSeen"] = -1
+	filter := struct {
+		UserId uuid.UUID `json:"userId" bson:"userId"`
+	}{
+		UserId: userId,
+	}
+	return s.FindUserDeviceList(filter, maxKnownDevices, 0, sortMap)
+}
+
+// FindByFingerprint find known device of a user by device fingerprint
+func (s UserDeviceServiceImpl) FindByFingerprint(userId uuid.UUID, fingerprint string) (*dto.UserDevice, error) {
+
+	filter := struct {
+		UserId      uuid.UUID `json:"userId" bson:"userId"`
+		Fingerprint string    `json:"fingerprint" bson:"fingerprint"`
+	}{
+		UserId:      userId,
+		Fingerprint: fingerprint,
+	}
+	return s.FindOneUserDevice(filter)
+}
+
+// TouchUserDevice update last seen of device and remember the IP range
+func (s UserDeviceServiceImpl) TouchUserDevice(objectId uuid.UUID, ipRange string, ipAddress string) error {
+
+	filter := struct {
+		ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	}{
+		ObjectId: objectId,
+	}
+
+	updateData := make(map[string]interface{})
+	updateData["$set"] = map[string]interface{}{"lastSeen": utils.UTCNowUnix(), "lastIpAddress": ipAddress}
+	updateData["$addToSet"] = map[string]interface{}{"ipRanges": ipRange}
+
+	result := <-s.UserDeviceRepo.Update(userDeviceCollectionName, filter, updateData)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+// BindUserDevice replace the fingerprint of a device which was known before devices got a cookie
+func (s UserDeviceServiceImpl) BindUserDevice(objectId uuid.UUID, fingerprint string) error {
+
+	filter := struct {
+		ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	}{
+		ObjectId: objectId,
+	}
+
+	updateData := make(map[string]interface{})
+	updateData["$set"] = map[string]interface{}{"fingerprint": fingerprint}
+
+	result := <-s.UserDeviceRepo.Update(userDeviceCollectionName, filter, updateData)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+// SetAlertToken keep hash of the "this wasn't me" token of device. A new alert replaces the previous token.
+func (s UserDeviceServiceImpl) SetAlertToken(objectId uuid.UUID, tokenHash string, expiresAt int64) error {
+
+	filter := struct {
+		ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	}{
+		ObjectId: objectId,
+	}
+
+	updateData := make(map[string]interface{})
+	updateData["$set"] = map[string]interface{}{"alertTokenHash": tokenHash, "alertExpiresAt": expiresAt}
+
+	result := <-s.UserDeviceRepo.Update(userDeviceCollectionName, filter, updateData)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+// FindByAlertToken find device by hash of a "this wasn't me" token which is not expired
+func (s UserDeviceServiceImpl) FindByAlertToken(tokenHash string) (*dto.UserDevice, error) {
+
+	filter := make(map[string]interface{})
+	filter["alertTokenHash"] = tokenHash
+	filter["alertExpiresAt"] = map[string]interface{}{"$gt": utils.UTCNowUnix()}
+	return s.FindOneUserDevice(filter)
+}
+
+// DeleteUserDevice forget a known device of user
+func (s UserDeviceServiceImpl) DeleteUserDevice(objectId uuid.UUID, userId uuid.UUID) error {
+
+	filter := struct {
+		ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+		UserId   uuid.UUID `json:"userId" bson:"userId"`
+	}{
+		ObjectId: objectId,
+		UserId:   userId,
+	}
+
+	result := <-s.UserDeviceRepo.Delete(userDeviceCollectionName, filter, true)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
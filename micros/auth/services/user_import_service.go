@@ -0,0 +1,226 @@
+package service
+
+import (
+	"fmt"
+
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/config"
+	repo "github.com/red-gold/telar-core/data"
+	"github.com/red-gold/telar-core/data/mongodb"
+	mongoRepo "github.com/red-gold/telar-core/data/mongodb"
+	"github.com/red-gold/telar-core/utils"
+	dto "github.com/red-gold/telar-web/micros/auth/dto"
+	models "github.com/red-gold/telar-web/micros/auth/models"
+)
+
+// UserImportService handlers with injected dependencies
+type UserImportServiceImpl struct {
+	UserImportRepo repo.Repository
+}
+
+// NewUserImportService initializes UserImportService's dependencies and create new UserImportService struct
+func NewUserImportService(db interface{}) (UserImportService, error) {
+
+	userImportService := &UserImportServiceImpl{}
+
+	switch *config.AppConfig.DBType {
+	case config.DB_MONGO:
+
+		mongodb := db.(mongodb.MongoDatabase)
+		userImportService.UserImportRepo = mongoRepo.NewDataRepositoryMongo(mongodb)
+
+	}
+	if userImportService.UserImportRepo == nil {
+		fmt.Printf("userImportService.UserImportRepo is nil! \n")
+	}
+	return userImportService, nil
+}
+
+// SaveUserImport save user import
+func (s UserImportServiceImpl) SaveUserImport(userImport *dto.UserImport) error {
+
+	if userImport.ObjectId == uuid.Nil {
+		var uuidErr error
+		userImport.ObjectId, uuidErr = uuid.NewV4()
+		if uuidErr != nil {
+			return uuidErr
+		}
+	}
+
+	if userImport.CreatedDate == 0 {
+		userImport.CreatedDate = utils.UTCNowUnix()
+		userImport.LastUpdated = userImport.CreatedDate
+	}
+
+	result := <-s.UserImportRepo.Save(userImportCollectionName, userImport)
+
+	return result.Error
+}
+
+// UpdateUserImport save progress of user import
+func (s UserImportServiceImpl) UpdateUserImport(userImport *dto.UserImport) error {
+
+	filter := struct {
+		ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	}{
+		ObjectId: userImport.ObjectId,
+	}
+
+	userImport.LastUpdated = utils.UTCNowUnix()
+	updateOperator := repo.UpdateOperator{
+		Set: userImport,
+	}
+	result := <-s.UserImportRepo.Update(userImportCollectionName, filter, updateOperator)
+	return result.Error
+}
+
+// FindOneUserImport get one user import
+func (s UserImportServiceImpl) FindOneUserImport(filter interface{}) (*dto.UserImport, error) {
+
+	result := <-s.UserImportRepo.FindOne(userImportCollectionName, filter)
+	if result.Error() != nil {
+		if result.Error() == repo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, result.Error()
+	}
+
+	var userImportResult dto.UserImport
+	errDecode := result.Decode(&userImportResult)
+	if errDecode != nil {
+		return nil, fmt.Errorf("Error docoding on dto.UserImport")
+	}
+	return &userImportResult, nil
+}
+
+// FindUserImportList get all user imports by filter
+func (s UserImportServiceImpl) FindUserImportList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.UserImport, error) {
+
+	result := <-s.UserImportRepo.Find(userImportCollectionName, filter, limit, skip, sort)
+	defer result.Close()
+	if result.Error() != nil {
+		return nil, result.Error()
+	}
+	userImportList := []dto.UserImport{}
+	for result.Next() {
+		var userImport dto.UserImport
+		errDecode := result.Decode(&userImport)
+		if errDecode != nil {
+			return nil, fmt.Errorf("Error docoding on dto.UserImport")
+		}
+		userImportList = append(userImportList, userImport)
+	}
+
+	return userImportList, nil
+}
+
+// FindById find user import by id
+func (s UserImportServiceImpl) FindById(objectId uuid.UUID) (*dto.UserImport, error) {
+
+	filter := struct {
+		ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	}{
+		ObjectId: objectId,
+	}
+	return s.FindOneUserImport(filter)
+}
+
+// QueryUserImport get user imports by page from newest to oldest
+func (s UserImportServiceImpl) QueryUserImport(page int64) ([]dto.UserImport, error) {
+
+	sortMap := make(map[string]int)
+	sortMap["created_date"] = -1
+	skip := numberOfItems * (page - 1)
+	return s.FindUserImportList(struct{}{}, numberOfItems, skip, sortMap)
+}
+
+// SaveManyUserImportRow save rows of user import
+func (s UserImportServiceImpl) SaveManyUserImportRow(userImportRows []dto.UserImportRow) error {
+
+	// https://github.com/golang/go/wiki/InterfaceSlice
+	var interfaceSlice []interface{} = make([]interface{}, len(userImportRows))
+	createdDate := utils.UTCNowUnix()
+	for i, d := range userImportRows {
+		if d.ObjectId == uuid.Nil {
+			var uuidErr error
+			d.ObjectId, uuidErr = uuid.NewV4()
+			if uuidErr != nil {
+				return uuidErr
+			}
+		}
+
+		if d.CreatedDate == 0 {
+			d.CreatedDate = createdDate
+			d.LastUpdated = createdDate
+		}
+		interfaceSlice[i] = d
+	}
+	result := <-s.UserImportRepo.SaveMany(userImportRowCollectionName, interfaceSlice)
+
+	return result.Error
+}
+
+// UpdateUserImportRow save status and step of user import row
+func (s UserImportServiceImpl) UpdateUserImportRow(userImportRow *dto.UserImportRow) error {
+
+	filter := struct {
+		ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	}{
+		ObjectId: userImportRow.ObjectId,
+	}
+
+	userImportRow.LastUpdated = utils.UTCNowUnix()
+	updateOperator := repo.UpdateOperator{
+		Set: userImportRow,
+	}
+	result := <-s.UserImportRepo.Update(userImportRowCollectionName, filter, updateOperator)
+	return result.Error
+}
+
+// FindUserImportRowList get all user import rows by filter
+func (s UserImportServiceImpl) FindUserImportRowList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.UserImportRow, error) {
+
+	result := <-s.UserImportRepo.Find(userImportRowCollectionName, filter, limit, skip, sort)
+	defer result.Close()
+	if result.Error() != nil {
+		return nil, result.Error()
+	}
+	userImportRowList := []dto.UserImportRow{}
+	for result.Next() {
+		var userImportRow dto.UserImportRow
+		errDecode := result.Decode(&userImportRow)
+		if errDecode != nil {
+			return nil, fmt.Errorf("Error docoding on dto.UserImportRow")
+		}
+		userImportRowList = append(userImportRowList, userImportRow)
+	}
+
+	return userImportRowList, nil
+}
+
+// FindPendingRows get the next rows of user import which are not processed yet in file order
+func (s UserImportServiceImpl) FindPendingRows(importId uuid.UUID, limit int64) ([]dto.UserImportRow, error) {
+
+	filter := struct {
+		ImportId uuid.UUID `json:"importId" bson:"importId"`
+		Status   string    `json:"status" bson:"status"`
+	}{
+		ImportId: importId,
+		Status:   models.UserImportRowPending,
+	}
+	sortMap := map[string]int{"row": 1}
+	return s.FindUserImportRowList(filter, limit, 0, sortMap)
+}
+
+// QueryUserImportRow get rows of user import by page in file order. Empty status gets all rows.
+func (s UserImportServiceImpl) QueryUserImportRow(importId uuid.UUID, status string, page int64) ([]dto.UserImportRow, error) {
+
+	filter := make(map[string]interface{})
+	filter["importId"] = importId
+	if status != "" {
+		filter["status"] = status
+	}
+	sortMap := map[string]int{"row": 1}
+	skip := numberOfItems * (page - 1)
+	return s.FindUserImportRowList(filter, numberOfItems, skip, sortMap)
+}
@@ -1,6 +1,9 @@
 package service
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
 
 	uuid "github.com/gofrs/uuid"
@@ -8,6 +11,7 @@ import (
 	repo "github.com/red-gold/telar-core/data"
 	"github.com/red-gold/telar-core/data/mongodb"
 	mongoRepo "github.com/red-gold/telar-core/data/mongodb"
+	"github.com/red-gold/telar-core/pkg/log"
 	"github.com/red-gold/telar-core/utils"
 	"github.com/red-gold/telar-web/constants"
 	dto "github.com/red-gold/telar-web/micros/auth/dto"
@@ -28,6 +32,11 @@ type EmailVerificationToken struct {
 	EmailSubject    string
 	FullName        string
 	UserPassword    string
+	InviteCode      string
+	AcceptedLegal   string
+	SocialName      string
+	CustomFields    string
+	DeviceNonce     string
 }
 
 type PhoneVerificationToken struct {
@@ -38,6 +47,11 @@ type PhoneVerificationToken struct {
 	PhoneNumber     string
 	FullName        string
 	UserPassword    string
+	InviteCode      string
+	AcceptedLegal   string
+	SocialName      string
+	CustomFields    string
+	DeviceNonce     string
 }
 
 type MetaVerificationTokenClaim struct {
@@ -49,6 +63,10 @@ type MetaVerificationTokenClaim struct {
 	Fullname        string                `json:"fullName"`
 	Email           string                `json:"email"`
 	Password        string                `json:"password"`
+	InviteCode      string                `json:"inviteCode"`
+	AcceptedLegal   string                `json:"acceptedLegal"`
+	SocialName      string                `json:"socialName"`
+	CustomFields    string                `json:"customFields,omitempty"`
 }
 
 // NewUserVerificationService initializes UserVerificationService's dependencies and create new UserVerificationService struct
@@ -146,6 +164,38 @@ func (s UserVerificationServiceImpl) UpdateUserVerification(filter interface{},
 	return nil
 }
 
+// UseUserVerification mark the user verification as used so its link can be used once.
+// It returns false when the verification was already used by another request.
+func (s UserVerificationServiceImpl) UseUserVerification(verifyId uuid.UUID) (bool, error) {
+
+	filter := struct {
+		ObjectId   uuid.UUID `json:"objectId" bson:"objectId"`
+		IsVerified bool      `json:"isVerified" bson:"isVerified"`
+	}{
+		ObjectId:   verifyId,
+		IsVerified: false,
+	}
+
+	updateData := struct {
+		Set interface{} `json:"$set" bson:"$set"`
+	}{
+		Set: struct {
+			LastUpdated int64 `json:"last_updated" bson:"last_updated"`
+			IsVerified  bool  `json:"isVerified" bson:"isVerified"`
+		}{
+			LastUpdated: utils.UTCNowUnix(),
+			IsVerified:  true,
+		},
+	}
+
+	result := <-s.UserVerificationRepo.Update(userVerificationCollectionName, filter, updateData)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	modifiedCount, _ := result.Result.(int64)
+	return modifiedCount > 0, nil
+}
+
 // DeleteUserVerification get all user authentication informaition
 func (s UserVerificationServiceImpl) DeleteUserVerification(filter interface{}) error {
 
@@ -178,8 +228,10 @@ func (s UserVerificationServiceImpl) FindByUserId(userId uuid.UUID) (*dto.UserVe
 	return s.FindOneUserVerification(filter)
 }
 
-// VerifyUserByCode verify user by verification code
-func (s UserVerificationServiceImpl) VerifyUserByCode(userId uuid.UUID, verifyId uuid.UUID, remoteIpAddress string, code string, target string) (bool, error) {
+// VerifyUserByCode verify user by verification code.
+// The verification is bound to the device nonce given at signup, the remote address is only
+// compared when strictRemoteAddress is set or the verification was created without a device nonce.
+func (s UserVerificationServiceImpl) VerifyUserByCode(userId uuid.UUID, verifyId uuid.UUID, remoteIpAddress string, deviceNonce string, code string, target string, strictRemoteAddress bool) (bool, error) {
 	userVerification, findErr := s.FindByVerifyId(verifyId)
 	if findErr != nil {
 		fmt.Println(findErr.Error())
@@ -187,7 +239,16 @@ func (s UserVerificationServiceImpl) VerifyUserByCode(userId uuid.UUID, verifyId
 	}
 
 	if userVerification.RemoteIpAddress != remoteIpAddress {
-		return false, fmt.Errorf("verifyUserByCode/differentRemoteAddress")
+		if strictRemoteAddress || userVerification.DeviceNonceHash == "" {
+			return false, fmt.Errorf("verifyUserByCode/differentRemoteAddress")
+		}
+		log.Warn("[VerifyUserByCode] Risk signal: remote address changed during verification of user %s from %s to %s",
+			userId.String(), userVerification.RemoteIpAddress, remoteIpAddress)
+	}
+
+	if userVerification.DeviceNonceHash != "" &&
+		subtle.ConstantTimeCompare([]byte(userVerification.DeviceNonceHash), []byte(HashDeviceNonce(deviceNonce))) != 1 {
+		return false, fmt.Errorf("verifyUserByCode/differentDevice")
 	}
 
 	newCounter := userVerification.Counter + 1
@@ -292,6 +353,7 @@ func (s UserVerificationServiceImpl) CreateEmailVerficationToken(input EmailVeri
 		TargetType:      constants.EmailVerifyConst,
 		Counter:         1,
 		RemoteIpAddress: input.RemoteIpAddress,
+		DeviceNonceHash: HashDeviceNonce(input.DeviceNonce),
 	}
 	saveErr := s.SaveUserVerification(userVerification)
 	if saveErr != nil {
@@ -307,6 +369,10 @@ func (s UserVerificationServiceImpl) CreateEmailVerficationToken(input EmailVeri
 		Fullname:        input.FullName,
 		Email:           input.EmailTo,
 		Password:        input.UserPassword,
+		InviteCode:      input.InviteCode,
+		AcceptedLegal:   input.AcceptedLegal,
+		SocialName:      input.SocialName,
+		CustomFields:    input.CustomFields,
 	}
 
 	return utils.GenerateJWTToken([]byte(*coreConfig.PrivateKey), utils.TokenClaims{
@@ -343,6 +409,7 @@ func (s UserVerificationServiceImpl) CreatePhoneVerficationToken(input PhoneVeri
 		TargetType:      constants.PhoneVerifyConst,
 		Counter:         1,
 		RemoteIpAddress: input.RemoteIpAddress,
+		DeviceNonceHash: HashDeviceNonce(input.DeviceNonce),
 	}
 	saveErr := s.SaveUserVerification(userVerification)
 	if saveErr != nil {
@@ -358,6 +425,10 @@ func (s UserVerificationServiceImpl) CreatePhoneVerficationToken(input PhoneVeri
 		Fullname:        input.FullName,
 		Email:           input.UserEmail,
 		Password:        input.UserPassword,
+		InviteCode:      input.InviteCode,
+		AcceptedLegal:   input.AcceptedLegal,
+		SocialName:      input.SocialName,
+		CustomFields:    input.CustomFields,
 	}
 
 	// Generate JWT token
@@ -365,3 +436,12 @@ func (s UserVerificationServiceImpl) CreatePhoneVerficationToken(input PhoneVeri
 		Claim: metaToken,
 	}, 1)
 }
+
+// HashDeviceNonce hash the device nonce to keep in verification record
+func HashDeviceNonce(deviceNonce string) string {
+	if deviceNonce == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(deviceNonce))
+	return hex.EncodeToString(hash[:])
+}
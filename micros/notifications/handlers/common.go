@@ -136,6 +136,22 @@ func getUsersNotificationSettings(userIds []uuid.UUID, userInfoInReq *UserInfoIn
 	return parsedData, nil
 }
 
+// getUserRestrictions get users blocked or muted by the user and users who blocked the user from profile micro
+func getUserRestrictions(userId uuid.UUID) (*models.UserRestrictionListModel, error) {
+	url := fmt.Sprintf("/profile/dto/restrictions/%s", userId.String())
+
+	resData, callErr := functionCall(http.MethodGet, []byte(""), url, nil)
+	if callErr != nil {
+		return nil, fmt.Errorf("Cannot send request to %s - %s", url, callErr.Error())
+	}
+
+	var restrictions models.UserRestrictionListModel
+	if err := json.Unmarshal(resData, &restrictions); err != nil {
+		return nil, err
+	}
+	return &restrictions, nil
+}
+
 // getNotificationTitle get notification title by notification type
 func getNotificationTitleByType(notificationType string, OwnerDisplayName string) string {
 	title := ""
@@ -58,6 +58,20 @@ func CreateNotificationHandle(c *fiber.Ctx) error {
 			"Can not get current user"))
 	}
 
+	// Receiver does not get notifications from users they blocked, muted or were blocked by
+	if currentUser.UserID != model.NotifyRecieverUserId {
+		restrictions, restrictionsErr := getUserRestrictions(model.NotifyRecieverUserId)
+		if restrictionsErr != nil {
+			log.Error("[CreateNotificationHandle] getUserRestrictions %s", restrictionsErr.Error())
+		} else if restrictions.IsRestricted(currentUser.UserID) {
+			log.Info("[CreateNotificationHandle] Drop notification from %s to %s", currentUser.UserID.String(), model.NotifyRecieverUserId.String())
+			return c.JSON(fiber.Map{
+				"objectId": "",
+				"dropped":  true,
+			})
+		}
+	}
+
 	newNotification := &domain.Notification{
 		ObjectId:             model.ObjectId,
 		OwnerUserId:          currentUser.UserID,
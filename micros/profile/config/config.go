@@ -6,6 +6,20 @@ import (
 	"strconv"
 )
 
+const (
+	// Two decimal places keep live location in a grid of about one kilometer
+	defaultLocationPrecision = 2
+	defaultNearbyMaxRadius   = 50000
+	// Users can change social name once in 30 days
+	defaultSocialNameChangeCooldown = 30 * 24 * 60 * 60
+	// Old social names can not be claimed by others for 90 days so shared links keep working
+	defaultSocialNameProtectionPeriod = 90 * 24 * 60 * 60
+	// Clients send heartbeat every 30 seconds so one missed heartbeat does not set the user offline
+	defaultPresenceTTL = 60
+	// Profile counters are reconciled once a day
+	defaultCounterReconcileInterval = 24 * 60 * 60
+)
+
 // Initialize AppConfig
 func InitConfig() {
 
@@ -32,6 +46,84 @@ func loadConfigFromEnvironment() {
 		log.Printf("[INFO]: Query Pretty URL information loaded from env.")
 	}
 
+	ProfileConfig.LocationPrecision = defaultLocationPrecision
+	locationPrecision, ok := os.LookupEnv("location_precision")
+	if ok {
+		parsedLocationPrecision, parseErr := strconv.Atoi(locationPrecision)
+		if parseErr != nil {
+			log.Printf("[ERROR]: Location precision information loading error: %s", parseErr.Error())
+		} else {
+			ProfileConfig.LocationPrecision = parsedLocationPrecision
+			log.Printf("[INFO]: Location precision information loaded from env.")
+		}
+	}
+
+	ProfileConfig.NearbyMaxRadius = defaultNearbyMaxRadius
+	nearbyMaxRadius, ok := os.LookupEnv("nearby_max_radius")
+	if ok {
+		parsedNearbyMaxRadius, parseErr := strconv.ParseFloat(nearbyMaxRadius, 64)
+		if parseErr != nil {
+			log.Printf("[ERROR]: Nearby max radius information loading error: %s", parseErr.Error())
+		} else {
+			ProfileConfig.NearbyMaxRadius = parsedNearbyMaxRadius
+			log.Printf("[INFO]: Nearby max radius information loaded from env.")
+		}
+	}
+
+	ProfileConfig.SocialNameChangeCooldown = defaultSocialNameChangeCooldown
+	socialNameChangeCooldown, ok := os.LookupEnv("social_name_change_cooldown")
+	if ok {
+		parsedSocialNameChangeCooldown, parseErr := strconv.ParseInt(socialNameChangeCooldown, 10, 64)
+		if parseErr != nil {
+			log.Printf("[ERROR]: Social name change cooldown information loading error: %s", parseErr.Error())
+		} else {
+			ProfileConfig.SocialNameChangeCooldown = parsedSocialNameChangeCooldown
+			log.Printf("[INFO]: Social name change cooldown information loaded from env.")
+		}
+	}
+
+	ProfileConfig.SocialNameProtectionPeriod = defaultSocialNameProtectionPeriod
+	socialNameProtectionPeriod, ok := os.LookupEnv("social_name_protection_period")
+	if ok {
+		parsedSocialNameProtectionPeriod, parseErr := strconv.ParseInt(socialNameProtectionPeriod, 10, 64)
+		if parseErr != nil {
+			log.Printf("[ERROR]: Social name protection period information loading error: %s", parseErr.Error())
+		} else {
+			ProfileConfig.SocialNameProtectionPeriod = parsedSocialNameProtectionPeriod
+			log.Printf("[INFO]: Social name protection period information loaded from env.")
+		}
+	}
+
+	ProfileConfig.PresenceTTL = defaultPresenceTTL
+	presenceTTL, ok := os.LookupEnv("presence_ttl")
+	if ok {
+		parsedPresenceTTL, parseErr := strconv.ParseInt(presenceTTL, 10, 64)
+		if parseErr != nil {
+			log.Printf("[ERROR]: Presence TTL information loading error: %s", parseErr.Error())
+		} else {
+			ProfileConfig.PresenceTTL = parsedPresenceTTL
+			log.Printf("[INFO]: Presence TTL information loaded from env.")
+		}
+	}
+
+	redisAddress, ok := os.LookupEnv("redis_address")
+	if ok {
+		ProfileConfig.RedisAddress = redisAddress
+		log.Printf("[INFO]: Redis address information loaded from env: %s", redisAddress)
+	}
+
+	ProfileConfig.CounterReconcileInterval = defaultCounterReconcileInterval
+	counterReconcileInterval, ok := os.LookupEnv("counter_reconcile_interval")
+	if ok {
+		parsedCounterReconcileInterval, parseErr := strconv.ParseInt(counterReconcileInterval, 10, 64)
+		if parseErr != nil {
+			log.Printf("[ERROR]: Counter reconcile interval information loading error: %s", parseErr.Error())
+		} else {
+			ProfileConfig.CounterReconcileInterval = parsedCounterReconcileInterval
+			log.Printf("[INFO]: Counter reconcile interval information loaded from env.")
+		}
+	}
+
 	debug, ok := os.LookupEnv("write_debug")
 	if ok {
 		parsedDebug, errParseDebug := strconv.ParseBool(debug)
@@ -2,9 +2,21 @@ package config
 
 type (
 	Configuration struct {
-		BaseRoute      string
-		QueryPrettyURL bool
-		Debug          bool // Debug enables verbose logging of claims / cookies
+		BaseRoute         string
+		QueryPrettyURL    bool
+		LocationPrecision int
+		NearbyMaxRadius   float64
+		// SocialNameChangeCooldown is the seconds a user waits between social name changes
+		SocialNameChangeCooldown int64
+		// SocialNameProtectionPeriod is the seconds an old social name is kept for its previous owner
+		SocialNameProtectionPeriod int64
+		// PresenceTTL is the seconds a heartbeat keeps the user online
+		PresenceTTL int64
+		// RedisAddress keeps presence in Redis to share it between instances. Presence is kept in memory when it is empty.
+		RedisAddress string
+		// CounterReconcileInterval is the seconds between scheduled counter reconciliations. Zero turns the schedule off.
+		CounterReconcileInterval int64
+		Debug                    bool // Debug enables verbose logging of claims / cookies
 	}
 )
 
@@ -0,0 +1,34 @@
+package dto
+
+import (
+	uuid "github.com/gofrs/uuid"
+)
+
+// CounterDiscrepancy is a profile counter which does not match its source collection
+type CounterDiscrepancy struct {
+	UserId uuid.UUID `json:"userId" bson:"userId"`
+	Field  string    `json:"field" bson:"field"`
+	Stored int64     `json:"stored" bson:"stored"`
+	Actual int64     `json:"actual" bson:"actual"`
+}
+
+// CounterReconciliation is a run of recomputing profile counters from their source collections
+type CounterReconciliation struct {
+	ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	// Trigger is admin or schedule
+	Trigger     string    `json:"trigger" bson:"trigger"`
+	TriggeredBy uuid.UUID `json:"triggeredBy" bson:"triggeredBy"`
+	// Fix saves the recomputed counters. Otherwise discrepancies are only reported.
+	Fix              bool   `json:"fix" bson:"fix"`
+	Status           string `json:"status" bson:"status"`
+	CheckedProfiles  int64  `json:"checkedProfiles" bson:"checkedProfiles"`
+	DiscrepancyCount int64  `json:"discrepancyCount" bson:"discrepancyCount"`
+	FixedCount       int64  `json:"fixedCount" bson:"fixedCount"`
+	// Discrepancies keeps the first discrepancies found so the report stays small
+	Discrepancies []CounterDiscrepancy `json:"discrepancies" bson:"discrepancies"`
+	// SkippedCounters are counters whose source collection does not exist in the database
+	SkippedCounters []string `json:"skippedCounters" bson:"skippedCounters"`
+	Error           string   `json:"error,omitempty" bson:"error,omitempty"`
+	CreatedDate     int64    `json:"created_date" bson:"created_date"`
+	FinishedDate    int64    `json:"finishedDate,omitempty" bson:"finishedDate,omitempty"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-web/constants"
+)
+
+// ProfileField is an admin-defined field which users fill in their profile.
+// Values are kept in the custom fields of the profile by the field name.
+type ProfileField struct {
+	ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	// Name is the key of the value in profile custom fields and can not be changed
+	Name  string `json:"name" bson:"name"`
+	Label string `json:"label" bson:"label"`
+	Type  string `json:"type" bson:"type"`
+	// Visibility is who can see the value of the field in other users profile
+	Visibility constants.UserPermissionConst `json:"visibility" bson:"visibility"`
+	// Required fields must be filled at signup and can not be cleared later
+	Required bool `json:"required" bson:"required"`
+	// Searchable fields can be used to filter profiles. Only public fields can be searchable.
+	Searchable bool `json:"searchable" bson:"searchable"`
+	// MinLength and MaxLength limit text of text and url fields and item count of list fields
+	MinLength int `json:"minLength,omitempty" bson:"minLength,omitempty"`
+	MaxLength int `json:"maxLength,omitempty" bson:"maxLength,omitempty"`
+	// Min and Max limit the value of number fields when set
+	Min *float64 `json:"min,omitempty" bson:"min,omitempty"`
+	Max *float64 `json:"max,omitempty" bson:"max,omitempty"`
+	// Pattern is a regular expression which text values and list items must match
+	Pattern string `json:"pattern,omitempty" bson:"pattern,omitempty"`
+	// Options are the allowed values of select fields and list items when not empty
+	Options     []string  `json:"options,omitempty" bson:"options,omitempty"`
+	Order       int       `json:"order" bson:"order"`
+	CreatedBy   uuid.UUID `json:"createdBy" bson:"createdBy"`
+	CreatedDate int64     `json:"created_date" bson:"created_date"`
+	LastUpdated int64     `json:"last_updated" bson:"last_updated"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+import (
+	uuid "github.com/gofrs/uuid"
+)
+
+// ProfileMigration is a data migration of the profile micro which has run to the end
+type ProfileMigration struct {
+	ObjectId      uuid.UUID `json:"objectId" bson:"objectId"`
+	Name          string    `json:"name" bson:"name"`
+	Count         int64     `json:"count" bson:"count"`
+	CompletedDate int64     `json:"completedDate" bson:"completedDate"`
+}
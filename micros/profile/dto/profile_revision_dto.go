@@ -0,0 +1,25 @@
+package dto
+
+import (
+	uuid "github.com/gofrs/uuid"
+)
+
+// ProfileFieldChange is the value of a profile field before and after an update
+type ProfileFieldChange struct {
+	Field  string      `json:"field" bson:"field"`
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
+
+// ProfileRevision is a recorded update of a user profile
+type ProfileRevision struct {
+	ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	UserId   uuid.UUID `json:"userId" bson:"userId"`
+	EditedBy uuid.UUID `json:"editedBy" bson:"editedBy"`
+	// Source is the kind of update like profile, socialName, visibility or rollback
+	Source string `json:"source" bson:"source"`
+	// RollbackOf is the revision which the profile is rolled back to before
+	RollbackOf  uuid.UUID            `json:"rollbackOf,omitempty" bson:"rollbackOf,omitempty"`
+	Changes     []ProfileFieldChange `json:"changes" bson:"changes"`
+	CreatedDate int64                `json:"created_date" bson:"created_date"`
+}
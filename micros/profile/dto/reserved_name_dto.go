@@ -0,0 +1,13 @@
+package dto
+
+import (
+	uuid "github.com/gofrs/uuid"
+)
+
+type ReservedName struct {
+	ObjectId    uuid.UUID `json:"objectId" bson:"objectId"`
+	Name        string    `json:"name" bson:"name"`
+	Reason      string    `json:"reason" bson:"reason"`
+	CreatedBy   uuid.UUID `json:"createdBy" bson:"createdBy"`
+	CreatedDate int64     `json:"created_date" bson:"created_date"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+import (
+	uuid "github.com/gofrs/uuid"
+)
+
+// SocialNameHistory is a previous social name of a user
+type SocialNameHistory struct {
+	ObjectId   uuid.UUID `json:"objectId" bson:"objectId"`
+	UserId     uuid.UUID `json:"userId" bson:"userId"`
+	SocialName string    `json:"socialName" bson:"socialName"`
+	ReplacedBy string    `json:"replacedBy" bson:"replacedBy"`
+	// ProtectedUntil is the time until other users can not claim the social name
+	ProtectedUntil int64 `json:"protectedUntil" bson:"protectedUntil"`
+	CreatedDate    int64 `json:"created_date" bson:"created_date"`
+}
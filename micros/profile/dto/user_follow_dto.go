@@ -0,0 +1,15 @@
+package dto
+
+import (
+	uuid "github.com/gofrs/uuid"
+)
+
+// UserFollow is a follow of following user by follower user. Follows of private profiles are pending until accepted.
+type UserFollow struct {
+	ObjectId     uuid.UUID `json:"objectId" bson:"objectId"`
+	FollowerId   uuid.UUID `json:"followerId" bson:"followerId"`
+	FollowingId  uuid.UUID `json:"followingId" bson:"followingId"`
+	Status       string    `json:"status" bson:"status"`
+	CreatedDate  int64     `json:"created_date" bson:"created_date"`
+	AcceptedDate int64     `json:"acceptedDate,omitempty" bson:"acceptedDate,omitempty"`
+}
@@ -10,10 +10,11 @@ type Location struct {
 	Coordinates []float64 `json:"coordinates" bson:"coordinates"`
 }
 
+// NewLocation create GeoJSON point. GeoJSON keeps longitude before latitude.
 func NewLocation(lat, long float64) Location {
 	return Location{
 		"Point",
-		[]float64{lat, long},
+		[]float64{long, lat},
 	}
 }
 
@@ -33,7 +34,9 @@ type UserProfile struct {
 	Country        string                        `json:"country" bson:"country"`
 	Address        string                        `json:"address" bson:"address"`
 	School         string                        `json:"school" bson:"school"`
-	LiveLocation   Location                      `json:"liveLocation" bson:"liveLocation"`
+	LiveLocation   *Location                     `json:"liveLocation,omitempty" bson:"liveLocation,omitempty"`
+	ShareLocation  bool                          `json:"shareLocation" bson:"shareLocation"`
+	LocationDate   int64                         `json:"locationDate" bson:"locationDate"`
 	Phone          string                        `json:"phone" bson:"phone"`
 	Lang           string                        `json:"lang" bson:"lang"`
 	CompanyName    string                        `json:"companyName" bson:"companyName"`
@@ -48,4 +51,17 @@ type UserProfile struct {
 	LinkedInId     string                        `json:"linkedInId" bson:"linkedInId"`
 	AccessUserList []string                      `json:"accessUserList" bson:"accessUserList"`
 	Permission     constants.UserPermissionConst `json:"permission" bson:"permission"`
+	// FieldVisibility maps email, phone, birthday and address to who can see them
+	FieldVisibility map[string]constants.UserPermissionConst `json:"fieldVisibility,omitempty" bson:"fieldVisibility,omitempty"`
+	// Verification is set only by admins
+	Verification *Verification `json:"verification,omitempty" bson:"verification,omitempty"`
+	// SocialNameChangedDate is the last time the user renamed the social name
+	SocialNameChangedDate int64     `json:"socialNameChangedDate,omitempty" bson:"socialNameChangedDate,omitempty"`
+	InvitedBy             uuid.UUID `json:"invitedBy" bson:"invitedBy"`
+	// CustomFields keeps the values of admin-defined profile fields by field name
+	CustomFields map[string]interface{} `json:"customFields,omitempty" bson:"customFields,omitempty"`
+	// SearchTokens are the folded words of full name and social name, indexed for prefix search
+	SearchTokens []string `json:"-" bson:"searchTokens,omitempty"`
+	// SearchKeys are the one-typo variants of the search token prefixes, indexed for search with typos
+	SearchKeys []string `json:"-" bson:"searchKeys,omitempty"`
 }
@@ -0,0 +1,14 @@
+package dto
+
+import (
+	uuid "github.com/gofrs/uuid"
+)
+
+// UserRestriction is a block or mute of target user by owner user
+type UserRestriction struct {
+	ObjectId     uuid.UUID `json:"objectId" bson:"objectId"`
+	OwnerUserId  uuid.UUID `json:"ownerUserId" bson:"ownerUserId"`
+	TargetUserId uuid.UUID `json:"targetUserId" bson:"targetUserId"`
+	Type         string    `json:"type" bson:"type"`
+	CreatedDate  int64     `json:"created_date" bson:"created_date"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+import (
+	uuid "github.com/gofrs/uuid"
+)
+
+// Verification is the verified badge an admin granted to the user
+type Verification struct {
+	Type      string    `json:"type" bson:"type"`
+	GrantedBy uuid.UUID `json:"grantedBy" bson:"grantedBy"`
+	GrantedAt int64     `json:"grantedAt" bson:"grantedAt"`
+}
+
+// VerificationRequest is a request of the user to get verified, reviewed by admins
+type VerificationRequest struct {
+	ObjectId     uuid.UUID `json:"objectId" bson:"objectId"`
+	UserId       uuid.UUID `json:"userId" bson:"userId"`
+	FullName     string    `json:"fullName" bson:"fullName"`
+	SocialName   string    `json:"socialName" bson:"socialName"`
+	Type         string    `json:"type" bson:"type"`
+	Reason       string    `json:"reason" bson:"reason"`
+	Status       string    `json:"status" bson:"status"`
+	ReviewedBy   uuid.UUID `json:"reviewedBy" bson:"reviewedBy"`
+	ReviewNote   string    `json:"reviewNote" bson:"reviewNote"`
+	ReviewedDate int64     `json:"reviewedDate" bson:"reviewedDate"`
+	CreatedDate  int64     `json:"created_date" bson:"created_date"`
+}
@@ -3,6 +3,7 @@ package function
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/gofiber/adaptor/v2"
 	"github.com/gofiber/fiber/v2"
@@ -13,7 +14,9 @@ import (
 	"github.com/red-gold/telar-core/config"
 	"github.com/red-gold/telar-core/pkg/log"
 	micros "github.com/red-gold/telar-web/micros"
+	profileConfig "github.com/red-gold/telar-web/micros/profile/config"
 	"github.com/red-gold/telar-web/micros/profile/database"
+	"github.com/red-gold/telar-web/micros/profile/handlers"
 	"github.com/red-gold/telar-web/micros/profile/router"
 )
 
@@ -23,6 +26,7 @@ var app *fiber.App
 func init() {
 
 	micros.InitConfig()
+	profileConfig.InitConfig()
 
 	// Initialize app
 
@@ -40,6 +44,43 @@ func init() {
 		AllowHeaders:     "Origin, Content-Type, Accept, Access-Control-Allow-Headers, X-Requested-With, X-HTTP-Method-Override, access-control-allow-origin, access-control-allow-headers",
 	}))
 	router.SetupRoutes(app)
+	startUserFollowBackfill()
+	startCounterReconcileSchedule()
+}
+
+// startUserFollowBackfill copy follow relations of the circles micro to user follows when the function starts
+func startUserFollowBackfill() {
+	go func() {
+		if database.Db == nil {
+			if err := database.Connect(context.Background()); err != nil {
+				log.Error("[startUserFollowBackfill] Connect database %s", err.Error())
+				return
+			}
+		}
+		handlers.RunUserFollowBackfill()
+	}()
+}
+
+// startCounterReconcileSchedule reconcile profile counters on an interval while the function is running
+func startCounterReconcileSchedule() {
+	interval := profileConfig.ProfileConfig.CounterReconcileInterval
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			if database.Db == nil {
+				if err := database.Connect(context.Background()); err != nil {
+					log.Error("[startCounterReconcileSchedule] Connect database %s", err.Error())
+					continue
+				}
+			}
+			handlers.RunScheduledCounterReconciliation()
+		}
+	}()
 }
 
 // Handler function
@@ -92,8 +92,13 @@ func getUserInfoReq(c *fiber.Ctx) *UserInfoInReq {
 
 // Dispatch action
 func dispatchAction(action Action, userInfoInReq *UserInfoInReq) {
+	dispatchActionToRoom(action, userInfoInReq.UserId, userInfoInReq)
+}
+
+// dispatchActionToRoom send action to the room of another user on behalf of the user in request
+func dispatchActionToRoom(action Action, roomId uuid.UUID, userInfoInReq *UserInfoInReq) {
 
-	actionURL := fmt.Sprintf("/actions/dispatch/%s", userInfoInReq.UserId.String())
+	actionURL := fmt.Sprintf("/actions/dispatch/%s", roomId.String())
 
 	actionBytes, marshalErr := json.Marshal(action)
 	if marshalErr != nil {
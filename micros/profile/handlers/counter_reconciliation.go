@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/pkg/parser"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+	"github.com/red-gold/telar-web/micros/profile/database"
+	"github.com/red-gold/telar-web/micros/profile/dto"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+	service "github.com/red-gold/telar-web/micros/profile/services"
+)
+
+const (
+	reconcileBatchSize int64 = 500
+	// maxReportedDiscrepancies keeps the reconciliation report under document size limit
+	maxReportedDiscrepancies = 1000
+	// A running reconciliation older than six hours is taken as stopped and does not block a new one
+	reconcileStaleAfter int64 = 6 * 60 * 60 * 1000
+)
+
+// ReconcileCountersHandle godoc
+// @Summary Reconcile profile counters
+// @Description Recompute follow, follower, post, vote and share counters of all profiles from their source collections.
+// @Description The reconciliation runs in background. Discrepancies are saved only when fix is true. Called by admin micro.
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param body body models.CounterReconcileModel true "Counter reconcile model"
+// @Success 200 {object} dto.CounterReconciliation
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 409 {object} utils.TelarError "Another reconciliation is running"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /dto/counters/reconcile [post]
+func ReconcileCountersHandle(c *fiber.Ctx) error {
+
+	model := new(models.CounterReconcileModel)
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(model); err != nil {
+			log.Error("[ReconcileCountersHandle] parse CounterReconcileModel %s", err.Error())
+			return c.Status(http.StatusBadRequest).JSON(utils.Error("parseCounterReconcileModel", "Error happened while parsing model!"))
+		}
+	}
+
+	currentUser, _ := c.Locals("user").(types.UserContext)
+	counterReconciliation, err := startCounterReconciliation(models.ReconcileTriggerAdmin, currentUser.UserID, model.Fix)
+	if err != nil {
+		log.Error("[ReconcileCountersHandle] startCounterReconciliation %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/startCounterReconciliation", "Error happened while starting counter reconciliation!"))
+	}
+	if counterReconciliation == nil {
+		return c.Status(http.StatusConflict).JSON(utils.Error("reconciliationRunning", "Another counter reconciliation is running!"))
+	}
+
+	go runCounterReconciliation(counterReconciliation)
+
+	return c.JSON(counterReconciliation)
+}
+
+// QueryCounterReconciliationsHandle godoc
+// @Summary Get counter reconciliations
+// @Description Get counter reconciliation reports by page from newest to oldest. Called by admin micro.
+// @Tags profile
+// @Produce json
+// @Param page query int false "Page number"
+// @Success 200 {array} dto.CounterReconciliation
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /dto/counters/reconciliations [get]
+func QueryCounterReconciliationsHandle(c *fiber.Ctx) error {
+
+	query := new(models.CounterReconciliationQueryModel)
+	if err := parser.QueryParser(c, query); err != nil {
+		log.Error("[QueryCounterReconciliationsHandle] QueryParser %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("queryParser", "Error happened while parsing query!"))
+	}
+
+	// Create service
+	counterReconciliationService, serviceErr := service.NewCounterReconciliationService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewCounterReconciliationService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/counterReconciliationService", "Error happened while creating counterReconciliationService!"))
+	}
+
+	counterReconciliationList, err := counterReconciliationService.QueryCounterReconciliation(query.Page)
+	if err != nil {
+		log.Error("[QueryCounterReconciliationsHandle] QueryCounterReconciliation %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/queryCounterReconciliation", "Error happened while querying counter reconciliations!"))
+	}
+
+	return c.JSON(counterReconciliationList)
+}
+
+// ReadCounterReconciliationHandle godoc
+// @Summary Get counter reconciliation
+// @Description Get counter reconciliation report with its discrepancies. Called by admin micro.
+// @Tags profile
+// @Produce json
+// @Param reconciliationId path string true "Counter reconciliation ID"
+// @Success 200 {object} dto.CounterReconciliation
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 404 {object} utils.TelarError "Counter reconciliation not found"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /dto/counters/reconciliations/{reconciliationId} [get]
+func ReadCounterReconciliationHandle(c *fiber.Ctx) error {
+
+	reconciliationId, uuidErr := uuid.FromString(c.Params("reconciliationId"))
+	if uuidErr != nil {
+		log.Error("[ReadCounterReconciliationHandle] Parse UUID %s ", uuidErr.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseUUIDError", "Can not parse reconciliation id!"))
+	}
+
+	// Create service
+	counterReconciliationService, serviceErr := service.NewCounterReconciliationService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewCounterReconciliationService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/counterReconciliationService", "Error happened while creating counterReconciliationService!"))
+	}
+
+	counterReconciliation, err := counterReconciliationService.FindById(reconciliationId)
+	if err != nil {
+		log.Error("[ReadCounterReconciliationHandle] FindById %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findCounterReconciliation", "Error happened while finding counter reconciliation!"))
+	}
+	if counterReconciliation == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundCounterReconciliation", "Counter reconciliation not found!"))
+	}
+
+	return c.JSON(counterReconciliation)
+}
+
+// RunScheduledCounterReconciliation reconcile and fix profile counters unless another reconciliation is running
+func RunScheduledCounterReconciliation() {
+	counterReconciliation, err := startCounterReconciliation(models.ReconcileTriggerSchedule, uuid.Nil, true)
+	if err != nil {
+		log.Error("[RunScheduledCounterReconciliation] startCounterReconciliation %s", err.Error())
+		return
+	}
+	if counterReconciliation == nil {
+		log.Info("[RunScheduledCounterReconciliation] Another counter reconciliation is running")
+		return
+	}
+	runCounterReconciliation(counterReconciliation)
+}
+
+// startCounterReconciliation save a running reconciliation report. It returns nil when another reconciliation is running.
+func startCounterReconciliation(trigger string, triggeredBy uuid.UUID, fix bool) (*dto.CounterReconciliation, error) {
+	counterReconciliationService, serviceErr := service.NewCounterReconciliationService(database.Db)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	runningReconciliation, err := counterReconciliationService.FindRunningSince(utils.UTCNowUnix() - reconcileStaleAfter)
+	if err != nil {
+		return nil, err
+	}
+	if runningReconciliation != nil {
+		return nil, nil
+	}
+
+	counterReconciliation := &dto.CounterReconciliation{
+		Trigger:         trigger,
+		TriggeredBy:     triggeredBy,
+		Fix:             fix,
+		Status:          models.ReconcileStatusRunning,
+		Discrepancies:   []dto.CounterDiscrepancy{},
+		SkippedCounters: []string{},
+	}
+	if err := counterReconciliationService.SaveCounterReconciliation(counterReconciliation); err != nil {
+		return nil, err
+	}
+	return counterReconciliation, nil
+}
+
+// runCounterReconciliation compare counters of all profiles with their source counts and save the report
+func runCounterReconciliation(counterReconciliation *dto.CounterReconciliation) {
+	counterReconciliationService, serviceErr := service.NewCounterReconciliationService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewCounterReconciliationService %s", serviceErr.Error())
+		return
+	}
+
+	if err := reconcileCounters(counterReconciliation); err != nil {
+		log.Error("[runCounterReconciliation] reconcileCounters %s", err.Error())
+		counterReconciliation.Status = models.ReconcileStatusFailed
+		counterReconciliation.Error = err.Error()
+	} else {
+		counterReconciliation.Status = models.ReconcileStatusCompleted
+	}
+	counterReconciliation.FinishedDate = utils.UTCNowUnix()
+
+	if err := counterReconciliationService.UpdateCounterReconciliation(counterReconciliation); err != nil {
+		log.Error("[runCounterReconciliation] UpdateCounterReconciliation %s", err.Error())
+	}
+}
+
+// reconcileCounters fill the report with discrepancies and fix them when asked.
+// Counters changed while the job runs may be reported and fixed again by the next run.
+func reconcileCounters(counterReconciliation *dto.CounterReconciliation) error {
+	counterReconciliationService, serviceErr := service.NewCounterReconciliationService(database.Db)
+	if serviceErr != nil {
+		return serviceErr
+	}
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	counts, skippedCounters, err := counterReconciliationService.CountCounterSources()
+	if err != nil {
+		return err
+	}
+	counterReconciliation.SkippedCounters = skippedCounters
+
+	sortMap := map[string]int{"objectId": 1}
+	for skip := int64(0); ; skip += reconcileBatchSize {
+		userProfileList, err := userProfileService.FindUserProfileList(struct{}{}, reconcileBatchSize, skip, sortMap)
+		if err != nil {
+			return err
+		}
+
+		for _, userProfile := range userProfileList {
+			counterReconciliation.CheckedProfiles++
+			fixedCounters := make(map[string]interface{})
+			for field, userCounts := range counts {
+				stored := profileCounter(&userProfile, field)
+				actual := userCounts[userProfile.ObjectId]
+				if stored == actual {
+					continue
+				}
+				counterReconciliation.DiscrepancyCount++
+				if len(counterReconciliation.Discrepancies) < maxReportedDiscrepancies {
+					counterReconciliation.Discrepancies = append(counterReconciliation.Discrepancies, dto.CounterDiscrepancy{
+						UserId: userProfile.ObjectId,
+						Field:  field,
+						Stored: stored,
+						Actual: actual,
+					})
+				}
+				fixedCounters[field] = actual
+			}
+
+			if !counterReconciliation.Fix || len(fixedCounters) == 0 {
+				continue
+			}
+			if err := userProfileService.UpdateUserProfileById(userProfile.ObjectId, fixedCounters); err != nil {
+				log.Error("[reconcileCounters] UpdateUserProfileById %s", err.Error())
+				continue
+			}
+			counterReconciliation.FixedCount += int64(len(fixedCounters))
+		}
+
+		if int64(len(userProfileList)) < reconcileBatchSize {
+			return nil
+		}
+	}
+}
+
+// profileCounter get the stored value of a profile counter
+func profileCounter(userProfile *dto.UserProfile, field string) int64 {
+	switch field {
+	case "followCount":
+		return userProfile.FollowCount
+	case "followerCount":
+		return userProfile.FollowerCount
+	case "postCount":
+		return userProfile.PostCount
+	case "voteCount":
+		return userProfile.VoteCount
+	case "shareCount":
+		return userProfile.ShareCount
+	}
+	return 0
+}
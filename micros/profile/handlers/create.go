@@ -9,10 +9,11 @@ import (
 	utils "github.com/red-gold/telar-core/utils"
 	"github.com/red-gold/telar-web/micros/profile/database"
 	"github.com/red-gold/telar-web/micros/profile/dto"
+	models "github.com/red-gold/telar-web/micros/profile/models"
 	service "github.com/red-gold/telar-web/micros/profile/services"
 )
 
-// @Summary Create a new index
+// @Description Create indexes for user profiles and set search fields of profiles saved before search fields were added
 // @Description Create a new index for user profiles
 // @Tags profiles
 // @Accept  json
@@ -33,15 +34,57 @@ func InitProfileIndexHandle(c *fiber.Ctx) error {
 		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userProfileService", "Error happened while creating userProfileService!"))
 	}
 
+	if err := profileService.RemoveInvalidLiveLocation(); err != nil {
+		log.Error("Remove invalid live location Error %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("removeInvalidLiveLocation", "Error happened while creating post index!"))
+	}
+
 	postIndexMap := make(map[string]interface{})
 	postIndexMap["fullName"] = "text"
 	postIndexMap["objectId"] = 1
+	postIndexMap["liveLocation"] = "2dsphere"
 	if err := profileService.CreateUserProfileIndex(postIndexMap); err != nil {
 		errorMessage := fmt.Sprintf("Create post index Error %s", err.Error())
 		log.Error(errorMessage)
 		return c.Status(http.StatusInternalServerError).JSON(utils.Error("createPostIndex", "Error happened while creating post index!"))
 	}
 
+	if err := profileService.CreateUniqueIndex("socialName"); err != nil {
+		log.Error("Create social name index Error %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("createSocialNameIndex", "Error happened while creating social name index!"))
+	}
+
+	if err := profileService.CreateSearchIndex(); err != nil {
+		log.Error("Create search index Error %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("createSearchIndex", "Error happened while creating search index!"))
+	}
+	updated, err := profileService.BackfillSearchFields()
+	if err != nil {
+		log.Error("Backfill search fields Error %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("backfillSearchFields", "Error happened while setting search fields!"))
+	}
+	log.Info("Search fields set for %d profiles", updated)
+
+	userFollowService, serviceErr := service.NewUserFollowService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserFollowService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userFollowService", "Error happened while creating userFollowService!"))
+	}
+	if err := userFollowService.CreateUserFollowIndex(); err != nil {
+		log.Error("Create user follow index Error %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("createUserFollowIndex", "Error happened while creating user follow index!"))
+	}
+
+	userRestrictionService, serviceErr := service.NewUserRestrictionService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserRestrictionService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userRestrictionService", "Error happened while creating userRestrictionService!"))
+	}
+	if err := userRestrictionService.CreateUserRestrictionIndex(); err != nil {
+		log.Error("Create user restriction index Error %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("createUserRestrictionIndex", "Error happened while creating user restriction index!"))
+	}
+
 	return c.SendStatus(http.StatusOK)
 
 }
@@ -56,6 +99,7 @@ func InitProfileIndexHandle(c *fiber.Ctx) error {
 // @Param   body  body     dto.UserProfile  true "User profile model"
 // @Success 200
 // @Failure 400 {object} utils.TelarError
+// @Failure 409 {object} utils.TelarError "Social name is taken"
 // @Failure 500 {object} utils.TelarError
 // @Router /dto [post]
 func CreateDtoProfileHandle(c *fiber.Ctx) error {
@@ -76,7 +120,19 @@ func CreateDtoProfileHandle(c *fiber.Ctx) error {
 		return c.Status(http.StatusInternalServerError).JSON(utils.Error("parseUserProfileModel", "Error happened while parsing model!"))
 
 	}
+	// Verified badge is granted only by admins
+	model.Verification = nil
+	// Required fields are asked at signup by auth micro, other sign in methods create the profile without them
+	if len(model.CustomFields) > 0 {
+		if model.CustomFields, err = checkCustomFields(nil, model.CustomFields, false); err != nil {
+			return profileFieldErrorResponse(c, err)
+		}
+	}
 	if err = profileService.SaveUserProfile(model); err != nil {
+		if socialNameErr, ok := err.(models.SocialNameError); ok {
+			log.Error("Create profile error %s", socialNameErr.Error())
+			return c.Status(http.StatusConflict).JSON(utils.Error(socialNameErr.Code, socialNameErr.Error()))
+		}
 		errorMessage := fmt.Sprintf("Create profile error %s", err.Error())
 		log.Error(errorMessage)
 		return c.Status(http.StatusInternalServerError).JSON(utils.Error("createProfileError", "Error happened while saving user profile!"))
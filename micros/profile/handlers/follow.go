@@ -0,0 +1,479 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/pkg/parser"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+	"github.com/red-gold/telar-web/constants"
+	"github.com/red-gold/telar-web/micros/profile/database"
+	"github.com/red-gold/telar-web/micros/profile/dto"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+	service "github.com/red-gold/telar-web/micros/profile/services"
+)
+
+// FollowUserHandle godoc
+// @Summary Follow user
+// @Description Follow user. Following a private profile sends a follow request which the user should accept.
+// @Tags follow
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param userId path string true "User ID"
+// @Success 200 {object} dto.UserFollow
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 404 {object} utils.TelarError "User not found"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /follow/{userId} [post]
+func FollowUserHandle(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok || currentUser.UserID == uuid.Nil {
+		log.Error("[FollowUserHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+
+	followingId, uuidErr := uuid.FromString(c.Params("userId"))
+	if uuidErr != nil {
+		log.Error("[FollowUserHandle] Parse UUID %s ", uuidErr.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseUUIDError", "Can not parse user id!"))
+	}
+	if followingId == currentUser.UserID {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("followSelf", "Can not follow yourself!"))
+	}
+
+	// Create service
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserProfileService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userProfileService", "Error happened while creating userProfileService!"))
+	}
+	userFollowService, serviceErr := service.NewUserFollowService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserFollowService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userFollowService", "Error happened while creating userFollowService!"))
+	}
+
+	foundUserChan, errChan := userProfileService.FindByUserId(followingId)
+	foundUser, err := <-foundUserChan, <-errChan
+	if err != nil {
+		log.Error("[FollowUserHandle] FindByUserId %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findByUserId", "Error happened while finding user profile!"))
+	}
+	if foundUser == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUser", "Error happened while finding user profile!"))
+	}
+
+	// Users who blocked each other can not follow each other
+	hidden, err := isHiddenFromViewer(foundUser, profileViewer{userId: currentUser.UserID})
+	if err != nil {
+		log.Error("[FollowUserHandle] isHiddenFromViewer %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/isHiddenFromViewer", "Error happened while finding user restriction!"))
+	}
+	if hidden {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUser", "Error happened while finding user profile!"))
+	}
+
+	userFollow := &dto.UserFollow{
+		FollowerId:  currentUser.UserID,
+		FollowingId: followingId,
+		Status:      models.FollowStatusAccepted,
+	}
+	if isPrivateProfile(foundUser) {
+		userFollow.Status = models.FollowStatusPending
+	} else {
+		userFollow.AcceptedDate = utils.UTCNowUnix()
+	}
+
+	created, err := userFollowService.SaveUserFollow(userFollow)
+	if err != nil {
+		log.Error("[FollowUserHandle] SaveUserFollow %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/saveUserFollow", "Error happened while saving user follow!"))
+	}
+	if !created {
+		userFollow, err = userFollowService.FindUserFollow(currentUser.UserID, followingId)
+		if err != nil {
+			log.Error("[FollowUserHandle] FindUserFollow %s", err.Error())
+			return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findUserFollow", "Error happened while finding user follow!"))
+		}
+		return c.JSON(userFollow)
+	}
+
+	if userFollow.Status == models.FollowStatusAccepted {
+		syncFollowCounts(currentUser.UserID, followingId)
+	}
+
+	return c.JSON(userFollow)
+}
+
+// UnfollowUserHandle godoc
+// @Summary Unfollow user
+// @Description Unfollow user or cancel the follow request
+// @Tags follow
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param userId path string true "User ID"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /follow/{userId} [delete]
+func UnfollowUserHandle(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok || currentUser.UserID == uuid.Nil {
+		log.Error("[UnfollowUserHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+
+	followingId, uuidErr := uuid.FromString(c.Params("userId"))
+	if uuidErr != nil {
+		log.Error("[UnfollowUserHandle] Parse UUID %s ", uuidErr.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseUUIDError", "Can not parse user id!"))
+	}
+
+	if err := deleteUserFollow(currentUser.UserID, followingId); err != nil {
+		log.Error("[UnfollowUserHandle] deleteUserFollow %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/deleteUserFollow", "Error happened while deleting user follow!"))
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
+
+// GetFollowersHandle godoc
+// @Summary Get followers
+// @Description Get followers of the user by page from newest to oldest.
+// @Description Followers of a private profile are shown to its owner and the users it allows.
+// @Tags follow
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param userId path string true "User ID"
+// @Param page query int false "Page number"
+// @Success 200 {array} dto.UserFollow
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 404 {object} utils.TelarError "User not found"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /followers/{userId} [get]
+func GetFollowersHandle(c *fiber.Ctx) error {
+	return queryFollowList(c, true)
+}
+
+// GetFollowingHandle godoc
+// @Summary Get following
+// @Description Get users the user follows by page from newest to oldest.
+// @Description Following of a private profile is shown to its owner and the users it allows.
+// @Tags follow
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param userId path string true "User ID"
+// @Param page query int false "Page number"
+// @Success 200 {array} dto.UserFollow
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 404 {object} utils.TelarError "User not found"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /following/{userId} [get]
+func GetFollowingHandle(c *fiber.Ctx) error {
+	return queryFollowList(c, false)
+}
+
+// GetFollowRequestsHandle godoc
+// @Summary Get follow requests
+// @Description Get pending follow requests of the current user by page from newest to oldest
+// @Tags follow
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param page query int false "Page number"
+// @Success 200 {array} dto.UserFollow
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /follow-requests [get]
+func GetFollowRequestsHandle(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok || currentUser.UserID == uuid.Nil {
+		log.Error("[GetFollowRequestsHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+
+	query := new(models.UserFollowQueryModel)
+	if err := parser.QueryParser(c, query); err != nil {
+		log.Error("[GetFollowRequestsHandle] QueryParser %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("queryParser", "Error happened while parsing query!"))
+	}
+
+	// Create service
+	userFollowService, serviceErr := service.NewUserFollowService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserFollowService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userFollowService", "Error happened while creating userFollowService!"))
+	}
+
+	followRequestList, err := userFollowService.QueryFollowers(currentUser.UserID, models.FollowStatusPending, query.Page)
+	if err != nil {
+		log.Error("[GetFollowRequestsHandle] QueryFollowers %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/queryFollowers", "Error happened while querying follow requests!"))
+	}
+
+	return c.JSON(followRequestList)
+}
+
+// AcceptFollowRequestHandle godoc
+// @Summary Accept follow request
+// @Description Accept the follow request of the user
+// @Tags follow
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param userId path string true "ID of the user who requested to follow"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 404 {object} utils.TelarError "Follow request not found"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /follow-requests/{userId} [put]
+func AcceptFollowRequestHandle(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok || currentUser.UserID == uuid.Nil {
+		log.Error("[AcceptFollowRequestHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+
+	followerId, uuidErr := uuid.FromString(c.Params("userId"))
+	if uuidErr != nil {
+		log.Error("[AcceptFollowRequestHandle] Parse UUID %s ", uuidErr.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseUUIDError", "Can not parse user id!"))
+	}
+
+	// Create service
+	userFollowService, serviceErr := service.NewUserFollowService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserFollowService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userFollowService", "Error happened while creating userFollowService!"))
+	}
+
+	accepted, err := userFollowService.AcceptUserFollow(followerId, currentUser.UserID)
+	if err != nil {
+		log.Error("[AcceptFollowRequestHandle] AcceptUserFollow %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/acceptUserFollow", "Error happened while accepting follow request!"))
+	}
+	if !accepted {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundFollowRequest", "Follow request not found!"))
+	}
+	syncFollowCounts(followerId, currentUser.UserID)
+
+	return c.SendStatus(http.StatusOK)
+}
+
+// RejectFollowRequestHandle godoc
+// @Summary Reject follow request
+// @Description Reject the follow request of the user
+// @Tags follow
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param userId path string true "ID of the user who requested to follow"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 404 {object} utils.TelarError "Follow request not found"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /follow-requests/{userId} [delete]
+func RejectFollowRequestHandle(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok || currentUser.UserID == uuid.Nil {
+		log.Error("[RejectFollowRequestHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+
+	followerId, uuidErr := uuid.FromString(c.Params("userId"))
+	if uuidErr != nil {
+		log.Error("[RejectFollowRequestHandle] Parse UUID %s ", uuidErr.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseUUIDError", "Can not parse user id!"))
+	}
+
+	// Create service
+	userFollowService, serviceErr := service.NewUserFollowService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserFollowService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userFollowService", "Error happened while creating userFollowService!"))
+	}
+
+	userFollow, err := userFollowService.FindUserFollow(followerId, currentUser.UserID)
+	if err != nil {
+		log.Error("[RejectFollowRequestHandle] FindUserFollow %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findUserFollow", "Error happened while finding follow request!"))
+	}
+	if userFollow == nil || userFollow.Status != models.FollowStatusPending {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundFollowRequest", "Follow request not found!"))
+	}
+
+	if err := deleteUserFollow(followerId, currentUser.UserID); err != nil {
+		log.Error("[RejectFollowRequestHandle] deleteUserFollow %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/deleteUserFollow", "Error happened while rejecting follow request!"))
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
+
+// queryFollowList write followers or following of the user in path by page
+func queryFollowList(c *fiber.Ctx, followers bool) error {
+
+	userId, uuidErr := uuid.FromString(c.Params("userId"))
+	if uuidErr != nil {
+		log.Error("[queryFollowList] Parse UUID %s ", uuidErr.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseUUIDError", "Can not parse user id!"))
+	}
+
+	query := new(models.UserFollowQueryModel)
+	if err := parser.QueryParser(c, query); err != nil {
+		log.Error("[queryFollowList] QueryParser %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("queryParser", "Error happened while parsing query!"))
+	}
+
+	// Create service
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserProfileService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userProfileService", "Error happened while creating userProfileService!"))
+	}
+	userFollowService, serviceErr := service.NewUserFollowService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserFollowService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userFollowService", "Error happened while creating userFollowService!"))
+	}
+
+	foundUserChan, errChan := userProfileService.FindByUserId(userId)
+	foundUser, err := <-foundUserChan, <-errChan
+	if err != nil {
+		log.Error("[queryFollowList] FindByUserId %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findByUserId", "Error happened while finding user profile!"))
+	}
+	if foundUser == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUser", "Error happened while finding user profile!"))
+	}
+
+	viewer := getProfileViewer(c)
+	hidden, err := isHiddenFromViewer(foundUser, viewer)
+	if err != nil {
+		log.Error("[queryFollowList] isHiddenFromViewer %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/isHiddenFromViewer", "Error happened while finding user restriction!"))
+	}
+	if hidden {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUser", "Error happened while finding user profile!"))
+	}
+	if !viewer.canView(foundUser, foundUser.Permission) {
+		return c.JSON([]dto.UserFollow{})
+	}
+
+	var userFollowList []dto.UserFollow
+	if followers {
+		userFollowList, err = userFollowService.QueryFollowers(userId, models.FollowStatusAccepted, query.Page)
+	} else {
+		userFollowList, err = userFollowService.QueryFollowing(userId, query.Page)
+	}
+	if err != nil {
+		log.Error("[queryFollowList] Query follow list %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/queryUserFollow", "Error happened while querying follow list!"))
+	}
+
+	return c.JSON(userFollowList)
+}
+
+// isPrivateProfile check following the profile needs the owner to accept
+func isPrivateProfile(userProfile *dto.UserProfile) bool {
+	return userProfile.Permission != "" && userProfile.Permission != constants.Public
+}
+
+// deleteUserFollow remove the follow or follow request and update counters when an accepted follow is removed
+func deleteUserFollow(followerId uuid.UUID, followingId uuid.UUID) error {
+	userFollowService, serviceErr := service.NewUserFollowService(database.Db)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	deletedFollow, err := userFollowService.DeleteUserFollow(followerId, followingId)
+	if err != nil {
+		return err
+	}
+	if deletedFollow != nil && deletedFollow.Status == models.FollowStatusAccepted {
+		syncFollowCounts(followerId, followingId)
+	}
+	return nil
+}
+
+// syncFollowCounts set follow count of follower and follower count of following user counted from user follows.
+// Counters are derived from the relationships, so a failed update is corrected by the next change or reconciliation.
+func syncFollowCounts(followerId uuid.UUID, followingId uuid.UUID) {
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserProfileService %s", serviceErr.Error())
+		return
+	}
+	userFollowService, serviceErr := service.NewUserFollowService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserFollowService %s", serviceErr.Error())
+		return
+	}
+
+	followCount, err := userFollowService.CountFollowing(followerId)
+	if err != nil {
+		log.Error("[syncFollowCounts] CountFollowing %s", err.Error())
+	} else if err := userProfileService.SetCounter(followerId, "followCount", followCount); err != nil {
+		log.Error("[syncFollowCounts] SetCounter followCount %s", err.Error())
+	}
+
+	followerCount, err := userFollowService.CountFollowers(followingId)
+	if err != nil {
+		log.Error("[syncFollowCounts] CountFollowers %s", err.Error())
+	} else if err := userProfileService.SetCounter(followingId, "followerCount", followerCount); err != nil {
+		log.Error("[syncFollowCounts] SetCounter followerCount %s", err.Error())
+	}
+}
+
+// RunUserFollowBackfill copy follow relations of the circles micro to user follows once
+func RunUserFollowBackfill() {
+	userFollowService, serviceErr := service.NewUserFollowService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserFollowService %s", serviceErr.Error())
+		return
+	}
+
+	backfilled, err := userFollowService.IsUserFollowBackfilled()
+	if err != nil {
+		log.Error("[RunUserFollowBackfill] IsUserFollowBackfilled %s", err.Error())
+		return
+	}
+	if backfilled {
+		return
+	}
+
+	copied, err := userFollowService.BackfillUserFollows()
+	if err != nil {
+		log.Error("[RunUserFollowBackfill] BackfillUserFollows %s", err.Error())
+		return
+	}
+	log.Info("User follows backfilled from circles relations: %d", copied)
+}
+
+// RemovedFollowCountHandle godoc
+// @Summary Removed follow counter update
+// @Description Follow and follower counters are derived from user follows and can not be changed by callers.
+// @Description The route is kept for one release so old callers get a clear error.
+// @Tags profile
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param inc path int true "Increment value"
+// @Failure 410 {object} utils.TelarError "Counter update is removed"
+// @Router /follow/inc/{inc}/{userId} [put]
+// @Router /follower/inc/{inc}/{userId} [put]
+func RemovedFollowCountHandle(c *fiber.Ctx) error {
+	log.Warn("[RemovedFollowCountHandle] Removed counter update is called %s", c.Path())
+	return c.Status(http.StatusGone).JSON(utils.Error("followCountRemoved", "Follow counters are derived from follows, use the follow API instead!"))
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/pkg/parser"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+	"github.com/red-gold/telar-web/micros/profile/config"
+	"github.com/red-gold/telar-web/micros/profile/database"
+	"github.com/red-gold/telar-web/micros/profile/dto"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+	service "github.com/red-gold/telar-web/micros/profile/services"
+)
+
+const (
+	defaultNearbyRadius = 5000
+	earthRadiusKm       = 6371
+)
+
+// UpdateLocationSharingHandle opts the current user in or out of nearby people
+// @Summary Update location sharing
+// @Description Opt in or out of nearby people. Opting out removes the stored live location.
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param body body models.LocationSharingModel true "Location sharing model"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /location/sharing [put]
+func UpdateLocationSharingHandle(c *fiber.Ctx) error {
+
+	model := new(models.LocationSharingModel)
+	if err := c.BodyParser(model); err != nil {
+		log.Error("[UpdateLocationSharingHandle] parse LocationSharingModel %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseLocationSharingModel", "Error happened while parsing model!"))
+	}
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok || currentUser.UserID == uuid.Nil {
+		log.Error("[UpdateLocationSharingHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+
+	// Create service
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserProfileService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userProfileService", "Error happened while creating userProfileService!"))
+	}
+
+	if err := userProfileService.SetLocationSharing(currentUser.UserID, model.Enabled); err != nil {
+		log.Error("[UpdateLocationSharingHandle] SetLocationSharing %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/updateLocationSharing", "Error happened while updating location sharing!"))
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
+
+// UpdateLocationHandle updates live location of the current user
+// @Summary Update live location
+// @Description Update live location of the current user. The location is rounded to a grid so the exact position is never stored.
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param body body models.UpdateLocationModel true "Location model"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 403 {object} utils.TelarError "Location sharing is disabled"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /location [put]
+func UpdateLocationHandle(c *fiber.Ctx) error {
+
+	model := new(models.UpdateLocationModel)
+	if err := c.BodyParser(model); err != nil {
+		log.Error("[UpdateLocationHandle] parse UpdateLocationModel %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseUpdateLocationModel", "Error happened while parsing model!"))
+	}
+	if model.Latitude < -90 || model.Latitude > 90 || model.Longitude < -180 || model.Longitude > 180 {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidLocation", "Location is not valid!"))
+	}
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok || currentUser.UserID == uuid.Nil {
+		log.Error("[UpdateLocationHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+
+	// Create service
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserProfileService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userProfileService", "Error happened while creating userProfileService!"))
+	}
+
+	foundUserChan, errChan := userProfileService.FindByUserId(currentUser.UserID)
+	foundUser, err := <-foundUserChan, <-errChan
+	if err != nil {
+		log.Error("[UpdateLocationHandle] FindByUserId %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findByUserId", "Error happened while finding user profile!"))
+	}
+	if foundUser == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUser", "Error happened while finding user profile!"))
+	}
+	if !foundUser.ShareLocation {
+		return c.Status(http.StatusForbidden).JSON(utils.Error("locationSharingDisabled", "Location sharing is disabled!"))
+	}
+
+	precision := config.ProfileConfig.LocationPrecision
+	location := dto.NewLocation(roundCoordinate(model.Latitude, precision), roundCoordinate(model.Longitude, precision))
+	if err := userProfileService.UpdateLiveLocation(currentUser.UserID, location); err != nil {
+		log.Error("[UpdateLocationHandle] UpdateLiveLocation %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/updateLiveLocation", "Error happened while updating location!"))
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
+
+// NearbyProfilesHandle gets people near the current user
+// @Summary People near me
+// @Description Get people who share their location near the current user, nearest first. The current user must share location too.
+// @Tags profile
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param radius query number false "Radius in meters, default 5000"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of profiles in page, default 10 and up to 50"
+// @Success 200 {array} models.NearbyProfileModel
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 403 {object} utils.TelarError "Location sharing is disabled"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /nearby [get]
+func NearbyProfilesHandle(c *fiber.Ctx) error {
+
+	query := new(models.NearbyQueryModel)
+	if err := parser.QueryParser(c, query); err != nil {
+		log.Error("[NearbyProfilesHandle] QueryParser %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("queryParser", "Error happened while parsing query!"))
+	}
+	if query.Radius <= 0 {
+		query.Radius = defaultNearbyRadius
+	}
+	if query.Radius > config.ProfileConfig.NearbyMaxRadius {
+		query.Radius = config.ProfileConfig.NearbyMaxRadius
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok || currentUser.UserID == uuid.Nil {
+		log.Error("[NearbyProfilesHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+
+	// Create service
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserProfileService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userProfileService", "Error happened while creating userProfileService!"))
+	}
+
+	foundUserChan, errChan := userProfileService.FindByUserId(currentUser.UserID)
+	foundUser, err := <-foundUserChan, <-errChan
+	if err != nil {
+		log.Error("[NearbyProfilesHandle] FindByUserId %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findByUserId", "Error happened while finding user profile!"))
+	}
+	if foundUser == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUser", "Error happened while finding user profile!"))
+	}
+	if !foundUser.ShareLocation || foundUser.LiveLocation == nil {
+		return c.Status(http.StatusForbidden).JSON(utils.Error("locationSharingDisabled", "Location sharing is disabled!"))
+	}
+
+	hiddenUserIds, err := getHiddenUserIds(getProfileViewer(c))
+	if err != nil {
+		log.Error("[NearbyProfilesHandle] getHiddenUserIds %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findUserRestrictions", "Error happened while finding user restrictions!"))
+	}
+
+	userList, err := userProfileService.FindNearby(*foundUser.LiveLocation, query.Radius, query.Page, query.Limit, append(hiddenUserIds, currentUser.UserID))
+	if err != nil {
+		log.Error("[NearbyProfilesHandle] FindNearby %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findNearby", "Error happened while finding nearby people!"))
+	}
+
+	nearbyList := []models.NearbyProfileModel{}
+	for _, user := range userList {
+		nearbyList = append(nearbyList, models.NearbyProfileModel{
+			ObjectId:   user.ObjectId,
+			FullName:   user.FullName,
+			SocialName: user.SocialName,
+			Avatar:     user.Avatar,
+			TagLine:    user.TagLine,
+			Distance:   roundCoordinate(distanceKm(*foundUser.LiveLocation, user.LiveLocation), 1),
+		})
+	}
+
+	return c.JSON(nearbyList)
+}
+
+// roundCoordinate round the coordinate to the number of decimal places
+func roundCoordinate(value float64, precision int) float64 {
+	scale := math.Pow(10, float64(precision))
+	return math.Round(value*scale) / scale
+}
+
+// distanceKm great-circle distance between two GeoJSON points in kilometers
+func distanceKm(from dto.Location, to *dto.Location) float64 {
+	if to == nil || len(from.Coordinates) < 2 || len(to.Coordinates) < 2 {
+		return 0
+	}
+	fromLat, toLat := from.Coordinates[1]*math.Pi/180, to.Coordinates[1]*math.Pi/180
+	deltaLat := toLat - fromLat
+	deltaLong := (to.Coordinates[0] - from.Coordinates[0]) * math.Pi / 180
+
+	a := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) +
+		math.Cos(fromLat)*math.Cos(toLat)*math.Sin(deltaLong/2)*math.Sin(deltaLong/2)
+	return earthRadiusKm * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/pkg/log"
+	utils "github.com/red-gold/telar-core/utils"
+	profileConfig "github.com/red-gold/telar-web/micros/profile/config"
+	"github.com/red-gold/telar-web/micros/profile/database"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+	service "github.com/red-gold/telar-web/micros/profile/services"
+)
+
+const (
+	// setUserPresenceAction is dispatched to followers when presence of a user changes
+	setUserPresenceAction = "SET_USER_PRESENCE"
+	maxPresenceQueryItems = 100
+)
+
+// HeartbeatHandle godoc
+// @Summary Send presence heartbeat
+// @Description Keep the current user online or away until the presence TTL passes without another heartbeat.
+// @Description Followers get the new status when it changes.
+// @Tags presence
+// @Accept json
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param body body models.PresenceHeartbeatModel false "Status is online or away. Default is online"
+// @Success 200 {object} models.PresenceModel
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /presence [put]
+func HeartbeatHandle(c *fiber.Ctx) error {
+
+	model := new(models.PresenceHeartbeatModel)
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(model); err != nil {
+			log.Error("[HeartbeatHandle] parse PresenceHeartbeatModel %s", err.Error())
+			return c.Status(http.StatusBadRequest).JSON(utils.Error("parsePresenceHeartbeatModel", "Error happened while parsing model!"))
+		}
+	}
+	if model.Status == "" {
+		model.Status = models.PresenceStatusOnline
+	}
+	if model.Status != models.PresenceStatusOnline && model.Status != models.PresenceStatusAway {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidPresenceStatus", "Presence status should be online or away!"))
+	}
+
+	userInfoInReq := getUserInfoReq(c)
+	if userInfoInReq.UserId == uuid.Nil {
+		log.Error("[HeartbeatHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+
+	presence, err := setPresence(userInfoInReq, model.Status)
+	if err != nil {
+		log.Error("[HeartbeatHandle] setPresence %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/setPresence", "Error happened while setting presence!"))
+	}
+
+	return c.JSON(presence)
+}
+
+// GoOfflineHandle godoc
+// @Summary Go offline
+// @Description Set the current user offline without waiting for the presence TTL
+// @Tags presence
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /presence [delete]
+func GoOfflineHandle(c *fiber.Ctx) error {
+
+	userInfoInReq := getUserInfoReq(c)
+	if userInfoInReq.UserId == uuid.Nil {
+		log.Error("[GoOfflineHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+
+	presenceService, serviceErr := service.NewPresenceService()
+	if serviceErr != nil {
+		log.Error("NewPresenceService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/presenceService", "Error happened while creating presenceService!"))
+	}
+
+	previous, err := presenceService.DeletePresence(userInfoInReq.UserId)
+	if err != nil {
+		log.Error("[GoOfflineHandle] DeletePresence %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/deletePresence", "Error happened while removing presence!"))
+	}
+	if previous != nil {
+		setOffline(models.PresenceModel{UserId: userInfoInReq.UserId, LastSeen: utils.UTCNowUnix()}, userInfoInReq)
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
+
+// QueryPresenceHandle godoc
+// @Summary Get presence of users
+// @Description Get online, away or offline status of users with their last seen time.
+// @Description Users who hide their online status or are blocked either way are shown offline.
+// @Tags presence
+// @Accept json
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param body body models.PresenceQueryModel true "User ids"
+// @Success 200 {array} models.PresenceModel
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /presence/query [post]
+func QueryPresenceHandle(c *fiber.Ctx) error {
+
+	model := new(models.PresenceQueryModel)
+	if err := c.BodyParser(model); err != nil {
+		log.Error("[QueryPresenceHandle] parse PresenceQueryModel %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parsePresenceQueryModel", "Error happened while parsing model!"))
+	}
+	if len(model.UserIds) == 0 {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("userIdRequired", "User id is required!"))
+	}
+	if len(model.UserIds) > maxPresenceQueryItems {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("tooManyUserIds", fmt.Sprintf("Presence of at most %d users can be queried!", maxPresenceQueryItems)))
+	}
+
+	presenceService, serviceErr := service.NewPresenceService()
+	if serviceErr != nil {
+		log.Error("NewPresenceService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/presenceService", "Error happened while creating presenceService!"))
+	}
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserProfileService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userProfileService", "Error happened while creating userProfileService!"))
+	}
+
+	presenceList, err := presenceService.FindPresence(model.UserIds)
+	if err != nil {
+		log.Error("[QueryPresenceHandle] FindPresence %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findPresence", "Error happened while finding presence!"))
+	}
+
+	// Last seen of offline users is kept in their profile
+	foundUsers, err := userProfileService.FindProfileByUserIds(model.UserIds)
+	if err != nil {
+		log.Error("[QueryPresenceHandle] FindProfileByUserIds %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findProfileByUserIds", "Error happened while finding users profile!"))
+	}
+	lastSeenList := make(map[uuid.UUID]int64)
+	for _, foundUser := range foundUsers {
+		lastSeenList[foundUser.ObjectId] = foundUser.LastSeen
+	}
+
+	userInfoInReq := getUserInfoReq(c)
+	hiddenUsers := make(map[uuid.UUID]bool)
+	blockedUserIds, err := getHiddenUserIds(getProfileViewer(c))
+	if err != nil {
+		log.Error("[QueryPresenceHandle] getHiddenUserIds %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/getHiddenUserIds", "Error happened while finding blocked users!"))
+	}
+	for _, userId := range blockedUserIds {
+		hiddenUsers[userId] = true
+	}
+	statusHiddenUsers, err := getOnlineStatusHiddenUsers(model.UserIds, userInfoInReq)
+	if err != nil {
+		log.Error("[QueryPresenceHandle] getOnlineStatusHiddenUsers %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/getOnlineStatusSetting", "Error happened while reading online status setting!"))
+	}
+	for userId := range statusHiddenUsers {
+		if userId != userInfoInReq.UserId {
+			hiddenUsers[userId] = true
+		}
+	}
+
+	result := []models.PresenceModel{}
+	for _, userId := range model.UserIds {
+		if hiddenUsers[userId] {
+			result = append(result, models.PresenceModel{UserId: userId, Status: models.PresenceStatusOffline})
+			continue
+		}
+		if presence, ok := presenceList[userId]; ok {
+			result = append(result, presence)
+			continue
+		}
+		result = append(result, models.PresenceModel{
+			UserId:   userId,
+			Status:   models.PresenceStatusOffline,
+			LastSeen: lastSeenList[userId],
+		})
+	}
+
+	go sweepExpiredPresence()
+
+	return c.JSON(result)
+}
+
+// setPresence keep the user online or away. Last seen is saved in profile only when the user comes online
+// so heartbeats do not write to database.
+func setPresence(userInfoInReq *UserInfoInReq, status string) (*models.PresenceModel, error) {
+	presenceService, serviceErr := service.NewPresenceService()
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	now := utils.UTCNowUnix()
+	presence := &models.PresenceModel{
+		UserId:    userInfoInReq.UserId,
+		Status:    status,
+		LastSeen:  now,
+		ExpiresAt: now + profileConfig.ProfileConfig.PresenceTTL*1000,
+	}
+	previous, err := presenceService.SetPresence(presence)
+	if err != nil {
+		return nil, err
+	}
+
+	if previous == nil {
+		updateLastSeen(presence.UserId, now)
+	}
+	if previous == nil || previous.Status != status {
+		go dispatchPresence(*presence, userInfoInReq)
+	}
+	go sweepExpiredPresence()
+
+	return presence, nil
+}
+
+// setOffline save last seen of the user who went offline and tell their followers
+func setOffline(presence models.PresenceModel, userInfoInReq *UserInfoInReq) {
+	presence.Status = models.PresenceStatusOffline
+	updateLastSeen(presence.UserId, presence.LastSeen)
+	go dispatchPresence(presence, userInfoInReq)
+}
+
+// sweepExpiredPresence set users offline whose heartbeat expired.
+// Expiry is checked on presence requests as the function may not run between them.
+func sweepExpiredPresence() {
+	presenceService, serviceErr := service.NewPresenceService()
+	if serviceErr != nil {
+		log.Error("NewPresenceService %s", serviceErr.Error())
+		return
+	}
+
+	expiredList, err := presenceService.PopExpiredPresence(utils.UTCNowUnix())
+	if err != nil {
+		log.Error("[sweepExpiredPresence] PopExpiredPresence %s", err.Error())
+		return
+	}
+	for _, presence := range expiredList {
+		setOffline(presence, &UserInfoInReq{UserId: presence.UserId})
+	}
+}
+
+// updateLastSeen save last seen in user profile. Failure is only logged as presence is already set.
+func updateLastSeen(userId uuid.UUID, lastSeen int64) {
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserProfileService %s", serviceErr.Error())
+		return
+	}
+	if err := userProfileService.UpdateLastSeen(userId, lastSeen); err != nil {
+		log.Error("[updateLastSeen] UpdateLastSeen %s", err.Error())
+	}
+}
+
+// dispatchPresence send presence of the user to the rooms of their followers unless the user hides online status
+func dispatchPresence(presence models.PresenceModel, userInfoInReq *UserInfoInReq) {
+	statusHiddenUsers, err := getOnlineStatusHiddenUsers([]uuid.UUID{presence.UserId}, userInfoInReq)
+	if err != nil {
+		log.Error("[dispatchPresence] getOnlineStatusHiddenUsers %s", err.Error())
+		return
+	}
+	if statusHiddenUsers[presence.UserId] {
+		return
+	}
+
+	userFollowService, serviceErr := service.NewUserFollowService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserFollowService %s", serviceErr.Error())
+		return
+	}
+	followerIds, err := userFollowService.FindFollowerIds(presence.UserId)
+	if err != nil {
+		log.Error("[dispatchPresence] FindFollowerIds %s", err.Error())
+		return
+	}
+
+	action := Action{
+		Type:    setUserPresenceAction,
+		Payload: presence,
+	}
+	for _, followerId := range followerIds {
+		dispatchActionToRoom(action, followerId, userInfoInReq)
+	}
+}
+
+// getOnlineStatusHiddenUsers get users who turned on hide online status from setting micro
+func getOnlineStatusHiddenUsers(userIds []uuid.UUID, userInfoInReq *UserInfoInReq) (map[uuid.UUID]bool, error) {
+	url := "/setting/dto/ids"
+	model := models.GetSettingsModel{
+		UserIds: userIds,
+		Type:    models.PresenceSettingType,
+	}
+	payload, marshalErr := json.Marshal(model)
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+
+	resData, callErr := functionCall(http.MethodPost, payload, url, getHeadersFromUserInfoReq(userInfoInReq))
+	if callErr != nil {
+		return nil, fmt.Errorf("Cannot send request to %s - %s", url, callErr.Error())
+	}
+
+	var settings map[string]string
+	if err := json.Unmarshal(resData, &settings); err != nil {
+		return nil, err
+	}
+
+	hiddenUsers := make(map[uuid.UUID]bool)
+	for _, userId := range userIds {
+		key := fmt.Sprintf("%s:%s:%s", userId, models.PresenceSettingType, models.HideOnlineStatusSettingName)
+		if settings[key] == "true" {
+			hiddenUsers[userId] = true
+		}
+	}
+	return hiddenUsers, nil
+}
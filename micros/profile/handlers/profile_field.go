@@ -0,0 +1,612 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+	"github.com/red-gold/telar-web/constants"
+	"github.com/red-gold/telar-web/micros/profile/database"
+	"github.com/red-gold/telar-web/micros/profile/dto"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+	service "github.com/red-gold/telar-web/micros/profile/services"
+)
+
+const (
+	// Text values and list items without a max length in the field definition are limited to keep profiles small
+	maxCustomFieldTextLength = 1000
+	maxCustomFieldListItems  = 50
+	// profileFieldCacheTTL is how long field definitions are reused before they are read again
+	profileFieldCacheTTL = 30 * time.Second
+)
+
+var profileFieldNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,29}$`)
+
+// profileFieldCache keeps field definitions since every profile read applies their visibility
+var profileFieldCache struct {
+	sync.Mutex
+	fields    []dto.ProfileField
+	expiresAt time.Time
+}
+
+// GetProfileFieldsHandle godoc
+// @Summary Get custom profile fields
+// @Description Get the admin-defined profile fields by order to build signup and profile forms
+// @Tags profile-fields
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Success 200 {array} dto.ProfileField
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /fields [get]
+func GetProfileFieldsHandle(c *fiber.Ctx) error {
+
+	profileFields, err := getProfileFields()
+	if err != nil {
+		log.Error("[GetProfileFieldsHandle] getProfileFields %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findProfileFields", "Error happened while finding profile fields!"))
+	}
+	return c.JSON(profileFields)
+}
+
+// CreateProfileFieldHandle godoc
+// @Summary Create custom profile field
+// @Description Define a new field which users fill in their profile. Called by admin micro.
+// @Tags profile-fields
+// @Accept json
+// @Produce json
+// @Param body body models.ProfileFieldModel true "Profile field model"
+// @Success 200 {object} dto.ProfileField
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 409 {object} utils.TelarError "Field name is already defined"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /dto/fields [post]
+func CreateProfileFieldHandle(c *fiber.Ctx) error {
+
+	model := new(models.ProfileFieldModel)
+	if err := c.BodyParser(model); err != nil {
+		log.Error("[CreateProfileFieldHandle] parse ProfileFieldModel %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseProfileFieldModel", "Error happened while parsing model!"))
+	}
+	if model.Visibility == "" {
+		model.Visibility = constants.Public
+	}
+	if err := validateProfileField(model); err != nil {
+		return profileFieldErrorResponse(c, err)
+	}
+
+	// Create service
+	profileFieldService, serviceErr := service.NewProfileFieldService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewProfileFieldService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/profileFieldService", "Error happened while creating profileFieldService!"))
+	}
+
+	foundProfileField, err := profileFieldService.FindByName(model.Name)
+	if err != nil {
+		log.Error("[CreateProfileFieldHandle] FindByName %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findProfileField", "Error happened while finding profile field!"))
+	}
+	if foundProfileField != nil {
+		return profileFieldErrorResponse(c, models.ProfileFieldError{Code: models.ProfileFieldErrorNameTaken, Field: model.Name})
+	}
+
+	currentUser, _ := c.Locals("user").(types.UserContext)
+	profileField := &dto.ProfileField{CreatedBy: currentUser.UserID}
+	setProfileFieldDefinition(profileField, model)
+	profileField.Name = model.Name
+	profileField.Type = model.Type
+	if err := profileFieldService.SaveProfileField(profileField); err != nil {
+		log.Error("[CreateProfileFieldHandle] SaveProfileField %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/saveProfileField", "Error happened while saving profile field!"))
+	}
+	resetProfileFieldCache()
+
+	return c.JSON(profileField)
+}
+
+// UpdateProfileFieldHandle godoc
+// @Summary Update custom profile field
+// @Description Update label, visibility and validation of a profile field. Name and type can not be changed,
+// @Description saved values which do not pass the new validation are kept until the user edits them. Called by admin micro.
+// @Tags profile-fields
+// @Accept json
+// @Produce json
+// @Param name path string true "Field name"
+// @Param body body models.ProfileFieldModel true "Profile field model"
+// @Success 200 {object} dto.ProfileField
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 404 {object} utils.TelarError "Profile field not found"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /dto/fields/{name} [put]
+func UpdateProfileFieldHandle(c *fiber.Ctx) error {
+
+	model := new(models.ProfileFieldModel)
+	if err := c.BodyParser(model); err != nil {
+		log.Error("[UpdateProfileFieldHandle] parse ProfileFieldModel %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseProfileFieldModel", "Error happened while parsing model!"))
+	}
+
+	// Create service
+	profileFieldService, serviceErr := service.NewProfileFieldService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewProfileFieldService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/profileFieldService", "Error happened while creating profileFieldService!"))
+	}
+
+	profileField, err := profileFieldService.FindByName(c.Params("name"))
+	if err != nil {
+		log.Error("[UpdateProfileFieldHandle] FindByName %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findProfileField", "Error happened while finding profile field!"))
+	}
+	if profileField == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundProfileField", "Profile field not found!"))
+	}
+
+	model.Name = profileField.Name
+	model.Type = profileField.Type
+	if model.Visibility == "" {
+		model.Visibility = profileField.Visibility
+	}
+	if err := validateProfileField(model); err != nil {
+		return profileFieldErrorResponse(c, err)
+	}
+
+	setProfileFieldDefinition(profileField, model)
+	profileField.LastUpdated = utils.UTCNowUnix()
+	if err := profileFieldService.UpdateProfileField(profileField.Name, profileField); err != nil {
+		log.Error("[UpdateProfileFieldHandle] UpdateProfileField %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/updateProfileField", "Error happened while updating profile field!"))
+	}
+	resetProfileFieldCache()
+
+	return c.JSON(profileField)
+}
+
+// DeleteProfileFieldHandle godoc
+// @Summary Delete custom profile field
+// @Description Delete a profile field and remove its value from every profile. Called by admin micro.
+// @Tags profile-fields
+// @Produce json
+// @Param name path string true "Field name"
+// @Success 200
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /dto/fields/{name} [delete]
+func DeleteProfileFieldHandle(c *fiber.Ctx) error {
+
+	// Create service
+	profileFieldService, serviceErr := service.NewProfileFieldService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewProfileFieldService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/profileFieldService", "Error happened while creating profileFieldService!"))
+	}
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserProfileService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userProfileService", "Error happened while creating userProfileService!"))
+	}
+
+	name := c.Params("name")
+	if err := profileFieldService.DeleteProfileField(name); err != nil {
+		log.Error("[DeleteProfileFieldHandle] DeleteProfileField %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/deleteProfileField", "Error happened while deleting profile field!"))
+	}
+	resetProfileFieldCache()
+
+	// Values of a deleted field are hidden from others already, so failure only leaves them for the owner
+	if profileFieldNamePattern.MatchString(name) {
+		if err := userProfileService.UnsetCustomField(name); err != nil {
+			log.Error("[DeleteProfileFieldHandle] UnsetCustomField %s", err.Error())
+		}
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
+
+// CheckCustomFieldsHandle godoc
+// @Summary Check custom field values
+// @Description Validate custom field values against the profile fields. Signup asks for the required fields too.
+// @Description Invalid values are not an error of the request and carry the reason code. Called by auth micro.
+// @Tags profile-fields
+// @Accept json
+// @Produce json
+// @Param body body models.CheckCustomFieldsModel true "Check custom fields model"
+// @Success 200 {object} models.CustomFieldsCheckResultModel
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /dto/fields/check [post]
+func CheckCustomFieldsHandle(c *fiber.Ctx) error {
+
+	model := new(models.CheckCustomFieldsModel)
+	if err := c.BodyParser(model); err != nil {
+		log.Error("[CheckCustomFieldsHandle] parse CheckCustomFieldsModel %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseCheckCustomFieldsModel", "Error happened while parsing model!"))
+	}
+
+	customFields, err := checkCustomFields(nil, model.CustomFields, model.Signup)
+	if err != nil {
+		profileFieldErr, ok := err.(models.ProfileFieldError)
+		if !ok {
+			log.Error("[CheckCustomFieldsHandle] checkCustomFields %s", err.Error())
+			return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/checkCustomFields", "Error happened while checking custom fields!"))
+		}
+		return c.JSON(models.CustomFieldsCheckResultModel{
+			Field:   profileFieldErr.Field,
+			Code:    profileFieldErr.Code,
+			Message: profileFieldErr.Error(),
+		})
+	}
+
+	return c.JSON(models.CustomFieldsCheckResultModel{
+		Valid:        true,
+		CustomFields: customFields,
+	})
+}
+
+// getProfileFields get field definitions from cache or database
+func getProfileFields() ([]dto.ProfileField, error) {
+	profileFieldCache.Lock()
+	defer profileFieldCache.Unlock()
+
+	if profileFieldCache.fields != nil && time.Now().Before(profileFieldCache.expiresAt) {
+		return profileFieldCache.fields, nil
+	}
+
+	profileFieldService, serviceErr := service.NewProfileFieldService(database.Db)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+	profileFields, err := profileFieldService.FindAllProfileFields()
+	if err != nil {
+		return nil, err
+	}
+	profileFieldCache.fields = profileFields
+	profileFieldCache.expiresAt = time.Now().Add(profileFieldCacheTTL)
+	return profileFields, nil
+}
+
+// resetProfileFieldCache read field definitions again on next use. Other instances see the change after the cache TTL.
+func resetProfileFieldCache() {
+	profileFieldCache.Lock()
+	defer profileFieldCache.Unlock()
+	profileFieldCache.fields = nil
+}
+
+// findProfileField find field definition by name in the list
+func findProfileField(profileFields []dto.ProfileField, name string) *dto.ProfileField {
+	for i := range profileFields {
+		if profileFields[i].Name == name {
+			return &profileFields[i]
+		}
+	}
+	return nil
+}
+
+// validateProfileField check the field definition
+func validateProfileField(model *models.ProfileFieldModel) error {
+	model.Name = strings.TrimSpace(model.Name)
+	model.Label = strings.TrimSpace(model.Label)
+
+	if !profileFieldNamePattern.MatchString(model.Name) {
+		return models.ProfileFieldError{Code: models.ProfileFieldErrorNameInvalid, Field: model.Name}
+	}
+	if model.Label == "" {
+		return models.ProfileFieldError{Code: models.ProfileFieldErrorLabelRequired, Field: model.Name}
+	}
+	switch model.Type {
+	case models.ProfileFieldTypeText, models.ProfileFieldTypeNumber, models.ProfileFieldTypeBoolean,
+		models.ProfileFieldTypeURL, models.ProfileFieldTypeSelect, models.ProfileFieldTypeList:
+	default:
+		return models.ProfileFieldError{Code: models.ProfileFieldErrorTypeInvalid, Field: model.Name}
+	}
+	if !isValidPermission(model.Visibility) {
+		return models.ProfileFieldError{Code: models.ProfileFieldErrorVisibilityInvalid, Field: model.Name}
+	}
+	if model.Searchable && model.Visibility != constants.Public {
+		return models.ProfileFieldError{Code: models.ProfileFieldErrorSearchable, Field: model.Name}
+	}
+	if model.Pattern != "" {
+		if _, err := regexp.Compile(model.Pattern); err != nil {
+			return models.ProfileFieldError{Code: models.ProfileFieldErrorPatternInvalid, Field: model.Name}
+		}
+	}
+
+	options := []string{}
+	for _, option := range model.Options {
+		option = strings.TrimSpace(option)
+		if option != "" && !contains(options, option) {
+			options = append(options, option)
+		}
+	}
+	model.Options = options
+	if model.Type == models.ProfileFieldTypeSelect && len(model.Options) == 0 {
+		return models.ProfileFieldError{Code: models.ProfileFieldErrorOptionsRequired, Field: model.Name}
+	}
+
+	if model.MinLength < 0 || model.MaxLength < 0 || (model.MaxLength > 0 && model.MinLength > model.MaxLength) {
+		return models.ProfileFieldError{Code: models.ProfileFieldErrorLimitInvalid, Field: model.Name}
+	}
+	if model.Min != nil && model.Max != nil && *model.Min > *model.Max {
+		return models.ProfileFieldError{Code: models.ProfileFieldErrorLimitInvalid, Field: model.Name}
+	}
+	return nil
+}
+
+// setProfileFieldDefinition copy the changeable parts of the definition
+func setProfileFieldDefinition(profileField *dto.ProfileField, model *models.ProfileFieldModel) {
+	profileField.Label = model.Label
+	profileField.Visibility = model.Visibility
+	profileField.Required = model.Required
+	profileField.Searchable = model.Searchable
+	profileField.MinLength = model.MinLength
+	profileField.MaxLength = model.MaxLength
+	profileField.Min = model.Min
+	profileField.Max = model.Max
+	profileField.Pattern = model.Pattern
+	profileField.Options = model.Options
+	profileField.Order = model.Order
+}
+
+// checkCustomFields validate the changed custom fields and merge them into the saved ones.
+// Empty values clear the field. Signup asks for every required field and later updates can not clear them.
+func checkCustomFields(saved map[string]interface{}, changed map[string]interface{}, signup bool) (map[string]interface{}, error) {
+	merged := make(map[string]interface{})
+	for name, value := range saved {
+		merged[name] = value
+	}
+	if len(changed) == 0 && !signup {
+		return merged, nil
+	}
+
+	profileFields, err := getProfileFields()
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range changed {
+		profileField := findProfileField(profileFields, name)
+		if profileField == nil {
+			return nil, models.ProfileFieldError{Code: models.CustomFieldErrorUnknown, Field: name}
+		}
+		normalized, err := normalizeCustomFieldValue(profileField, value)
+		if err != nil {
+			return nil, err
+		}
+		if normalized == nil {
+			if profileField.Required {
+				return nil, models.ProfileFieldError{Code: models.CustomFieldErrorRequired, Field: name}
+			}
+			delete(merged, name)
+			continue
+		}
+		merged[name] = normalized
+	}
+
+	if signup {
+		for _, profileField := range profileFields {
+			if _, ok := merged[profileField.Name]; profileField.Required && !ok {
+				return nil, models.ProfileFieldError{Code: models.CustomFieldErrorRequired, Field: profileField.Name}
+			}
+		}
+	}
+	return merged, nil
+}
+
+// normalizeCustomFieldValue check the value by the field type and validation.
+// It returns nil when the value is empty.
+func normalizeCustomFieldValue(profileField *dto.ProfileField, value interface{}) (interface{}, error) {
+	fieldErr := func(code string) error {
+		return models.ProfileFieldError{Code: code, Field: profileField.Name}
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	switch profileField.Type {
+	case models.ProfileFieldTypeNumber:
+		number, ok := value.(float64)
+		if !ok {
+			return nil, fieldErr(models.CustomFieldErrorType)
+		}
+		if (profileField.Min != nil && number < *profileField.Min) || (profileField.Max != nil && number > *profileField.Max) {
+			return nil, fieldErr(models.CustomFieldErrorRange)
+		}
+		return number, nil
+
+	case models.ProfileFieldTypeBoolean:
+		boolean, ok := value.(bool)
+		if !ok {
+			return nil, fieldErr(models.CustomFieldErrorType)
+		}
+		return boolean, nil
+
+	case models.ProfileFieldTypeList:
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fieldErr(models.CustomFieldErrorType)
+		}
+		list := []string{}
+		for _, item := range items {
+			text, ok := item.(string)
+			if !ok {
+				return nil, fieldErr(models.CustomFieldErrorType)
+			}
+			text = strings.TrimSpace(text)
+			if text == "" || contains(list, text) {
+				continue
+			}
+			if err := checkCustomFieldText(profileField, text, maxCustomFieldTextLength); err != nil {
+				return nil, err
+			}
+			list = append(list, text)
+		}
+		if len(list) == 0 {
+			return nil, nil
+		}
+		maxItems := profileField.MaxLength
+		if maxItems == 0 {
+			maxItems = maxCustomFieldListItems
+		}
+		if len(list) < profileField.MinLength || len(list) > maxItems {
+			return nil, fieldErr(models.CustomFieldErrorLength)
+		}
+		return list, nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return nil, fieldErr(models.CustomFieldErrorType)
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, nil
+	}
+	if profileField.Type == models.ProfileFieldTypeURL {
+		parsedURL, err := url.Parse(text)
+		if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+			return nil, fieldErr(models.CustomFieldErrorType)
+		}
+	}
+	maxLength := profileField.MaxLength
+	if maxLength == 0 {
+		maxLength = maxCustomFieldTextLength
+	}
+	length := utf8.RuneCountInString(text)
+	if length < profileField.MinLength || length > maxLength {
+		return nil, fieldErr(models.CustomFieldErrorLength)
+	}
+	if err := checkCustomFieldText(profileField, text, maxLength); err != nil {
+		return nil, err
+	}
+	return text, nil
+}
+
+// checkCustomFieldText check text value or list item against the options and pattern of the field
+func checkCustomFieldText(profileField *dto.ProfileField, text string, maxLength int) error {
+	if utf8.RuneCountInString(text) > maxLength {
+		return models.ProfileFieldError{Code: models.CustomFieldErrorLength, Field: profileField.Name}
+	}
+	if len(profileField.Options) > 0 && !contains(profileField.Options, text) {
+		return models.ProfileFieldError{Code: models.CustomFieldErrorOption, Field: profileField.Name}
+	}
+	if profileField.Pattern != "" {
+		matched, err := regexp.MatchString(profileField.Pattern, text)
+		if err != nil || !matched {
+			return models.ProfileFieldError{Code: models.CustomFieldErrorPattern, Field: profileField.Name}
+		}
+	}
+	return nil
+}
+
+// setUpdateCustomFields put the merged custom fields on the profile update model.
+// Custom fields are saved as a whole so fields missing in the request keep their saved values.
+func setUpdateCustomFields(model interface{}, userProfile *dto.UserProfile) error {
+	var customFields *map[string]interface{}
+	switch updateModel := model.(type) {
+	case *models.ProfileUpdateModel:
+		customFields = &updateModel.CustomFields
+	case *models.ProfileGeneralUpdateModel:
+		customFields = &updateModel.CustomFields
+	default:
+		return nil
+	}
+
+	merged, err := checkCustomFields(userProfile.CustomFields, *customFields, false)
+	if err != nil {
+		return err
+	}
+	*customFields = merged
+	return nil
+}
+
+// applyCustomFieldVisibility keep the custom fields which are defined and visible to the viewer.
+// Custom fields are hidden when the definitions can not be read.
+func applyCustomFieldVisibility(userProfile *dto.UserProfile, viewer profileViewer) {
+	if len(userProfile.CustomFields) == 0 {
+		return
+	}
+	profileFields, err := getProfileFields()
+	if err != nil {
+		log.Error("[applyCustomFieldVisibility] getProfileFields %s", err.Error())
+		userProfile.CustomFields = nil
+		return
+	}
+
+	visibleFields := make(map[string]interface{})
+	for name, value := range userProfile.CustomFields {
+		profileField := findProfileField(profileFields, name)
+		if profileField != nil && viewer.canView(userProfile, profileField.Visibility) {
+			visibleFields[name] = value
+		}
+	}
+	userProfile.CustomFields = visibleFields
+}
+
+// getCustomFieldFilter get custom field values from the filter query parameters like cf.team=platform.
+// Only searchable fields can filter profiles.
+func getCustomFieldFilter(c *fiber.Ctx) (map[string]interface{}, error) {
+	queryValues := make(map[string]string)
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		if name := strings.TrimPrefix(string(key), models.CustomFieldFilterPrefix); name != string(key) {
+			queryValues[name] = strings.TrimSpace(string(value))
+		}
+	})
+	if len(queryValues) == 0 {
+		return nil, nil
+	}
+
+	profileFields, err := getProfileFields()
+	if err != nil {
+		return nil, err
+	}
+
+	customFields := make(map[string]interface{})
+	for name, value := range queryValues {
+		profileField := findProfileField(profileFields, name)
+		if profileField == nil || !profileField.Searchable {
+			return nil, models.ProfileFieldError{Code: models.CustomFieldErrorNotSearchable, Field: name}
+		}
+		if value == "" {
+			continue
+		}
+		switch profileField.Type {
+		case models.ProfileFieldTypeNumber:
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, models.ProfileFieldError{Code: models.CustomFieldErrorType, Field: name}
+			}
+			customFields[name] = number
+		case models.ProfileFieldTypeBoolean:
+			boolean, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, models.ProfileFieldError{Code: models.CustomFieldErrorType, Field: name}
+			}
+			customFields[name] = boolean
+		default:
+			customFields[name] = value
+		}
+	}
+	return customFields, nil
+}
+
+// profileFieldErrorResponse write profile field error on response
+func profileFieldErrorResponse(c *fiber.Ctx, err error) error {
+	profileFieldErr, ok := err.(models.ProfileFieldError)
+	if !ok {
+		log.Error("[profileField] %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/checkProfileField", "Error happened while checking profile fields!"))
+	}
+	if profileFieldErr.Code == models.ProfileFieldErrorNameTaken {
+		return c.Status(http.StatusConflict).JSON(utils.Error(profileFieldErr.Code, profileFieldErr.Error()))
+	}
+	return c.Status(http.StatusBadRequest).JSON(utils.Error(profileFieldErr.Code, profileFieldErr.Field+": "+profileFieldErr.Error()))
+}
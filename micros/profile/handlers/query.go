@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"net/http"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,6 +11,7 @@ import (
 	"github.com/red-gold/telar-core/pkg/parser"
 	utils "github.com/red-gold/telar-core/utils"
 	"github.com/red-gold/telar-web/micros/profile/database"
+	models "github.com/red-gold/telar-web/micros/profile/models"
 	service "github.com/red-gold/telar-web/micros/profile/services"
 )
 
@@ -16,17 +19,23 @@ type UserProfileQueryModel struct {
 	Search     string      `query:"search"`
 	Page       int64       `query:"page"`
 	NotInclude []uuid.UUID `query:"nin"`
+	Cursor     string      `query:"cursor"`
+	Limit      int64       `query:"limit"`
 }
 
 // @Summary Query user profiles
-// @Description Query user profiles by search query
+// @Description Query user profiles by search query from newest to oldest.
+// @Description Passing cursor or limit returns a page object with nextCursor, otherwise the profile list of the given page is returned.
+// @Description Searchable custom fields filter public profiles by value.
 // @Tags profiles
 // @Accept  json
 // @Produce  json
 // @Security JWT
 // @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
 // @Param   query  body     UserProfileQueryModel  true "User profile query model"
-// @Success 200 {array} dto.UserProfile
+// @Param   cf.{name}  query     string  false "Value of a searchable custom field like cf.team=platform"
+// @Success 200 {object} models.UserProfileCursorPageModel "When cursor or limit is passed"
+// @Success 200 {array} dto.UserProfile "When only page is passed"
 // @Failure 400 {object} utils.TelarError
 // @Failure 500 {object} utils.TelarError
 // @Router / [get]
@@ -46,11 +55,73 @@ func QueryUserProfileHandle(c *fiber.Ctx) error {
 		return c.Status(http.StatusBadRequest).JSON(utils.Error("queryParser", "Error happened while parsing query!"))
 	}
 
-	userList, err := userService.QueryUserProfile(query.Search, "created_date", query.Page, query.NotInclude)
+	customFields, err := getCustomFieldFilter(c)
 	if err != nil {
-		log.Error("[QueryUserProfile] %s", err.Error())
-		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userProfileService", "Error happened while creating userProfileService!"))
+		return profileFieldErrorResponse(c, err)
+	}
+
+	viewer := getProfileViewer(c)
+	hiddenUserIds, err := getHiddenUserIds(viewer)
+	if err != nil {
+		log.Error("[QueryUserProfileHandle] getHiddenUserIds %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findUserRestrictions", "Error happened while finding user restrictions!"))
+	}
+	query.NotInclude = append(query.NotInclude, hiddenUserIds...)
+
+	if query.Cursor == "" && query.Limit == 0 {
+		userList, err := userService.QueryUserProfile(query.Search, customFields, "created_date", query.Page, query.NotInclude)
+		if err != nil {
+			log.Error("[QueryUserProfile] %s", err.Error())
+			return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userProfileService", "Error happened while creating userProfileService!"))
+		}
+		applyProfileListVisibility(userList, viewer)
+		return c.JSON(userList)
+	}
+
+	var after *models.UserProfileCursorModel
+	if query.Cursor != "" {
+		after, err = decodeUserProfileCursor(query.Cursor)
+		if err != nil {
+			log.Error("[QueryUserProfileHandle] decodeUserProfileCursor %s", err.Error())
+			return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCursor", "Cursor is not valid!"))
+		}
+	}
+
+	userList, next, err := userService.QueryUserProfileAfter(query.Search, customFields, after, query.Limit, query.NotInclude)
+	if err != nil {
+		log.Error("[QueryUserProfileAfter] %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/queryUserProfile", "Error happened while querying user profiles!"))
 	}
 
-	return c.JSON(userList)
+	applyProfileListVisibility(userList, viewer)
+	return c.JSON(models.UserProfileCursorPageModel{
+		Profiles:   userList,
+		NextCursor: encodeUserProfileCursor(next),
+	})
+}
+
+// encodeUserProfileCursor encode cursor as an opaque token. Nil cursor is encoded as empty token.
+func encodeUserProfileCursor(cursor *models.UserProfileCursorModel) string {
+	if cursor == nil {
+		return ""
+	}
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		log.Error("[encodeUserProfileCursor] %s", err.Error())
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeUserProfileCursor decode the opaque cursor token
+func decodeUserProfileCursor(token string) (*models.UserProfileCursorModel, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	var cursor models.UserProfileCursorModel
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
 }
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"testing"
+
+	uuid "github.com/gofrs/uuid"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+)
+
+func TestUserProfileCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor models.UserProfileCursorModel
+	}{
+		{"zero cursor", models.UserProfileCursorModel{}},
+		{"created date and object id", models.UserProfileCursorModel{
+			CreatedDate: 1760832000123,
+			ObjectId:    uuid.Must(uuid.FromString("6ba7b810-9dad-11d1-80b4-00c04fd430c8")),
+		}},
+		{"negative created date", models.UserProfileCursorModel{CreatedDate: -1, ObjectId: uuid.Must(uuid.NewV4())}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := encodeUserProfileCursor(&tt.cursor)
+			if token == "" {
+				t.Fatalf("encodeUserProfileCursor(%+v) returned empty token", tt.cursor)
+			}
+			decoded, err := decodeUserProfileCursor(token)
+			if err != nil {
+				t.Fatalf("decodeUserProfileCursor(%q) error = %v", token, err)
+			}
+			if *decoded != tt.cursor {
+				t.Errorf("decodeUserProfileCursor(%q) = %+v, want %+v", token, *decoded, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestEncodeUserProfileCursorNil(t *testing.T) {
+	if token := encodeUserProfileCursor(nil); token != "" {
+		t.Errorf("encodeUserProfileCursor(nil) = %q, want empty token", token)
+	}
+}
+
+func TestDecodeUserProfileCursorInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"not base64", "not a cursor!"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"createdDate":1}`))},
+		{"not json", base64.RawURLEncoding.EncodeToString([]byte("cursor"))},
+		{"wrong field type", base64.RawURLEncoding.EncodeToString([]byte(`{"createdDate":"yesterday"}`))},
+		{"invalid object id", base64.RawURLEncoding.EncodeToString([]byte(`{"createdDate":1,"objectId":"abc"}`))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if cursor, err := decodeUserProfileCursor(tt.token); err == nil {
+				t.Errorf("decodeUserProfileCursor(%q) = %+v, want error", tt.token, cursor)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/gofiber/fiber/v2"
 	uuid "github.com/gofrs/uuid"
@@ -10,6 +11,7 @@ import (
 	"github.com/red-gold/telar-core/types"
 	utils "github.com/red-gold/telar-core/utils"
 	"github.com/red-gold/telar-web/micros/profile/database"
+	"github.com/red-gold/telar-web/micros/profile/dto"
 	models "github.com/red-gold/telar-web/micros/profile/models"
 	service "github.com/red-gold/telar-web/micros/profile/services"
 )
@@ -107,6 +109,18 @@ func ReadProfileHandle(c *fiber.Ctx) error {
 		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUser", "Error happened while finding user profile!"))
 	}
 
+	viewer := getProfileViewer(c)
+	hidden, err := isHiddenFromViewer(foundUser, viewer)
+	if err != nil {
+		log.Error("isHiddenFromViewer %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findUserRestriction", "Error happened while finding user restriction!"))
+	}
+	if hidden {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUser", "Error happened while finding user profile!"))
+	}
+	applyProfileVisibility(foundUser, viewer)
+	applyOnlineStatusVisibility(viewer, foundUser)
+
 	profileModel := models.MyProfileModel{
 		ObjectId:       foundUser.ObjectId,
 		FullName:       foundUser.FullName,
@@ -114,6 +128,9 @@ func ReadProfileHandle(c *fiber.Ctx) error {
 		Avatar:         foundUser.Avatar,
 		Banner:         foundUser.Banner,
 		TagLine:        foundUser.TagLine,
+		Email:          foundUser.Email,
+		Phone:          foundUser.Phone,
+		Country:        foundUser.Country,
 		Birthday:       foundUser.Birthday,
 		Address:        foundUser.Address,
 		LastSeen:       foundUser.LastSeen,
@@ -127,7 +144,9 @@ func ReadProfileHandle(c *fiber.Ctx) error {
 		LinkedInId:     foundUser.LinkedInId,
 		AccessUserList: foundUser.AccessUserList,
 		Permission:     foundUser.Permission,
+		Verification:   foundUser.Verification,
 		CreatedDate:    foundUser.CreatedDate,
+		CustomFields:   foundUser.CustomFields,
 	}
 
 	return c.JSON(profileModel)
@@ -143,6 +162,7 @@ func ReadProfileHandle(c *fiber.Ctx) error {
 // @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
 // @Param   name  path     string  true "Social name"
 // @Success 200 {object} models.MyProfileModel
+// @Success 307 {object} models.SocialNameMovedModel "Social name is an old name of the user"
 // @Failure 400 {object} utils.TelarError
 // @Failure 500 {object} utils.TelarError
 // @Router /social/{name} [get]
@@ -169,11 +189,44 @@ func GetBySocialName(c *fiber.Ctx) error {
 		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findBySocialName", "Error happened while finding user profile!"))
 	}
 
+	// Old social names resolve to the user who renamed it
+	moved := false
+	if foundUser == nil {
+		foundUser, err = findMovedProfile(socialName)
+		if err != nil {
+			log.Error("findMovedProfile %s", err.Error())
+			return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findMovedProfile", "Error happened while finding user profile!"))
+		}
+		moved = foundUser != nil
+	}
+
 	if foundUser == nil {
 		log.Error("[GetBySocialName] Could not find user " + socialName)
 		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUser", "Error happened while finding user profile!"))
 	}
 
+	viewer := getProfileViewer(c)
+	hidden, err := isHiddenFromViewer(foundUser, viewer)
+	if err != nil {
+		log.Error("isHiddenFromViewer %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findUserRestriction", "Error happened while finding user restriction!"))
+	}
+	if hidden {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUser", "Error happened while finding user profile!"))
+	}
+
+	// Old names can be claimed again, so the redirect must not be cached as permanent
+	if moved {
+		c.Location(url.PathEscape(foundUser.SocialName))
+		return c.Status(http.StatusTemporaryRedirect).JSON(models.SocialNameMovedModel{
+			ObjectId:   foundUser.ObjectId,
+			SocialName: normalizeSocialName(socialName),
+			MovedTo:    foundUser.SocialName,
+		})
+	}
+	applyProfileVisibility(foundUser, viewer)
+	applyOnlineStatusVisibility(viewer, foundUser)
+
 	profileModel := models.MyProfileModel{
 		ObjectId:       foundUser.ObjectId,
 		FullName:       foundUser.FullName,
@@ -181,6 +234,9 @@ func GetBySocialName(c *fiber.Ctx) error {
 		Avatar:         foundUser.Avatar,
 		Banner:         foundUser.Banner,
 		TagLine:        foundUser.TagLine,
+		Email:          foundUser.Email,
+		Phone:          foundUser.Phone,
+		Country:        foundUser.Country,
 		Birthday:       foundUser.Birthday,
 		Address:        foundUser.Address,
 		LastSeen:       foundUser.LastSeen,
@@ -194,7 +250,9 @@ func GetBySocialName(c *fiber.Ctx) error {
 		LinkedInId:     foundUser.LinkedInId,
 		AccessUserList: foundUser.AccessUserList,
 		Permission:     foundUser.Permission,
+		Verification:   foundUser.Verification,
 		CreatedDate:    foundUser.CreatedDate,
+		CustomFields:   foundUser.CustomFields,
 	}
 
 	return c.JSON(profileModel)
@@ -245,27 +303,30 @@ func ReadMyProfileHandle(c *fiber.Ctx) error {
 	}
 
 	profileModel := models.MyProfileModel{
-		ObjectId:       foundUser.ObjectId,
-		FullName:       foundUser.FullName,
-		SocialName:     foundUser.SocialName,
-		Avatar:         foundUser.Avatar,
-		Banner:         foundUser.Banner,
-		TagLine:        foundUser.TagLine,
-		Birthday:       foundUser.Birthday,
-		CompanyName:    foundUser.CompanyName,
-		Country:        foundUser.Country,
-		Address:        foundUser.Address,
-		LastSeen:       foundUser.LastSeen,
-		Phone:          foundUser.Phone,
-		WebUrl:         foundUser.WebUrl,
-		FollowCount:    foundUser.FollowCount,
-		FollowerCount:  foundUser.FollowerCount,
-		FacebookId:     foundUser.FacebookId,
-		InstagramId:    foundUser.InstagramId,
-		TwitterId:      foundUser.TwitterId,
-		LinkedInId:     foundUser.LinkedInId,
-		AccessUserList: foundUser.AccessUserList,
-		Permission:     foundUser.Permission,
+		ObjectId:        foundUser.ObjectId,
+		FullName:        foundUser.FullName,
+		SocialName:      foundUser.SocialName,
+		Avatar:          foundUser.Avatar,
+		Banner:          foundUser.Banner,
+		TagLine:         foundUser.TagLine,
+		Birthday:        foundUser.Birthday,
+		CompanyName:     foundUser.CompanyName,
+		Country:         foundUser.Country,
+		Address:         foundUser.Address,
+		LastSeen:        foundUser.LastSeen,
+		Phone:           foundUser.Phone,
+		WebUrl:          foundUser.WebUrl,
+		FollowCount:     foundUser.FollowCount,
+		FollowerCount:   foundUser.FollowerCount,
+		FacebookId:      foundUser.FacebookId,
+		InstagramId:     foundUser.InstagramId,
+		TwitterId:       foundUser.TwitterId,
+		LinkedInId:      foundUser.LinkedInId,
+		AccessUserList:  foundUser.AccessUserList,
+		Permission:      foundUser.Permission,
+		FieldVisibility: foundUser.FieldVisibility,
+		Verification:    foundUser.Verification,
+		CustomFields:    foundUser.CustomFields,
 	}
 	c.Set("action-access-key", actionAccessKey.AccessKey)
 	return c.JSON(profileModel)
@@ -313,6 +374,13 @@ func DispatchProfilesHandle(c *fiber.Ctx) error {
 		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findProfileByUserIds", "Error happened while finding users profile!"))
 	}
 
+	viewer := getProfileViewer(c)
+	profiles := make([]*dto.UserProfile, 0, len(foundUsers))
+	for i := range foundUsers {
+		profiles = append(profiles, &foundUsers[i])
+	}
+	applyOnlineStatusVisibility(viewer, profiles...)
+
 	mappedUsers := make(map[string]interface{})
 	for _, v := range foundUsers {
 		mappedUser := make(map[string]interface{})
@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/pkg/parser"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+	"github.com/red-gold/telar-web/micros/profile/database"
+	"github.com/red-gold/telar-web/micros/profile/dto"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+	service "github.com/red-gold/telar-web/micros/profile/services"
+)
+
+// BlockUserHandle godoc
+// @Summary Block user
+// @Description Block user. Blocked user and current user can not see each other's profile.
+// @Tags profiles
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param userId path string true "User ID"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 404 {object} utils.TelarError "User not found"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /block/{userId} [post]
+func BlockUserHandle(c *fiber.Ctx) error {
+	return restrictUser(c, models.RestrictionTypeBlock)
+}
+
+// UnblockUserHandle godoc
+// @Summary Unblock user
+// @Description Remove user from block list of current user
+// @Tags profiles
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param userId path string true "User ID"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /block/{userId} [delete]
+func UnblockUserHandle(c *fiber.Ctx) error {
+	return unrestrictUser(c, models.RestrictionTypeBlock)
+}
+
+// GetBlockedUsersHandle godoc
+// @Summary Get blocked users
+// @Description Get block list of current user by page from newest to oldest
+// @Tags profiles
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param page query int false "Page number"
+// @Success 200 {array} dto.UserRestriction
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /block [get]
+func GetBlockedUsersHandle(c *fiber.Ctx) error {
+	return queryRestrictedUsers(c, models.RestrictionTypeBlock)
+}
+
+// MuteUserHandle godoc
+// @Summary Mute user
+// @Description Mute user. Current user does not get notifications from muted user.
+// @Tags profiles
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param userId path string true "User ID"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 404 {object} utils.TelarError "User not found"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /mute/{userId} [post]
+func MuteUserHandle(c *fiber.Ctx) error {
+	return restrictUser(c, models.RestrictionTypeMute)
+}
+
+// UnmuteUserHandle godoc
+// @Summary Unmute user
+// @Description Remove user from mute list of current user
+// @Tags profiles
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param userId path string true "User ID"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /mute/{userId} [delete]
+func UnmuteUserHandle(c *fiber.Ctx) error {
+	return unrestrictUser(c, models.RestrictionTypeMute)
+}
+
+// GetMutedUsersHandle godoc
+// @Summary Get muted users
+// @Description Get mute list of current user by page from newest to oldest
+// @Tags profiles
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param page query int false "Page number"
+// @Success 200 {array} dto.UserRestriction
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /mute [get]
+func GetMutedUsersHandle(c *fiber.Ctx) error {
+	return queryRestrictedUsers(c, models.RestrictionTypeMute)
+}
+
+// ReadDtoUserRestrictionsHandle godoc
+// @Summary Get user restrictions
+// @Description Get users blocked and muted by the user and users who blocked the user. Used by other micros.
+// @Tags profiles
+// @Produce json
+// @Param userId path string true "User ID"
+// @Success 200 {object} models.UserRestrictionListModel
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /dto/restrictions/{userId} [get]
+func ReadDtoUserRestrictionsHandle(c *fiber.Ctx) error {
+
+	userUUID, uuidErr := uuid.FromString(c.Params("userId"))
+	if uuidErr != nil {
+		log.Error("[ReadDtoUserRestrictionsHandle] Parse UUID %s ", uuidErr.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseUUIDError", "Can not parse user id!"))
+	}
+
+	// Create service
+	userRestrictionService, serviceErr := service.NewUserRestrictionService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserRestrictionService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userRestrictionService", "Error happened while creating userRestrictionService!"))
+	}
+
+	restrictions, err := userRestrictionService.FindUserRestrictions(userUUID)
+	if err != nil {
+		log.Error("[ReadDtoUserRestrictionsHandle] FindUserRestrictions %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findUserRestrictions", "Error happened while finding user restrictions!"))
+	}
+
+	return c.JSON(restrictions)
+}
+
+// restrictUser add user in path to block or mute list of current user
+func restrictUser(c *fiber.Ctx, restrictionType string) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok || currentUser.UserID == uuid.Nil {
+		log.Error("[restrictUser] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+
+	targetUserId, uuidErr := uuid.FromString(c.Params("userId"))
+	if uuidErr != nil {
+		log.Error("[restrictUser] Parse UUID %s ", uuidErr.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseUUIDError", "Can not parse user id!"))
+	}
+	if targetUserId == currentUser.UserID {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("restrictSelf", "Can not "+restrictionType+" yourself!"))
+	}
+
+	// Create service
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserProfileService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userProfileService", "Error happened while creating userProfileService!"))
+	}
+	userRestrictionService, serviceErr := service.NewUserRestrictionService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserRestrictionService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userRestrictionService", "Error happened while creating userRestrictionService!"))
+	}
+
+	foundUserChan, errChan := userProfileService.FindByUserId(targetUserId)
+	foundUser, err := <-foundUserChan, <-errChan
+	if err != nil {
+		log.Error("[restrictUser] FindByUserId %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findByUserId", "Error happened while finding user profile!"))
+	}
+	if foundUser == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUser", "Error happened while finding user profile!"))
+	}
+
+	userRestriction, err := userRestrictionService.FindUserRestriction(currentUser.UserID, targetUserId, restrictionType)
+	if err != nil {
+		log.Error("[restrictUser] FindUserRestriction %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findUserRestriction", "Error happened while finding user restriction!"))
+	}
+	if userRestriction != nil {
+		return c.SendStatus(http.StatusOK)
+	}
+
+	userRestriction = &dto.UserRestriction{
+		OwnerUserId:  currentUser.UserID,
+		TargetUserId: targetUserId,
+		Type:         restrictionType,
+	}
+	saved, err := userRestrictionService.SaveUserRestriction(userRestriction)
+	if err != nil {
+		log.Error("[restrictUser] SaveUserRestriction %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/saveUserRestriction", "Error happened while saving user restriction!"))
+	}
+	// Another request restricted the user at the same time
+	if !saved {
+		return c.SendStatus(http.StatusOK)
+	}
+
+	// Blocking ends follows in both directions
+	if restrictionType == models.RestrictionTypeBlock {
+		if err := deleteUserFollow(currentUser.UserID, targetUserId); err != nil {
+			log.Error("[restrictUser] deleteUserFollow %s", err.Error())
+		}
+		if err := deleteUserFollow(targetUserId, currentUser.UserID); err != nil {
+			log.Error("[restrictUser] deleteUserFollow %s", err.Error())
+		}
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
+
+// unrestrictUser remove user in path from block or mute list of current user
+func unrestrictUser(c *fiber.Ctx, restrictionType string) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok || currentUser.UserID == uuid.Nil {
+		log.Error("[unrestrictUser] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+
+	targetUserId, uuidErr := uuid.FromString(c.Params("userId"))
+	if uuidErr != nil {
+		log.Error("[unrestrictUser] Parse UUID %s ", uuidErr.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseUUIDError", "Can not parse user id!"))
+	}
+
+	// Create service
+	userRestrictionService, serviceErr := service.NewUserRestrictionService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserRestrictionService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userRestrictionService", "Error happened while creating userRestrictionService!"))
+	}
+
+	if err := userRestrictionService.DeleteUserRestriction(currentUser.UserID, targetUserId, restrictionType); err != nil {
+		log.Error("[unrestrictUser] DeleteUserRestriction %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/deleteUserRestriction", "Error happened while deleting user restriction!"))
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
+
+// queryRestrictedUsers get block or mute list of current user
+func queryRestrictedUsers(c *fiber.Ctx, restrictionType string) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok || currentUser.UserID == uuid.Nil {
+		log.Error("[queryRestrictedUsers] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+
+	query := new(models.UserRestrictionQueryModel)
+	if err := parser.QueryParser(c, query); err != nil {
+		log.Error("[queryRestrictedUsers] QueryParser %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("queryParser", "Error happened while parsing query!"))
+	}
+
+	// Create service
+	userRestrictionService, serviceErr := service.NewUserRestrictionService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserRestrictionService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userRestrictionService", "Error happened while creating userRestrictionService!"))
+	}
+
+	userRestrictionList, err := userRestrictionService.QueryUserRestriction(currentUser.UserID, restrictionType, query.Page)
+	if err != nil {
+		log.Error("[queryRestrictedUsers] QueryUserRestriction %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/queryUserRestriction", "Error happened while querying user restrictions!"))
+	}
+
+	return c.JSON(userRestrictionList)
+}
+
+// getHiddenUserIds get users hidden from the viewer because either of them blocked the other
+func getHiddenUserIds(viewer profileViewer) ([]uuid.UUID, error) {
+	if viewer.fullAccess || viewer.userId == uuid.Nil {
+		return nil, nil
+	}
+
+	userRestrictionService, serviceErr := service.NewUserRestrictionService(database.Db)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+	restrictions, err := userRestrictionService.FindUserRestrictions(viewer.userId)
+	if err != nil {
+		return nil, err
+	}
+	return append(restrictions.Blocked, restrictions.BlockedBy...), nil
+}
+
+// isHiddenFromViewer check whether the viewer and the profile owner blocked each other
+func isHiddenFromViewer(userProfile *dto.UserProfile, viewer profileViewer) (bool, error) {
+	if viewer.isOwner(userProfile) || viewer.userId == uuid.Nil {
+		return false, nil
+	}
+
+	userRestrictionService, serviceErr := service.NewUserRestrictionService(database.Db)
+	if serviceErr != nil {
+		return false, serviceErr
+	}
+	return userRestrictionService.IsBlocked(viewer.userId, userProfile.ObjectId)
+}
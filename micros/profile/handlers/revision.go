@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/gofiber/fiber/v2"
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/pkg/parser"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+	"github.com/red-gold/telar-web/micros/profile/database"
+	"github.com/red-gold/telar-web/micros/profile/dto"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+	service "github.com/red-gold/telar-web/micros/profile/services"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// revisionIgnoredFields change on every update and are not kept in profile history
+var revisionIgnoredFields = map[string]bool{
+	"last_updated":          true,
+	"socialNameChangedDate": true,
+}
+
+// GetMyProfileHistoryHandle godoc
+// @Summary Get my profile history
+// @Description Get the recorded changes of the current user profile by page from newest to oldest
+// @Tags profile
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param page query int false "Page number"
+// @Success 200 {array} dto.ProfileRevision
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /history [get]
+func GetMyProfileHistoryHandle(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok || currentUser.UserID == uuid.Nil {
+		log.Error("[GetMyProfileHistoryHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+	return queryProfileRevisions(c, currentUser.UserID)
+}
+
+// QueryDtoProfileRevisionsHandle godoc
+// @Summary Get profile history of a user
+// @Description Get the recorded changes of the user profile by page from newest to oldest. Called by admin micro.
+// @Tags profile
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param page query int false "Page number"
+// @Success 200 {array} dto.ProfileRevision
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /dto/revisions/{userId} [get]
+func QueryDtoProfileRevisionsHandle(c *fiber.Ctx) error {
+
+	userUUID, uuidErr := uuid.FromString(c.Params("userId"))
+	if uuidErr != nil {
+		log.Error("[QueryDtoProfileRevisionsHandle] Parse UUID %s ", uuidErr.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseUUIDError", "Can not parse user id!"))
+	}
+	return queryProfileRevisions(c, userUUID)
+}
+
+// RollbackProfileRevisionHandle godoc
+// @Summary Roll back user profile
+// @Description Restore the user profile as it was before the revision, undoing the revision and every later change.
+// @Description The rollback is recorded as a new revision. Called by admin micro.
+// @Tags profile
+// @Produce json
+// @Param revisionId path string true "Profile revision ID"
+// @Success 200 {object} dto.ProfileRevision
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 404 {object} utils.TelarError "Revision not found"
+// @Failure 409 {object} utils.TelarError "Old social name is taken"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /dto/revisions/{revisionId}/rollback [post]
+func RollbackProfileRevisionHandle(c *fiber.Ctx) error {
+
+	revisionId, uuidErr := uuid.FromString(c.Params("revisionId"))
+	if uuidErr != nil {
+		log.Error("[RollbackProfileRevisionHandle] Parse UUID %s ", uuidErr.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseUUIDError", "Can not parse revision id!"))
+	}
+
+	// Create service
+	profileRevisionService, serviceErr := service.NewProfileRevisionService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewProfileRevisionService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/profileRevisionService", "Error happened while creating profileRevisionService!"))
+	}
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserProfileService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userProfileService", "Error happened while creating userProfileService!"))
+	}
+
+	revision, err := profileRevisionService.FindById(revisionId)
+	if err != nil {
+		log.Error("[RollbackProfileRevisionHandle] FindById %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findProfileRevision", "Error happened while finding profile revision!"))
+	}
+	if revision == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundProfileRevision", "Profile revision not found!"))
+	}
+
+	laterRevisions, err := profileRevisionService.FindSince(revision.UserId, revision.CreatedDate)
+	if err != nil {
+		log.Error("[RollbackProfileRevisionHandle] FindSince %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findProfileRevision", "Error happened while finding profile revision!"))
+	}
+
+	// Revisions are newest first so the oldest value before the rolled back revision wins
+	restored := bson.M{}
+	for _, laterRevision := range laterRevisions {
+		for _, change := range laterRevision.Changes {
+			restored[change.Field] = change.Before
+		}
+	}
+
+	foundUserChan, errChan := userProfileService.FindByUserId(revision.UserId)
+	foundUser, err := <-foundUserChan, <-errChan
+	if err != nil {
+		log.Error("[RollbackProfileRevisionHandle] FindByUserId %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findByUserId", "Error happened while finding user profile!"))
+	}
+	if foundUser == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUser", "Error happened while finding user profile!"))
+	}
+
+	changes, err := diffProfileUpdate(foundUser, restored)
+	if err != nil {
+		log.Error("[RollbackProfileRevisionHandle] diffProfileUpdate %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/diffProfile", "Error happened while comparing profile!"))
+	}
+
+	currentUser, _ := c.Locals("user").(types.UserContext)
+	rollbackRevision := &dto.ProfileRevision{
+		UserId:     revision.UserId,
+		EditedBy:   currentUser.UserID,
+		Source:     models.RevisionSourceRollback,
+		RollbackOf: revision.ObjectId,
+		Changes:    changes,
+	}
+	if len(changes) == 0 {
+		return c.JSON(rollbackRevision)
+	}
+
+	// The old social name may be taken since. It is renamed like the rename endpoint without the cooldown.
+	var socialNameHistory *dto.SocialNameHistory
+	if socialName, ok := restored["socialName"].(string); ok && socialName != foundUser.SocialName {
+		checkedName, err := checkSocialName(socialName, foundUser.ObjectId)
+		if err != nil {
+			return socialNameChangeErrorResponse(c, err)
+		}
+		restored["socialName"] = checkedName
+		now := utils.UTCNowUnix()
+		restored["socialNameChangedDate"] = now
+		if foundUser.SocialName != "" {
+			socialNameHistory = newSocialNameHistory(foundUser, socialName, now)
+		}
+	}
+
+	update := bson.M{"last_updated": utils.UTCNowUnix()}
+	for _, change := range changes {
+		update[change.Field] = restored[change.Field]
+	}
+	if changedDate, ok := restored["socialNameChangedDate"]; ok {
+		update["socialNameChangedDate"] = changedDate
+	}
+	// The name can still be claimed between the check and the update
+	if err := userProfileService.UpdateUserProfileById(revision.UserId, update); err != nil {
+		if _, ok := err.(models.SocialNameError); ok {
+			return socialNameChangeErrorResponse(c, err)
+		}
+		log.Error("[RollbackProfileRevisionHandle] UpdateUserProfileById %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/rollbackProfile", "Error happened while rolling back profile!"))
+	}
+	saveSocialNameHistory(socialNameHistory)
+
+	if err := profileRevisionService.SaveProfileRevision(rollbackRevision); err != nil {
+		log.Error("[RollbackProfileRevisionHandle] SaveProfileRevision %s", err.Error())
+	}
+
+	return c.JSON(rollbackRevision)
+}
+
+// queryProfileRevisions write revisions of the user profile by page
+func queryProfileRevisions(c *fiber.Ctx, userId uuid.UUID) error {
+
+	query := new(models.ProfileRevisionQueryModel)
+	if err := parser.QueryParser(c, query); err != nil {
+		log.Error("[queryProfileRevisions] QueryParser %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("queryParser", "Error happened while parsing query!"))
+	}
+
+	// Create service
+	profileRevisionService, serviceErr := service.NewProfileRevisionService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewProfileRevisionService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/profileRevisionService", "Error happened while creating profileRevisionService!"))
+	}
+
+	revisionList, err := profileRevisionService.QueryProfileRevision(userId, query.Page)
+	if err != nil {
+		log.Error("[queryProfileRevisions] QueryProfileRevision %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/queryProfileRevision", "Error happened while querying profile history!"))
+	}
+
+	return c.JSON(revisionList)
+}
+
+// recordProfileRevision save the fields the update changed in the profile. The update is already saved so failure is only logged.
+func recordProfileRevision(before *dto.UserProfile, update interface{}, editedBy uuid.UUID, source string) {
+	changes, err := diffProfileUpdate(before, update)
+	if err != nil {
+		log.Error("[recordProfileRevision] diffProfileUpdate %s", err.Error())
+		return
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	profileRevisionService, serviceErr := service.NewProfileRevisionService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewProfileRevisionService %s", serviceErr.Error())
+		return
+	}
+	revision := &dto.ProfileRevision{
+		UserId:   before.ObjectId,
+		EditedBy: editedBy,
+		Source:   source,
+		Changes:  changes,
+	}
+	if err := profileRevisionService.SaveProfileRevision(revision); err != nil {
+		log.Error("[recordProfileRevision] SaveProfileRevision %s", err.Error())
+	}
+}
+
+// diffProfileUpdate compare the fields of the update with the profile by their stored names
+func diffProfileUpdate(before *dto.UserProfile, update interface{}) ([]dto.ProfileFieldChange, error) {
+	beforeFields, err := toBsonMap(before)
+	if err != nil {
+		return nil, err
+	}
+	updateFields, err := toBsonMap(update)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, 0, len(updateFields))
+	for field := range updateFields {
+		if !revisionIgnoredFields[field] {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	changes := []dto.ProfileFieldChange{}
+	for _, field := range fields {
+		if isSameFieldValue(beforeFields[field], updateFields[field]) {
+			continue
+		}
+		changes = append(changes, dto.ProfileFieldChange{
+			Field:  field,
+			Before: beforeFields[field],
+			After:  updateFields[field],
+		})
+	}
+	return changes, nil
+}
+
+// toBsonMap get the fields of the model as they are stored
+func toBsonMap(model interface{}) (bson.M, error) {
+	data, err := bson.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+	fields := bson.M{}
+	if err := bson.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// isSameFieldValue compare stored values where missing, null and empty values are the same
+func isSameFieldValue(a, b interface{}) bool {
+	if isEmptyFieldValue(a) && isEmptyFieldValue(b) {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// isEmptyFieldValue check the stored value is missing or empty
+func isEmptyFieldValue(value interface{}) bool {
+	switch typedValue := value.(type) {
+	case nil:
+		return true
+	case string:
+		return typedValue == ""
+	case primitive.A:
+		return len(typedValue) == 0
+	case primitive.M:
+		return len(typedValue) == 0
+	case primitive.D:
+		return len(typedValue) == 0
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/pkg/parser"
+	utils "github.com/red-gold/telar-core/utils"
+	"github.com/red-gold/telar-web/micros/profile/database"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+	service "github.com/red-gold/telar-web/micros/profile/services"
+)
+
+// SearchProfileHandle searches people by name for mentions and people search
+// @Summary Search user profiles
+// @Description Search people whose full name or social name start with the query. Diacritics are ignored,
+// @Description small typos are tolerated and people the current user follows are ranked first.
+// @Tags profiles
+// @Produce  json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param   q  query     string  true "Search query"
+// @Param   limit  query     int  false "Number of profiles, default 10 and up to 50"
+// @Param   nin  query     []string  false "Excluded user ids"
+// @Param   cf.{name}  query     string  false "Value of a searchable custom field like cf.team=platform"
+// @Success 200 {array} dto.UserProfile
+// @Failure 400 {object} utils.TelarError
+// @Failure 500 {object} utils.TelarError
+// @Router /search [get]
+func SearchProfileHandle(c *fiber.Ctx) error {
+
+	query := new(models.ProfileSearchQueryModel)
+	if err := parser.QueryParser(c, query); err != nil {
+		log.Error("[SearchProfileHandle] QueryParser %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("queryParser", "Error happened while parsing query!"))
+	}
+
+	customFields, err := getCustomFieldFilter(c)
+	if err != nil {
+		return profileFieldErrorResponse(c, err)
+	}
+
+	// Create service
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserProfileService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userProfileService", "Error happened while creating userProfileService!"))
+	}
+
+	// Search still works without ranking by following when follow list can not be read
+	var followingIds []uuid.UUID
+	userInfoInReq := getUserInfoReq(c)
+	if userInfoInReq.UserId != uuid.Nil {
+		userFollowService, serviceErr := service.NewUserFollowService(database.Db)
+		if serviceErr != nil {
+			log.Error("NewUserFollowService %s", serviceErr.Error())
+		} else {
+			var followingErr error
+			followingIds, followingErr = userFollowService.FindFollowingIds(userInfoInReq.UserId)
+			if followingErr != nil {
+				log.Error("[SearchProfileHandle] FindFollowingIds %s", followingErr.Error())
+			}
+		}
+	}
+
+	viewer := getProfileViewer(c)
+	hiddenUserIds, err := getHiddenUserIds(viewer)
+	if err != nil {
+		log.Error("[SearchProfileHandle] getHiddenUserIds %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findUserRestrictions", "Error happened while finding user restrictions!"))
+	}
+	query.NotInclude = append(query.NotInclude, hiddenUserIds...)
+
+	userList, err := userProfileService.SearchUserProfile(query.Query, customFields, followingIds, query.Limit, query.NotInclude)
+	if err != nil {
+		log.Error("[SearchProfileHandle] SearchUserProfile %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/searchUserProfile", "Error happened while searching user profiles!"))
+	}
+
+	applyProfileListVisibility(userList, viewer)
+	return c.JSON(userList)
+}
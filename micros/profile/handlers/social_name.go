@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/pkg/parser"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+	"github.com/red-gold/telar-web/micros/profile/database"
+	"github.com/red-gold/telar-web/micros/profile/dto"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+	service "github.com/red-gold/telar-web/micros/profile/services"
+)
+
+const (
+	socialNameMinLength = 3
+	socialNameMaxLength = 30
+)
+
+// socialNamePattern allows dots only between other characters so names stay safe in URLs
+var socialNamePattern = regexp.MustCompile(`^[a-z0-9_]+(\.[a-z0-9_]+)*$`)
+
+// defaultReservedNames are route and system names which are always reserved, beside the admin-managed list
+var defaultReservedNames = []string{
+	"about", "admin", "administrator", "api", "auth", "explore", "help", "home",
+	"login", "logout", "me", "messages", "notifications", "privacy", "profile",
+	"root", "search", "settings", "signup", "support", "system", "telar", "terms",
+	"user", "users",
+}
+
+// normalizeSocialName lower case the social name and remove leading @
+func normalizeSocialName(socialName string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(socialName), "@"))
+}
+
+// validateSocialName check charset and length of a normalized social name
+func validateSocialName(socialName string) error {
+	if socialName == "" {
+		return models.SocialNameError{Code: models.SocialNameErrorRequired}
+	}
+	if len(socialName) < socialNameMinLength || len(socialName) > socialNameMaxLength {
+		return models.SocialNameError{Code: models.SocialNameErrorLength}
+	}
+	if !socialNamePattern.MatchString(socialName) {
+		return models.SocialNameError{Code: models.SocialNameErrorInvalid}
+	}
+	return nil
+}
+
+// checkSocialName validate the social name and check it is neither reserved, protected nor used by another user.
+// The social name of the given user is available to that user.
+func checkSocialName(socialName string, userId uuid.UUID) (string, error) {
+	socialName = normalizeSocialName(socialName)
+	if err := validateSocialName(socialName); err != nil {
+		return socialName, err
+	}
+
+	if contains(defaultReservedNames, socialName) {
+		return socialName, models.SocialNameError{Code: models.SocialNameErrorReserved}
+	}
+
+	reservedNameService, serviceErr := service.NewReservedNameService(database.Db)
+	if serviceErr != nil {
+		return socialName, serviceErr
+	}
+	reservedName, err := reservedNameService.FindByName(socialName)
+	if err != nil {
+		return socialName, err
+	}
+	if reservedName != nil {
+		return socialName, models.SocialNameError{Code: models.SocialNameErrorReserved}
+	}
+
+	// Old names keep pointing to their previous owner for the protection period
+	socialNameHistoryService, serviceErr := service.NewSocialNameHistoryService(database.Db)
+	if serviceErr != nil {
+		return socialName, serviceErr
+	}
+	protectedHistory, err := socialNameHistoryService.FindProtectedBySocialName(socialName, userId)
+	if err != nil {
+		return socialName, err
+	}
+	if protectedHistory != nil {
+		return socialName, models.SocialNameError{Code: models.SocialNameErrorTaken}
+	}
+
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		return socialName, serviceErr
+	}
+	foundUserChan, errChan := userProfileService.FindBySocialName(socialName)
+	foundUser, err := <-foundUserChan, <-errChan
+	if err != nil {
+		return socialName, err
+	}
+	if foundUser != nil && foundUser.ObjectId != userId {
+		return socialName, models.SocialNameError{Code: models.SocialNameErrorTaken}
+	}
+	return socialName, nil
+}
+
+// CheckSocialNameHandle checks whether a social name can be used
+// @Summary Check social name availability
+// @Description Validate the social name and check it is not reserved or taken. Invalid names are reported as unavailable with the reason code.
+// @Tags profiles
+// @Produce  json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param   name  path     string  true "Social name"
+// @Success 200 {object} models.SocialNameAvailabilityModel
+// @Failure 500 {object} utils.TelarError
+// @Router /social/available/{name} [get]
+func CheckSocialNameHandle(c *fiber.Ctx) error {
+
+	currentUser, _ := c.Locals("user").(types.UserContext)
+
+	socialName, err := checkSocialName(c.Params("name"), currentUser.UserID)
+	if err != nil {
+		socialNameErr, ok := err.(models.SocialNameError)
+		if !ok {
+			log.Error("[CheckSocialNameHandle] checkSocialName %s", err.Error())
+			return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/checkSocialName", "Error happened while checking social name!"))
+		}
+		return c.JSON(models.SocialNameAvailabilityModel{
+			SocialName: socialName,
+			Available:  false,
+			Code:       socialNameErr.Code,
+			Message:    socialNameErr.Error(),
+		})
+	}
+
+	return c.JSON(models.SocialNameAvailabilityModel{
+		SocialName: socialName,
+		Available:  true,
+	})
+}
+
+// CreateReservedNameHandle reserves a social name
+// @Summary Reserve a social name
+// @Description Add a social name to the admin-managed reserved list so users can not pick it
+// @Tags reserved-names
+// @Accept  json
+// @Produce  json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param   body  body     models.CreateReservedNameModel  true "Reserved name model"
+// @Success 200 {object} dto.ReservedName
+// @Failure 400 {object} utils.TelarError
+// @Failure 500 {object} utils.TelarError
+// @Router /dto/reserved-names [post]
+func CreateReservedNameHandle(c *fiber.Ctx) error {
+
+	model := new(models.CreateReservedNameModel)
+	if err := c.BodyParser(model); err != nil {
+		log.Error("[CreateReservedNameHandle] parse CreateReservedNameModel %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseReservedNameModel", "Error happened while parsing model!"))
+	}
+
+	name := normalizeSocialName(model.Name)
+	if name == "" {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("nameRequired", "Name is required!"))
+	}
+
+	// Create service
+	reservedNameService, serviceErr := service.NewReservedNameService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewReservedNameService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/reservedNameService", "Error happened while creating reservedNameService!"))
+	}
+
+	foundReservedName, err := reservedNameService.FindByName(name)
+	if err != nil {
+		log.Error("[CreateReservedNameHandle] FindByName %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findReservedName", "Error happened while finding reserved name!"))
+	}
+	if foundReservedName != nil {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("reservedNameExists", "Name is already reserved!"))
+	}
+
+	currentUser, _ := c.Locals("user").(types.UserContext)
+	reservedName := &dto.ReservedName{
+		Name:      name,
+		Reason:    model.Reason,
+		CreatedBy: currentUser.UserID,
+	}
+	if err := reservedNameService.SaveReservedName(reservedName); err != nil {
+		log.Error("[CreateReservedNameHandle] SaveReservedName %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/saveReservedName", "Error happened while saving reserved name!"))
+	}
+
+	return c.JSON(reservedName)
+}
+
+// QueryReservedNamesHandle gets the admin-managed reserved social names
+// @Summary Query reserved social names
+// @Description Get reserved social names by page. Built-in reserved names are not listed.
+// @Tags reserved-names
+// @Produce  json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param   search  query     string  false "Name prefix"
+// @Param   page  query     int  false "Page number"
+// @Success 200 {array} dto.ReservedName
+// @Failure 400 {object} utils.TelarError
+// @Failure 500 {object} utils.TelarError
+// @Router /dto/reserved-names [get]
+func QueryReservedNamesHandle(c *fiber.Ctx) error {
+
+	query := new(models.ReservedNameQueryModel)
+	if err := parser.QueryParser(c, query); err != nil {
+		log.Error("[QueryReservedNamesHandle] QueryParser %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("queryParser", "Error happened while parsing query!"))
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+
+	// Create service
+	reservedNameService, serviceErr := service.NewReservedNameService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewReservedNameService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/reservedNameService", "Error happened while creating reservedNameService!"))
+	}
+
+	reservedNameList, err := reservedNameService.QueryReservedName(normalizeSocialName(query.Search), query.Page)
+	if err != nil {
+		log.Error("[QueryReservedNamesHandle] QueryReservedName %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/queryReservedName", "Error happened while querying reserved names!"))
+	}
+
+	return c.JSON(reservedNameList)
+}
+
+// DeleteReservedNameHandle releases a reserved social name
+// @Summary Delete reserved social name
+// @Description Remove a social name from the admin-managed reserved list
+// @Tags reserved-names
+// @Produce  json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param   name  path     string  true "Reserved name"
+// @Success 200
+// @Failure 500 {object} utils.TelarError
+// @Router /dto/reserved-names/{name} [delete]
+func DeleteReservedNameHandle(c *fiber.Ctx) error {
+
+	// Create service
+	reservedNameService, serviceErr := service.NewReservedNameService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewReservedNameService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/reservedNameService", "Error happened while creating reservedNameService!"))
+	}
+
+	if err := reservedNameService.DeleteReservedName(normalizeSocialName(c.Params("name"))); err != nil {
+		log.Error("[DeleteReservedNameHandle] DeleteReservedName %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/deleteReservedName", "Error happened while deleting reserved name!"))
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+	"github.com/red-gold/telar-web/micros/profile/config"
+	"github.com/red-gold/telar-web/micros/profile/database"
+	"github.com/red-gold/telar-web/micros/profile/dto"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+	service "github.com/red-gold/telar-web/micros/profile/services"
+)
+
+// ChangeSocialNameHandle godoc
+// @Summary Change social name
+// @Description Rename social name of the current user. Social name can be changed once per cooldown and
+// @Description the old name keeps pointing to the user and can not be claimed by others for the protection period.
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param body body models.ChangeSocialNameModel true "New social name"
+// @Success 200 {object} models.SocialNameAvailabilityModel
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 409 {object} utils.TelarError "Social name is taken or reserved"
+// @Failure 429 {object} utils.TelarError "Social name was changed recently"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /social-name [put]
+func ChangeSocialNameHandle(c *fiber.Ctx) error {
+
+	model := new(models.ChangeSocialNameModel)
+	if err := c.BodyParser(model); err != nil {
+		log.Error("[ChangeSocialNameHandle] parse ChangeSocialNameModel %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseChangeSocialNameModel", "Error happened while parsing model!"))
+	}
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok || currentUser.UserID == uuid.Nil {
+		log.Error("[ChangeSocialNameHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+
+	// Create service
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserProfileService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userProfileService", "Error happened while creating userProfileService!"))
+	}
+
+	foundUserChan, errChan := userProfileService.FindByUserId(currentUser.UserID)
+	foundUser, err := <-foundUserChan, <-errChan
+	if err != nil {
+		log.Error("[ChangeSocialNameHandle] FindByUserId %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findByUserId", "Error happened while finding user profile!"))
+	}
+	if foundUser == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUser", "Error happened while finding user profile!"))
+	}
+
+	socialName, socialNameHistory, err := changeSocialName(foundUser, model.SocialName)
+	if err != nil {
+		return socialNameChangeErrorResponse(c, err)
+	}
+
+	if socialName != foundUser.SocialName {
+		data := struct {
+			SocialName            string `json:"socialName" bson:"socialName"`
+			SocialNameChangedDate int64  `json:"socialNameChangedDate" bson:"socialNameChangedDate"`
+		}{
+			SocialName:            socialName,
+			SocialNameChangedDate: utils.UTCNowUnix(),
+		}
+		if err := userProfileService.UpdateUserProfileById(currentUser.UserID, data); err != nil {
+			if _, ok := err.(models.SocialNameError); ok {
+				return socialNameChangeErrorResponse(c, err)
+			}
+			log.Error("[ChangeSocialNameHandle] UpdateUserProfileById %s", err.Error())
+			return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/updateSocialName", "Error happened while changing social name!"))
+		}
+		saveSocialNameHistory(socialNameHistory)
+		recordProfileRevision(foundUser, data, currentUser.UserID, models.RevisionSourceSocialName)
+	}
+
+	return c.JSON(models.SocialNameAvailabilityModel{
+		SocialName: socialName,
+		Available:  true,
+	})
+}
+
+// GetSocialNameHistoryHandle godoc
+// @Summary Get my previous social names
+// @Description Get previous social names of the current user from newest to oldest
+// @Tags profile
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Success 200 {array} dto.SocialNameHistory
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /social-name/history [get]
+func GetSocialNameHistoryHandle(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok || currentUser.UserID == uuid.Nil {
+		log.Error("[GetSocialNameHistoryHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+
+	// Create service
+	socialNameHistoryService, serviceErr := service.NewSocialNameHistoryService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewSocialNameHistoryService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/socialNameHistoryService", "Error happened while creating socialNameHistoryService!"))
+	}
+
+	socialNameHistoryList, err := socialNameHistoryService.FindByUserId(currentUser.UserID)
+	if err != nil {
+		log.Error("[GetSocialNameHistoryHandle] FindByUserId %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findSocialNameHistory", "Error happened while finding social name history!"))
+	}
+
+	return c.JSON(socialNameHistoryList)
+}
+
+// changeSocialName check the new social name against name rules and rename cooldown.
+// It returns the normalized name and the history of the old name to save once the profile is updated,
+// or nil history when there is no old name to keep.
+func changeSocialName(userProfile *dto.UserProfile, socialName string) (string, *dto.SocialNameHistory, error) {
+	socialName, err := checkSocialName(socialName, userProfile.ObjectId)
+	if err != nil {
+		return socialName, nil, err
+	}
+	// Picking the first social name is not a rename
+	if socialName == userProfile.SocialName || userProfile.SocialName == "" {
+		return socialName, nil, nil
+	}
+
+	// Dates are in milliseconds while the periods are configured in seconds
+	now := utils.UTCNowUnix()
+	if userProfile.SocialNameChangedDate+config.ProfileConfig.SocialNameChangeCooldown*1000 > now {
+		return socialName, nil, models.SocialNameError{Code: models.SocialNameErrorCooldown}
+	}
+
+	return socialName, newSocialNameHistory(userProfile, socialName, now), nil
+}
+
+// newSocialNameHistory create history of the current social name of the user which is replaced by the new name
+func newSocialNameHistory(userProfile *dto.UserProfile, socialName string, now int64) *dto.SocialNameHistory {
+	return &dto.SocialNameHistory{
+		UserId:         userProfile.ObjectId,
+		SocialName:     userProfile.SocialName,
+		ReplacedBy:     socialName,
+		ProtectedUntil: now + config.ProfileConfig.SocialNameProtectionPeriod*1000,
+		CreatedDate:    now,
+	}
+}
+
+// saveSocialNameHistory save the old social name. The rename is already saved so failure is only logged.
+func saveSocialNameHistory(socialNameHistory *dto.SocialNameHistory) {
+	if socialNameHistory == nil {
+		return
+	}
+	socialNameHistoryService, serviceErr := service.NewSocialNameHistoryService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewSocialNameHistoryService %s", serviceErr.Error())
+		return
+	}
+	if err := socialNameHistoryService.SaveSocialNameHistory(socialNameHistory); err != nil {
+		log.Error("[saveSocialNameHistory] SaveSocialNameHistory %s", err.Error())
+	}
+}
+
+// socialNameChangeErrorResponse write response of rejected social name change
+func socialNameChangeErrorResponse(c *fiber.Ctx, err error) error {
+	socialNameErr, ok := err.(models.SocialNameError)
+	if !ok {
+		log.Error("[changeSocialName] %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/checkSocialName", "Error happened while checking social name!"))
+	}
+	switch socialNameErr.Code {
+	case models.SocialNameErrorCooldown:
+		return c.Status(http.StatusTooManyRequests).JSON(utils.Error(socialNameErr.Code, socialNameErr.Error()))
+	case models.SocialNameErrorTaken, models.SocialNameErrorReserved:
+		return c.Status(http.StatusConflict).JSON(utils.Error(socialNameErr.Code, socialNameErr.Error()))
+	}
+	return c.Status(http.StatusBadRequest).JSON(utils.Error(socialNameErr.Code, socialNameErr.Error()))
+}
+
+// findMovedProfile find the current profile of the user who used the social name before
+func findMovedProfile(socialName string) (*dto.UserProfile, error) {
+	socialNameHistoryService, serviceErr := service.NewSocialNameHistoryService(database.Db)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+	socialNameHistory, err := socialNameHistoryService.FindLatestBySocialName(normalizeSocialName(socialName))
+	if err != nil || socialNameHistory == nil {
+		return nil, err
+	}
+
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+	foundUserChan, errChan := userProfileService.FindByUserId(socialNameHistory.UserId)
+	return <-foundUserChan, <-errChan
+}
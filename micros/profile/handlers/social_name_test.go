@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	models "github.com/red-gold/telar-web/micros/profile/models"
+)
+
+func TestValidateSocialName(t *testing.T) {
+	tests := []struct {
+		name       string
+		socialName string
+		wantCode   string
+	}{
+		{"valid", "jane_doe", ""},
+		{"valid with dots and digits", "jane.doe.42", ""},
+		{"min length", "abc", ""},
+		{"max length", strings.Repeat("a", socialNameMaxLength), ""},
+		{"empty", "", models.SocialNameErrorRequired},
+		{"too short", "ab", models.SocialNameErrorLength},
+		{"too long", strings.Repeat("a", socialNameMaxLength+1), models.SocialNameErrorLength},
+		{"upper case", "JaneDoe", models.SocialNameErrorInvalid},
+		{"leading dot", ".jane", models.SocialNameErrorInvalid},
+		{"trailing dot", "jane.", models.SocialNameErrorInvalid},
+		{"double dot", "jane..doe", models.SocialNameErrorInvalid},
+		{"dash", "jane-doe", models.SocialNameErrorInvalid},
+		{"space", "jane doe", models.SocialNameErrorInvalid},
+		{"slash", "jane/doe", models.SocialNameErrorInvalid},
+		{"non ascii", "jané", models.SocialNameErrorInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSocialName(tt.socialName)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Errorf("validateSocialName(%q) = %v, want nil", tt.socialName, err)
+				}
+				return
+			}
+			socialNameErr, ok := err.(models.SocialNameError)
+			if !ok || socialNameErr.Code != tt.wantCode {
+				t.Errorf("validateSocialName(%q) = %v, want %s", tt.socialName, err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestNormalizeSocialName(t *testing.T) {
+	tests := []struct {
+		socialName string
+		want       string
+	}{
+		{"jane", "jane"},
+		{"@Jane.Doe", "jane.doe"},
+		{"  @jane  ", "jane"},
+		{"@@jane", "@jane"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeSocialName(tt.socialName); got != tt.want {
+			t.Errorf("normalizeSocialName(%q) = %q, want %q", tt.socialName, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/pkg/parser"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+	"github.com/red-gold/telar-web/micros/profile/config"
+	"github.com/red-gold/telar-web/micros/profile/database"
+	"github.com/red-gold/telar-web/micros/profile/dto"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+	service "github.com/red-gold/telar-web/micros/profile/services"
+)
+
+const (
+	defaultSuggestionLimit int64 = 10
+	maxSuggestionLimit     int64 = 50
+	// suggestionCandidateLimit is how many people each source gives before ranking
+	suggestionCandidateLimit int64 = 100
+	suggestionNearbyRadius         = 50000
+
+	followedByScore    = 25
+	maxFollowedByScore = 125
+	sameCompanyScore   = 40
+	sameSchoolScore    = 30
+	nearbyScore        = 25
+	activeTodayScore   = 15
+	activeWeekScore    = 8
+
+	activeTodayPeriod int64 = 24 * 60 * 60 * 1000
+	activeWeekPeriod  int64 = 7 * activeTodayPeriod
+)
+
+// GetFollowSuggestionsHandle godoc
+// @Summary Get who to follow
+// @Description Suggest people to follow ranked by followed users who follow them, same company or school,
+// @Description location nearby and recent activity. Each suggestion carries the reasons of its score.
+// @Description Followed, requested, blocked and muted users are left out.
+// @Tags profile
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param limit query int false "Number of suggestions, default 10 and up to 50"
+// @Success 200 {array} models.FollowSuggestionModel
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /suggestions [get]
+func GetFollowSuggestionsHandle(c *fiber.Ctx) error {
+
+	query := new(models.SuggestionQueryModel)
+	if err := parser.QueryParser(c, query); err != nil {
+		log.Error("[GetFollowSuggestionsHandle] QueryParser %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("queryParser", "Error happened while parsing query!"))
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultSuggestionLimit
+	}
+	if query.Limit > maxSuggestionLimit {
+		query.Limit = maxSuggestionLimit
+	}
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok || currentUser.UserID == uuid.Nil {
+		log.Error("[GetFollowSuggestionsHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+
+	// Create service
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserProfileService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userProfileService", "Error happened while creating userProfileService!"))
+	}
+
+	foundUserChan, errChan := userProfileService.FindByUserId(currentUser.UserID)
+	foundUser, err := <-foundUserChan, <-errChan
+	if err != nil {
+		log.Error("[GetFollowSuggestionsHandle] FindByUserId %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findByUserId", "Error happened while finding user profile!"))
+	}
+	if foundUser == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUser", "Error happened while finding user profile!"))
+	}
+
+	viewer := getProfileViewer(c)
+	suggestions, err := findFollowSuggestions(foundUser, viewer, getUserInfoReq(c))
+	if err != nil {
+		log.Error("[GetFollowSuggestionsHandle] findFollowSuggestions %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findFollowSuggestions", "Error happened while finding who to follow!"))
+	}
+	if int64(len(suggestions)) > query.Limit {
+		suggestions = suggestions[:query.Limit]
+	}
+
+	profiles := make([]*dto.UserProfile, 0, len(suggestions))
+	for i := range suggestions {
+		applyProfileVisibility(&suggestions[i].Profile, viewer)
+		profiles = append(profiles, &suggestions[i].Profile)
+	}
+	applyOnlineStatusVisibility(viewer, profiles...)
+	return c.JSON(suggestions)
+}
+
+// findFollowSuggestions gather people from every source and rank them by the score of their reasons
+func findFollowSuggestions(userProfile *dto.UserProfile, viewer profileViewer, userInfoInReq *UserInfoInReq) ([]models.FollowSuggestionModel, error) {
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+	userFollowService, serviceErr := service.NewUserFollowService(database.Db)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	notIncludeUserIds, err := getSuggestionExcludedIds(userProfile.ObjectId)
+	if err != nil {
+		return nil, err
+	}
+	followingIds, err := userFollowService.FindFollowingIds(userProfile.ObjectId)
+	if err != nil {
+		return nil, err
+	}
+	notIncludeUserIds = append(notIncludeUserIds, followingIds...)
+
+	candidates := make(map[uuid.UUID]dto.UserProfile)
+	addCandidates := func(userProfiles []dto.UserProfile) {
+		for _, candidate := range userProfiles {
+			candidates[candidate.ObjectId] = candidate
+		}
+	}
+
+	followedByList, err := userFollowService.FindFollowedByFollowing(followingIds, notIncludeUserIds, suggestionCandidateLimit)
+	if err != nil {
+		return nil, err
+	}
+	followedByMap := make(map[uuid.UUID]models.FollowedByModel)
+	candidateIds := []uuid.UUID{}
+	followerIds := []uuid.UUID{}
+	for _, followedBy := range followedByList {
+		followedByMap[followedBy.UserId] = followedBy
+		candidateIds = append(candidateIds, followedBy.UserId)
+		followerIds = append(followerIds, followedBy.FollowerIds...)
+	}
+	if len(candidateIds) > 0 {
+		userProfiles, err := userProfileService.FindProfileByUserIds(candidateIds)
+		if err != nil {
+			return nil, err
+		}
+		addCandidates(userProfiles)
+	}
+
+	followers := make(map[uuid.UUID]dto.UserProfile)
+	if len(followerIds) > 0 {
+		followerProfiles, err := userProfileService.FindProfileByUserIds(followerIds)
+		if err != nil {
+			return nil, err
+		}
+		for _, follower := range followerProfiles {
+			followers[follower.ObjectId] = follower
+		}
+	}
+
+	sameOrganization, err := userProfileService.FindSameOrganization(userProfile.CompanyName, userProfile.School, notIncludeUserIds, suggestionCandidateLimit)
+	if err != nil {
+		return nil, err
+	}
+	addCandidates(sameOrganization)
+
+	nearbyRadius := float64(suggestionNearbyRadius)
+	if nearbyRadius > config.ProfileConfig.NearbyMaxRadius {
+		nearbyRadius = config.ProfileConfig.NearbyMaxRadius
+	}
+	sharesLocation := userProfile.ShareLocation && userProfile.LiveLocation != nil
+	if sharesLocation {
+		nearby, err := userProfileService.FindNearby(*userProfile.LiveLocation, nearbyRadius, 1, suggestionCandidateLimit, notIncludeUserIds)
+		if err != nil {
+			return nil, err
+		}
+		addCandidates(nearby)
+	}
+
+	now := utils.UTCNowUnix()
+	recentlyActive, err := userProfileService.FindRecentlyActive(now-activeWeekPeriod, notIncludeUserIds, suggestionCandidateLimit)
+	if err != nil {
+		return nil, err
+	}
+	addCandidates(recentlyActive)
+
+	// Activity of people who hide their online status is not shown nor ranked
+	activeIds := []uuid.UUID{}
+	for _, candidate := range candidates {
+		if candidate.LastSeen >= now-activeWeekPeriod {
+			activeIds = append(activeIds, candidate.ObjectId)
+		}
+	}
+	onlineStatusHidden := make(map[uuid.UUID]bool)
+	if len(activeIds) > 0 {
+		onlineStatusHidden, err = getOnlineStatusHiddenUsers(activeIds, userInfoInReq)
+		if err != nil {
+			log.Error("[findFollowSuggestions] getOnlineStatusHiddenUsers %s", err.Error())
+			onlineStatusHidden = make(map[uuid.UUID]bool)
+			for _, userId := range activeIds {
+				onlineStatusHidden[userId] = true
+			}
+		}
+	}
+
+	suggestions := []models.FollowSuggestionModel{}
+	for _, candidate := range candidates {
+		reasons := []models.SuggestionReasonModel{}
+		if followedBy, ok := followedByMap[candidate.ObjectId]; ok {
+			reasons = append(reasons, followedByReason(followedBy, followers))
+		}
+
+		// Details of people who do not share their profile with the viewer are not used
+		if viewer.canView(&candidate, candidate.Permission) {
+			if userProfile.CompanyName != "" && strings.EqualFold(strings.TrimSpace(candidate.CompanyName), strings.TrimSpace(userProfile.CompanyName)) {
+				reasons = append(reasons, models.SuggestionReasonModel{
+					Type:  models.SuggestionReasonSameCompany,
+					Score: sameCompanyScore,
+					Text:  fmt.Sprintf("Works at %s", candidate.CompanyName),
+				})
+			}
+			if userProfile.School != "" && strings.EqualFold(strings.TrimSpace(candidate.School), strings.TrimSpace(userProfile.School)) {
+				reasons = append(reasons, models.SuggestionReasonModel{
+					Type:  models.SuggestionReasonSameSchool,
+					Score: sameSchoolScore,
+					Text:  fmt.Sprintf("Studied at %s", candidate.School),
+				})
+			}
+		}
+
+		if sharesLocation && candidate.ShareLocation && candidate.LiveLocation != nil {
+			distance := distanceKm(*userProfile.LiveLocation, candidate.LiveLocation)
+			if distance*1000 <= nearbyRadius {
+				reasons = append(reasons, models.SuggestionReasonModel{
+					Type:     models.SuggestionReasonNearby,
+					Score:    nearbyScore,
+					Text:     fmt.Sprintf("%.1f km away", distance),
+					Distance: roundCoordinate(distance, 1),
+				})
+			}
+		}
+
+		if !onlineStatusHidden[candidate.ObjectId] {
+			if candidate.LastSeen >= now-activeTodayPeriod {
+				reasons = append(reasons, models.SuggestionReasonModel{
+					Type:  models.SuggestionReasonRecentlyActive,
+					Score: activeTodayScore,
+					Text:  "Active today",
+				})
+			} else if candidate.LastSeen >= now-activeWeekPeriod {
+				reasons = append(reasons, models.SuggestionReasonModel{
+					Type:  models.SuggestionReasonRecentlyActive,
+					Score: activeWeekScore,
+					Text:  "Active this week",
+				})
+			}
+		}
+
+		if len(reasons) == 0 {
+			continue
+		}
+		score := 0
+		for _, reason := range reasons {
+			score += reason.Score
+		}
+		suggestions = append(suggestions, models.FollowSuggestionModel{
+			Profile: candidate,
+			Score:   score,
+			Reasons: reasons,
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		if suggestions[i].Profile.FollowerCount != suggestions[j].Profile.FollowerCount {
+			return suggestions[i].Profile.FollowerCount > suggestions[j].Profile.FollowerCount
+		}
+		return suggestions[i].Profile.ObjectId.String() < suggestions[j].Profile.ObjectId.String()
+	})
+	return suggestions, nil
+}
+
+// getSuggestionExcludedIds get the user, people the user asked to follow and people blocked or muted either way
+func getSuggestionExcludedIds(userId uuid.UUID) ([]uuid.UUID, error) {
+	userFollowService, serviceErr := service.NewUserFollowService(database.Db)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+	userRestrictionService, serviceErr := service.NewUserRestrictionService(database.Db)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	requestedIds, err := userFollowService.FindRequestedIds(userId)
+	if err != nil {
+		return nil, err
+	}
+	restrictions, err := userRestrictionService.FindUserRestrictions(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	excludedIds := []uuid.UUID{userId}
+	excludedIds = append(excludedIds, requestedIds...)
+	excludedIds = append(excludedIds, restrictions.Blocked...)
+	excludedIds = append(excludedIds, restrictions.BlockedBy...)
+	excludedIds = append(excludedIds, restrictions.Muted...)
+	return excludedIds, nil
+}
+
+// followedByReason explain the suggestion by followed users who follow the suggested user
+func followedByReason(followedBy models.FollowedByModel, followers map[uuid.UUID]dto.UserProfile) models.SuggestionReasonModel {
+	score := followedByScore * int(followedBy.Count)
+	if score > maxFollowedByScore {
+		score = maxFollowedByScore
+	}
+
+	reason := models.SuggestionReasonModel{
+		Type:       models.SuggestionReasonFollowedBy,
+		Score:      score,
+		FollowedBy: followedBy.Count,
+		Followers:  []models.SuggestionFollowerModel{},
+	}
+	for _, followerId := range followedBy.FollowerIds {
+		if follower, ok := followers[followerId]; ok {
+			reason.Followers = append(reason.Followers, models.SuggestionFollowerModel{
+				UserId:     follower.ObjectId,
+				FullName:   follower.FullName,
+				SocialName: follower.SocialName,
+				Avatar:     follower.Avatar,
+			})
+		}
+	}
+
+	if len(reason.Followers) == 0 {
+		reason.Text = fmt.Sprintf("Followed by %d people you follow", followedBy.Count)
+		return reason
+	}
+	name := reason.Followers[0].FullName
+	switch others := followedBy.Count - 1; {
+	case others == 0:
+		reason.Text = fmt.Sprintf("Followed by %s", name)
+	case others == 1 && len(reason.Followers) > 1:
+		reason.Text = fmt.Sprintf("Followed by %s and %s", name, reason.Followers[1].FullName)
+	case others == 1:
+		reason.Text = fmt.Sprintf("Followed by %s and 1 other", name)
+	default:
+		reason.Text = fmt.Sprintf("Followed by %s and %d others", name, others)
+	}
+	return reason
+}
@@ -3,21 +3,21 @@ package handlers
 import (
 	"fmt"
 	"net/http"
-	"strconv"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofrs/uuid"
 	"github.com/red-gold/telar-core/pkg/log"
 	"github.com/red-gold/telar-core/types"
 	utils "github.com/red-gold/telar-core/utils"
 	"github.com/red-gold/telar-web/micros/profile/database"
+	"github.com/red-gold/telar-web/micros/profile/dto"
 	models "github.com/red-gold/telar-web/micros/profile/models"
 	service "github.com/red-gold/telar-web/micros/profile/services"
 )
 
 // UpdateProfileHandle updates the user profile
 // @Summary Update user profile
-// @Description Update the profile of the current user
+// @Description Update the profile of the current user. Custom fields in the request are validated against the
+// @Description admin-defined profile fields and merged into the saved ones, empty values clear a field.
 // @Tags profile
 // @Accept json
 // @Produce json
@@ -25,7 +25,8 @@ import (
 // @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
 // @Param body body models.ProfileUpdateModel true "Profile Update Model"
 // @Success 200 {string} string "OK"
-// @Failure 400 {object} utils.TelarError "Invalid current user"
+// @Failure 400 {object} utils.TelarError "Invalid current user or custom field"
+// @Failure 409 {object} utils.TelarError "Social name is taken"
 // @Failure 500 {object} utils.TelarError "Internal server error"
 // @Router / [put]
 func UpdateProfileHandle(c *fiber.Ctx) error {
@@ -54,12 +55,46 @@ func UpdateProfileHandle(c *fiber.Ctx) error {
 			"Can not get current user"))
 	}
 
+	// Profile before update is kept for the profile history
+	foundUserChan, errChan := userProfileService.FindByUserId(currentUser.UserID)
+	foundUser, err := <-foundUserChan, <-errChan
+	if err != nil {
+		log.Error("[UpdateProfileHandle] FindByUserId %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findByUserId", "Error happened while finding user profile!"))
+	}
+	if foundUser == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUser", "Error happened while finding user profile!"))
+	}
+
+	// Social name changes follow the same cooldown and history as the rename endpoint
+	var socialNameHistory *dto.SocialNameHistory
+	if generalModel, ok := model.(*models.ProfileGeneralUpdateModel); ok && generalModel.SocialName != "" {
+		socialName, history, err := changeSocialName(foundUser, generalModel.SocialName)
+		if err != nil {
+			return socialNameChangeErrorResponse(c, err)
+		}
+		generalModel.SocialName = socialName
+		if socialName != foundUser.SocialName {
+			generalModel.SocialNameChangedDate = utils.UTCNowUnix()
+		}
+		socialNameHistory = history
+	}
+
+	if err := setUpdateCustomFields(model, foundUser); err != nil {
+		return profileFieldErrorResponse(c, err)
+	}
+
 	log.Info("Update profile %s - %v", currentUser.UserID, model)
-	err := userProfileService.UpdateUserProfileById(currentUser.UserID, model)
+	err = userProfileService.UpdateUserProfileById(currentUser.UserID, model)
 	if err != nil {
+		if _, ok := err.(models.SocialNameError); ok {
+			return socialNameChangeErrorResponse(c, err)
+		}
 		log.Error("Could not update user profile! %s", err.Error())
 		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userProfileService", "Could not update user profile!"))
 	}
+	saveSocialNameHistory(socialNameHistory)
+	recordProfileRevision(foundUser, model, currentUser.UserID, models.RevisionSourceProfile)
 
 	return c.SendStatus(http.StatusOK)
 
@@ -67,7 +102,7 @@ func UpdateProfileHandle(c *fiber.Ctx) error {
 
 // UpdateLastSeen updates the last seen time of a user
 // @Summary Update last seen time
-// @Description Update the last seen time of a user
+// @Description Send presence heartbeat for a user. Last seen is saved in profile when the user comes online.
 // @Tags profile
 // @Accept json
 // @Produce json
@@ -89,13 +124,7 @@ func UpdateLastSeen(c *fiber.Ctx) error {
 		return c.Status(http.StatusBadRequest).JSON(utils.Error("bodyParserUpdateLastSeenModel", "Could not parse UpdateLastSeenModel!"))
 	}
 
-	// Create service
-	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
-	if serviceErr != nil {
-		return c.Status(http.StatusBadRequest).JSON(utils.Error("internal/userProfileService", "Internal error happened while creating userProfileService!"))
-	}
-
-	err := userProfileService.UpdateLastSeenNow(model.UserId)
+	_, err := setPresence(&UserInfoInReq{UserId: model.UserId}, models.PresenceStatusOnline)
 	if err != nil {
 		errorMessage := fmt.Sprintf("Update last seen %s",
 			err.Error())
@@ -107,115 +136,3 @@ func UpdateLastSeen(c *fiber.Ctx) error {
 	return c.SendStatus(http.StatusOK)
 
 }
-
-// IncreaseFollowCount increases the follow count of a user
-// @Summary Increase follow count
-// @Description Increase the follow count of a user by a specified amount
-// @Tags profile
-// @Produce json
-// @Security JWT
-// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
-// @Param userId path string true "User ID"
-// @Param inc path int true "Increment value"
-// @Success 200 {string} string "OK"
-// @Failure 400 {object} utils.TelarError "Bad request"
-// @Router /follow/inc/{inc}/{userId} [put]
-func IncreaseFollowCount(c *fiber.Ctx) error {
-
-	// params from /follow/inc/:inc/:userId
-	userId := c.Params("userId")
-	if userId == "" {
-		errorMessage := fmt.Sprintf("User Id is required!")
-		log.Error(errorMessage)
-		return c.Status(http.StatusBadRequest).JSON(utils.Error("userIdRequired", errorMessage))
-	}
-
-	userUUID, uuidErr := uuid.FromString(userId)
-	if uuidErr != nil {
-		errorMessage := fmt.Sprintf("UUID Error %s", uuidErr.Error())
-		log.Error(errorMessage)
-		return c.Status(http.StatusBadRequest).JSON(utils.Error("userIdIsNotValid", "Post id is not valid!"))
-	}
-
-	incParam := c.Params("inc")
-	inc, err := strconv.Atoi(incParam)
-	if err != nil {
-		log.Error("Wrong inc param %s - %s", incParam, err.Error())
-		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidIncParam", "Wrong inc param!"))
-
-	}
-
-	// Create service
-	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
-	if serviceErr != nil {
-		return c.Status(http.StatusBadRequest).JSON(utils.Error("internal/userProfileService", "Internal error happened while creating userProfileService!"))
-	}
-
-	err = userProfileService.IncreaseFollowCount(userUUID, inc)
-	if err != nil {
-		errorMessage := fmt.Sprintf("Update follow count %s",
-			err.Error())
-		log.Error(errorMessage)
-		return c.Status(http.StatusBadRequest).JSON(utils.Error("updateFollowCount", "Error happened while updating follow count!"))
-
-	}
-
-	return c.SendStatus(http.StatusOK)
-
-}
-
-// IncreaseFollowerCount increases the follower count of a user
-// @Summary Increase follower count
-// @Description Increase the follower count of a user by a specified amount
-// @Tags profile
-// @Produce json
-// @Security JWT
-// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
-// @Param userId path string true "User ID"
-// @Param inc path int true "Increment value"
-// @Success 200 {string} string "OK"
-// @Failure 400 {object} utils.TelarError "Bad request"
-// @Router /follower/inc/{inc}/{userId} [put]
-func IncreaseFollowerCount(c *fiber.Ctx) error {
-
-	// params from /follower/inc/:inc/:userId
-	userId := c.Params("userId")
-	if userId == "" {
-		errorMessage := fmt.Sprintf("User Id is required!")
-		log.Error(errorMessage)
-		return c.Status(http.StatusBadRequest).JSON(utils.Error("userIdRequired", errorMessage))
-	}
-
-	userUUID, uuidErr := uuid.FromString(userId)
-	if uuidErr != nil {
-		errorMessage := fmt.Sprintf("UUID Error %s", uuidErr.Error())
-		log.Error(errorMessage)
-		return c.Status(http.StatusBadRequest).JSON(utils.Error("userIdIsNotValid", "Post id is not valid!"))
-	}
-
-	incParam := c.Params("inc")
-	inc, err := strconv.Atoi(incParam)
-	if err != nil {
-		log.Error("Wrong inc param %s - %s", incParam, err.Error())
-		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidIncParam", "Wrong inc param!"))
-
-	}
-
-	// Create service
-	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
-	if serviceErr != nil {
-		return c.Status(http.StatusBadRequest).JSON(utils.Error("internal/userProfileService", "Internal error happened while creating userProfileService!"))
-	}
-
-	err = userProfileService.IncreaseFollowerCount(userUUID, inc)
-	if err != nil {
-		errorMessage := fmt.Sprintf("Update follower count %s",
-			err.Error())
-		log.Error(errorMessage)
-		return c.Status(http.StatusBadRequest).JSON(utils.Error("updateFollowerCount", "Error happened while updating follower count!"))
-
-	}
-
-	return c.SendStatus(http.StatusOK)
-
-}
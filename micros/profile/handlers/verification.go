@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/pkg/parser"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+	"github.com/red-gold/telar-web/micros/profile/database"
+	"github.com/red-gold/telar-web/micros/profile/dto"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+	service "github.com/red-gold/telar-web/micros/profile/services"
+)
+
+// CreateVerificationRequestHandle godoc
+// @Summary Request verification
+// @Description Ask admins to verify the current user. Only one request can be pending at a time.
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param body body models.CreateVerificationRequestModel true "Verification request model"
+// @Success 200 {object} dto.VerificationRequest
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 409 {object} utils.TelarError "Already verified or has a pending request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /verification-request [post]
+func CreateVerificationRequestHandle(c *fiber.Ctx) error {
+
+	model := new(models.CreateVerificationRequestModel)
+	if err := c.BodyParser(model); err != nil {
+		log.Error("[CreateVerificationRequestHandle] parse CreateVerificationRequestModel %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseCreateVerificationRequestModel", "Error happened while parsing model!"))
+	}
+	if !models.IsValidVerificationType(model.Type) {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidVerificationType", "Verification type is not valid!"))
+	}
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok || currentUser.UserID == uuid.Nil {
+		log.Error("[CreateVerificationRequestHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+
+	// Create service
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserProfileService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userProfileService", "Error happened while creating userProfileService!"))
+	}
+	verificationRequestService, serviceErr := service.NewVerificationRequestService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewVerificationRequestService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/verificationRequestService", "Error happened while creating verificationRequestService!"))
+	}
+
+	foundUserChan, errChan := userProfileService.FindByUserId(currentUser.UserID)
+	foundUser, err := <-foundUserChan, <-errChan
+	if err != nil {
+		log.Error("[CreateVerificationRequestHandle] FindByUserId %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findByUserId", "Error happened while finding user profile!"))
+	}
+	if foundUser == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUser", "Error happened while finding user profile!"))
+	}
+	if foundUser.Verification != nil {
+		return c.Status(http.StatusConflict).JSON(utils.Error("alreadyVerified", "User is already verified!"))
+	}
+
+	lastRequest, err := verificationRequestService.FindLatestByUserId(currentUser.UserID)
+	if err != nil {
+		log.Error("[CreateVerificationRequestHandle] FindLatestByUserId %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findVerificationRequest", "Error happened while finding verification request!"))
+	}
+	if lastRequest != nil && lastRequest.Status == models.VerificationRequestPending {
+		return c.Status(http.StatusConflict).JSON(utils.Error("pendingVerificationRequest", "Verification request is already pending!"))
+	}
+
+	verificationRequest := &dto.VerificationRequest{
+		UserId:     currentUser.UserID,
+		FullName:   foundUser.FullName,
+		SocialName: foundUser.SocialName,
+		Type:       model.Type,
+		Reason:     model.Reason,
+		Status:     models.VerificationRequestPending,
+	}
+	if err := verificationRequestService.SaveVerificationRequest(verificationRequest); err != nil {
+		log.Error("[CreateVerificationRequestHandle] SaveVerificationRequest %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/saveVerificationRequest", "Error happened while saving verification request!"))
+	}
+
+	return c.JSON(verificationRequest)
+}
+
+// GetMyVerificationRequestHandle godoc
+// @Summary Get my verification request
+// @Description Get the last verification request of the current user
+// @Tags profile
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Success 200 {object} dto.VerificationRequest
+// @Failure 404 {object} utils.TelarError "No verification request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /verification-request [get]
+func GetMyVerificationRequestHandle(c *fiber.Ctx) error {
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok || currentUser.UserID == uuid.Nil {
+		log.Error("[GetMyVerificationRequestHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+
+	// Create service
+	verificationRequestService, serviceErr := service.NewVerificationRequestService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewVerificationRequestService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/verificationRequestService", "Error happened while creating verificationRequestService!"))
+	}
+
+	lastRequest, err := verificationRequestService.FindLatestByUserId(currentUser.UserID)
+	if err != nil {
+		log.Error("[GetMyVerificationRequestHandle] FindLatestByUserId %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findVerificationRequest", "Error happened while finding verification request!"))
+	}
+	if lastRequest == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundVerificationRequest", "Verification request not found!"))
+	}
+
+	return c.JSON(lastRequest)
+}
+
+// GrantVerificationHandle godoc
+// @Summary Grant verified badge
+// @Description Mark the user as verified. Called by admin micro.
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param body body models.GrantVerificationModel true "Verification type"
+// @Success 200 {object} dto.Verification
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 404 {object} utils.TelarError "User not found"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /dto/verification/{userId} [put]
+func GrantVerificationHandle(c *fiber.Ctx) error {
+
+	userUUID, uuidErr := uuid.FromString(c.Params("userId"))
+	if uuidErr != nil {
+		log.Error("[GrantVerificationHandle] Parse UUID %s ", uuidErr.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseUUIDError", "Can not parse user id!"))
+	}
+
+	model := new(models.GrantVerificationModel)
+	if err := c.BodyParser(model); err != nil {
+		log.Error("[GrantVerificationHandle] parse GrantVerificationModel %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseGrantVerificationModel", "Error happened while parsing model!"))
+	}
+	if !models.IsValidVerificationType(model.Type) {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidVerificationType", "Verification type is not valid!"))
+	}
+
+	currentUser, _ := c.Locals("user").(types.UserContext)
+	verification, err := grantVerification(userUUID, model.Type, currentUser.UserID)
+	if err != nil {
+		log.Error("[GrantVerificationHandle] grantVerification %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/grantVerification", "Error happened while granting verification!"))
+	}
+	if verification == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUser", "Error happened while finding user profile!"))
+	}
+
+	return c.JSON(verification)
+}
+
+// RevokeVerificationHandle godoc
+// @Summary Revoke verified badge
+// @Description Remove verified badge of the user. Called by admin micro.
+// @Tags profile
+// @Produce json
+// @Param userId path string true "User ID"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /dto/verification/{userId} [delete]
+func RevokeVerificationHandle(c *fiber.Ctx) error {
+
+	userUUID, uuidErr := uuid.FromString(c.Params("userId"))
+	if uuidErr != nil {
+		log.Error("[RevokeVerificationHandle] Parse UUID %s ", uuidErr.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseUUIDError", "Can not parse user id!"))
+	}
+
+	// Create service
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserProfileService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userProfileService", "Error happened while creating userProfileService!"))
+	}
+
+	if err := userProfileService.SetVerification(userUUID, nil); err != nil {
+		log.Error("[RevokeVerificationHandle] SetVerification %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/revokeVerification", "Error happened while revoking verification!"))
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
+
+// QueryVerificationRequestsHandle godoc
+// @Summary Query verification requests
+// @Description Get verification requests by status, oldest first. Called by admin micro for the review queue.
+// @Tags profile
+// @Produce json
+// @Param status query string false "pending, approved or rejected"
+// @Param page query int false "Page number"
+// @Success 200 {array} dto.VerificationRequest
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /dto/verification-requests [get]
+func QueryVerificationRequestsHandle(c *fiber.Ctx) error {
+
+	query := new(models.VerificationRequestQueryModel)
+	if err := parser.QueryParser(c, query); err != nil {
+		log.Error("[QueryVerificationRequestsHandle] QueryParser %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("queryParser", "Error happened while parsing query!"))
+	}
+
+	// Create service
+	verificationRequestService, serviceErr := service.NewVerificationRequestService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewVerificationRequestService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/verificationRequestService", "Error happened while creating verificationRequestService!"))
+	}
+
+	verificationRequestList, err := verificationRequestService.QueryVerificationRequest(query.Status, query.Page)
+	if err != nil {
+		log.Error("[QueryVerificationRequestsHandle] QueryVerificationRequest %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/queryVerificationRequest", "Error happened while querying verification requests!"))
+	}
+
+	return c.JSON(verificationRequestList)
+}
+
+// ReviewVerificationRequestHandle godoc
+// @Summary Review verification request
+// @Description Approve or reject a pending verification request. Approving grants the verified badge. Called by admin micro.
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param requestId path string true "Verification request ID"
+// @Param body body models.ReviewVerificationRequestModel true "Review model"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 404 {object} utils.TelarError "Request not found"
+// @Failure 409 {object} utils.TelarError "Request is already reviewed"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /dto/verification-requests/{requestId} [put]
+func ReviewVerificationRequestHandle(c *fiber.Ctx) error {
+
+	requestId, uuidErr := uuid.FromString(c.Params("requestId"))
+	if uuidErr != nil {
+		log.Error("[ReviewVerificationRequestHandle] Parse UUID %s ", uuidErr.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseUUIDError", "Can not parse verification request id!"))
+	}
+
+	model := new(models.ReviewVerificationRequestModel)
+	if err := c.BodyParser(model); err != nil {
+		log.Error("[ReviewVerificationRequestHandle] parse ReviewVerificationRequestModel %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseReviewVerificationRequestModel", "Error happened while parsing model!"))
+	}
+	if model.Status != models.VerificationRequestApproved && model.Status != models.VerificationRequestRejected {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidReviewStatus", "Review status should be approved or rejected!"))
+	}
+	if model.Type != "" && !models.IsValidVerificationType(model.Type) {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidVerificationType", "Verification type is not valid!"))
+	}
+
+	// Create service
+	verificationRequestService, serviceErr := service.NewVerificationRequestService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewVerificationRequestService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/verificationRequestService", "Error happened while creating verificationRequestService!"))
+	}
+
+	verificationRequest, err := verificationRequestService.FindById(requestId)
+	if err != nil {
+		log.Error("[ReviewVerificationRequestHandle] FindById %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findVerificationRequest", "Error happened while finding verification request!"))
+	}
+	if verificationRequest == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundVerificationRequest", "Verification request not found!"))
+	}
+	if verificationRequest.Status != models.VerificationRequestPending {
+		return c.Status(http.StatusConflict).JSON(utils.Error("verificationRequestReviewed", "Verification request is already reviewed!"))
+	}
+
+	currentUser, _ := c.Locals("user").(types.UserContext)
+	if model.Status == models.VerificationRequestApproved {
+		verificationType := verificationRequest.Type
+		if model.Type != "" {
+			verificationType = model.Type
+		}
+		if _, err := grantVerification(verificationRequest.UserId, verificationType, currentUser.UserID); err != nil {
+			log.Error("[ReviewVerificationRequestHandle] grantVerification %s", err.Error())
+			return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/grantVerification", "Error happened while granting verification!"))
+		}
+	}
+
+	review := struct {
+		Status       string    `json:"status" bson:"status"`
+		ReviewedBy   uuid.UUID `json:"reviewedBy" bson:"reviewedBy"`
+		ReviewNote   string    `json:"reviewNote" bson:"reviewNote"`
+		ReviewedDate int64     `json:"reviewedDate" bson:"reviewedDate"`
+	}{
+		Status:       model.Status,
+		ReviewedBy:   currentUser.UserID,
+		ReviewNote:   model.Note,
+		ReviewedDate: utils.UTCNowUnix(),
+	}
+	if err := verificationRequestService.UpdateVerificationRequestById(requestId, review); err != nil {
+		log.Error("[ReviewVerificationRequestHandle] UpdateVerificationRequestById %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/updateVerificationRequest", "Error happened while updating verification request!"))
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
+
+// grantVerification set verified badge of the user. Returns nil verification when user does not exist.
+func grantVerification(userId uuid.UUID, verificationType string, grantedBy uuid.UUID) (*dto.Verification, error) {
+
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	foundUserChan, errChan := userProfileService.FindByUserId(userId)
+	foundUser, err := <-foundUserChan, <-errChan
+	if err != nil || foundUser == nil {
+		return nil, err
+	}
+
+	verification := &dto.Verification{
+		Type:      verificationType,
+		GrantedBy: grantedBy,
+		GrantedAt: utils.UTCNowUnix(),
+	}
+	if err := userProfileService.SetVerification(userId, verification); err != nil {
+		return nil, err
+	}
+	return verification, nil
+}
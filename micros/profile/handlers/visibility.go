@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/types"
+	utils "github.com/red-gold/telar-core/utils"
+	"github.com/red-gold/telar-web/constants"
+	"github.com/red-gold/telar-web/micros/profile/database"
+	"github.com/red-gold/telar-web/micros/profile/dto"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+	service "github.com/red-gold/telar-web/micros/profile/services"
+)
+
+// profileViewer is the user who reads a profile
+type profileViewer struct {
+	userId uuid.UUID
+	// fullAccess is given to admins and to functions calling by HMAC without a user
+	fullAccess bool
+	// userInfoInReq is used to read online status setting of profile owners
+	userInfoInReq *UserInfoInReq
+}
+
+// getProfileViewer get viewer of the request
+func getProfileViewer(c *fiber.Ctx) profileViewer {
+	currentUser, _ := c.Locals("user").(types.UserContext)
+	hmacCall := c.Get(types.HeaderHMACAuthenticate) != ""
+	return profileViewer{
+		userId:        currentUser.UserID,
+		fullAccess:    currentUser.SystemRole == "admin" || (hmacCall && currentUser.UserID == uuid.Nil),
+		userInfoInReq: getUserInfoReq(c),
+	}
+}
+
+// isListed check the viewer is in access list of the profile
+func (v profileViewer) isListed(userProfile *dto.UserProfile) bool {
+	return v.userId != uuid.Nil && contains(userProfile.AccessUserList, v.userId.String())
+}
+
+// isOwner check the viewer owns the profile or has full access
+func (v profileViewer) isOwner(userProfile *dto.UserProfile) bool {
+	return v.fullAccess || (v.userId != uuid.Nil && v.userId == userProfile.ObjectId)
+}
+
+// canView check the viewer is allowed by the permission
+func (v profileViewer) canView(userProfile *dto.UserProfile, permission constants.UserPermissionConst) bool {
+	if v.isOwner(userProfile) {
+		return true
+	}
+	switch permission {
+	case constants.OnlyMe:
+		return false
+	case constants.Circles, constants.Custom:
+		return v.isListed(userProfile)
+	default:
+		return true
+	}
+}
+
+// fieldPermission get who can see the field of the profile
+func fieldPermission(userProfile *dto.UserProfile, field string) constants.UserPermissionConst {
+	if permission, ok := userProfile.FieldVisibility[field]; ok {
+		return permission
+	}
+	return models.DefaultFieldVisibility[field]
+}
+
+// applyProfileVisibility remove the fields of the profile which the viewer is not allowed to see.
+// Viewers not allowed by the profile permission get the public view with name, avatar and counters.
+func applyProfileVisibility(userProfile *dto.UserProfile, viewer profileViewer) {
+	if viewer.isOwner(userProfile) {
+		return
+	}
+
+	// Settings of the owner are never shown to others
+	userProfile.AccessUserList = nil
+	userProfile.FieldVisibility = nil
+	userProfile.InvitedBy = uuid.Nil
+	userProfile.LiveLocation = nil
+	userProfile.ShareLocation = false
+	userProfile.LocationDate = 0
+	userProfile.Lang = ""
+
+	if !viewer.canView(userProfile, userProfile.Permission) {
+		*userProfile = dto.UserProfile{
+			ObjectId:      userProfile.ObjectId,
+			FullName:      userProfile.FullName,
+			SocialName:    userProfile.SocialName,
+			Avatar:        userProfile.Avatar,
+			Banner:        userProfile.Banner,
+			TagLine:       userProfile.TagLine,
+			CreatedDate:   userProfile.CreatedDate,
+			FollowCount:   userProfile.FollowCount,
+			FollowerCount: userProfile.FollowerCount,
+			PostCount:     userProfile.PostCount,
+			Permission:    userProfile.Permission,
+			Verification:  userProfile.Verification,
+		}
+		return
+	}
+
+	if !viewer.canView(userProfile, fieldPermission(userProfile, models.VisibilityFieldEmail)) {
+		userProfile.Email = ""
+	}
+	if !viewer.canView(userProfile, fieldPermission(userProfile, models.VisibilityFieldPhone)) {
+		userProfile.Phone = ""
+	}
+	if !viewer.canView(userProfile, fieldPermission(userProfile, models.VisibilityFieldBirthday)) {
+		userProfile.Birthday = 0
+	}
+	if !viewer.canView(userProfile, fieldPermission(userProfile, models.VisibilityFieldAddress)) {
+		userProfile.Address = ""
+	}
+	applyCustomFieldVisibility(userProfile, viewer)
+}
+
+// applyProfileListVisibility remove the fields of each profile which the viewer is not allowed to see
+func applyProfileListVisibility(userProfiles []dto.UserProfile, viewer profileViewer) {
+	profiles := make([]*dto.UserProfile, 0, len(userProfiles))
+	for i := range userProfiles {
+		applyProfileVisibility(&userProfiles[i], viewer)
+		profiles = append(profiles, &userProfiles[i])
+	}
+	applyOnlineStatusVisibility(viewer, profiles...)
+}
+
+// applyOnlineStatusVisibility remove last seen of people who hide their online status from the viewer.
+// Last seen is removed for everyone when the setting can not be read.
+func applyOnlineStatusVisibility(viewer profileViewer, userProfiles ...*dto.UserProfile) {
+	userIds := []uuid.UUID{}
+	for _, userProfile := range userProfiles {
+		if userProfile.LastSeen != 0 && !viewer.isOwner(userProfile) {
+			userIds = append(userIds, userProfile.ObjectId)
+		}
+	}
+	if len(userIds) == 0 {
+		return
+	}
+
+	hiddenUsers, err := getOnlineStatusHiddenUsers(userIds, viewer.userInfoInReq)
+	if err != nil {
+		log.Error("[applyOnlineStatusVisibility] getOnlineStatusHiddenUsers %s", err.Error())
+	}
+	for _, userProfile := range userProfiles {
+		if viewer.isOwner(userProfile) {
+			continue
+		}
+		if err != nil || hiddenUsers[userProfile.ObjectId] {
+			userProfile.LastSeen = 0
+		}
+	}
+}
+
+// isValidPermission check the permission is one of the known permissions
+func isValidPermission(permission constants.UserPermissionConst) bool {
+	switch permission {
+	case constants.OnlyMe, constants.Public, constants.Circles, constants.Custom:
+		return true
+	}
+	return false
+}
+
+// UpdateVisibilityHandle updates who can see the profile of the current user
+// @Summary Update profile visibility
+// @Description Set who can see the profile and each of email, phone, birthday and address fields.
+// @Description Circles and Custom allow the users in access user list.
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security JWT
+// @Param Authorization header string true "Authentication" default(Bearer <Add_token_here>)
+// @Param body body models.ProfileVisibilityModel true "Profile visibility model"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} utils.TelarError "Bad request"
+// @Failure 500 {object} utils.TelarError "Internal server error"
+// @Router /visibility [put]
+func UpdateVisibilityHandle(c *fiber.Ctx) error {
+
+	model := new(models.ProfileVisibilityModel)
+	if err := c.BodyParser(model); err != nil {
+		log.Error("[UpdateVisibilityHandle] parse ProfileVisibilityModel %s", err.Error())
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("parseProfileVisibilityModel", "Error happened while parsing model!"))
+	}
+
+	if !isValidPermission(model.Permission) {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidPermission", "Permission is not valid!"))
+	}
+	for field, permission := range model.FieldVisibility {
+		if _, ok := models.DefaultFieldVisibility[field]; !ok {
+			return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidVisibilityField", "Visibility of "+field+" can not be changed!"))
+		}
+		if !isValidPermission(permission) {
+			return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidPermission", "Permission is not valid!"))
+		}
+	}
+	if model.AccessUserList == nil {
+		model.AccessUserList = []string{}
+	}
+	if model.FieldVisibility == nil {
+		model.FieldVisibility = map[string]constants.UserPermissionConst{}
+	}
+	model.LastUpdated = utils.UTCNowUnix()
+
+	currentUser, ok := c.Locals("user").(types.UserContext)
+	if !ok || currentUser.UserID == uuid.Nil {
+		log.Error("[UpdateVisibilityHandle] Can not get current user")
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidCurrentUser", "Can not get current user"))
+	}
+
+	// Create service
+	userProfileService, serviceErr := service.NewUserProfileService(database.Db)
+	if serviceErr != nil {
+		log.Error("NewUserProfileService %s", serviceErr.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/userProfileService", "Error happened while creating userProfileService!"))
+	}
+
+	foundUserChan, errChan := userProfileService.FindByUserId(currentUser.UserID)
+	foundUser, err := <-foundUserChan, <-errChan
+	if err != nil {
+		log.Error("[UpdateVisibilityHandle] FindByUserId %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/findByUserId", "Error happened while finding user profile!"))
+	}
+	if foundUser == nil {
+		return c.Status(http.StatusNotFound).JSON(utils.Error("notFoundUser", "Error happened while finding user profile!"))
+	}
+
+	if err := userProfileService.UpdateUserProfileById(currentUser.UserID, model); err != nil {
+		log.Error("[UpdateVisibilityHandle] UpdateUserProfileById %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/updateVisibility", "Error happened while updating profile visibility!"))
+	}
+	recordProfileRevision(foundUser, model, currentUser.UserID, models.RevisionSourceVisibility)
+
+	return c.SendStatus(http.StatusOK)
+}
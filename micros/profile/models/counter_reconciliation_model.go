@@ -0,0 +1,22 @@
+package models
+
+// Trigger of a counter reconciliation
+const (
+	ReconcileTriggerAdmin    = "admin"
+	ReconcileTriggerSchedule = "schedule"
+)
+
+// Status of a counter reconciliation
+const (
+	ReconcileStatusRunning   = "running"
+	ReconcileStatusCompleted = "completed"
+	ReconcileStatusFailed    = "failed"
+)
+
+type CounterReconcileModel struct {
+	Fix bool `json:"fix"`
+}
+
+type CounterReconciliationQueryModel struct {
+	Page int64 `query:"page"`
+}
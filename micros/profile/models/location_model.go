@@ -0,0 +1,27 @@
+package models
+
+import "github.com/gofrs/uuid"
+
+type UpdateLocationModel struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type LocationSharingModel struct {
+	Enabled bool `json:"enabled"`
+}
+
+type NearbyQueryModel struct {
+	Radius float64 `query:"radius"`
+	Page   int64   `query:"page"`
+	Limit  int64   `query:"limit"`
+}
+
+type NearbyProfileModel struct {
+	ObjectId   uuid.UUID `json:"objectId"`
+	FullName   string    `json:"fullName"`
+	SocialName string    `json:"socialName"`
+	Avatar     string    `json:"avatar"`
+	TagLine    string    `json:"tagLine"`
+	Distance   float64   `json:"distance"`
+}
@@ -3,6 +3,7 @@ package models
 import (
 	uuid "github.com/gofrs/uuid"
 	"github.com/red-gold/telar-web/constants"
+	"github.com/red-gold/telar-web/micros/profile/dto"
 )
 
 type Location struct {
@@ -11,31 +12,34 @@ type Location struct {
 }
 
 type MyProfileModel struct {
-	ObjectId       uuid.UUID                     `json:"objectId"`
-	FullName       string                        `json:"fullName"`
-	SocialName     string                        `json:"socialName"`
-	Avatar         string                        `json:"avatar"`
-	Banner         string                        `json:"banner"`
-	TagLine        string                        `json:"tagLine"`
-	CreatedDate    int64                         `json:"created_date"`
-	LastUpdated    int64                         `json:"last_updated"`
-	LastSeen       int64                         `json:"lastSeen"`
-	Email          string                        `json:"email"`
-	Birthday       int64                         `json:"birthday"`
-	WebUrl         string                        `json:"webUrl"`
-	CompanyName    string                        `json:"companyName"`
-	Country        string                        `json:"country"`
-	Address        string                        `json:"address"`
-	Phone          string                        `json:"phone"`
-	VoteCount      int64                         `json:"voteCount"`
-	ShareCount     int64                         `json:"shareCount"`
-	FollowCount    int64                         `json:"followCount"`
-	FollowerCount  int64                         `json:"followerCount"`
-	PostCount      int64                         `json:"postCount"`
-	FacebookId     string                        `json:"facebookId"`
-	InstagramId    string                        `json:"instagramId"`
-	TwitterId      string                        `json:"twitterId"`
-	LinkedInId     string                        `json:"linkedInId"`
-	AccessUserList []string                      `json:"accessUserList"`
-	Permission     constants.UserPermissionConst `json:"permission"`
+	ObjectId        uuid.UUID                                `json:"objectId"`
+	FullName        string                                   `json:"fullName"`
+	SocialName      string                                   `json:"socialName"`
+	Avatar          string                                   `json:"avatar"`
+	Banner          string                                   `json:"banner"`
+	TagLine         string                                   `json:"tagLine"`
+	CreatedDate     int64                                    `json:"created_date"`
+	LastUpdated     int64                                    `json:"last_updated"`
+	LastSeen        int64                                    `json:"lastSeen"`
+	Email           string                                   `json:"email"`
+	Birthday        int64                                    `json:"birthday"`
+	WebUrl          string                                   `json:"webUrl"`
+	CompanyName     string                                   `json:"companyName"`
+	Country         string                                   `json:"country"`
+	Address         string                                   `json:"address"`
+	Phone           string                                   `json:"phone"`
+	VoteCount       int64                                    `json:"voteCount"`
+	ShareCount      int64                                    `json:"shareCount"`
+	FollowCount     int64                                    `json:"followCount"`
+	FollowerCount   int64                                    `json:"followerCount"`
+	PostCount       int64                                    `json:"postCount"`
+	FacebookId      string                                   `json:"facebookId"`
+	InstagramId     string                                   `json:"instagramId"`
+	TwitterId       string                                   `json:"twitterId"`
+	LinkedInId      string                                   `json:"linkedInId"`
+	AccessUserList  []string                                 `json:"accessUserList"`
+	Permission      constants.UserPermissionConst            `json:"permission"`
+	FieldVisibility map[string]constants.UserPermissionConst `json:"fieldVisibility,omitempty"`
+	Verification    *dto.Verification                        `json:"verification,omitempty"`
+	CustomFields    map[string]interface{}                   `json:"customFields,omitempty"`
 }
@@ -0,0 +1,39 @@
+package models
+
+import (
+	uuid "github.com/gofrs/uuid"
+)
+
+// Presence status of a user
+const (
+	PresenceStatusOnline  = "online"
+	PresenceStatusAway    = "away"
+	PresenceStatusOffline = "offline"
+)
+
+// Setting which hides online status of the user from others
+const (
+	PresenceSettingType         = "privacy"
+	HideOnlineStatusSettingName = "hide_online_status"
+)
+
+type PresenceModel struct {
+	UserId   uuid.UUID `json:"userId"`
+	Status   string    `json:"status"`
+	LastSeen int64     `json:"lastSeen"`
+	// ExpiresAt is the time the user goes offline without another heartbeat
+	ExpiresAt int64 `json:"-"`
+}
+
+type PresenceHeartbeatModel struct {
+	Status string `json:"status"`
+}
+
+type PresenceQueryModel struct {
+	UserIds []uuid.UUID `json:"userIds"`
+}
+
+type GetSettingsModel struct {
+	UserIds []uuid.UUID `json:"userIds"`
+	Type    string      `json:"type"`
+}
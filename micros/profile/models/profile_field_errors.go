@@ -0,0 +1,75 @@
+package models
+
+// ProfileFieldError is a custom error for rejected field definitions and custom field values
+type ProfileFieldError struct {
+	Code string
+	// Field is the name of the field which is rejected
+	Field string
+}
+
+// Codes of rejected field definitions
+const (
+	ProfileFieldErrorNameInvalid       = "profileFieldNameInvalid"
+	ProfileFieldErrorNameTaken         = "profileFieldNameTaken"
+	ProfileFieldErrorLabelRequired     = "profileFieldLabelRequired"
+	ProfileFieldErrorTypeInvalid       = "profileFieldTypeInvalid"
+	ProfileFieldErrorVisibilityInvalid = "profileFieldVisibilityInvalid"
+	ProfileFieldErrorSearchable        = "profileFieldSearchable"
+	ProfileFieldErrorPatternInvalid    = "profileFieldPatternInvalid"
+	ProfileFieldErrorOptionsRequired   = "profileFieldOptionsRequired"
+	ProfileFieldErrorLimitInvalid      = "profileFieldLimitInvalid"
+)
+
+// Codes of rejected custom field values
+const (
+	CustomFieldErrorUnknown       = "customFieldUnknown"
+	CustomFieldErrorRequired      = "customFieldRequired"
+	CustomFieldErrorType          = "customFieldType"
+	CustomFieldErrorLength        = "customFieldLength"
+	CustomFieldErrorRange         = "customFieldRange"
+	CustomFieldErrorPattern       = "customFieldPattern"
+	CustomFieldErrorOption        = "customFieldOption"
+	CustomFieldErrorNotSearchable = "customFieldNotSearchable"
+)
+
+// Error get message by error code
+func (e ProfileFieldError) Error() string {
+	switch e.Code {
+	case ProfileFieldErrorNameInvalid:
+		return "Field name must start with a letter and contain only letters, numbers and underscores up to 30 characters!"
+	case ProfileFieldErrorNameTaken:
+		return "Field name is already defined!"
+	case ProfileFieldErrorLabelRequired:
+		return "Field label is required!"
+	case ProfileFieldErrorTypeInvalid:
+		return "Field type must be one of text, number, boolean, url, select or list!"
+	case ProfileFieldErrorVisibilityInvalid:
+		return "Field visibility is not valid!"
+	case ProfileFieldErrorSearchable:
+		return "Only public fields can be searchable!"
+	case ProfileFieldErrorPatternInvalid:
+		return "Field pattern is not a valid regular expression!"
+	case ProfileFieldErrorOptionsRequired:
+		return "Select fields need at least one option!"
+	case ProfileFieldErrorLimitInvalid:
+		return "Field minimum can not be more than its maximum!"
+	case CustomFieldErrorUnknown:
+		return "Custom field is not defined!"
+	case CustomFieldErrorRequired:
+		return "Custom field is required!"
+	case CustomFieldErrorType:
+		return "Custom field value does not match the field type!"
+	case CustomFieldErrorLength:
+		return "Custom field value is too short or too long!"
+	case CustomFieldErrorRange:
+		return "Custom field value is out of range!"
+	case CustomFieldErrorPattern:
+		return "Custom field value does not match the field pattern!"
+	case CustomFieldErrorOption:
+		return "Custom field value is not one of the field options!"
+	case CustomFieldErrorNotSearchable:
+		return "Custom field can not be used to filter profiles!"
+	default:
+		return "Unrecognized profile field error code"
+	}
+}
@@ -0,0 +1,48 @@
+package models
+
+import "github.com/red-gold/telar-web/constants"
+
+// Types of admin-defined profile fields
+const (
+	ProfileFieldTypeText    = "text"
+	ProfileFieldTypeNumber  = "number"
+	ProfileFieldTypeBoolean = "boolean"
+	ProfileFieldTypeURL     = "url"
+	ProfileFieldTypeSelect  = "select"
+	// ProfileFieldTypeList is a list of text like skills
+	ProfileFieldTypeList = "list"
+)
+
+// CustomFieldFilterPrefix is the query parameter prefix to filter profiles by a searchable custom field
+const CustomFieldFilterPrefix = "cf."
+
+type ProfileFieldModel struct {
+	Name       string                        `json:"name"`
+	Label      string                        `json:"label"`
+	Type       string                        `json:"type"`
+	Visibility constants.UserPermissionConst `json:"visibility"`
+	Required   bool                          `json:"required"`
+	Searchable bool                          `json:"searchable"`
+	MinLength  int                           `json:"minLength"`
+	MaxLength  int                           `json:"maxLength"`
+	Min        *float64                      `json:"min"`
+	Max        *float64                      `json:"max"`
+	Pattern    string                        `json:"pattern"`
+	Options    []string                      `json:"options"`
+	Order      int                           `json:"order"`
+}
+
+type CheckCustomFieldsModel struct {
+	CustomFields map[string]interface{} `json:"customFields"`
+	// Signup asks for the required fields too
+	Signup bool `json:"signup"`
+}
+
+type CustomFieldsCheckResultModel struct {
+	Valid bool `json:"valid"`
+	// CustomFields are the values as they will be saved when valid
+	CustomFields map[string]interface{} `json:"customFields,omitempty"`
+	Field        string                 `json:"field,omitempty"`
+	Code         string                 `json:"code,omitempty"`
+	Message      string                 `json:"message,omitempty"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// Sources of profile revision
+const (
+	RevisionSourceProfile    = "profile"
+	RevisionSourceSocialName = "socialName"
+	RevisionSourceVisibility = "visibility"
+	RevisionSourceRollback   = "rollback"
+)
+
+type ProfileRevisionQueryModel struct {
+	Page int64 `query:"page"`
+}
@@ -0,0 +1,9 @@
+package models
+
+import "github.com/gofrs/uuid"
+
+type ProfileSearchQueryModel struct {
+	Query      string      `query:"q"`
+	Limit      int64       `query:"limit"`
+	NotInclude []uuid.UUID `query:"nin"`
+}
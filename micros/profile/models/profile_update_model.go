@@ -19,19 +19,25 @@ type ProfileUpdateModel struct {
 	AccessUserList []string                      `json:"accessUserList" bson:"accessUserList"`
 	Permission     constants.UserPermissionConst `json:"permission" bson:"permission"`
 	LastUpdated    int64                         `json:"last_updated" bson:"last_updated"`
+	// CustomFields holds the changed custom fields in request and every custom field of the profile on save
+	CustomFields map[string]interface{} `json:"customFields" bson:"customFields"`
 }
 
 type ProfileGeneralUpdateModel struct {
-	Address     string                        `json:"address" bson:"address"`
-	Avatar      string                        `json:"avatar" bson:"avatar"`
-	Banner      string                        `json:"banner" bson:"banner"`
-	Country     string                        `json:"country" bson:"country"`
-	FullName    string                        `json:"fullName" bson:"fullName"`
-	SocialName  string                        `json:"socialName" bson:"socialName"`
-	Permission  constants.UserPermissionConst `json:"permission" bson:"permission"`
-	Phone       string                        `json:"phone" bson:"phone"`
-	TagLine     string                        `json:"tagLine" bson:"tagLine"`
-	LastUpdated int64                         `json:"last_updated" bson:"last_updated"`
+	Address    string `json:"address" bson:"address"`
+	Avatar     string `json:"avatar" bson:"avatar"`
+	Banner     string `json:"banner" bson:"banner"`
+	Country    string `json:"country" bson:"country"`
+	FullName   string `json:"fullName" bson:"fullName"`
+	SocialName string `json:"socialName" bson:"socialName,omitempty"`
+	// SocialNameChangedDate is set by the service when the social name is renamed
+	SocialNameChangedDate int64                         `json:"-" bson:"socialNameChangedDate,omitempty"`
+	Permission            constants.UserPermissionConst `json:"permission" bson:"permission"`
+	Phone                 string                        `json:"phone" bson:"phone"`
+	TagLine               string                        `json:"tagLine" bson:"tagLine"`
+	LastUpdated           int64                         `json:"last_updated" bson:"last_updated"`
+	// CustomFields holds the changed custom fields in request and every custom field of the profile on save
+	CustomFields map[string]interface{} `json:"customFields" bson:"customFields"`
 }
 
 type SocialInfoUpdateModel struct {
@@ -0,0 +1,26 @@
+package models
+
+import "github.com/red-gold/telar-web/constants"
+
+// Profile fields which visibility is controlled by the owner
+const (
+	VisibilityFieldEmail    = "email"
+	VisibilityFieldPhone    = "phone"
+	VisibilityFieldBirthday = "birthday"
+	VisibilityFieldAddress  = "address"
+)
+
+// DefaultFieldVisibility keeps contact fields private until the owner shares them
+var DefaultFieldVisibility = map[string]constants.UserPermissionConst{
+	VisibilityFieldEmail:    constants.OnlyMe,
+	VisibilityFieldPhone:    constants.OnlyMe,
+	VisibilityFieldBirthday: constants.Public,
+	VisibilityFieldAddress:  constants.Public,
+}
+
+type ProfileVisibilityModel struct {
+	Permission      constants.UserPermissionConst            `json:"permission" bson:"permission"`
+	AccessUserList  []string                                 `json:"accessUserList" bson:"accessUserList"`
+	FieldVisibility map[string]constants.UserPermissionConst `json:"fieldVisibility" bson:"fieldVisibility"`
+	LastUpdated     int64                                    `json:"last_updated" bson:"last_updated"`
+}
@@ -0,0 +1,11 @@
+package models
+
+type CreateReservedNameModel struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+type ReservedNameQueryModel struct {
+	Search string `query:"search"`
+	Page   int64  `query:"page"`
+}
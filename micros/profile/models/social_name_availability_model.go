@@ -0,0 +1,8 @@
+package models
+
+type SocialNameAvailabilityModel struct {
+	SocialName string `json:"socialName"`
+	Available  bool   `json:"available"`
+	Code       string `json:"code,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import uuid "github.com/gofrs/uuid"
+
+type ChangeSocialNameModel struct {
+	SocialName string `json:"socialName"`
+}
+
+// SocialNameMovedModel is the response for an old social name of a user
+type SocialNameMovedModel struct {
+	ObjectId   uuid.UUID `json:"objectId"`
+	SocialName string    `json:"socialName"`
+	MovedTo    string    `json:"movedTo"`
+}
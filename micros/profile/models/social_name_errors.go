@@ -0,0 +1,35 @@
+package models
+
+// SocialNameError is a custom error for rejected social names
+type SocialNameError struct {
+	Code string
+}
+
+const (
+	SocialNameErrorRequired = "socialNameRequired"
+	SocialNameErrorInvalid  = "socialNameInvalid"
+	SocialNameErrorLength   = "socialNameLength"
+	SocialNameErrorReserved = "socialNameReserved"
+	SocialNameErrorTaken    = "socialNameTaken"
+	SocialNameErrorCooldown = "socialNameCooldown"
+)
+
+// Error get message by error code
+func (e SocialNameError) Error() string {
+	switch e.Code {
+	case SocialNameErrorRequired:
+		return "Social name is required!"
+	case SocialNameErrorInvalid:
+		return "Social name can only contain letters, numbers, underscores and single dots between them!"
+	case SocialNameErrorLength:
+		return "Social name must be between 3 and 30 characters!"
+	case SocialNameErrorReserved:
+		return "Social name is reserved!"
+	case SocialNameErrorTaken:
+		return "Social name is already taken!"
+	case SocialNameErrorCooldown:
+		return "Social name was changed recently, please try again later!"
+	default:
+		return "Unrecognized social name error code"
+	}
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-web/micros/profile/dto"
+)
+
+// Reasons a user is suggested to follow
+const (
+	SuggestionReasonFollowedBy     = "followedBy"
+	SuggestionReasonSameCompany    = "sameCompany"
+	SuggestionReasonSameSchool     = "sameSchool"
+	SuggestionReasonNearby         = "nearby"
+	SuggestionReasonRecentlyActive = "recentlyActive"
+)
+
+type SuggestionQueryModel struct {
+	Limit int64 `query:"limit"`
+}
+
+type SuggestionReasonModel struct {
+	Type  string `json:"type"`
+	Score int    `json:"score"`
+	// Text explains the reason like "Followed by Jane and 3 others"
+	Text string `json:"text"`
+	// FollowedBy is the number of followed users who follow the suggested user
+	FollowedBy int64 `json:"followedBy,omitempty"`
+	// Followers are some of the followed users who follow the suggested user
+	Followers []SuggestionFollowerModel `json:"followers,omitempty"`
+	// Distance is in kilometers for nearby reason
+	Distance float64 `json:"distance,omitempty"`
+}
+
+type SuggestionFollowerModel struct {
+	UserId     uuid.UUID `json:"userId"`
+	FullName   string    `json:"fullName"`
+	SocialName string    `json:"socialName"`
+	Avatar     string    `json:"avatar"`
+}
+
+type FollowSuggestionModel struct {
+	Profile dto.UserProfile         `json:"profile"`
+	Score   int                     `json:"score"`
+	Reasons []SuggestionReasonModel `json:"reasons"`
+}
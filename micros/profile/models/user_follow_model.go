@@ -0,0 +1,21 @@
+package models
+
+import uuid "github.com/gofrs/uuid"
+
+// Status of a follow
+const (
+	FollowStatusAccepted = "accepted"
+	FollowStatusPending  = "pending"
+)
+
+type UserFollowQueryModel struct {
+	Page int64 `query:"page"`
+}
+
+// FollowedByModel is a user followed by some of the users another user follows
+type FollowedByModel struct {
+	UserId uuid.UUID `json:"userId" bson:"_id"`
+	Count  int64     `json:"count" bson:"count"`
+	// FollowerIds are some of the followers to explain the count
+	FollowerIds []uuid.UUID `json:"followerIds" bson:"followerIds"`
+}
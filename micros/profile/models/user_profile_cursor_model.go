@@ -0,0 +1,17 @@
+package models
+
+import (
+	"github.com/gofrs/uuid"
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+)
+
+// UserProfileCursorModel is the position of the last profile of a page in created date and object id order
+type UserProfileCursorModel struct {
+	CreatedDate int64     `json:"createdDate"`
+	ObjectId    uuid.UUID `json:"objectId"`
+}
+
+type UserProfileCursorPageModel struct {
+	Profiles   []dto.UserProfile `json:"profiles"`
+	NextCursor string            `json:"nextCursor"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import uuid "github.com/gofrs/uuid"
+
+// Types of user restriction
+const (
+	RestrictionTypeBlock = "block"
+	RestrictionTypeMute  = "mute"
+)
+
+// UserRestrictionListModel is the users one user has restricted or has been blocked by
+type UserRestrictionListModel struct {
+	Blocked   []uuid.UUID `json:"blocked"`
+	Muted     []uuid.UUID `json:"muted"`
+	BlockedBy []uuid.UUID `json:"blockedBy"`
+}
+
+type UserRestrictionQueryModel struct {
+	Page int64 `query:"page"`
+}
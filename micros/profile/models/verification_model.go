@@ -0,0 +1,45 @@
+package models
+
+// Types of verified badge
+const (
+	VerificationTypeOfficial     = "official"
+	VerificationTypeNotable      = "notable"
+	VerificationTypeOrganization = "organization"
+)
+
+// Status of verification request
+const (
+	VerificationRequestPending  = "pending"
+	VerificationRequestApproved = "approved"
+	VerificationRequestRejected = "rejected"
+)
+
+type GrantVerificationModel struct {
+	Type string `json:"type"`
+}
+
+type CreateVerificationRequestModel struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+type ReviewVerificationRequestModel struct {
+	Status string `json:"status"`
+	// Type overrides the requested type when the request is approved
+	Type string `json:"type"`
+	Note string `json:"note"`
+}
+
+type VerificationRequestQueryModel struct {
+	Status string `query:"status"`
+	Page   int64  `query:"page"`
+}
+
+// IsValidVerificationType check the type is one of the verified badge types
+func IsValidVerificationType(verificationType string) bool {
+	switch verificationType {
+	case VerificationTypeOfficial, VerificationTypeNotable, VerificationTypeOrganization:
+		return true
+	}
+	return false
+}
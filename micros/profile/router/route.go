@@ -69,15 +69,61 @@ func SetupRoutes(app *fiber.App) {
 	app.Get("/", append(hmacCookieHandlers, handlers.QueryUserProfileHandle)...)
 	app.Get("/id/:userId", append(hmacCookieHandlers, handlers.ReadProfileHandle)...)
 	app.Get("/social/:name", append(hmacCookieHandlers, handlers.GetBySocialName)...)
+	app.Get("/social/available/:name", append(hmacCookieHandlers, handlers.CheckSocialNameHandle)...)
+	app.Put("/social-name", append(hmacCookieHandlers, handlers.ChangeSocialNameHandle)...)
+	app.Get("/social-name/history", append(hmacCookieHandlers, handlers.GetSocialNameHistoryHandle)...)
+	app.Get("/history", append(hmacCookieHandlers, handlers.GetMyProfileHistoryHandle)...)
+	app.Get("/search", append(hmacCookieHandlers, handlers.SearchProfileHandle)...)
+	app.Get("/nearby", append(hmacCookieHandlers, handlers.NearbyProfilesHandle)...)
+	app.Put("/location", append(hmacCookieHandlers, handlers.UpdateLocationHandle)...)
+	app.Put("/location/sharing", append(hmacCookieHandlers, handlers.UpdateLocationSharingHandle)...)
+	app.Put("/visibility", append(hmacCookieHandlers, handlers.UpdateVisibilityHandle)...)
+	app.Put("/presence", append(hmacCookieHandlers, handlers.HeartbeatHandle)...)
+	app.Delete("/presence", append(hmacCookieHandlers, handlers.GoOfflineHandle)...)
+	app.Post("/presence/query", append(hmacCookieHandlers, handlers.QueryPresenceHandle)...)
+	app.Post("/follow/:userId", append(hmacCookieHandlers, handlers.FollowUserHandle)...)
+	app.Delete("/follow/:userId", append(hmacCookieHandlers, handlers.UnfollowUserHandle)...)
+	app.Get("/followers/:userId", append(hmacCookieHandlers, handlers.GetFollowersHandle)...)
+	app.Get("/following/:userId", append(hmacCookieHandlers, handlers.GetFollowingHandle)...)
+	app.Get("/follow-requests", append(hmacCookieHandlers, handlers.GetFollowRequestsHandle)...)
+	app.Put("/follow-requests/:userId", append(hmacCookieHandlers, handlers.AcceptFollowRequestHandle)...)
+	app.Delete("/follow-requests/:userId", append(hmacCookieHandlers, handlers.RejectFollowRequestHandle)...)
+	app.Get("/block", append(hmacCookieHandlers, handlers.GetBlockedUsersHandle)...)
+	app.Post("/block/:userId", append(hmacCookieHandlers, handlers.BlockUserHandle)...)
+	app.Delete("/block/:userId", append(hmacCookieHandlers, handlers.UnblockUserHandle)...)
+	app.Get("/mute", append(hmacCookieHandlers, handlers.GetMutedUsersHandle)...)
+	app.Post("/mute/:userId", append(hmacCookieHandlers, handlers.MuteUserHandle)...)
+	app.Delete("/mute/:userId", append(hmacCookieHandlers, handlers.UnmuteUserHandle)...)
+	app.Post("/verification-request", append(hmacCookieHandlers, handlers.CreateVerificationRequestHandle)...)
+	app.Get("/verification-request", append(hmacCookieHandlers, handlers.GetMyVerificationRequestHandle)...)
+	app.Get("/fields", append(hmacCookieHandlers, handlers.GetProfileFieldsHandle)...)
+	app.Get("/suggestions", append(hmacCookieHandlers, handlers.GetFollowSuggestionsHandle)...)
 	app.Post("/index", authHMACMiddleware(false), handlers.InitProfileIndexHandle)
 	app.Put("/last-seen", authHMACMiddleware(false), handlers.UpdateLastSeen)
 
 	// Invoke between functions and protected by HMAC
 	app.Put("/", authHMACMiddleware(false), handlers.UpdateProfileHandle)
 	app.Get("/dto/id/:userId", authHMACMiddleware(false), handlers.ReadDtoProfileHandle)
+	app.Get("/dto/restrictions/:userId", authHMACMiddleware(false), handlers.ReadDtoUserRestrictionsHandle)
 	app.Post("/dto", authHMACMiddleware(false), handlers.CreateDtoProfileHandle)
 	app.Post("/dispatch", authHMACMiddleware(false), handlers.DispatchProfilesHandle)
 	app.Post("/dto/ids", authHMACMiddleware(false), handlers.GetProfileByIds)
-	app.Put("/follow/inc/:inc/:userId", authHMACMiddleware(false), handlers.IncreaseFollowCount)
-	app.Put("/follower/inc/:inc/:userId", authHMACMiddleware(false), handlers.IncreaseFollowerCount)
+	app.Put("/follow/inc/:inc/:userId", authHMACMiddleware(false), handlers.RemovedFollowCountHandle)
+	app.Put("/follower/inc/:inc/:userId", authHMACMiddleware(false), handlers.RemovedFollowCountHandle)
+	app.Post("/dto/reserved-names", authHMACMiddleware(false), handlers.CreateReservedNameHandle)
+	app.Get("/dto/reserved-names", authHMACMiddleware(false), handlers.QueryReservedNamesHandle)
+	app.Delete("/dto/reserved-names/:name", authHMACMiddleware(false), handlers.DeleteReservedNameHandle)
+	app.Put("/dto/verification/:userId", authHMACMiddleware(false), handlers.GrantVerificationHandle)
+	app.Delete("/dto/verification/:userId", authHMACMiddleware(false), handlers.RevokeVerificationHandle)
+	app.Get("/dto/verification-requests", authHMACMiddleware(false), handlers.QueryVerificationRequestsHandle)
+	app.Put("/dto/verification-requests/:requestId", authHMACMiddleware(false), handlers.ReviewVerificationRequestHandle)
+	app.Get("/dto/revisions/:userId", authHMACMiddleware(false), handlers.QueryDtoProfileRevisionsHandle)
+	app.Post("/dto/revisions/:revisionId/rollback", authHMACMiddleware(false), handlers.RollbackProfileRevisionHandle)
+	app.Post("/dto/counters/reconcile", authHMACMiddleware(false), handlers.ReconcileCountersHandle)
+	app.Get("/dto/counters/reconciliations", authHMACMiddleware(false), handlers.QueryCounterReconciliationsHandle)
+	app.Get("/dto/counters/reconciliations/:reconciliationId", authHMACMiddleware(false), handlers.ReadCounterReconciliationHandle)
+	app.Post("/dto/fields", authHMACMiddleware(false), handlers.CreateProfileFieldHandle)
+	app.Post("/dto/fields/check", authHMACMiddleware(false), handlers.CheckCustomFieldsHandle)
+	app.Put("/dto/fields/:name", authHMACMiddleware(false), handlers.UpdateProfileFieldHandle)
+	app.Delete("/dto/fields/:name", authHMACMiddleware(false), handlers.DeleteProfileFieldHandle)
 }
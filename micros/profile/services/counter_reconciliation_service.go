@@ -0,0 +1,237 @@
+package service
+
+import (
+	"fmt"
+
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/config"
+	coreData "github.com/red-gold/telar-core/data"
+	"github.com/red-gold/telar-core/data/mongodb"
+	mongoRepo "github.com/red-gold/telar-core/data/mongodb"
+	"github.com/red-gold/telar-core/utils"
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// counterSource is the collection a profile counter is counted from.
+// Post, vote and share collections are written by the social micros on the same database.
+type counterSource struct {
+	field      string
+	collection string
+	userField  string
+	filter     bson.M
+}
+
+var counterSources = []counterSource{
+	{field: "followCount", collection: userFollowCollectionName, userField: "followerId", filter: bson.M{"status": models.FollowStatusAccepted}},
+	{field: "followerCount", collection: userFollowCollectionName, userField: "followingId", filter: bson.M{"status": models.FollowStatusAccepted}},
+	{field: "postCount", collection: "post", userField: "ownerUserId", filter: bson.M{"deleted": bson.M{"$ne": true}}},
+	{field: "voteCount", collection: "vote", userField: "ownerUserId", filter: bson.M{}},
+	{field: "shareCount", collection: "share", userField: "ownerUserId", filter: bson.M{}},
+}
+
+// CounterReconciliationService handlers with injected dependencies
+type CounterReconciliationServiceImpl struct {
+	CounterReconciliationRepo coreData.Repository
+	CounterReconciliationDb   mongodb.MongoDatabase
+}
+
+// NewCounterReconciliationService initializes CounterReconciliationService's dependencies and create new CounterReconciliationService struct
+func NewCounterReconciliationService(db interface{}) (CounterReconciliationService, error) {
+
+	counterReconciliationService := &CounterReconciliationServiceImpl{}
+
+	switch *config.AppConfig.DBType {
+	case config.DB_MONGO:
+
+		mongodb := db.(mongodb.MongoDatabase)
+		counterReconciliationService.CounterReconciliationRepo = mongoRepo.NewDataRepositoryMongo(mongodb)
+		counterReconciliationService.CounterReconciliationDb = mongodb
+
+	}
+	if counterReconciliationService.CounterReconciliationRepo == nil {
+		fmt.Printf("counterReconciliationService.CounterReconciliationRepo is nil! \n")
+	}
+	return counterReconciliationService, nil
+}
+
+// SaveCounterReconciliation save counter reconciliation
+func (s CounterReconciliationServiceImpl) SaveCounterReconciliation(counterReconciliation *dto.CounterReconciliation) error {
+
+	if counterReconciliation.ObjectId == uuid.Nil {
+		var uuidErr error
+		counterReconciliation.ObjectId, uuidErr = uuid.NewV4()
+		if uuidErr != nil {
+			return uuidErr
+		}
+	}
+
+	if counterReconciliation.CreatedDate == 0 {
+		counterReconciliation.CreatedDate = utils.UTCNowUnix()
+	}
+
+	result := <-s.CounterReconciliationRepo.Save(counterReconciliationCollectionName, counterReconciliation)
+
+	return result.Error
+}
+
+// UpdateCounterReconciliation save the result of counter reconciliation
+func (s CounterReconciliationServiceImpl) UpdateCounterReconciliation(counterReconciliation *dto.CounterReconciliation) error {
+
+	filter := struct {
+		ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	}{
+		ObjectId: counterReconciliation.ObjectId,
+	}
+	updateOperator := coreData.UpdateOperator{
+		Set: counterReconciliation,
+	}
+
+	result := <-s.CounterReconciliationRepo.Update(counterReconciliationCollectionName, filter, updateOperator)
+	return result.Error
+}
+
+// FindOneCounterReconciliation get one counter reconciliation
+func (s CounterReconciliationServiceImpl) FindOneCounterReconciliation(filter interface{}) (*dto.CounterReconciliation, error) {
+
+	result := <-s.CounterReconciliationRepo.FindOne(counterReconciliationCollectionName, filter)
+	if result.Error() != nil {
+		if result.Error() == coreData.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, result.Error()
+	}
+
+	var counterReconciliationResult dto.CounterReconciliation
+	errDecode := result.Decode(&counterReconciliationResult)
+	if errDecode != nil {
+		return nil, fmt.Errorf("Error docoding on dto.CounterReconciliation")
+	}
+	return &counterReconciliationResult, nil
+}
+
+// FindCounterReconciliationList get all counter reconciliations by filter
+func (s CounterReconciliationServiceImpl) FindCounterReconciliationList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.CounterReconciliation, error) {
+
+	result := <-s.CounterReconciliationRepo.Find(counterReconciliationCollectionName, filter, limit, skip, sort)
+	defer result.Close()
+	if result.Error() != nil {
+		return nil, result.Error()
+	}
+	counterReconciliationList := []dto.CounterReconciliation{}
+	for result.Next() {
+		var counterReconciliation dto.CounterReconciliation
+		errDecode := result.Decode(&counterReconciliation)
+		if errDecode != nil {
+			return nil, fmt.Errorf("Error docoding on dto.CounterReconciliation")
+		}
+		counterReconciliationList = append(counterReconciliationList, counterReconciliation)
+	}
+
+	return counterReconciliationList, nil
+}
+
+// FindById find counter reconciliation by id
+func (s CounterReconciliationServiceImpl) FindById(objectId uuid.UUID) (*dto.CounterReconciliation, error) {
+
+	filter := struct {
+		ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	}{
+		ObjectId: objectId,
+	}
+	return s.FindOneCounterReconciliation(filter)
+}
+
+// FindRunningSince find a counter reconciliation which started at or after the date and is still running
+func (s CounterReconciliationServiceImpl) FindRunningSince(createdDate int64) (*dto.CounterReconciliation, error) {
+
+	filter := map[string]interface{}{
+		"status":       models.ReconcileStatusRunning,
+		"created_date": map[string]interface{}{"$gte": createdDate},
+	}
+	return s.FindOneCounterReconciliation(filter)
+}
+
+// QueryCounterReconciliation get counter reconciliations by page from newest to oldest
+func (s CounterReconciliationServiceImpl) QueryCounterReconciliation(page int64) ([]dto.CounterReconciliation, error) {
+
+	sortMap := make(map[string]int)
+	sortMap["created_date"] = -1
+	skip := numberOfItems * (page - 1)
+	return s.FindCounterReconciliationList(struct{}{}, numberOfItems, skip, sortMap)
+}
+
+// CountCounterSources count the source documents of each profile counter by user.
+// Counters whose source collection does not exist, and follow counters before the user follow backfill,
+// are returned as skipped so they are not reset to zero.
+func (s CounterReconciliationServiceImpl) CountCounterSources() (map[string]map[uuid.UUID]int64, []string, error) {
+	if s.CounterReconciliationDb == nil {
+		return nil, nil, fmt.Errorf("counter reconciliation is not supported by %s database", *config.AppConfig.DBType)
+	}
+
+	db, err := s.CounterReconciliationDb.GetDb()
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, err := s.CounterReconciliationDb.GetContext()
+	if err != nil {
+		return nil, nil, err
+	}
+	collectionNames, err := db.ListCollectionNames(ctx, bson.M{})
+	if err != nil {
+		return nil, nil, err
+	}
+	existingCollections := make(map[string]bool)
+	for _, collectionName := range collectionNames {
+		existingCollections[collectionName] = true
+	}
+
+	// Follows are not the source of follow counters until circles relations are copied to them
+	followBackfilled, err := db.Collection(profileMigrationCollectionName).CountDocuments(ctx, bson.M{"name": userFollowBackfillMigration})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	counts := make(map[string]map[uuid.UUID]int64)
+	skippedCounters := []string{}
+	for _, source := range counterSources {
+		if !existingCollections[source.collection] || (source.collection == userFollowCollectionName && followBackfilled == 0) {
+			skippedCounters = append(skippedCounters, source.field)
+			continue
+		}
+		sourceCounts, err := s.countBy(source)
+		if err != nil {
+			return nil, nil, fmt.Errorf("count %s: %s", source.field, err.Error())
+		}
+		counts[source.field] = sourceCounts
+	}
+	return counts, skippedCounters, nil
+}
+
+// countBy count documents of the source collection by user
+func (s CounterReconciliationServiceImpl) countBy(source counterSource) (map[uuid.UUID]int64, error) {
+	pipeline := []bson.M{
+		{"$match": source.filter},
+		{"$group": bson.M{"_id": "$" + source.userField, "count": bson.M{"$sum": 1}}},
+	}
+
+	result := <-s.CounterReconciliationRepo.Aggregate(source.collection, pipeline)
+	defer result.Close()
+	if result.Error() != nil {
+		return nil, result.Error()
+	}
+
+	counts := make(map[uuid.UUID]int64)
+	for result.Next() {
+		var userCount struct {
+			UserId uuid.UUID `bson:"_id"`
+			Count  int64     `bson:"count"`
+		}
+		if err := result.Decode(&userCount); err != nil {
+			return nil, fmt.Errorf("Error docoding on %s count", source.collection)
+		}
+		counts[userCount.UserId] = userCount.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	uuid "github.com/gofrs/uuid"
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+)
+
+type CounterReconciliationService interface {
+	SaveCounterReconciliation(counterReconciliation *dto.CounterReconciliation) error
+	UpdateCounterReconciliation(counterReconciliation *dto.CounterReconciliation) error
+	FindOneCounterReconciliation(filter interface{}) (*dto.CounterReconciliation, error)
+	FindCounterReconciliationList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.CounterReconciliation, error)
+	FindById(objectId uuid.UUID) (*dto.CounterReconciliation, error)
+	FindRunningSince(createdDate int64) (*dto.CounterReconciliation, error)
+	QueryCounterReconciliation(page int64) ([]dto.CounterReconciliation, error)
+	CountCounterSources() (map[string]map[uuid.UUID]int64, []string, error)
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	uuid "github.com/gofrs/uuid"
+	profileConfig "github.com/red-gold/telar-web/micros/profile/config"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+)
+
+// PresenceService keeps presence of users until their heartbeat expires
+type PresenceService interface {
+	SetPresence(presence *models.PresenceModel) (*models.PresenceModel, error)
+	FindPresence(userIds []uuid.UUID) (map[uuid.UUID]models.PresenceModel, error)
+	DeletePresence(userId uuid.UUID) (*models.PresenceModel, error)
+	PopExpiredPresence(now int64) ([]models.PresenceModel, error)
+}
+
+// NewPresenceService create presence service on Redis when redis address is set, otherwise in memory
+func NewPresenceService() (PresenceService, error) {
+	if profileConfig.ProfileConfig.RedisAddress != "" {
+		return newRedisPresenceService()
+	}
+	return memoryPresence, nil
+}
@@ -0,0 +1,15 @@
+package service
+
+import (
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+)
+
+type ProfileFieldService interface {
+	SaveProfileField(profileField *dto.ProfileField) error
+	FindOneProfileField(filter interface{}) (*dto.ProfileField, error)
+	FindProfileFieldList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.ProfileField, error)
+	FindByName(name string) (*dto.ProfileField, error)
+	FindAllProfileFields() ([]dto.ProfileField, error)
+	UpdateProfileField(name string, data interface{}) error
+	DeleteProfileField(name string) error
+}
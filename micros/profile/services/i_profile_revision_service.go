@@ -0,0 +1,15 @@
+package service
+
+import (
+	uuid "github.com/gofrs/uuid"
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+)
+
+type ProfileRevisionService interface {
+	SaveProfileRevision(profileRevision *dto.ProfileRevision) error
+	FindOneProfileRevision(filter interface{}) (*dto.ProfileRevision, error)
+	FindProfileRevisionList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.ProfileRevision, error)
+	FindById(objectId uuid.UUID) (*dto.ProfileRevision, error)
+	QueryProfileRevision(userId uuid.UUID, page int64) ([]dto.ProfileRevision, error)
+	FindSince(userId uuid.UUID, createdDate int64) ([]dto.ProfileRevision, error)
+}
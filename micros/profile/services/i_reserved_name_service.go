@@ -0,0 +1,14 @@
+package service
+
+import (
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+)
+
+type ReservedNameService interface {
+	SaveReservedName(reservedName *dto.ReservedName) error
+	FindOneReservedName(filter interface{}) (*dto.ReservedName, error)
+	FindReservedNameList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.ReservedName, error)
+	FindByName(name string) (*dto.ReservedName, error)
+	QueryReservedName(search string, page int64) ([]dto.ReservedName, error)
+	DeleteReservedName(name string) error
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	uuid "github.com/gofrs/uuid"
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+)
+
+type SocialNameHistoryService interface {
+	SaveSocialNameHistory(socialNameHistory *dto.SocialNameHistory) error
+	FindSocialNameHistoryList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.SocialNameHistory, error)
+	FindLatestBySocialName(socialName string) (*dto.SocialNameHistory, error)
+	FindProtectedBySocialName(socialName string, notUserId uuid.UUID) (*dto.SocialNameHistory, error)
+	FindByUserId(userId uuid.UUID) ([]dto.SocialNameHistory, error)
+}
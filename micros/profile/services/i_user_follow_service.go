@@ -0,0 +1,27 @@
+package service
+
+import (
+	uuid "github.com/gofrs/uuid"
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+)
+
+type UserFollowService interface {
+	CreateUserFollowIndex() error
+	SaveUserFollow(userFollow *dto.UserFollow) (bool, error)
+	FindOneUserFollow(filter interface{}) (*dto.UserFollow, error)
+	FindUserFollowList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.UserFollow, error)
+	FindUserFollow(followerId uuid.UUID, followingId uuid.UUID) (*dto.UserFollow, error)
+	QueryFollowers(userId uuid.UUID, status string, page int64) ([]dto.UserFollow, error)
+	QueryFollowing(userId uuid.UUID, page int64) ([]dto.UserFollow, error)
+	FindFollowerIds(userId uuid.UUID) ([]uuid.UUID, error)
+	FindFollowingIds(userId uuid.UUID) ([]uuid.UUID, error)
+	FindRequestedIds(userId uuid.UUID) ([]uuid.UUID, error)
+	FindFollowedByFollowing(followingIds []uuid.UUID, notIncludeUserIDList []uuid.UUID, limit int64) ([]models.FollowedByModel, error)
+	AcceptUserFollow(followerId uuid.UUID, followingId uuid.UUID) (bool, error)
+	DeleteUserFollow(followerId uuid.UUID, followingId uuid.UUID) (*dto.UserFollow, error)
+	CountFollowing(userId uuid.UUID) (int64, error)
+	CountFollowers(userId uuid.UUID) (int64, error)
+	IsUserFollowBackfilled() (bool, error)
+	BackfillUserFollows() (int64, error)
+}
@@ -3,23 +3,39 @@ package service
 import (
 	uuid "github.com/gofrs/uuid"
 	dto "github.com/red-gold/telar-web/micros/profile/dto"
+	models "github.com/red-gold/telar-web/micros/profile/models"
 )
 
 type UserProfileService interface {
 	SaveUserProfile(userProfile *dto.UserProfile) error
 	FindOneUserProfile(filter interface{}) (chan *dto.UserProfile, chan error)
 	FindUserProfileList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.UserProfile, error)
-	QueryUserProfile(search string, sortBy string, page int64, notIncludeUserIDList []uuid.UUID) ([]dto.UserProfile, error)
+	QueryUserProfile(search string, customFields map[string]interface{}, sortBy string, page int64, notIncludeUserIDList []uuid.UUID) ([]dto.UserProfile, error)
+	QueryUserProfileAfter(search string, customFields map[string]interface{}, after *models.UserProfileCursorModel, limit int64, notIncludeUserIDList []uuid.UUID) ([]dto.UserProfile, *models.UserProfileCursorModel, error)
+	SearchUserProfile(search string, customFields map[string]interface{}, followingIds []uuid.UUID, limit int64, notIncludeUserIDList []uuid.UUID) ([]dto.UserProfile, error)
 	FindProfileByUserIds(userIds []uuid.UUID) ([]dto.UserProfile, error)
 	FindByUserId(userId uuid.UUID) (chan *dto.UserProfile, chan error)
 	FindBySocialName(socialName string) (chan *dto.UserProfile, chan error)
 	UpdateUserProfile(filter interface{}, data interface{}) error
 	UpdateLastSeenNow(userId uuid.UUID) error
+	UpdateLastSeen(userId uuid.UUID, lastSeen int64) error
 	UpdateUserProfileById(userId uuid.UUID, data interface{}) error
 	DeleteUserProfile(filter interface{}) error
 	DeleteManyUserProfile(filter interface{}) error
 	FindByUsername(username string) (chan *dto.UserProfile, chan error)
 	CreateUserProfileIndex(indexes map[string]interface{}) error
+	CreateUniqueIndex(field string) error
+	CreateSearchIndex() error
+	BackfillSearchFields() (int64, error)
+	UpdateLiveLocation(userId uuid.UUID, location dto.Location) error
+	SetLocationSharing(userId uuid.UUID, enabled bool) error
+	SetVerification(userId uuid.UUID, verification *dto.Verification) error
+	UnsetCustomField(name string) error
+	FindSameOrganization(companyName string, school string, notIncludeUserIDList []uuid.UUID, limit int64) ([]dto.UserProfile, error)
+	FindRecentlyActive(since int64, notIncludeUserIDList []uuid.UUID, limit int64) ([]dto.UserProfile, error)
+	FindNearby(location dto.Location, radius float64, page int64, limit int64, notIncludeUserIDList []uuid.UUID) ([]dto.UserProfile, error)
+	RemoveInvalidLiveLocation() error
+	SetCounter(objectId uuid.UUID, field string, value int64) error
 	IncreaseFollowCount(objectId uuid.UUID, inc int) error
 	IncreaseFollowerCount(objectId uuid.UUID, inc int) error
 }
@@ -0,0 +1,19 @@
+package service
+
+import (
+	uuid "github.com/gofrs/uuid"
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+)
+
+type UserRestrictionService interface {
+	CreateUserRestrictionIndex() error
+	SaveUserRestriction(userRestriction *dto.UserRestriction) (bool, error)
+	FindOneUserRestriction(filter interface{}) (*dto.UserRestriction, error)
+	FindUserRestrictionList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.UserRestriction, error)
+	FindUserRestriction(ownerUserId uuid.UUID, targetUserId uuid.UUID, restrictionType string) (*dto.UserRestriction, error)
+	QueryUserRestriction(ownerUserId uuid.UUID, restrictionType string, page int64) ([]dto.UserRestriction, error)
+	FindUserRestrictions(userId uuid.UUID) (*models.UserRestrictionListModel, error)
+	IsBlocked(userId uuid.UUID, otherUserId uuid.UUID) (bool, error)
+	DeleteUserRestriction(ownerUserId uuid.UUID, targetUserId uuid.UUID, restrictionType string) error
+}
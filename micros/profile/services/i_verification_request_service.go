@@ -0,0 +1,17 @@
+package service
+
+import (
+	uuid "github.com/gofrs/uuid"
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+)
+
+type VerificationRequestService interface {
+	SaveVerificationRequest(verificationRequest *dto.VerificationRequest) error
+	FindOneVerificationRequest(filter interface{}) (*dto.VerificationRequest, error)
+	FindVerificationRequestList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.VerificationRequest, error)
+	FindById(objectId uuid.UUID) (*dto.VerificationRequest, error)
+	FindLatestByUserId(userId uuid.UUID) (*dto.VerificationRequest, error)
+	QueryVerificationRequest(status string, page int64) ([]dto.VerificationRequest, error)
+	UpdateVerificationRequest(filter interface{}, data interface{}) error
+	UpdateVerificationRequestById(objectId uuid.UUID, data interface{}) error
+}
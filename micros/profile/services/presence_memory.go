@@ -0,0 +1,79 @@
+package service
+
+import (
+	"sync"
+
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/utils"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+)
+
+// memoryPresence is shared by all requests of the instance
+var memoryPresence = &MemoryPresenceServiceImpl{
+	presenceList: make(map[uuid.UUID]models.PresenceModel),
+}
+
+// MemoryPresenceServiceImpl keeps presence in the memory of one instance
+type MemoryPresenceServiceImpl struct {
+	mutex        sync.Mutex
+	presenceList map[uuid.UUID]models.PresenceModel
+}
+
+// SetPresence save presence and return the previous presence if it is not expired
+func (s *MemoryPresenceServiceImpl) SetPresence(presence *models.PresenceModel) (*models.PresenceModel, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous := s.findPresence(presence.UserId, utils.UTCNowUnix())
+	s.presenceList[presence.UserId] = *presence
+	return previous, nil
+}
+
+// FindPresence get presence of users which is not expired
+func (s *MemoryPresenceServiceImpl) FindPresence(userIds []uuid.UUID) (map[uuid.UUID]models.PresenceModel, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := utils.UTCNowUnix()
+	presenceList := make(map[uuid.UUID]models.PresenceModel)
+	for _, userId := range userIds {
+		if presence := s.findPresence(userId, now); presence != nil {
+			presenceList[userId] = *presence
+		}
+	}
+	return presenceList, nil
+}
+
+// DeletePresence remove presence and return it if it is not expired
+func (s *MemoryPresenceServiceImpl) DeletePresence(userId uuid.UUID) (*models.PresenceModel, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous := s.findPresence(userId, utils.UTCNowUnix())
+	delete(s.presenceList, userId)
+	return previous, nil
+}
+
+// PopExpiredPresence remove and return presence which expired before now
+func (s *MemoryPresenceServiceImpl) PopExpiredPresence(now int64) ([]models.PresenceModel, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	expiredList := []models.PresenceModel{}
+	for userId, presence := range s.presenceList {
+		if presence.ExpiresAt <= now {
+			expiredList = append(expiredList, presence)
+			delete(s.presenceList, userId)
+		}
+	}
+	return expiredList, nil
+}
+
+// findPresence get presence of the user if it is not expired at the time
+func (s *MemoryPresenceServiceImpl) findPresence(userId uuid.UUID, now int64) *models.PresenceModel {
+	presence, ok := s.presenceList[userId]
+	if !ok || presence.ExpiresAt <= now {
+		return nil
+	}
+	return &presence
+}
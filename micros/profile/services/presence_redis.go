@@ -0,0 +1,206 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/go-redis/redis"
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/utils"
+	profileConfig "github.com/red-gold/telar-web/micros/profile/config"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+)
+
+const (
+	// presenceHashKey keeps presence of each user by user id
+	presenceHashKey = "presence"
+	// presenceExpiryKey is a sorted set of user ids by the time their presence expires
+	presenceExpiryKey = "presence:expiry"
+)
+
+var (
+	redisClient     *redis.Client
+	redisClientOnce sync.Once
+)
+
+// RedisPresenceServiceImpl keeps presence in Redis to share it between instances
+type RedisPresenceServiceImpl struct {
+	Client *redis.Client
+}
+
+// redisPresence is presence as it is stored in Redis
+type redisPresence struct {
+	Status    string `json:"status"`
+	LastSeen  int64  `json:"lastSeen"`
+	ExpiresAt int64  `json:"expiresAt"`
+}
+
+// newRedisPresenceService connect to Redis once for the instance
+func newRedisPresenceService() (PresenceService, error) {
+	redisClientOnce.Do(func() {
+		redisPassword, redisErr := utils.ReadSecret("redis-pwd")
+		if redisErr != nil {
+			log.Error("[newRedisPresenceService] Read redis-pwd secret %s", redisErr.Error())
+		}
+		redisClient = redis.NewClient(&redis.Options{
+			Addr:     profileConfig.ProfileConfig.RedisAddress,
+			Password: redisPassword,
+			DB:       0,
+		})
+	})
+	return &RedisPresenceServiceImpl{Client: redisClient}, nil
+}
+
+// SetPresence save presence and return the previous presence if it is not expired
+func (s *RedisPresenceServiceImpl) SetPresence(presence *models.PresenceModel) (*models.PresenceModel, error) {
+	previous, err := s.findPresence(presence.UserId, utils.UTCNowUnix())
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(redisPresence{
+		Status:    presence.Status,
+		LastSeen:  presence.LastSeen,
+		ExpiresAt: presence.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := s.Client.TxPipeline()
+	pipe.HSet(presenceHashKey, presence.UserId.String(), data)
+	pipe.ZAdd(presenceExpiryKey, redis.Z{Score: float64(presence.ExpiresAt), Member: presence.UserId.String()})
+	if _, err := pipe.Exec(); err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// FindPresence get presence of users which is not expired
+func (s *RedisPresenceServiceImpl) FindPresence(userIds []uuid.UUID) (map[uuid.UUID]models.PresenceModel, error) {
+	presenceList := make(map[uuid.UUID]models.PresenceModel)
+	if len(userIds) == 0 {
+		return presenceList, nil
+	}
+
+	fields := make([]string, 0, len(userIds))
+	for _, userId := range userIds {
+		fields = append(fields, userId.String())
+	}
+	values, err := s.Client.HMGet(presenceHashKey, fields...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	now := utils.UTCNowUnix()
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		presence, err := parseRedisPresence(userIds[i], data)
+		if err != nil {
+			return nil, err
+		}
+		if presence.ExpiresAt > now {
+			presenceList[userIds[i]] = *presence
+		}
+	}
+	return presenceList, nil
+}
+
+// DeletePresence remove presence and return it if it is not expired
+func (s *RedisPresenceServiceImpl) DeletePresence(userId uuid.UUID) (*models.PresenceModel, error) {
+	previous, err := s.findPresence(userId, utils.UTCNowUnix())
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := s.Client.TxPipeline()
+	pipe.HDel(presenceHashKey, userId.String())
+	pipe.ZRem(presenceExpiryKey, userId.String())
+	if _, err := pipe.Exec(); err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// PopExpiredPresence remove and return presence which expired before now.
+// Each expired presence is returned by only one instance, the one which removes it from the expiry set.
+func (s *RedisPresenceServiceImpl) PopExpiredPresence(now int64) ([]models.PresenceModel, error) {
+	members, err := s.Client.ZRangeByScore(presenceExpiryKey, redis.ZRangeBy{
+		Min: "-inf",
+		Max: fmt.Sprintf("%d", now),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	expiredList := []models.PresenceModel{}
+	for _, member := range members {
+		removed, err := s.Client.ZRem(presenceExpiryKey, member).Result()
+		if err != nil {
+			return nil, err
+		}
+		if removed == 0 {
+			continue
+		}
+
+		userId, err := uuid.FromString(member)
+		if err != nil {
+			continue
+		}
+		presence, err := s.findPresence(userId, 0)
+		if err != nil {
+			return nil, err
+		}
+		if presence == nil {
+			continue
+		}
+
+		// A heartbeat came after the expiry set was read so the presence is kept
+		if presence.ExpiresAt > now {
+			s.Client.ZAdd(presenceExpiryKey, redis.Z{Score: float64(presence.ExpiresAt), Member: member})
+			continue
+		}
+		s.Client.HDel(presenceHashKey, member)
+		expiredList = append(expiredList, *presence)
+	}
+	return expiredList, nil
+}
+
+// findPresence get presence of the user if it is not expired at the time
+func (s *RedisPresenceServiceImpl) findPresence(userId uuid.UUID, now int64) (*models.PresenceModel, error) {
+	data, err := s.Client.HGet(presenceHashKey, userId.String()).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	presence, err := parseRedisPresence(userId, data)
+	if err != nil {
+		return nil, err
+	}
+	if presence.ExpiresAt <= now {
+		return nil, nil
+	}
+	return presence, nil
+}
+
+// parseRedisPresence read presence of the user stored in Redis
+func parseRedisPresence(userId uuid.UUID, data string) (*models.PresenceModel, error) {
+	var stored redisPresence
+	if err := json.Unmarshal([]byte(data), &stored); err != nil {
+		return nil, err
+	}
+	return &models.PresenceModel{
+		UserId:    userId,
+		Status:    stored.Status,
+		LastSeen:  stored.LastSeen,
+		ExpiresAt: stored.ExpiresAt,
+	}, nil
+}
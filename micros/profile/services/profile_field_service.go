@@ -0,0 +1,152 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/config"
+	coreData "github.com/red-gold/telar-core/data"
+	"github.com/red-gold/telar-core/data/mongodb"
+	mongoRepo "github.com/red-gold/telar-core/data/mongodb"
+	"github.com/red-gold/telar-core/utils"
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+)
+
+// ProfileFieldService handlers with injected dependencies
+type ProfileFieldServiceImpl struct {
+	ProfileFieldRepo coreData.Repository
+}
+
+// NewProfileFieldService initializes ProfileFieldService's dependencies and create new ProfileFieldService struct
+func NewProfileFieldService(db interface{}) (ProfileFieldService, error) {
+
+	profileFieldService := &ProfileFieldServiceImpl{}
+
+	switch *config.AppConfig.DBType {
+	case config.DB_MONGO:
+
+		mongodb := db.(mongodb.MongoDatabase)
+		profileFieldService.ProfileFieldRepo = mongoRepo.NewDataRepositoryMongo(mongodb)
+
+	}
+	if profileFieldService.ProfileFieldRepo == nil {
+		fmt.Printf("profileFieldService.ProfileFieldRepo is nil! \n")
+	}
+	return profileFieldService, nil
+}
+
+// SaveProfileField save profile field definition
+func (s ProfileFieldServiceImpl) SaveProfileField(profileField *dto.ProfileField) error {
+
+	if profileField.ObjectId == uuid.Nil {
+		var uuidErr error
+		profileField.ObjectId, uuidErr = uuid.NewV4()
+		if uuidErr != nil {
+			return uuidErr
+		}
+	}
+
+	if profileField.CreatedDate == 0 {
+		profileField.CreatedDate = utils.UTCNowUnix()
+	}
+	profileField.LastUpdated = profileField.CreatedDate
+
+	result := <-s.ProfileFieldRepo.Save(profileFieldCollectionName, profileField)
+
+	return result.Error
+}
+
+// FindOneProfileField get one profile field definition
+func (s ProfileFieldServiceImpl) FindOneProfileField(filter interface{}) (*dto.ProfileField, error) {
+
+	result := <-s.ProfileFieldRepo.FindOne(profileFieldCollectionName, filter)
+	if result.Error() != nil {
+		if result.Error() == coreData.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, result.Error()
+	}
+
+	var profileFieldResult dto.ProfileField
+	errDecode := result.Decode(&profileFieldResult)
+	if errDecode != nil {
+		return nil, fmt.Errorf("Error docoding on dto.ProfileField")
+	}
+	return &profileFieldResult, nil
+}
+
+// FindProfileFieldList get all profile field definitions by filter
+func (s ProfileFieldServiceImpl) FindProfileFieldList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.ProfileField, error) {
+
+	result := <-s.ProfileFieldRepo.Find(profileFieldCollectionName, filter, limit, skip, sort)
+	defer result.Close()
+	if result.Error() != nil {
+		return nil, result.Error()
+	}
+	var profileFieldList []dto.ProfileField
+	for result.Next() {
+		var profileField dto.ProfileField
+		errDecode := result.Decode(&profileField)
+		if errDecode != nil {
+			return nil, fmt.Errorf("Error docoding on dto.ProfileField")
+		}
+		profileFieldList = append(profileFieldList, profileField)
+	}
+
+	return profileFieldList, nil
+}
+
+// FindByName find profile field definition by name
+func (s ProfileFieldServiceImpl) FindByName(name string) (*dto.ProfileField, error) {
+
+	filter := struct {
+		Name string `json:"name" bson:"name"`
+	}{
+		Name: name,
+	}
+	return s.FindOneProfileField(filter)
+}
+
+// FindAllProfileFields get every profile field definition by order and then by name
+func (s ProfileFieldServiceImpl) FindAllProfileFields() ([]dto.ProfileField, error) {
+
+	profileFieldList, err := s.FindProfileFieldList(struct{}{}, 0, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	if profileFieldList == nil {
+		profileFieldList = []dto.ProfileField{}
+	}
+	sort.SliceStable(profileFieldList, func(i, j int) bool {
+		if profileFieldList[i].Order != profileFieldList[j].Order {
+			return profileFieldList[i].Order < profileFieldList[j].Order
+		}
+		return profileFieldList[i].Name < profileFieldList[j].Name
+	})
+	return profileFieldList, nil
+}
+
+// UpdateProfileField update profile field definition by name
+func (s ProfileFieldServiceImpl) UpdateProfileField(name string, data interface{}) error {
+
+	filter := struct {
+		Name string `json:"name" bson:"name"`
+	}{
+		Name: name,
+	}
+	result := <-s.ProfileFieldRepo.Update(profileFieldCollectionName, filter, data)
+	return result.Error
+}
+
+// DeleteProfileField delete profile field definition by name
+func (s ProfileFieldServiceImpl) DeleteProfileField(name string) error {
+
+	filter := struct {
+		Name string `json:"name" bson:"name"`
+	}{
+		Name: name,
+	}
+	result := <-s.ProfileFieldRepo.Delete(profileFieldCollectionName, filter, true)
+	return result.Error
+}
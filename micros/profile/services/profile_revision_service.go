@@ -0,0 +1,163 @@
+package service
+
+import (
+	"fmt"
+
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/config"
+	coreData "github.com/red-gold/telar-core/data"
+	"github.com/red-gold/telar-core/data/mongodb"
+	mongoRepo "github.com/red-gold/telar-core/data/mongodb"
+	"github.com/red-gold/telar-core/utils"
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProfileRevisionService handlers with injected dependencies
+type ProfileRevisionServiceImpl struct {
+	ProfileRevisionRepo coreData.Repository
+}
+
+// NewProfileRevisionService initializes ProfileRevisionService's dependencies and create new ProfileRevisionService struct
+func NewProfileRevisionService(db interface{}) (ProfileRevisionService, error) {
+
+	profileRevisionService := &ProfileRevisionServiceImpl{}
+
+	switch *config.AppConfig.DBType {
+	case config.DB_MONGO:
+
+		mongodb := db.(mongodb.MongoDatabase)
+		profileRevisionService.ProfileRevisionRepo = mongoRepo.NewDataRepositoryMongo(mongodb)
+
+	}
+	if profileRevisionService.ProfileRevisionRepo == nil {
+		fmt.Printf("profileRevisionService.ProfileRevisionRepo is nil! \n")
+	}
+	return profileRevisionService, nil
+}
+
+// SaveProfileRevision save profile revision
+func (s ProfileRevisionServiceImpl) SaveProfileRevision(profileRevision *dto.ProfileRevision) error {
+
+	if profileRevision.ObjectId == uuid.Nil {
+		var uuidErr error
+		profileRevision.ObjectId, uuidErr = uuid.NewV4()
+		if uuidErr != nil {
+			return uuidErr
+		}
+	}
+
+	if profileRevision.CreatedDate == 0 {
+		profileRevision.CreatedDate = utils.UTCNowUnix()
+	}
+
+	result := <-s.ProfileRevisionRepo.Save(profileRevisionCollectionName, profileRevision)
+
+	return result.Error
+}
+
+// FindOneProfileRevision get one profile revision
+func (s ProfileRevisionServiceImpl) FindOneProfileRevision(filter interface{}) (*dto.ProfileRevision, error) {
+
+	result := <-s.ProfileRevisionRepo.FindOne(profileRevisionCollectionName, filter)
+	if result.Error() != nil {
+		if result.Error() == coreData.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, result.Error()
+	}
+
+	var profileRevisionResult dto.ProfileRevision
+	errDecode := result.Decode(&profileRevisionResult)
+	if errDecode != nil {
+		return nil, fmt.Errorf("Error docoding on dto.ProfileRevision")
+	}
+	normalizeChanges(profileRevisionResult.Changes)
+	return &profileRevisionResult, nil
+}
+
+// FindProfileRevisionList get all profile revisions by filter
+func (s ProfileRevisionServiceImpl) FindProfileRevisionList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.ProfileRevision, error) {
+
+	result := <-s.ProfileRevisionRepo.Find(profileRevisionCollectionName, filter, limit, skip, sort)
+	defer result.Close()
+	if result.Error() != nil {
+		return nil, result.Error()
+	}
+	var profileRevisionList []dto.ProfileRevision
+	for result.Next() {
+		var profileRevision dto.ProfileRevision
+		errDecode := result.Decode(&profileRevision)
+		if errDecode != nil {
+			return nil, fmt.Errorf("Error docoding on dto.ProfileRevision")
+		}
+		normalizeChanges(profileRevision.Changes)
+		profileRevisionList = append(profileRevisionList, profileRevision)
+	}
+
+	return profileRevisionList, nil
+}
+
+// FindById find profile revision by id
+func (s ProfileRevisionServiceImpl) FindById(objectId uuid.UUID) (*dto.ProfileRevision, error) {
+
+	filter := struct {
+		ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	}{
+		ObjectId: objectId,
+	}
+	return s.FindOneProfileRevision(filter)
+}
+
+// QueryProfileRevision get revisions of the user profile by page from newest to oldest
+func (s ProfileRevisionServiceImpl) QueryProfileRevision(userId uuid.UUID, page int64) ([]dto.ProfileRevision, error) {
+
+	sortMap := make(map[string]int)
+	sortMap["created_date"] = -1
+	skip := numberOfItems * (page - 1)
+	filter := struct {
+		UserId uuid.UUID `json:"userId" bson:"userId"`
+	}{
+		UserId: userId,
+	}
+	return s.FindProfileRevisionList(filter, numberOfItems, skip, sortMap)
+}
+
+// FindSince get revisions of the user profile created at or after the date from newest to oldest
+func (s ProfileRevisionServiceImpl) FindSince(userId uuid.UUID, createdDate int64) ([]dto.ProfileRevision, error) {
+
+	sortMap := make(map[string]int)
+	sortMap["created_date"] = -1
+	filter := map[string]interface{}{
+		"userId":       userId,
+		"created_date": map[string]interface{}{"$gte": createdDate},
+	}
+	return s.FindProfileRevisionList(filter, 0, 0, sortMap)
+}
+
+// normalizeChanges decode embedded documents of changed values as maps instead of ordered key-value pairs
+func normalizeChanges(changes []dto.ProfileFieldChange) {
+	for i := range changes {
+		changes[i].Before = normalizeStoredValue(changes[i].Before)
+		changes[i].After = normalizeStoredValue(changes[i].After)
+	}
+}
+
+// normalizeStoredValue convert embedded documents in the value to maps
+func normalizeStoredValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case primitive.D:
+		document := bson.M{}
+		for _, element := range typedValue {
+			document[element.Key] = normalizeStoredValue(element.Value)
+		}
+		return document
+	case primitive.A:
+		for i := range typedValue {
+			typedValue[i] = normalizeStoredValue(typedValue[i])
+		}
+		return typedValue
+	}
+	return value
+}
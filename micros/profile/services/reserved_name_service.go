@@ -0,0 +1,133 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/config"
+	coreData "github.com/red-gold/telar-core/data"
+	"github.com/red-gold/telar-core/data/mongodb"
+	mongoRepo "github.com/red-gold/telar-core/data/mongodb"
+	"github.com/red-gold/telar-core/utils"
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+)
+
+// ReservedNameService handlers with injected dependencies
+type ReservedNameServiceImpl struct {
+	ReservedNameRepo coreData.Repository
+}
+
+// NewReservedNameService initializes ReservedNameService's dependencies and create new ReservedNameService struct
+func NewReservedNameService(db interface{}) (ReservedNameService, error) {
+
+	reservedNameService := &ReservedNameServiceImpl{}
+
+	switch *config.AppConfig.DBType {
+	case config.DB_MONGO:
+
+		mongodb := db.(mongodb.MongoDatabase)
+		reservedNameService.ReservedNameRepo = mongoRepo.NewDataRepositoryMongo(mongodb)
+
+	}
+	if reservedNameService.ReservedNameRepo == nil {
+		fmt.Printf("reservedNameService.ReservedNameRepo is nil! \n")
+	}
+	return reservedNameService, nil
+}
+
+// SaveReservedName save reserved social name
+func (s ReservedNameServiceImpl) SaveReservedName(reservedName *dto.ReservedName) error {
+
+	if reservedName.ObjectId == uuid.Nil {
+		var uuidErr error
+		reservedName.ObjectId, uuidErr = uuid.NewV4()
+		if uuidErr != nil {
+			return uuidErr
+		}
+	}
+
+	if reservedName.CreatedDate == 0 {
+		reservedName.CreatedDate = utils.UTCNowUnix()
+	}
+
+	result := <-s.ReservedNameRepo.Save(reservedNameCollectionName, reservedName)
+
+	return result.Error
+}
+
+// FindOneReservedName get one reserved social name
+func (s ReservedNameServiceImpl) FindOneReservedName(filter interface{}) (*dto.ReservedName, error) {
+
+	result := <-s.ReservedNameRepo.FindOne(reservedNameCollectionName, filter)
+	if result.Error() != nil {
+		if result.Error() == coreData.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, result.Error()
+	}
+
+	var reservedNameResult dto.ReservedName
+	errDecode := result.Decode(&reservedNameResult)
+	if errDecode != nil {
+		return nil, fmt.Errorf("Error docoding on dto.ReservedName")
+	}
+	return &reservedNameResult, nil
+}
+
+// FindReservedNameList get all reserved social names by filter
+func (s ReservedNameServiceImpl) FindReservedNameList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.ReservedName, error) {
+
+	result := <-s.ReservedNameRepo.Find(reservedNameCollectionName, filter, limit, skip, sort)
+	defer result.Close()
+	if result.Error() != nil {
+		return nil, result.Error()
+	}
+	var reservedNameList []dto.ReservedName
+	for result.Next() {
+		var reservedName dto.ReservedName
+		errDecode := result.Decode(&reservedName)
+		if errDecode != nil {
+			return nil, fmt.Errorf("Error docoding on dto.ReservedName")
+		}
+		reservedNameList = append(reservedNameList, reservedName)
+	}
+
+	return reservedNameList, nil
+}
+
+// FindByName find reserved social name by name
+func (s ReservedNameServiceImpl) FindByName(name string) (*dto.ReservedName, error) {
+
+	filter := struct {
+		Name string `json:"name" bson:"name"`
+	}{
+		Name: name,
+	}
+	return s.FindOneReservedName(filter)
+}
+
+// QueryReservedName get reserved social names by page. Search matches the start of the name.
+func (s ReservedNameServiceImpl) QueryReservedName(search string, page int64) ([]dto.ReservedName, error) {
+
+	sortMap := make(map[string]int)
+	sortMap["name"] = 1
+	skip := numberOfItems * (page - 1)
+	filter := make(map[string]interface{})
+	if search != "" {
+		filter["name"] = map[string]interface{}{"$regex": "^" + regexp.QuoteMeta(search)}
+	}
+	return s.FindReservedNameList(filter, numberOfItems, skip, sortMap)
+}
+
+// DeleteReservedName delete reserved social name by name
+func (s ReservedNameServiceImpl) DeleteReservedName(name string) error {
+
+	filter := struct {
+		Name string `json:"name" bson:"name"`
+	}{
+		Name: name,
+	}
+	result := <-s.ReservedNameRepo.Delete(reservedNameCollectionName, filter, true)
+	return result.Error
+}
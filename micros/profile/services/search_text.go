@@ -0,0 +1,150 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// searchKeyLength is the prefix length of search tokens which typo keys are made of
+const searchKeyLength = 4
+
+// foldText lower case the text and remove diacritics so "José" and "jose" are equal
+func foldText(text string) string {
+	folder := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(folder, text)
+	if err != nil {
+		folded = text
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case 'ø', 'Ø':
+			return 'o'
+		case 'ł', 'Ł':
+			return 'l'
+		case 'đ', 'Đ':
+			return 'd'
+		case 'ı':
+			return 'i'
+		}
+		return unicode.ToLower(r)
+	}, folded)
+}
+
+// searchWords split folded text into words
+func searchWords(folded string) []string {
+	return strings.FieldsFunc(folded, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '.' || r == '_' || r == '-'
+	})
+}
+
+// searchTokens get folded words of the full name and social name and the whole social name
+func searchTokens(fullName, socialName string) []string {
+	foldedSocialName := foldText(socialName)
+	words := append(searchWords(foldText(fullName)), searchWords(foldedSocialName)...)
+	if foldedSocialName != "" {
+		words = append(words, foldedSocialName)
+	}
+	return uniqueStrings(words)
+}
+
+// searchKeys get the typo keys of every search token
+func searchKeys(tokens []string) []string {
+	keys := []string{}
+	for _, token := range tokens {
+		keys = append(keys, typoKeys(token)...)
+	}
+	return uniqueStrings(keys)
+}
+
+// typoKeys get the word prefix and the prefix with one letter deleted.
+// Two words which are one edit apart in their prefix share at least one key.
+func typoKeys(word string) []string {
+	prefix := []rune(word)
+	if len(prefix) > searchKeyLength {
+		prefix = prefix[:searchKeyLength]
+	}
+	keys := []string{string(prefix)}
+	for i := range prefix {
+		deleted := string(prefix[:i]) + string(prefix[i+1:])
+		if deleted != "" {
+			keys = append(keys, deleted)
+		}
+	}
+	return uniqueStrings(keys)
+}
+
+// uniqueStrings remove repeated values and keep the order
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool)
+	unique := []string{}
+	for _, value := range values {
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		unique = append(unique, value)
+	}
+	return unique
+}
+
+// allowedTypos is the number of typos tolerated for a search word of the given length
+func allowedTypos(length int) int {
+	switch {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// prefixDistance is the edit distance between the search word and the closest prefix of the word
+func prefixDistance(search, word []rune) int {
+	best := -1
+	for length := len(search) - 1; length <= len(search)+1; length++ {
+		if length < 1 || length > len(word) {
+			continue
+		}
+		distance := editDistance(search, word[:length])
+		if best == -1 || distance < best {
+			best = distance
+		}
+	}
+	if best == -1 {
+		return editDistance(search, word)
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance of two words
+func editDistance(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFoldText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Jane", "jane"},
+		{"José Álvarez", "jose alvarez"},
+		{"Łukasz Søren", "lukasz soren"},
+		{"Đorđe", "dorde"},
+		{"Müller", "muller"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := foldText(tt.text); got != tt.want {
+			t.Errorf("foldText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSearchTokens(t *testing.T) {
+	tests := []struct {
+		name       string
+		fullName   string
+		socialName string
+		want       []string
+	}{
+		{"empty", "", "", []string{}},
+		{"full name only", "Jane Doe", "", []string{"jane", "doe"}},
+		{"social name words and whole social name", "", "jane.doe_42", []string{"jane", "doe", "42", "jane.doe_42"}},
+		{"repeated words are removed", "José Álvarez", "jose.alvarez", []string{"jose", "alvarez", "jose.alvarez"}},
+		{"separators and spaces", "  Mary-Jane   O_Neil ", "mj", []string{"mary", "jane", "o", "neil", "mj"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := searchTokens(tt.fullName, tt.socialName); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("searchTokens(%q, %q) = %v, want %v", tt.fullName, tt.socialName, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypoKeys(t *testing.T) {
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"a", []string{"a"}},
+		{"jo", []string{"jo", "o", "j"}},
+		{"aaaa", []string{"aaaa", "aaa"}},
+		{"jose", []string{"jose", "ose", "jse", "joe", "jos"}},
+		{"alvarez", []string{"alva", "lva", "ava", "ala", "alv"}},
+		{"łódź", []string{"łódź", "ódź", "łdź", "łóź", "łód"}},
+	}
+
+	for _, tt := range tests {
+		if got := typoKeys(tt.word); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("typoKeys(%q) = %v, want %v", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestSearchKeys(t *testing.T) {
+	got := searchKeys([]string{"jo", "joe"})
+	want := []string{"jo", "o", "j", "joe", "oe", "je"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("searchKeys = %v, want %v", got, want)
+	}
+	if got := searchKeys(nil); len(got) != 0 {
+		t.Errorf("searchKeys(nil) = %v, want empty", got)
+	}
+}
+
+func TestTypoKeysShareKey(t *testing.T) {
+	tests := []struct {
+		search string
+		word   string
+		want   bool
+	}{
+		{"john", "john", true},
+		{"jhon", "john", true},
+		{"jise", "jose", true},
+		{"smyth", "smith", true},
+		{"alvares", "alvarez", true},
+		{"jos", "jose", true},
+		{"mary", "jose", false},
+	}
+
+	for _, tt := range tests {
+		keys := map[string]bool{}
+		for _, key := range typoKeys(tt.word) {
+			keys[key] = true
+		}
+		shared := false
+		for _, key := range typoKeys(tt.search) {
+			shared = shared || keys[key]
+		}
+		if shared != tt.want {
+			t.Errorf("typoKeys of %q and %q share a key = %v, want %v", tt.search, tt.word, shared, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"jose", "jose", 0},
+		{"jose", "", 4},
+		{"jhon", "john", 2},
+		{"smyth", "smith", 1},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestPrefixDistance(t *testing.T) {
+	tests := []struct {
+		search, word string
+		want         int
+	}{
+		{"alv", "alvarez", 0},
+		{"alvr", "alvarez", 1},
+		{"jose", "jo", 2},
+		{"smyth", "smithson", 1},
+	}
+
+	for _, tt := range tests {
+		if got := prefixDistance([]rune(tt.search), []rune(tt.word)); got != tt.want {
+			t.Errorf("prefixDistance(%q, %q) = %d, want %d", tt.search, tt.word, got, tt.want)
+		}
+	}
+}
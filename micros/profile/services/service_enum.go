@@ -1,11 +1,32 @@
 package service
 
 const (
-	userProfileCollectionName = "userProfile"
+	userProfileCollectionName           = "userProfile"
+	reservedNameCollectionName          = "reservedName"
+	userRestrictionCollectionName       = "userRestriction"
+	verificationRequestCollectionName   = "verificationRequest"
+	socialNameHistoryCollectionName     = "socialNameHistory"
+	profileRevisionCollectionName       = "profileRevision"
+	userFollowCollectionName            = "userFollow"
+	counterReconciliationCollectionName = "counterReconciliation"
+	profileFieldCollectionName          = "profileField"
+	profileMigrationCollectionName      = "profileMigration"
+
+	// userRelCollectionName is the follow relations of the circles micro which user follows replace
+	userRelCollectionName = "userRel"
 )
 
+// userFollowBackfillMigration is the migration which copies circles follow relations to user follows
+const userFollowBackfillMigration = "userFollowBackfill"
+
 const (
 	numberOfVerifyRequest       = 3
 	expireTimeOffset            = 3600
 	numberOfItems         int64 = 10
+	maxNumberOfItems      int64 = 50
+
+	numberOfSearchCandidates int64 = 200
+
+	// numberOfFollowedByFollowers is how many followers explain a friend of friend suggestion
+	numberOfFollowedByFollowers = 3
 )
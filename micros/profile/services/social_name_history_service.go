@@ -0,0 +1,122 @@
+package service
+
+import (
+	"fmt"
+
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/config"
+	coreData "github.com/red-gold/telar-core/data"
+	"github.com/red-gold/telar-core/data/mongodb"
+	mongoRepo "github.com/red-gold/telar-core/data/mongodb"
+	"github.com/red-gold/telar-core/utils"
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+)
+
+// SocialNameHistoryService handlers with injected dependencies
+type SocialNameHistoryServiceImpl struct {
+	SocialNameHistoryRepo coreData.Repository
+}
+
+// NewSocialNameHistoryService initializes SocialNameHistoryService's dependencies and create new SocialNameHistoryService struct
+func NewSocialNameHistoryService(db interface{}) (SocialNameHistoryService, error) {
+
+	socialNameHistoryService := &SocialNameHistoryServiceImpl{}
+
+	switch *config.AppConfig.DBType {
+	case config.DB_MONGO:
+
+		mongodb := db.(mongodb.MongoDatabase)
+		socialNameHistoryService.SocialNameHistoryRepo = mongoRepo.NewDataRepositoryMongo(mongodb)
+
+	}
+	if socialNameHistoryService.SocialNameHistoryRepo == nil {
+		fmt.Printf("socialNameHistoryService.SocialNameHistoryRepo is nil! \n")
+	}
+	return socialNameHistoryService, nil
+}
+
+// SaveSocialNameHistory save previous social name of a user
+func (s SocialNameHistoryServiceImpl) SaveSocialNameHistory(socialNameHistory *dto.SocialNameHistory) error {
+
+	if socialNameHistory.ObjectId == uuid.Nil {
+		var uuidErr error
+		socialNameHistory.ObjectId, uuidErr = uuid.NewV4()
+		if uuidErr != nil {
+			return uuidErr
+		}
+	}
+
+	if socialNameHistory.CreatedDate == 0 {
+		socialNameHistory.CreatedDate = utils.UTCNowUnix()
+	}
+
+	result := <-s.SocialNameHistoryRepo.Save(socialNameHistoryCollectionName, socialNameHistory)
+
+	return result.Error
+}
+
+// FindSocialNameHistoryList get social name history by filter
+func (s SocialNameHistoryServiceImpl) FindSocialNameHistoryList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.SocialNameHistory, error) {
+
+	result := <-s.SocialNameHistoryRepo.Find(socialNameHistoryCollectionName, filter, limit, skip, sort)
+	defer result.Close()
+	if result.Error() != nil {
+		return nil, result.Error()
+	}
+	var socialNameHistoryList []dto.SocialNameHistory
+	for result.Next() {
+		var socialNameHistory dto.SocialNameHistory
+		errDecode := result.Decode(&socialNameHistory)
+		if errDecode != nil {
+			return nil, fmt.Errorf("Error docoding on dto.SocialNameHistory")
+		}
+		socialNameHistoryList = append(socialNameHistoryList, socialNameHistory)
+	}
+
+	return socialNameHistoryList, nil
+}
+
+// FindLatestBySocialName find the user who used the social name most recently
+func (s SocialNameHistoryServiceImpl) FindLatestBySocialName(socialName string) (*dto.SocialNameHistory, error) {
+
+	sortMap := make(map[string]int)
+	sortMap["created_date"] = -1
+	filter := struct {
+		SocialName string `json:"socialName" bson:"socialName"`
+	}{
+		SocialName: socialName,
+	}
+	socialNameHistoryList, err := s.FindSocialNameHistoryList(filter, 1, 0, sortMap)
+	if err != nil || len(socialNameHistoryList) == 0 {
+		return nil, err
+	}
+	return &socialNameHistoryList[0], nil
+}
+
+// FindProtectedBySocialName find a history of another user which still protects the social name
+func (s SocialNameHistoryServiceImpl) FindProtectedBySocialName(socialName string, notUserId uuid.UUID) (*dto.SocialNameHistory, error) {
+
+	filter := map[string]interface{}{
+		"socialName":     socialName,
+		"userId":         map[string]interface{}{"$ne": notUserId},
+		"protectedUntil": map[string]interface{}{"$gt": utils.UTCNowUnix()},
+	}
+	socialNameHistoryList, err := s.FindSocialNameHistoryList(filter, 1, 0, nil)
+	if err != nil || len(socialNameHistoryList) == 0 {
+		return nil, err
+	}
+	return &socialNameHistoryList[0], nil
+}
+
+// FindByUserId get previous social names of the user from newest to oldest
+func (s SocialNameHistoryServiceImpl) FindByUserId(userId uuid.UUID) ([]dto.SocialNameHistory, error) {
+
+	sortMap := make(map[string]int)
+	sortMap["created_date"] = -1
+	filter := struct {
+		UserId uuid.UUID `json:"userId" bson:"userId"`
+	}{
+		UserId: userId,
+	}
+	return s.FindSocialNameHistoryList(filter, 0, 0, sortMap)
+}
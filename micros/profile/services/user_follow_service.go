@@ -0,0 +1,468 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/config"
+	coreData "github.com/red-gold/telar-core/data"
+	"github.com/red-gold/telar-core/data/mongodb"
+	mongoRepo "github.com/red-gold/telar-core/data/mongodb"
+	"github.com/red-gold/telar-core/utils"
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UserFollowService handlers with injected dependencies
+type UserFollowServiceImpl struct {
+	UserFollowRepo coreData.Repository
+	UserFollowDb   mongodb.MongoDatabase
+}
+
+// NewUserFollowService initializes UserFollowService's dependencies and create new UserFollowService struct
+func NewUserFollowService(db interface{}) (UserFollowService, error) {
+
+	userFollowService := &UserFollowServiceImpl{}
+
+	switch *config.AppConfig.DBType {
+	case config.DB_MONGO:
+
+		mongodb := db.(mongodb.MongoDatabase)
+		userFollowService.UserFollowRepo = mongoRepo.NewDataRepositoryMongo(mongodb)
+		userFollowService.UserFollowDb = mongodb
+
+	}
+	if userFollowService.UserFollowRepo == nil {
+		fmt.Printf("userFollowService.UserFollowRepo is nil! \n")
+	}
+	return userFollowService, nil
+}
+
+// CreateUserFollowIndex create unique index so a user follows another user once
+func (s UserFollowServiceImpl) CreateUserFollowIndex() error {
+	collection, ctx, err := s.getCollection()
+	if err != nil {
+		return err
+	}
+
+	indexOption := options.Index().SetUnique(true).SetBackground(true)
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "followerId", Value: 1}, {Key: "followingId", Value: 1}},
+		Options: indexOption,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "followingId", Value: 1}, {Key: "status", Value: 1}},
+		Options: options.Index().SetBackground(true),
+	})
+	return err
+}
+
+// SaveUserFollow save user follow. It returns false when the follower already follows or requested to follow the user.
+func (s UserFollowServiceImpl) SaveUserFollow(userFollow *dto.UserFollow) (bool, error) {
+
+	if userFollow.ObjectId == uuid.Nil {
+		var uuidErr error
+		userFollow.ObjectId, uuidErr = uuid.NewV4()
+		if uuidErr != nil {
+			return false, uuidErr
+		}
+	}
+
+	if userFollow.CreatedDate == 0 {
+		userFollow.CreatedDate = utils.UTCNowUnix()
+	}
+
+	result := <-s.UserFollowRepo.Save(userFollowCollectionName, userFollow)
+	if result.Error != nil {
+		if mongo.IsDuplicateKeyError(result.Error) {
+			return false, nil
+		}
+		return false, result.Error
+	}
+	return true, nil
+}
+
+// FindOneUserFollow get one user follow
+func (s UserFollowServiceImpl) FindOneUserFollow(filter interface{}) (*dto.UserFollow, error) {
+
+	result := <-s.UserFollowRepo.FindOne(userFollowCollectionName, filter)
+	if result.Error() != nil {
+		if result.Error() == coreData.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, result.Error()
+	}
+
+	var userFollowResult dto.UserFollow
+	errDecode := result.Decode(&userFollowResult)
+	if errDecode != nil {
+		return nil, fmt.Errorf("Error docoding on dto.UserFollow")
+	}
+	return &userFollowResult, nil
+}
+
+// FindUserFollowList get all user follows by filter
+func (s UserFollowServiceImpl) FindUserFollowList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.UserFollow, error) {
+
+	result := <-s.UserFollowRepo.Find(userFollowCollectionName, filter, limit, skip, sort)
+	defer result.Close()
+	if result.Error() != nil {
+		return nil, result.Error()
+	}
+	userFollowList := []dto.UserFollow{}
+	for result.Next() {
+		var userFollow dto.UserFollow
+		errDecode := result.Decode(&userFollow)
+		if errDecode != nil {
+			return nil, fmt.Errorf("Error docoding on dto.UserFollow")
+		}
+		userFollowList = append(userFollowList, userFollow)
+	}
+
+	return userFollowList, nil
+}
+
+// FindUserFollow find the follow of following user by follower user
+func (s UserFollowServiceImpl) FindUserFollow(followerId uuid.UUID, followingId uuid.UUID) (*dto.UserFollow, error) {
+
+	filter := struct {
+		FollowerId  uuid.UUID `json:"followerId" bson:"followerId"`
+		FollowingId uuid.UUID `json:"followingId" bson:"followingId"`
+	}{
+		FollowerId:  followerId,
+		FollowingId: followingId,
+	}
+	return s.FindOneUserFollow(filter)
+}
+
+// QueryFollowers get followers or follow requests of the user by page from newest to oldest
+func (s UserFollowServiceImpl) QueryFollowers(userId uuid.UUID, status string, page int64) ([]dto.UserFollow, error) {
+
+	sortMap := make(map[string]int)
+	sortMap["created_date"] = -1
+	skip := numberOfItems * (page - 1)
+	filter := struct {
+		FollowingId uuid.UUID `json:"followingId" bson:"followingId"`
+		Status      string    `json:"status" bson:"status"`
+	}{
+		FollowingId: userId,
+		Status:      status,
+	}
+	return s.FindUserFollowList(filter, numberOfItems, skip, sortMap)
+}
+
+// QueryFollowing get users the user follows by page from newest to oldest
+func (s UserFollowServiceImpl) QueryFollowing(userId uuid.UUID, page int64) ([]dto.UserFollow, error) {
+
+	sortMap := make(map[string]int)
+	sortMap["created_date"] = -1
+	skip := numberOfItems * (page - 1)
+	filter := struct {
+		FollowerId uuid.UUID `json:"followerId" bson:"followerId"`
+		Status     string    `json:"status" bson:"status"`
+	}{
+		FollowerId: userId,
+		Status:     models.FollowStatusAccepted,
+	}
+	return s.FindUserFollowList(filter, numberOfItems, skip, sortMap)
+}
+
+// FindFollowerIds get ids of all users who follow the user
+func (s UserFollowServiceImpl) FindFollowerIds(userId uuid.UUID) ([]uuid.UUID, error) {
+
+	filter := struct {
+		FollowingId uuid.UUID `json:"followingId" bson:"followingId"`
+		Status      string    `json:"status" bson:"status"`
+	}{
+		FollowingId: userId,
+		Status:      models.FollowStatusAccepted,
+	}
+	userFollowList, err := s.FindUserFollowList(filter, 0, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	followerIds := []uuid.UUID{}
+	for _, userFollow := range userFollowList {
+		followerIds = append(followerIds, userFollow.FollowerId)
+	}
+	return followerIds, nil
+}
+
+// FindFollowingIds get ids of all users the user follows
+func (s UserFollowServiceImpl) FindFollowingIds(userId uuid.UUID) ([]uuid.UUID, error) {
+
+	filter := struct {
+		FollowerId uuid.UUID `json:"followerId" bson:"followerId"`
+		Status     string    `json:"status" bson:"status"`
+	}{
+		FollowerId: userId,
+		Status:     models.FollowStatusAccepted,
+	}
+	userFollowList, err := s.FindUserFollowList(filter, 0, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	followingIds := []uuid.UUID{}
+	for _, userFollow := range userFollowList {
+		followingIds = append(followingIds, userFollow.FollowingId)
+	}
+	return followingIds, nil
+}
+
+// FindRequestedIds get ids of users the user asked to follow and who have not accepted yet
+func (s UserFollowServiceImpl) FindRequestedIds(userId uuid.UUID) ([]uuid.UUID, error) {
+
+	filter := struct {
+		FollowerId uuid.UUID `json:"followerId" bson:"followerId"`
+		Status     string    `json:"status" bson:"status"`
+	}{
+		FollowerId: userId,
+		Status:     models.FollowStatusPending,
+	}
+	userFollowList, err := s.FindUserFollowList(filter, 0, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	requestedIds := []uuid.UUID{}
+	for _, userFollow := range userFollowList {
+		requestedIds = append(requestedIds, userFollow.FollowingId)
+	}
+	return requestedIds, nil
+}
+
+// FindFollowedByFollowing get users followed by the following users, the most followed first.
+// Each user keeps a few of the following users who follow them.
+func (s UserFollowServiceImpl) FindFollowedByFollowing(followingIds []uuid.UUID, notIncludeUserIDList []uuid.UUID, limit int64) ([]models.FollowedByModel, error) {
+	if len(followingIds) == 0 {
+		return []models.FollowedByModel{}, nil
+	}
+
+	match := bson.M{
+		"followerId": bson.M{"$in": followingIds},
+		"status":     models.FollowStatusAccepted,
+	}
+	if len(notIncludeUserIDList) > 0 {
+		match["followingId"] = bson.M{"$nin": notIncludeUserIDList}
+	}
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$group": bson.M{"_id": "$followingId", "count": bson.M{"$sum": 1}, "followerIds": bson.M{"$push": "$followerId"}}},
+		{"$sort": bson.M{"count": -1}},
+		{"$limit": limit},
+		{"$project": bson.M{"count": 1, "followerIds": bson.M{"$slice": bson.A{"$followerIds", numberOfFollowedByFollowers}}}},
+	}
+
+	result := <-s.UserFollowRepo.Aggregate(userFollowCollectionName, pipeline)
+	defer result.Close()
+	if result.Error() != nil {
+		return nil, result.Error()
+	}
+
+	followedByList := []models.FollowedByModel{}
+	for result.Next() {
+		var followedBy models.FollowedByModel
+		if err := result.Decode(&followedBy); err != nil {
+			return nil, fmt.Errorf("Error docoding on models.FollowedByModel")
+		}
+		followedByList = append(followedByList, followedBy)
+	}
+	return followedByList, nil
+}
+
+// AcceptUserFollow accept pending follow request. It returns false when there is no pending request.
+func (s UserFollowServiceImpl) AcceptUserFollow(followerId uuid.UUID, followingId uuid.UUID) (bool, error) {
+
+	filter := struct {
+		FollowerId  uuid.UUID `json:"followerId" bson:"followerId"`
+		FollowingId uuid.UUID `json:"followingId" bson:"followingId"`
+		Status      string    `json:"status" bson:"status"`
+	}{
+		FollowerId:  followerId,
+		FollowingId: followingId,
+		Status:      models.FollowStatusPending,
+	}
+	updateOperator := coreData.UpdateOperator{
+		Set: map[string]interface{}{
+			"status":       models.FollowStatusAccepted,
+			"acceptedDate": utils.UTCNowUnix(),
+		},
+	}
+
+	result := <-s.UserFollowRepo.Update(userFollowCollectionName, filter, updateOperator)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	modifiedCount, _ := result.Result.(int64)
+	return modifiedCount > 0, nil
+}
+
+// DeleteUserFollow delete the follow or follow request and return it. It returns nil when nothing is deleted.
+func (s UserFollowServiceImpl) DeleteUserFollow(followerId uuid.UUID, followingId uuid.UUID) (*dto.UserFollow, error) {
+	collection, ctx, err := s.getCollection()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := struct {
+		FollowerId  uuid.UUID `json:"followerId" bson:"followerId"`
+		FollowingId uuid.UUID `json:"followingId" bson:"followingId"`
+	}{
+		FollowerId:  followerId,
+		FollowingId: followingId,
+	}
+
+	var deletedFollow dto.UserFollow
+	if err := collection.FindOneAndDelete(ctx, filter).Decode(&deletedFollow); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &deletedFollow, nil
+}
+
+// CountFollowing count accepted follows of the user
+func (s UserFollowServiceImpl) CountFollowing(userId uuid.UUID) (int64, error) {
+	collection, ctx, err := s.getCollection()
+	if err != nil {
+		return 0, err
+	}
+	return collection.CountDocuments(ctx, bson.M{"followerId": userId, "status": models.FollowStatusAccepted})
+}
+
+// CountFollowers count accepted followers of the user
+func (s UserFollowServiceImpl) CountFollowers(userId uuid.UUID) (int64, error) {
+	collection, ctx, err := s.getCollection()
+	if err != nil {
+		return 0, err
+	}
+	return collection.CountDocuments(ctx, bson.M{"followingId": userId, "status": models.FollowStatusAccepted})
+}
+
+// IsUserFollowBackfilled check follow relations of the circles micro are copied to user follows
+func (s UserFollowServiceImpl) IsUserFollowBackfilled() (bool, error) {
+	if s.UserFollowDb == nil {
+		return false, fmt.Errorf("user follow is not supported by %s database", *config.AppConfig.DBType)
+	}
+
+	collection, err := s.UserFollowDb.GetCollection(profileMigrationCollectionName)
+	if err != nil {
+		return false, err
+	}
+	ctx, err := s.UserFollowDb.GetContext()
+	if err != nil {
+		return false, err
+	}
+
+	count, err := collection.CountDocuments(ctx, bson.M{"name": userFollowBackfillMigration})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// BackfillUserFollows copy follow relations of the circles micro to accepted user follows and record the migration.
+// Relations already copied and follows made with the follow API are kept, so it is safe to run again.
+func (s UserFollowServiceImpl) BackfillUserFollows() (int64, error) {
+	collection, ctx, err := s.getCollection()
+	if err != nil {
+		return 0, err
+	}
+	userRelCollection, err := s.UserFollowDb.GetCollection(userRelCollectionName)
+	if err != nil {
+		return 0, err
+	}
+	migrationCollection, err := s.UserFollowDb.GetCollection(profileMigrationCollectionName)
+	if err != nil {
+		return 0, err
+	}
+
+	projection := bson.M{"leftId": 1, "rightId": 1, "created_date": 1}
+	cur, err := userRelCollection.Find(ctx, bson.M{}, options.Find().SetProjection(projection))
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close(ctx)
+
+	var copied int64
+	for cur.Next(ctx) {
+		var userRel struct {
+			LeftId      uuid.UUID `bson:"leftId"`
+			RightId     uuid.UUID `bson:"rightId"`
+			CreatedDate int64     `bson:"created_date"`
+		}
+		if err := cur.Decode(&userRel); err != nil {
+			return copied, fmt.Errorf("Error docoding on userRel")
+		}
+		if userRel.LeftId == uuid.Nil || userRel.RightId == uuid.Nil || userRel.LeftId == userRel.RightId {
+			continue
+		}
+
+		objectId, uuidErr := uuid.NewV4()
+		if uuidErr != nil {
+			return copied, uuidErr
+		}
+		createdDate := userRel.CreatedDate
+		if createdDate == 0 {
+			createdDate = utils.UTCNowUnix()
+		}
+
+		// Left user follows right user in the circles micro
+		filter := bson.M{"followerId": userRel.LeftId, "followingId": userRel.RightId}
+		update := bson.M{"$setOnInsert": dto.UserFollow{
+			ObjectId:     objectId,
+			FollowerId:   userRel.LeftId,
+			FollowingId:  userRel.RightId,
+			Status:       models.FollowStatusAccepted,
+			CreatedDate:  createdDate,
+			AcceptedDate: createdDate,
+		}}
+		result, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+		if err != nil {
+			return copied, err
+		}
+		copied += result.UpsertedCount
+	}
+	if err := cur.Err(); err != nil {
+		return copied, err
+	}
+
+	migrationId, uuidErr := uuid.NewV4()
+	if uuidErr != nil {
+		return copied, uuidErr
+	}
+	_, err = migrationCollection.InsertOne(ctx, dto.ProfileMigration{
+		ObjectId:      migrationId,
+		Name:          userFollowBackfillMigration,
+		Count:         copied,
+		CompletedDate: utils.UTCNowUnix(),
+	})
+	return copied, err
+}
+
+// getCollection get user follow collection for operations the repository does not have
+func (s UserFollowServiceImpl) getCollection() (*mongo.Collection, context.Context, error) {
+	if s.UserFollowDb == nil {
+		return nil, nil, fmt.Errorf("user follow is not supported by %s database", *config.AppConfig.DBType)
+	}
+
+	collection, err := s.UserFollowDb.GetCollection(userFollowCollectionName)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, err := s.UserFollowDb.GetContext()
+	if err != nil {
+		return nil, nil, err
+	}
+	return collection, ctx, nil
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"regexp"
+
+	"github.com/red-gold/telar-web/constants"
+)
+
+// addCustomFieldFilter match profiles having the custom field values. Text matches the whole value
+// ignoring case and matches any item of list fields. Profiles which are not public are left out
+// so filtering can not reveal values their owners do not share.
+func addCustomFieldFilter(filter map[string]interface{}, customFields map[string]interface{}) {
+	if len(customFields) == 0 {
+		return
+	}
+	for name, value := range customFields {
+		if text, ok := value.(string); ok {
+			filter["customFields."+name] = map[string]interface{}{"$regex": "^" + regexp.QuoteMeta(text) + "$", "$options": "i"}
+			continue
+		}
+		filter["customFields."+name] = value
+	}
+	filter["permission"] = map[string]interface{}{"$nin": []constants.UserPermissionConst{constants.OnlyMe, constants.Circles, constants.Custom}}
+}
+
+// UnsetCustomField remove the value of a custom field from every profile
+func (s UserProfileServiceImpl) UnsetCustomField(name string) error {
+	filter := map[string]interface{}{
+		"customFields." + name: map[string]interface{}{"$exists": true},
+	}
+	updateOperator := map[string]interface{}{
+		"$unset": map[string]interface{}{"customFields." + name: ""},
+	}
+	result := <-s.UserProfileRepo.UpdateMany(userProfileCollectionName, filter, updateOperator)
+	return result.Error
+}
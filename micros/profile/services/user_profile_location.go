@@ -0,0 +1,76 @@
+package service
+
+import (
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/utils"
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+)
+
+// UpdateLiveLocation set live location of the user
+func (s UserProfileServiceImpl) UpdateLiveLocation(userId uuid.UUID, location dto.Location) error {
+	data := struct {
+		LiveLocation dto.Location `json:"liveLocation" bson:"liveLocation"`
+		LocationDate int64        `json:"locationDate" bson:"locationDate"`
+	}{
+		LiveLocation: location,
+		LocationDate: utils.UTCNowUnix(),
+	}
+	return s.UpdateUserProfileById(userId, data)
+}
+
+// SetLocationSharing opt the user in or out of nearby people. Opting out removes the live location.
+func (s UserProfileServiceImpl) SetLocationSharing(userId uuid.UUID, enabled bool) error {
+	filter := struct {
+		ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	}{
+		ObjectId: userId,
+	}
+
+	updateOperator := map[string]interface{}{
+		"$set": map[string]interface{}{"shareLocation": enabled},
+	}
+	if !enabled {
+		updateOperator["$unset"] = map[string]interface{}{"liveLocation": "", "locationDate": ""}
+	}
+	return s.UpdateUserProfile(filter, updateOperator)
+}
+
+// FindNearby find people who share their location within radius meters of the location, nearest first
+func (s UserProfileServiceImpl) FindNearby(location dto.Location, radius float64, page int64, limit int64, notIncludeUserIDList []uuid.UUID) ([]dto.UserProfile, error) {
+	if limit <= 0 {
+		limit = numberOfItems
+	}
+	if limit > maxNumberOfItems {
+		limit = maxNumberOfItems
+	}
+	skip := limit * (page - 1)
+
+	filter := make(map[string]interface{})
+	filter["shareLocation"] = true
+	filter["liveLocation"] = map[string]interface{}{
+		"$nearSphere": map[string]interface{}{
+			"$geometry":    location,
+			"$maxDistance": radius,
+		},
+	}
+	if len(notIncludeUserIDList) > 0 {
+		filter["objectId"] = map[string]interface{}{"$nin": notIncludeUserIDList}
+	}
+
+	// $nearSphere sorts by distance
+	return s.FindUserProfileList(filter, limit, skip, nil)
+}
+
+// RemoveInvalidLiveLocation unset empty live locations which profiles got before live location was optional.
+// The 2dsphere index can not be created while such documents exist.
+func (s UserProfileServiceImpl) RemoveInvalidLiveLocation() error {
+	filter := map[string]interface{}{
+		"liveLocation":      map[string]interface{}{"$exists": true},
+		"liveLocation.type": map[string]interface{}{"$ne": "Point"},
+	}
+	updateOperator := map[string]interface{}{
+		"$unset": map[string]interface{}{"liveLocation": ""},
+	}
+	result := <-s.UserProfileRepo.UpdateMany(userProfileCollectionName, filter, updateOperator)
+	return result.Error
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	uuid "github.com/gofrs/uuid"
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+)
+
+const (
+	exactSocialNameScore  = 100
+	prefixSocialNameScore = 80
+	exactNameScore        = 70
+	prefixNameScore       = 60
+	firstNameScore        = 5
+	prefixWordScore       = 55
+	typoScore             = 40
+	typoPenalty           = 10
+	followingScore        = 50
+)
+
+type scoredUserProfile struct {
+	profile dto.UserProfile
+	score   int
+}
+
+// SearchUserProfile find people whose full name or social name start with the search words.
+// Diacritics and case are ignored, typos are tolerated for longer words and people in the
+// following list are ranked first. Custom fields narrow the people to those with the same values.
+func (s UserProfileServiceImpl) SearchUserProfile(search string, customFields map[string]interface{}, followingIds []uuid.UUID, limit int64, notIncludeUserIDList []uuid.UUID) ([]dto.UserProfile, error) {
+	if limit <= 0 {
+		limit = numberOfItems
+	}
+	if limit > maxNumberOfItems {
+		limit = maxNumberOfItems
+	}
+
+	words := searchWords(foldText(strings.TrimPrefix(strings.TrimSpace(search), "@")))
+	if len(words) == 0 {
+		return []dto.UserProfile{}, nil
+	}
+
+	following := make(map[uuid.UUID]bool)
+	for _, userId := range followingIds {
+		following[userId] = true
+	}
+
+	scoredList := []scoredUserProfile{}
+	seen := make(map[uuid.UUID]bool)
+	collect := func(candidates []dto.UserProfile) {
+		for _, candidate := range candidates {
+			if seen[candidate.ObjectId] {
+				continue
+			}
+			seen[candidate.ObjectId] = true
+			score, matched := scoreUserProfile(words, candidate)
+			if !matched {
+				continue
+			}
+			if following[candidate.ObjectId] {
+				score += followingScore
+			}
+			scoredList = append(scoredList, scoredUserProfile{profile: candidate, score: score})
+		}
+	}
+
+	firstWord := []rune(words[0])
+	prefixFilter := map[string]interface{}{"searchTokens": map[string]interface{}{"$regex": "^" + regexp.QuoteMeta(words[0])}}
+	candidates, err := s.findSearchCandidates(prefixFilter, customFields, notIncludeUserIDList)
+	if err != nil {
+		return nil, err
+	}
+	collect(candidates)
+
+	// Words with a typo do not match the prefix, so look for words with a close prefix
+	if int64(len(scoredList)) < limit && allowedTypos(len(firstWord)) > 0 {
+		typoFilter := map[string]interface{}{"searchKeys": map[string]interface{}{"$in": typoKeys(words[0])}}
+		candidates, err = s.findSearchCandidates(typoFilter, customFields, notIncludeUserIDList)
+		if err != nil {
+			return nil, err
+		}
+		collect(candidates)
+	}
+
+	sort.SliceStable(scoredList, func(i, j int) bool {
+		if scoredList[i].score != scoredList[j].score {
+			return scoredList[i].score > scoredList[j].score
+		}
+		if scoredList[i].profile.FollowerCount != scoredList[j].profile.FollowerCount {
+			return scoredList[i].profile.FollowerCount > scoredList[j].profile.FollowerCount
+		}
+		return len(scoredList[i].profile.FullName) < len(scoredList[j].profile.FullName)
+	})
+
+	userProfileList := []dto.UserProfile{}
+	for _, scored := range scoredList {
+		if int64(len(userProfileList)) == limit {
+			break
+		}
+		userProfileList = append(userProfileList, scored.profile)
+	}
+	return userProfileList, nil
+}
+
+// findSearchCandidates find profiles by a filter on the indexed search fields
+func (s UserProfileServiceImpl) findSearchCandidates(filter map[string]interface{}, customFields map[string]interface{}, notIncludeUserIDList []uuid.UUID) ([]dto.UserProfile, error) {
+	addCustomFieldFilter(filter, customFields)
+	if len(notIncludeUserIDList) > 0 {
+		filter["objectId"] = map[string]interface{}{"$nin": notIncludeUserIDList}
+	}
+
+	sortMap := make(map[string]int)
+	sortMap["followerCount"] = -1
+	return s.FindUserProfileList(filter, numberOfSearchCandidates, 0, sortMap)
+}
+
+// scoreUserProfile score how well the profile matches every search word
+func scoreUserProfile(words []string, userProfile dto.UserProfile) (int, bool) {
+	socialName := foldText(userProfile.SocialName)
+	socialNameWords := searchWords(socialName)
+	nameWords := searchWords(foldText(userProfile.FullName))
+
+	total := 0
+	for _, word := range words {
+		best := -1
+		setBest := func(score int) {
+			if score > best {
+				best = score
+			}
+		}
+
+		if socialName == word {
+			setBest(exactSocialNameScore)
+		} else if strings.HasPrefix(socialName, word) {
+			setBest(prefixSocialNameScore)
+		}
+		for i, nameWord := range nameWords {
+			bonus := 0
+			if i == 0 {
+				bonus = firstNameScore
+			}
+			if nameWord == word {
+				setBest(exactNameScore + bonus)
+			} else if strings.HasPrefix(nameWord, word) {
+				setBest(prefixNameScore + bonus)
+			}
+		}
+		for _, socialNameWord := range socialNameWords {
+			if strings.HasPrefix(socialNameWord, word) {
+				setBest(prefixWordScore)
+			}
+		}
+
+		if best < 0 {
+			wordRunes := []rune(word)
+			typos := allowedTypos(len(wordRunes))
+			for _, candidate := range append(nameWords, socialNameWords...) {
+				distance := prefixDistance(wordRunes, []rune(candidate))
+				if distance <= typos {
+					setBest(typoScore - typoPenalty*distance)
+				}
+			}
+		}
+
+		if best < 0 {
+			return 0, false
+		}
+		total += best
+	}
+	return total, true
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"strings"
 
 	uuid "github.com/gofrs/uuid"
 	"github.com/red-gold/telar-core/config"
@@ -10,11 +11,16 @@ import (
 	mongoRepo "github.com/red-gold/telar-core/data/mongodb"
 	"github.com/red-gold/telar-core/utils"
 	dto "github.com/red-gold/telar-web/micros/profile/dto"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // UserProfileService handlers with injected dependencies
 type UserProfileServiceImpl struct {
 	UserProfileRepo coreData.Repository
+	UserProfileDb   mongodb.MongoDatabase
 }
 
 // NewUserProfileService initializes UserProfileService's dependencies and create new UserProfileService struct
@@ -27,6 +33,7 @@ func NewUserProfileService(db interface{}) (UserProfileService, error) {
 
 		mongodb := db.(mongodb.MongoDatabase)
 		userProfileService.UserProfileRepo = mongoRepo.NewDataRepositoryMongo(mongodb)
+		userProfileService.UserProfileDb = mongodb
 
 	}
 	if userProfileService.UserProfileRepo == nil {
@@ -50,7 +57,14 @@ func (s UserProfileServiceImpl) SaveUserProfile(userProfile *dto.UserProfile) er
 		userProfile.CreatedDate = utils.UTCNowUnix()
 	}
 
+	userProfile.SocialName = strings.ToLower(userProfile.SocialName)
+	userProfile.SearchTokens = searchTokens(userProfile.FullName, userProfile.SocialName)
+	userProfile.SearchKeys = searchKeys(userProfile.SearchTokens)
+
 	result := <-s.UserProfileRepo.Save(userProfileCollectionName, userProfile)
+	if mongo.IsDuplicateKeyError(result.Error) {
+		return models.SocialNameError{Code: models.SocialNameErrorTaken}
+	}
 
 	return result.Error
 }
@@ -107,7 +121,7 @@ func (s UserProfileServiceImpl) FindUserProfileList(filter interface{}, limit in
 }
 
 // QueryPost get all user profile by query
-func (s UserProfileServiceImpl) QueryUserProfile(search string, sortBy string, page int64, notIncludeUserIDList []uuid.UUID) ([]dto.UserProfile, error) {
+func (s UserProfileServiceImpl) QueryUserProfile(search string, customFields map[string]interface{}, sortBy string, page int64, notIncludeUserIDList []uuid.UUID) ([]dto.UserProfile, error) {
 	sortMap := make(map[string]int)
 	sortMap[sortBy] = -1
 	skip := numberOfItems * (page - 1)
@@ -116,6 +130,7 @@ func (s UserProfileServiceImpl) QueryUserProfile(search string, sortBy string, p
 	if search != "" {
 		filter["$text"] = coreData.SearchOperator{Search: search}
 	}
+	addCustomFieldFilter(filter, customFields)
 	if notIncludeUserIDList != nil && len(notIncludeUserIDList) > 0 {
 		nin := make(map[string]interface{})
 		nin["$nin"] = notIncludeUserIDList
@@ -127,6 +142,77 @@ func (s UserProfileServiceImpl) QueryUserProfile(search string, sortBy string, p
 	return result, err
 }
 
+// QueryUserProfileAfter get user profiles from newest to oldest which come after the cursor.
+// Object id breaks the tie of profiles created in the same millisecond, so pages neither repeat nor skip
+// profiles when new users sign up. Nil cursor starts from the newest profile and nil next cursor means the last page.
+func (s UserProfileServiceImpl) QueryUserProfileAfter(search string, customFields map[string]interface{}, after *models.UserProfileCursorModel, limit int64, notIncludeUserIDList []uuid.UUID) ([]dto.UserProfile, *models.UserProfileCursorModel, error) {
+	if limit <= 0 {
+		limit = numberOfItems
+	}
+	if limit > maxNumberOfItems {
+		limit = maxNumberOfItems
+	}
+
+	filter := make(map[string]interface{})
+	if search != "" {
+		filter["$text"] = coreData.SearchOperator{Search: search}
+	}
+	addCustomFieldFilter(filter, customFields)
+	if len(notIncludeUserIDList) > 0 {
+		filter["objectId"] = map[string]interface{}{"$nin": notIncludeUserIDList}
+	}
+	if after != nil {
+		filter["$or"] = bson.A{
+			bson.M{"created_date": bson.M{"$lt": after.CreatedDate}},
+			bson.M{"created_date": after.CreatedDate, "objectId": bson.M{"$lt": after.ObjectId}},
+		}
+	}
+
+	sort := bson.D{{Key: "created_date", Value: -1}, {Key: "objectId", Value: -1}}
+	userProfileList, err := s.findUserProfileListSorted(filter, limit, sort)
+	if err != nil || int64(len(userProfileList)) < limit {
+		return userProfileList, nil, err
+	}
+
+	last := userProfileList[len(userProfileList)-1]
+	return userProfileList, &models.UserProfileCursorModel{CreatedDate: last.CreatedDate, ObjectId: last.ObjectId}, nil
+}
+
+// findUserProfileListSorted get user profiles sorted by more than one field.
+// Sort map of the repository does not keep the order of fields.
+func (s UserProfileServiceImpl) findUserProfileListSorted(filter interface{}, limit int64, sort bson.D) ([]dto.UserProfile, error) {
+	if s.UserProfileDb == nil {
+		return nil, fmt.Errorf("sorted query is not supported by %s database", *config.AppConfig.DBType)
+	}
+
+	collection, err := s.UserProfileDb.GetCollection(userProfileCollectionName)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := s.UserProfileDb.GetContext()
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := collection.Find(ctx, filter, options.Find().SetSort(sort).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var userProfileList []dto.UserProfile
+	for cur.Next(ctx) {
+		var userProfile dto.UserProfile
+		errDecode := cur.Decode(&userProfile)
+		if errDecode != nil {
+			return nil, fmt.Errorf("Error docoding on dto.UserProfile")
+		}
+		userProfileList = append(userProfileList, userProfile)
+	}
+
+	return userProfileList, cur.Err()
+}
+
 // FindProfileByUserIds Find profile by user IDs
 func (s UserProfileServiceImpl) FindProfileByUserIds(userIds []uuid.UUID) ([]dto.UserProfile, error) {
 	sortMap := make(map[string]int)
@@ -171,7 +257,7 @@ func (s UserProfileServiceImpl) FindBySocialName(socialName string) (chan *dto.U
 	filter := struct {
 		SocialName string `json:"socialName" bson:"socialName"`
 	}{
-		SocialName: socialName,
+		SocialName: strings.ToLower(socialName),
 	}
 	return s.FindOneUserProfile(filter)
 }
@@ -188,10 +274,15 @@ func (s UserProfileServiceImpl) UpdateUserProfile(filter interface{}, data inter
 
 // UpdateLastSeen update user profile information
 func (s UserProfileServiceImpl) UpdateLastSeenNow(userId uuid.UUID) error {
+	return s.UpdateLastSeen(userId, utils.UTCNowUnix())
+}
+
+// UpdateLastSeen set last seen time of the user
+func (s UserProfileServiceImpl) UpdateLastSeen(userId uuid.UUID, lastSeen int64) error {
 	data := struct {
 		LastSeen int64 `json:"lastSeen" bson:"lastSeen"`
 	}{
-		LastSeen: utils.UTCNowUnix(),
+		LastSeen: lastSeen,
 	}
 
 	filter := struct {
@@ -215,17 +306,101 @@ func (s UserProfileServiceImpl) UpdateUserProfileById(userId uuid.UUID, data int
 		ObjectId: userId,
 	}
 
-	updateOperator := coreData.UpdateOperator{
-		Set: data,
+	if s.UserProfileDb == nil {
+		return s.UpdateUserProfile(filter, coreData.UpdateOperator{Set: data})
+	}
+
+	// Update on the collection, the repository can not report a duplicate social name
+	collection, err := s.UserProfileDb.GetCollection(userProfileCollectionName)
+	if err != nil {
+		return err
+	}
+	ctx, err := s.UserProfileDb.GetContext()
+	if err != nil {
+		return err
 	}
 
-	err := s.UpdateUserProfile(filter, updateOperator)
+	_, err = collection.UpdateOne(ctx, filter, bson.M{"$set": data})
+	if mongo.IsDuplicateKeyError(err) {
+		return models.SocialNameError{Code: models.SocialNameErrorTaken}
+	}
 	if err != nil {
 		return err
 	}
+
+	if changesSearchFields(data) {
+		return s.refreshSearchFields(userId)
+	}
 	return nil
 }
 
+// changesSearchFields check whether the update sets full name or social name
+func changesSearchFields(data interface{}) bool {
+	raw, err := bson.Marshal(data)
+	if err != nil {
+		return false
+	}
+	fields := bson.M{}
+	if err := bson.Unmarshal(raw, &fields); err != nil {
+		return false
+	}
+	_, fullName := fields["fullName"]
+	_, socialName := fields["socialName"]
+	return fullName || socialName
+}
+
+// refreshSearchFields set search tokens of the user from the saved full name and social name
+func (s UserProfileServiceImpl) refreshSearchFields(userId uuid.UUID) error {
+	foundUserChan, errChan := s.FindByUserId(userId)
+	foundUser, err := <-foundUserChan, <-errChan
+	if err != nil || foundUser == nil {
+		return err
+	}
+
+	tokens := searchTokens(foundUser.FullName, foundUser.SocialName)
+	return s.UpdateUserProfile(bson.M{"objectId": userId}, coreData.UpdateOperator{
+		Set: bson.M{"searchTokens": tokens, "searchKeys": searchKeys(tokens)},
+	})
+}
+
+// BackfillSearchFields set search tokens of profiles saved before profiles had them
+func (s UserProfileServiceImpl) BackfillSearchFields() (int64, error) {
+	if s.UserProfileDb == nil {
+		return 0, fmt.Errorf("search backfill is not supported by %s database", *config.AppConfig.DBType)
+	}
+
+	collection, err := s.UserProfileDb.GetCollection(userProfileCollectionName)
+	if err != nil {
+		return 0, err
+	}
+	ctx, err := s.UserProfileDb.GetContext()
+	if err != nil {
+		return 0, err
+	}
+
+	projection := bson.M{"objectId": 1, "fullName": 1, "socialName": 1}
+	cur, err := collection.Find(ctx, bson.M{"searchTokens": bson.M{"$exists": false}}, options.Find().SetProjection(projection))
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close(ctx)
+
+	var updated int64
+	for cur.Next(ctx) {
+		var userProfile dto.UserProfile
+		if err := cur.Decode(&userProfile); err != nil {
+			return updated, fmt.Errorf("Error docoding on dto.UserProfile")
+		}
+		tokens := searchTokens(userProfile.FullName, userProfile.SocialName)
+		update := bson.M{"$set": bson.M{"searchTokens": tokens, "searchKeys": searchKeys(tokens)}}
+		if _, err := collection.UpdateOne(ctx, bson.M{"objectId": userProfile.ObjectId}, update); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, cur.Err()
+}
+
 // DeleteUserProfile get all user profile informaition.
 func (s UserProfileServiceImpl) DeleteUserProfile(filter interface{}) error {
 
@@ -253,6 +428,49 @@ func (s UserProfileServiceImpl) CreateUserProfileIndex(indexes map[string]interf
 	return result
 }
 
+// CreateUniqueIndex create a unique index on a user profile field.
+// Profiles without the field are skipped by the index.
+func (s UserProfileServiceImpl) CreateUniqueIndex(field string) error {
+	if s.UserProfileDb == nil {
+		return fmt.Errorf("unique index is not supported by %s database", *config.AppConfig.DBType)
+	}
+
+	collection, err := s.UserProfileDb.GetCollection(userProfileCollectionName)
+	if err != nil {
+		return err
+	}
+	ctx, err := s.UserProfileDb.GetContext()
+	if err != nil {
+		return err
+	}
+
+	indexOption := options.Index().SetUnique(true).SetSparse(true).SetBackground(true)
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.M{field: 1}, Options: indexOption})
+	return err
+}
+
+// CreateSearchIndex create indexes of the search tokens and typo keys
+func (s UserProfileServiceImpl) CreateSearchIndex() error {
+	if s.UserProfileDb == nil {
+		return fmt.Errorf("search index is not supported by %s database", *config.AppConfig.DBType)
+	}
+
+	collection, err := s.UserProfileDb.GetCollection(userProfileCollectionName)
+	if err != nil {
+		return err
+	}
+	ctx, err := s.UserProfileDb.GetContext()
+	if err != nil {
+		return err
+	}
+
+	_, err = collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "searchTokens", Value: 1}, {Key: "followerCount", Value: -1}}, Options: options.Index().SetBackground(true)},
+		{Keys: bson.D{{Key: "searchKeys", Value: 1}, {Key: "followerCount", Value: -1}}, Options: options.Index().SetBackground(true)},
+	})
+	return err
+}
+
 // Increment increment a profile field
 func (s UserProfileServiceImpl) Increment(objectId uuid.UUID, field string, value int) error {
 
@@ -271,6 +489,21 @@ func (s UserProfileServiceImpl) Increment(objectId uuid.UUID, field string, valu
 	return s.UpdateUserProfile(filter, incOperator)
 }
 
+// SetCounter set a profile counter to the value counted from its source
+func (s UserProfileServiceImpl) SetCounter(objectId uuid.UUID, field string, value int64) error {
+
+	filter := struct {
+		ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	}{
+		ObjectId: objectId,
+	}
+
+	updateOperator := coreData.UpdateOperator{
+		Set: map[string]interface{}{field: value},
+	}
+	return s.UpdateUserProfile(filter, updateOperator)
+}
+
 // IncreaseFollowCount increment follow count of post
 func (s UserProfileServiceImpl) IncreaseFollowCount(objectId uuid.UUID, inc int) error {
 	return s.Increment(objectId, "followCount", inc)
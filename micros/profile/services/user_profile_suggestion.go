@@ -0,0 +1,45 @@
+package service
+
+import (
+	"regexp"
+
+	uuid "github.com/gofrs/uuid"
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+)
+
+// FindSameOrganization find people who work at the company or studied at the school, ignoring case
+func (s UserProfileServiceImpl) FindSameOrganization(companyName string, school string, notIncludeUserIDList []uuid.UUID, limit int64) ([]dto.UserProfile, error) {
+	conditions := []map[string]interface{}{}
+	if companyName != "" {
+		conditions = append(conditions, map[string]interface{}{"companyName": map[string]interface{}{"$regex": "^" + regexp.QuoteMeta(companyName) + "$", "$options": "i"}})
+	}
+	if school != "" {
+		conditions = append(conditions, map[string]interface{}{"school": map[string]interface{}{"$regex": "^" + regexp.QuoteMeta(school) + "$", "$options": "i"}})
+	}
+	if len(conditions) == 0 {
+		return []dto.UserProfile{}, nil
+	}
+
+	filter := make(map[string]interface{})
+	filter["$or"] = conditions
+	if len(notIncludeUserIDList) > 0 {
+		filter["objectId"] = map[string]interface{}{"$nin": notIncludeUserIDList}
+	}
+
+	sortMap := make(map[string]int)
+	sortMap["lastSeen"] = -1
+	return s.FindUserProfileList(filter, limit, 0, sortMap)
+}
+
+// FindRecentlyActive find people seen since the time, the latest first
+func (s UserProfileServiceImpl) FindRecentlyActive(since int64, notIncludeUserIDList []uuid.UUID, limit int64) ([]dto.UserProfile, error) {
+	filter := make(map[string]interface{})
+	filter["lastSeen"] = map[string]interface{}{"$gte": since}
+	if len(notIncludeUserIDList) > 0 {
+		filter["objectId"] = map[string]interface{}{"$nin": notIncludeUserIDList}
+	}
+
+	sortMap := make(map[string]int)
+	sortMap["lastSeen"] = -1
+	return s.FindUserProfileList(filter, limit, 0, sortMap)
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	uuid "github.com/gofrs/uuid"
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+)
+
+// SetVerification set verified badge of the user. Nil verification removes the badge.
+func (s UserProfileServiceImpl) SetVerification(userId uuid.UUID, verification *dto.Verification) error {
+	filter := struct {
+		ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	}{
+		ObjectId: userId,
+	}
+
+	updateOperator := map[string]interface{}{
+		"$set": map[string]interface{}{"verification": verification},
+	}
+	if verification == nil {
+		updateOperator = map[string]interface{}{
+			"$unset": map[string]interface{}{"verification": ""},
+		}
+	}
+	return s.UpdateUserProfile(filter, updateOperator)
+}
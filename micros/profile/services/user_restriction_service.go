@@ -0,0 +1,255 @@
+package service
+
+import (
+	"fmt"
+
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/config"
+	coreData "github.com/red-gold/telar-core/data"
+	"github.com/red-gold/telar-core/data/mongodb"
+	mongoRepo "github.com/red-gold/telar-core/data/mongodb"
+	"github.com/red-gold/telar-core/utils"
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+	models "github.com/red-gold/telar-web/micros/profile/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UserRestrictionService handlers with injected dependencies
+type UserRestrictionServiceImpl struct {
+	UserRestrictionRepo coreData.Repository
+	UserRestrictionDb   mongodb.MongoDatabase
+}
+
+// NewUserRestrictionService initializes UserRestrictionService's dependencies and create new UserRestrictionService struct
+func NewUserRestrictionService(db interface{}) (UserRestrictionService, error) {
+
+	userRestrictionService := &UserRestrictionServiceImpl{}
+
+	switch *config.AppConfig.DBType {
+	case config.DB_MONGO:
+
+		mongodb := db.(mongodb.MongoDatabase)
+		userRestrictionService.UserRestrictionRepo = mongoRepo.NewDataRepositoryMongo(mongodb)
+		userRestrictionService.UserRestrictionDb = mongodb
+
+	}
+	if userRestrictionService.UserRestrictionRepo == nil {
+		fmt.Printf("userRestrictionService.UserRestrictionRepo is nil! \n")
+	}
+	return userRestrictionService, nil
+}
+
+// CreateUserRestrictionIndex create unique index so a user restricts another user once by each type.
+// Restrictions saved twice before the index are removed first.
+func (s UserRestrictionServiceImpl) CreateUserRestrictionIndex() error {
+	if s.UserRestrictionDb == nil {
+		return fmt.Errorf("user restriction index is not supported by %s database", *config.AppConfig.DBType)
+	}
+
+	collection, err := s.UserRestrictionDb.GetCollection(userRestrictionCollectionName)
+	if err != nil {
+		return err
+	}
+	ctx, err := s.UserRestrictionDb.GetContext()
+	if err != nil {
+		return err
+	}
+
+	pipeline := []bson.M{
+		{"$group": bson.M{
+			"_id":   bson.M{"ownerUserId": "$ownerUserId", "targetUserId": "$targetUserId", "type": "$type"},
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}},
+		{"$match": bson.M{"count": bson.M{"$gt": 1}}},
+	}
+	cur, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var duplicate struct {
+			Ids []interface{} `bson:"ids"`
+		}
+		if err := cur.Decode(&duplicate); err != nil {
+			return err
+		}
+		if _, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": duplicate.Ids[1:]}}); err != nil {
+			return err
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return err
+	}
+
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "ownerUserId", Value: 1}, {Key: "targetUserId", Value: 1}, {Key: "type", Value: 1}},
+		Options: options.Index().SetUnique(true).SetBackground(true),
+	})
+	return err
+}
+
+// SaveUserRestriction save user restriction. It returns false when the user is already restricted by the same type.
+func (s UserRestrictionServiceImpl) SaveUserRestriction(userRestriction *dto.UserRestriction) (bool, error) {
+
+	if userRestriction.ObjectId == uuid.Nil {
+		var uuidErr error
+		userRestriction.ObjectId, uuidErr = uuid.NewV4()
+		if uuidErr != nil {
+			return false, uuidErr
+		}
+	}
+
+	if userRestriction.CreatedDate == 0 {
+		userRestriction.CreatedDate = utils.UTCNowUnix()
+	}
+
+	result := <-s.UserRestrictionRepo.Save(userRestrictionCollectionName, userRestriction)
+	if result.Error != nil {
+		if mongo.IsDuplicateKeyError(result.Error) {
+			return false, nil
+		}
+		return false, result.Error
+	}
+	return true, nil
+}
+
+// FindOneUserRestriction get one user restriction
+func (s UserRestrictionServiceImpl) FindOneUserRestriction(filter interface{}) (*dto.UserRestriction, error) {
+
+	result := <-s.UserRestrictionRepo.FindOne(userRestrictionCollectionName, filter)
+	if result.Error() != nil {
+		if result.Error() == coreData.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, result.Error()
+	}
+
+	var userRestrictionResult dto.UserRestriction
+	errDecode := result.Decode(&userRestrictionResult)
+	if errDecode != nil {
+		return nil, fmt.Errorf("Error docoding on dto.UserRestriction")
+	}
+	return &userRestrictionResult, nil
+}
+
+// FindUserRestrictionList get all user restrictions by filter
+func (s UserRestrictionServiceImpl) FindUserRestrictionList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.UserRestriction, error) {
+
+	result := <-s.UserRestrictionRepo.Find(userRestrictionCollectionName, filter, limit, skip, sort)
+	defer result.Close()
+	if result.Error() != nil {
+		return nil, result.Error()
+	}
+	var userRestrictionList []dto.UserRestriction
+	for result.Next() {
+		var userRestriction dto.UserRestriction
+		errDecode := result.Decode(&userRestriction)
+		if errDecode != nil {
+			return nil, fmt.Errorf("Error docoding on dto.UserRestriction")
+		}
+		userRestrictionList = append(userRestrictionList, userRestriction)
+	}
+
+	return userRestrictionList, nil
+}
+
+// FindUserRestriction find the restriction of target user by owner user
+func (s UserRestrictionServiceImpl) FindUserRestriction(ownerUserId uuid.UUID, targetUserId uuid.UUID, restrictionType string) (*dto.UserRestriction, error) {
+
+	filter := struct {
+		OwnerUserId  uuid.UUID `json:"ownerUserId" bson:"ownerUserId"`
+		TargetUserId uuid.UUID `json:"targetUserId" bson:"targetUserId"`
+		Type         string    `json:"type" bson:"type"`
+	}{
+		OwnerUserId:  ownerUserId,
+		TargetUserId: targetUserId,
+		Type:         restrictionType,
+	}
+	return s.FindOneUserRestriction(filter)
+}
+
+// QueryUserRestriction get restrictions of owner user by page from newest to oldest
+func (s UserRestrictionServiceImpl) QueryUserRestriction(ownerUserId uuid.UUID, restrictionType string, page int64) ([]dto.UserRestriction, error) {
+
+	sortMap := make(map[string]int)
+	sortMap["created_date"] = -1
+	skip := numberOfItems * (page - 1)
+	filter := struct {
+		OwnerUserId uuid.UUID `json:"ownerUserId" bson:"ownerUserId"`
+		Type        string    `json:"type" bson:"type"`
+	}{
+		OwnerUserId: ownerUserId,
+		Type:        restrictionType,
+	}
+	return s.FindUserRestrictionList(filter, numberOfItems, skip, sortMap)
+}
+
+// FindUserRestrictions get all users the user has blocked or muted and the users who blocked the user
+func (s UserRestrictionServiceImpl) FindUserRestrictions(userId uuid.UUID) (*models.UserRestrictionListModel, error) {
+
+	filter := map[string]interface{}{
+		"$or": []map[string]interface{}{
+			{"ownerUserId": userId},
+			{"targetUserId": userId, "type": models.RestrictionTypeBlock},
+		},
+	}
+	userRestrictionList, err := s.FindUserRestrictionList(filter, 0, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	restrictions := &models.UserRestrictionListModel{
+		Blocked:   []uuid.UUID{},
+		Muted:     []uuid.UUID{},
+		BlockedBy: []uuid.UUID{},
+	}
+	for _, userRestriction := range userRestrictionList {
+		switch {
+		case userRestriction.TargetUserId == userId:
+			restrictions.BlockedBy = append(restrictions.BlockedBy, userRestriction.OwnerUserId)
+		case userRestriction.Type == models.RestrictionTypeBlock:
+			restrictions.Blocked = append(restrictions.Blocked, userRestriction.TargetUserId)
+		case userRestriction.Type == models.RestrictionTypeMute:
+			restrictions.Muted = append(restrictions.Muted, userRestriction.TargetUserId)
+		}
+	}
+	return restrictions, nil
+}
+
+// IsBlocked check whether either of the users has blocked the other
+func (s UserRestrictionServiceImpl) IsBlocked(userId uuid.UUID, otherUserId uuid.UUID) (bool, error) {
+
+	filter := map[string]interface{}{
+		"type": models.RestrictionTypeBlock,
+		"$or": []map[string]interface{}{
+			{"ownerUserId": userId, "targetUserId": otherUserId},
+			{"ownerUserId": otherUserId, "targetUserId": userId},
+		},
+	}
+	userRestriction, err := s.FindOneUserRestriction(filter)
+	if err != nil {
+		return false, err
+	}
+	return userRestriction != nil, nil
+}
+
+// DeleteUserRestriction delete the restriction of target user by owner user
+func (s UserRestrictionServiceImpl) DeleteUserRestriction(ownerUserId uuid.UUID, targetUserId uuid.UUID, restrictionType string) error {
+
+	filter := struct {
+		OwnerUserId  uuid.UUID `json:"ownerUserId" bson:"ownerUserId"`
+		TargetUserId uuid.UUID `json:"targetUserId" bson:"targetUserId"`
+		Type         string    `json:"type" bson:"type"`
+	}{
+		OwnerUserId:  ownerUserId,
+		TargetUserId: targetUserId,
+		Type:         restrictionType,
+	}
+	// Remove every copy, restrictions saved twice before the unique index would leave the user restricted
+	result := <-s.UserRestrictionRepo.Delete(userRestrictionCollectionName, filter, false)
+	return result.Error
+}
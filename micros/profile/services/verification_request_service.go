@@ -0,0 +1,159 @@
+package service
+
+import (
+	"fmt"
+
+	uuid "github.com/gofrs/uuid"
+	"github.com/red-gold/telar-core/config"
+	coreData "github.com/red-gold/telar-core/data"
+	"github.com/red-gold/telar-core/data/mongodb"
+	mongoRepo "github.com/red-gold/telar-core/data/mongodb"
+	"github.com/red-gold/telar-core/utils"
+	dto "github.com/red-gold/telar-web/micros/profile/dto"
+)
+
+// VerificationRequestService handlers with injected dependencies
+type VerificationRequestServiceImpl struct {
+	VerificationRequestRepo coreData.Repository
+}
+
+// NewVerificationRequestService initializes VerificationRequestService's dependencies and create new VerificationRequestService struct
+func NewVerificationRequestService(db interface{}) (VerificationRequestService, error) {
+
+	verificationRequestService := &VerificationRequestServiceImpl{}
+
+	switch *config.AppConfig.DBType {
+	case config.DB_MONGO:
+
+		mongodb := db.(mongodb.MongoDatabase)
+		verificationRequestService.VerificationRequestRepo = mongoRepo.NewDataRepositoryMongo(mongodb)
+
+	}
+	if verificationRequestService.VerificationRequestRepo == nil {
+		fmt.Printf("verificationRequestService.VerificationRequestRepo is nil! \n")
+	}
+	return verificationRequestService, nil
+}
+
+// SaveVerificationRequest save verification request
+func (s VerificationRequestServiceImpl) SaveVerificationRequest(verificationRequest *dto.VerificationRequest) error {
+
+	if verificationRequest.ObjectId == uuid.Nil {
+		var uuidErr error
+		verificationRequest.ObjectId, uuidErr = uuid.NewV4()
+		if uuidErr != nil {
+			return uuidErr
+		}
+	}
+
+	if verificationRequest.CreatedDate == 0 {
+		verificationRequest.CreatedDate = utils.UTCNowUnix()
+	}
+
+	result := <-s.VerificationRequestRepo.Save(verificationRequestCollectionName, verificationRequest)
+
+	return result.Error
+}
+
+// FindOneVerificationRequest get one verification request
+func (s VerificationRequestServiceImpl) FindOneVerificationRequest(filter interface{}) (*dto.VerificationRequest, error) {
+
+	result := <-s.VerificationRequestRepo.FindOne(verificationRequestCollectionName, filter)
+	if result.Error() != nil {
+		if result.Error() == coreData.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, result.Error()
+	}
+
+	var verificationRequestResult dto.VerificationRequest
+	errDecode := result.Decode(&verificationRequestResult)
+	if errDecode != nil {
+		return nil, fmt.Errorf("Error docoding on dto.VerificationRequest")
+	}
+	return &verificationRequestResult, nil
+}
+
+// FindVerificationRequestList get all verification requests by filter
+func (s VerificationRequestServiceImpl) FindVerificationRequestList(filter interface{}, limit int64, skip int64, sort map[string]int) ([]dto.VerificationRequest, error) {
+
+	result := <-s.VerificationRequestRepo.Find(verificationRequestCollectionName, filter, limit, skip, sort)
+	defer result.Close()
+	if result.Error() != nil {
+		return nil, result.Error()
+	}
+	var verificationRequestList []dto.VerificationRequest
+	for result.Next() {
+		var verificationRequest dto.VerificationRequest
+		errDecode := result.Decode(&verificationRequest)
+		if errDecode != nil {
+			return nil, fmt.Errorf("Error docoding on dto.VerificationRequest")
+		}
+		verificationRequestList = append(verificationRequestList, verificationRequest)
+	}
+
+	return verificationRequestList, nil
+}
+
+// FindById find verification request by id
+func (s VerificationRequestServiceImpl) FindById(objectId uuid.UUID) (*dto.VerificationRequest, error) {
+
+	filter := struct {
+		ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	}{
+		ObjectId: objectId,
+	}
+	return s.FindOneVerificationRequest(filter)
+}
+
+// FindLatestByUserId find the last verification request of the user
+func (s VerificationRequestServiceImpl) FindLatestByUserId(userId uuid.UUID) (*dto.VerificationRequest, error) {
+
+	sortMap := make(map[string]int)
+	sortMap["created_date"] = -1
+	filter := struct {
+		UserId uuid.UUID `json:"userId" bson:"userId"`
+	}{
+		UserId: userId,
+	}
+	verificationRequestList, err := s.FindVerificationRequestList(filter, 1, 0, sortMap)
+	if err != nil || len(verificationRequestList) == 0 {
+		return nil, err
+	}
+	return &verificationRequestList[0], nil
+}
+
+// QueryVerificationRequest get verification requests by status and page, oldest first so the queue is reviewed in order
+func (s VerificationRequestServiceImpl) QueryVerificationRequest(status string, page int64) ([]dto.VerificationRequest, error) {
+
+	sortMap := make(map[string]int)
+	sortMap["created_date"] = 1
+	skip := numberOfItems * (page - 1)
+	filter := make(map[string]interface{})
+	if status != "" {
+		filter["status"] = status
+	}
+	return s.FindVerificationRequestList(filter, numberOfItems, skip, sortMap)
+}
+
+// UpdateVerificationRequest update verification request by filter
+func (s VerificationRequestServiceImpl) UpdateVerificationRequest(filter interface{}, data interface{}) error {
+
+	result := <-s.VerificationRequestRepo.Update(verificationRequestCollectionName, filter, data)
+	return result.Error
+}
+
+// UpdateVerificationRequestById update verification request by id
+func (s VerificationRequestServiceImpl) UpdateVerificationRequestById(objectId uuid.UUID, data interface{}) error {
+
+	filter := struct {
+		ObjectId uuid.UUID `json:"objectId" bson:"objectId"`
+	}{
+		ObjectId: objectId,
+	}
+
+	updateOperator := coreData.UpdateOperator{
+		Set: data,
+	}
+	return s.UpdateVerificationRequest(filter, updateOperator)
+}
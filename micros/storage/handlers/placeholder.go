@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/red-gold/telar-core/pkg/log"
+	"github.com/red-gold/telar-core/utils"
+)
+
+const (
+	avatarDefaultSize    = 128
+	avatarMinSize        = 16
+	avatarMaxSize        = 512
+	maxSeedLength        = 128
+	maxNameLength        = 256
+	placeholderMaxAge    = 2592000
+	avatarStyleIdenticon = "identicon"
+	avatarStyleInitials  = "initials"
+	imageFormatSVG       = "svg"
+	imageFormatPNG       = "png"
+)
+
+// @Summary Get a generated avatar
+// @Description Draw a default avatar from the seed. The same seed and query always give the same image.
+// @Description Initials style falls back to identicon when name has no letter, and PNG initials only support A-Z and 0-9.
+// @Tags placeholder
+// @Produce image/svg+xml
+// @Produce image/png
+// @Param   seed    path     string     true        "Seed of the avatar like user ID"
+// @Param   style   query    string     false       "identicon or initials" default(identicon)
+// @Param   name    query    string     false       "Name to take the initials from"
+// @Param   format  query    string     false       "svg or png" default(svg)
+// @Param   size    query    int        false       "Width and height in pixels from 16 to 512" default(128)
+// @Success 200
+// @Success 304
+// @Failure 400 {object} utils.TelarError
+// @Router /avatars/{seed} [get]
+func GetAvatarHandle(c *fiber.Ctx) error {
+
+	seed := c.Params("seed")
+	if seed == "" || len(seed) > maxSeedLength {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidSeed", "Seed is required and must be at most 128 characters!"))
+	}
+
+	format := strings.ToLower(c.Query("format", imageFormatSVG))
+	if format != imageFormatSVG && format != imageFormatPNG {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidFormat", "Format must be svg or png!"))
+	}
+
+	style := c.Query("style", avatarStyleIdenticon)
+	if style != avatarStyleIdenticon && style != avatarStyleInitials {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidStyle", "Style must be identicon or initials!"))
+	}
+
+	size := avatarDefaultSize
+	if rawSize := c.Query("size"); rawSize != "" {
+		parsedSize, err := strconv.Atoi(rawSize)
+		if err != nil || parsedSize < avatarMinSize || parsedSize > avatarMaxSize {
+			return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidSize", "Size must be a number from 16 to 512!"))
+		}
+		size = parsedSize
+	}
+
+	initials := ""
+	if style == avatarStyleInitials {
+		name := c.Query("name")
+		if len(name) > maxNameLength {
+			return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidName", "Name must be at most 256 characters!"))
+		}
+		initials = avatarInitials(name)
+		if initials == "" {
+			style = avatarStyleIdenticon
+		}
+	}
+
+	etag := placeholderETag("avatar", seed, style, initials, format, strconv.Itoa(size))
+	if placeholderNotModified(c, etag) {
+		return c.SendStatus(http.StatusNotModified)
+	}
+
+	hash := seedHash(seed)
+	if format == imageFormatSVG {
+		if style == avatarStyleInitials {
+			return sendPlaceholder(c, format, initialsSVG(hash, initials, size))
+		}
+		return sendPlaceholder(c, format, identiconSVG(hash, size))
+	}
+
+	img := identiconImage(hash, size)
+	if style == avatarStyleInitials {
+		if initialsImg, ok := initialsImage(hash, initials, size); ok {
+			img = initialsImg
+		}
+	}
+	data, err := encodePNG(img)
+	if err != nil {
+		log.Error("[GetAvatarHandle] Encode PNG %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/encodeAvatar", "Error happened while drawing avatar!"))
+	}
+	return sendPlaceholder(c, format, data)
+}
+
+// @Summary Get a generated banner
+// @Description Draw a 900x300 default banner from the seed. The same seed always gives the same image.
+// @Tags placeholder
+// @Produce image/svg+xml
+// @Produce image/png
+// @Param   seed    path     string     true        "Seed of the banner like user ID"
+// @Param   format  query    string     false       "svg or png" default(svg)
+// @Success 200
+// @Success 304
+// @Failure 400 {object} utils.TelarError
+// @Router /banners/{seed} [get]
+func GetBannerHandle(c *fiber.Ctx) error {
+
+	seed := c.Params("seed")
+	if seed == "" || len(seed) > maxSeedLength {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidSeed", "Seed is required and must be at most 128 characters!"))
+	}
+
+	format := strings.ToLower(c.Query("format", imageFormatSVG))
+	if format != imageFormatSVG && format != imageFormatPNG {
+		return c.Status(http.StatusBadRequest).JSON(utils.Error("invalidFormat", "Format must be svg or png!"))
+	}
+
+	etag := placeholderETag("banner", seed, format)
+	if placeholderNotModified(c, etag) {
+		return c.SendStatus(http.StatusNotModified)
+	}
+
+	hash := seedHash(seed)
+	if format == imageFormatSVG {
+		return sendPlaceholder(c, format, bannerSVG(hash))
+	}
+
+	data, err := encodePNG(bannerImage(hash))
+	if err != nil {
+		log.Error("[GetBannerHandle] Encode PNG %s", err.Error())
+		return c.Status(http.StatusInternalServerError).JSON(utils.Error("internal/encodeBanner", "Error happened while drawing banner!"))
+	}
+	return sendPlaceholder(c, format, data)
+}
+
+// placeholderETag get the ETag of a placeholder image from everything the image is drawn from
+func placeholderETag(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return fmt.Sprintf(`"%s"`, hex.EncodeToString(sum[:16]))
+}
+
+// placeholderNotModified set the cache headers and check whether the client already has the image
+func placeholderNotModified(c *fiber.Ctx, etag string) bool {
+	c.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d, immutable", placeholderMaxAge))
+	c.Set(fiber.HeaderETag, etag)
+	return c.Get(fiber.HeaderIfNoneMatch) == etag
+}
+
+// sendPlaceholder write the image on response with its content type
+func sendPlaceholder(c *fiber.Ctx, format string, data []byte) error {
+	if format == imageFormatSVG {
+		c.Set(fiber.HeaderContentType, "image/svg+xml")
+	} else {
+		c.Set(fiber.HeaderContentType, "image/png")
+	}
+	return c.Send(data)
+}
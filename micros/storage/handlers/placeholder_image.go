@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	identiconGridSize = 5
+	bannerWidth       = 900
+	bannerHeight      = 300
+	bannerCircleCount = 3
+)
+
+var identiconBackground = color.RGBA{R: 0xf0, G: 0xf0, B: 0xf0, A: 0xff}
+
+// glyphs is a 5x7 bitmap font to draw initials on PNG avatars without a font file.
+// Each row keeps the pixels in the five low bits from left to right.
+var glyphs = map[rune][7]uint8{
+	'A': {0x0E, 0x11, 0x11, 0x1F, 0x11, 0x11, 0x11},
+	'B': {0x1E, 0x11, 0x11, 0x1E, 0x11, 0x11, 0x1E},
+	'C': {0x0E, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0E},
+	'D': {0x1C, 0x12, 0x11, 0x11, 0x11, 0x12, 0x1C},
+	'E': {0x1F, 0x10, 0x10, 0x1E, 0x10, 0x10, 0x1F},
+	'F': {0x1F, 0x10, 0x10, 0x1E, 0x10, 0x10, 0x10},
+	'G': {0x0E, 0x11, 0x10, 0x17, 0x11, 0x11, 0x0F},
+	'H': {0x11, 0x11, 0x11, 0x1F, 0x11, 0x11, 0x11},
+	'I': {0x0E, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0E},
+	'J': {0x07, 0x02, 0x02, 0x02, 0x02, 0x12, 0x0C},
+	'K': {0x11, 0x12, 0x14, 0x18, 0x14, 0x12, 0x11},
+	'L': {0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x1F},
+	'M': {0x11, 0x1B, 0x15, 0x15, 0x11, 0x11, 0x11},
+	'N': {0x11, 0x11, 0x19, 0x15, 0x13, 0x11, 0x11},
+	'O': {0x0E, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0E},
+	'P': {0x1E, 0x11, 0x11, 0x1E, 0x10, 0x10, 0x10},
+	'Q': {0x0E, 0x11, 0x11, 0x11, 0x15, 0x12, 0x0D},
+	'R': {0x1E, 0x11, 0x11, 0x1E, 0x14, 0x12, 0x11},
+	'S': {0x0F, 0x10, 0x10, 0x0E, 0x01, 0x01, 0x1E},
+	'T': {0x1F, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04},
+	'U': {0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0E},
+	'V': {0x11, 0x11, 0x11, 0x11, 0x11, 0x0A, 0x04},
+	'W': {0x11, 0x11, 0x11, 0x15, 0x15, 0x15, 0x0A},
+	'X': {0x11, 0x11, 0x0A, 0x04, 0x0A, 0x11, 0x11},
+	'Y': {0x11, 0x11, 0x0A, 0x04, 0x04, 0x04, 0x04},
+	'Z': {0x1F, 0x01, 0x02, 0x04, 0x08, 0x10, 0x1F},
+	'0': {0x0E, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0E},
+	'1': {0x04, 0x0C, 0x04, 0x04, 0x04, 0x04, 0x0E},
+	'2': {0x0E, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1F},
+	'3': {0x1F, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0E},
+	'4': {0x02, 0x06, 0x0A, 0x12, 0x1F, 0x02, 0x02},
+	'5': {0x1F, 0x10, 0x1E, 0x01, 0x01, 0x11, 0x0E},
+	'6': {0x06, 0x08, 0x10, 0x1E, 0x11, 0x11, 0x0E},
+	'7': {0x1F, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08},
+	'8': {0x0E, 0x11, 0x11, 0x0E, 0x11, 0x11, 0x0E},
+	'9': {0x0E, 0x11, 0x11, 0x0F, 0x01, 0x02, 0x0C},
+}
+
+// seedHue get a hue in degrees from two bytes of the seed hash
+func seedHue(hash [32]byte, index int) float64 {
+	return float64((int(hash[index])<<8 | int(hash[index+1])) % 360)
+}
+
+// hslColor convert a hue in degrees with saturation and lightness between 0 and 1 to RGB
+func hslColor(hue, saturation, lightness float64) color.RGBA {
+	chroma := (1 - math.Abs(2*lightness-1)) * saturation
+	huePrime := math.Mod(hue, 360) / 60
+	x := chroma * (1 - math.Abs(math.Mod(huePrime, 2)-1))
+	var r, g, b float64
+	switch {
+	case huePrime < 1:
+		r, g, b = chroma, x, 0
+	case huePrime < 2:
+		r, g, b = x, chroma, 0
+	case huePrime < 3:
+		r, g, b = 0, chroma, x
+	case huePrime < 4:
+		r, g, b = 0, x, chroma
+	case huePrime < 5:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+	m := lightness - chroma/2
+	return color.RGBA{
+		R: uint8(math.Round((r + m) * 255)),
+		G: uint8(math.Round((g + m) * 255)),
+		B: uint8(math.Round((b + m) * 255)),
+		A: 0xff,
+	}
+}
+
+// hexColor format a color for SVG
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// mixColor blend from one color to another by the ratio between 0 and 1
+func mixColor(from, to color.RGBA, ratio float64) color.RGBA {
+	mix := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*ratio))
+	}
+	return color.RGBA{R: mix(from.R, to.R), G: mix(from.G, to.G), B: mix(from.B, to.B), A: 0xff}
+}
+
+// avatarInitials get up to two initials from the first and last word of the name
+func avatarInitials(name string) string {
+	words := []string{}
+	for _, word := range strings.Fields(name) {
+		first := []rune(word)[0]
+		if unicode.IsLetter(first) || unicode.IsDigit(first) {
+			words = append(words, string(unicode.ToUpper(first)))
+		}
+	}
+	if len(words) == 0 {
+		return ""
+	}
+	if len(words) == 1 {
+		return words[0]
+	}
+	return words[0] + words[len(words)-1]
+}
+
+// identiconGrid get a mirrored grid of the identicon cells from the seed hash
+func identiconGrid(hash [32]byte) [identiconGridSize][identiconGridSize]bool {
+	var grid [identiconGridSize][identiconGridSize]bool
+	half := (identiconGridSize + 1) / 2
+	for row := 0; row < identiconGridSize; row++ {
+		for col := 0; col < half; col++ {
+			filled := hash[row*half+col]%2 == 0
+			grid[row][col] = filled
+			grid[row][identiconGridSize-1-col] = filled
+		}
+	}
+	return grid
+}
+
+// identiconSVG draw the identicon of the seed hash as SVG. The grid keeps half a cell of margin.
+func identiconSVG(hash [32]byte, size int) []byte {
+	grid := identiconGrid(hash)
+	fill := hexColor(hslColor(seedHue(hash, 16), 0.55, 0.5))
+	viewSize := identiconGridSize + 1
+
+	var svg bytes.Buffer
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, viewSize, viewSize)
+	fmt.Fprintf(&svg, `<rect width="%d" height="%d" fill="%s"/>`, viewSize, viewSize, hexColor(identiconBackground))
+	for row := 0; row < identiconGridSize; row++ {
+		for col := 0; col < identiconGridSize; col++ {
+			if grid[row][col] {
+				fmt.Fprintf(&svg, `<rect x="%d.5" y="%d.5" width="1" height="1" fill="%s"/>`, col, row, fill)
+			}
+		}
+	}
+	svg.WriteString(`</svg>`)
+	return svg.Bytes()
+}
+
+// identiconImage draw the identicon of the seed hash as image
+func identiconImage(hash [32]byte, size int) image.Image {
+	grid := identiconGrid(hash)
+	fill := hslColor(seedHue(hash, 16), 0.55, 0.5)
+	viewSize := float64(identiconGridSize + 1)
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		row := int(math.Floor(float64(y)*viewSize/float64(size) - 0.5))
+		for x := 0; x < size; x++ {
+			col := int(math.Floor(float64(x)*viewSize/float64(size) - 0.5))
+			if row >= 0 && row < identiconGridSize && col >= 0 && col < identiconGridSize && grid[row][col] {
+				img.SetRGBA(x, y, fill)
+			} else {
+				img.SetRGBA(x, y, identiconBackground)
+			}
+		}
+	}
+	return img
+}
+
+// initialsSVG draw the initials on a background color from the seed hash as SVG
+func initialsSVG(hash [32]byte, initials string, size int) []byte {
+	background := hexColor(hslColor(seedHue(hash, 16), 0.45, 0.45))
+
+	var svg bytes.Buffer
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 100 100">`, size, size)
+	fmt.Fprintf(&svg, `<rect width="100" height="100" fill="%s"/>`, background)
+	fmt.Fprintf(&svg, `<text x="50" y="50" dy=".35em" fill="#ffffff" font-family="Helvetica, Arial, sans-serif" font-size="42" font-weight="600" text-anchor="middle">%s</text>`, html.EscapeString(initials))
+	svg.WriteString(`</svg>`)
+	return svg.Bytes()
+}
+
+// initialsImage draw the initials on a background color from the seed hash as image.
+// It returns false when a letter is not in the bitmap font.
+func initialsImage(hash [32]byte, initials string, size int) (image.Image, bool) {
+	letters := []rune(initials)
+	for _, letter := range letters {
+		if _, ok := glyphs[letter]; !ok {
+			return nil, false
+		}
+	}
+
+	background := hslColor(seedHue(hash, 16), 0.45, 0.45)
+	foreground := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.SetRGBA(x, y, background)
+		}
+	}
+
+	// Letters are 5x7 with one empty column between them and take about 40% of the avatar height
+	scale := int(math.Max(1, math.Round(float64(size)*0.4/7)))
+	textWidth := (len(letters)*6 - 1) * scale
+	left := (size - textWidth) / 2
+	top := (size - 7*scale) / 2
+	for index, letter := range letters {
+		glyph := glyphs[letter]
+		for row := 0; row < 7; row++ {
+			for col := 0; col < 5; col++ {
+				if glyph[row]&(1<<uint(4-col)) == 0 {
+					continue
+				}
+				for dy := 0; dy < scale; dy++ {
+					for dx := 0; dx < scale; dx++ {
+						img.SetRGBA(left+(index*6+col)*scale+dx, top+row*scale+dy, foreground)
+					}
+				}
+			}
+		}
+	}
+	return img, true
+}
+
+// bannerColors get the gradient colors of the banner from the seed hash
+func bannerColors(hash [32]byte) (color.RGBA, color.RGBA) {
+	hue := seedHue(hash, 0)
+	return hslColor(hue, 0.55, 0.55), hslColor(hue+40+float64(hash[2]%80), 0.6, 0.4)
+}
+
+// bannerCircle is a soft light circle on the banner
+type bannerCircle struct {
+	X, Y, Radius float64
+}
+
+// bannerCircles get the position and size of the banner circles from the seed hash
+func bannerCircles(hash [32]byte) []bannerCircle {
+	circles := make([]bannerCircle, bannerCircleCount)
+	for i := range circles {
+		circles[i] = bannerCircle{
+			X:      float64(hash[3+i*3]) / 255 * bannerWidth,
+			Y:      float64(hash[4+i*3]) / 255 * bannerHeight,
+			Radius: float64(60 + int(hash[5+i*3])%120),
+		}
+	}
+	return circles
+}
+
+// bannerSVG draw a gradient banner with circles from the seed hash as SVG
+func bannerSVG(hash [32]byte) []byte {
+	from, to := bannerColors(hash)
+
+	var svg bytes.Buffer
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, bannerWidth, bannerHeight, bannerWidth, bannerHeight)
+	fmt.Fprintf(&svg, `<defs><linearGradient id="g" x1="0" y1="0" x2="1" y2="0"><stop offset="0" stop-color="%s"/><stop offset="1" stop-color="%s"/></linearGradient></defs>`, hexColor(from), hexColor(to))
+	fmt.Fprintf(&svg, `<rect width="%d" height="%d" fill="url(#g)"/>`, bannerWidth, bannerHeight)
+	for _, circle := range bannerCircles(hash) {
+		fmt.Fprintf(&svg, `<circle cx="%.1f" cy="%.1f" r="%.0f" fill="#ffffff" fill-opacity="0.12"/>`, circle.X, circle.Y, circle.Radius)
+	}
+	svg.WriteString(`</svg>`)
+	return svg.Bytes()
+}
+
+// bannerImage draw a gradient banner with circles from the seed hash as image
+func bannerImage(hash [32]byte) image.Image {
+	from, to := bannerColors(hash)
+	circles := bannerCircles(hash)
+	white := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+
+	img := image.NewRGBA(image.Rect(0, 0, bannerWidth, bannerHeight))
+	for x := 0; x < bannerWidth; x++ {
+		column := mixColor(from, to, float64(x)/float64(bannerWidth-1))
+		for y := 0; y < bannerHeight; y++ {
+			pixel := column
+			for _, circle := range circles {
+				if math.Hypot(float64(x)-circle.X, float64(y)-circle.Y) <= circle.Radius {
+					pixel = mixColor(pixel, white, 0.12)
+				}
+			}
+			img.SetRGBA(x, y, pixel)
+		}
+	}
+	return img
+}
+
+// seedHash get the hash which all the placeholder images are drawn from
+func seedHash(seed string) [32]byte {
+	return sha256.Sum256([]byte(seed))
+}
+
+// encodePNG encode the image as PNG
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -43,6 +43,9 @@ func SetupRoutes(app *fiber.App) {
 	})
 
 	// Router
+	// Placeholder images are public to be shown before login and in emails
+	app.Get("/avatars/:seed", handlers.GetAvatarHandle)
+	app.Get("/banners/:seed", handlers.GetBannerHandle)
 	app.Post("/:uid/:dir", authCookieMiddleware, handlers.UploadeHandle)
 	app.Get("/:uid/:dir/:name", authCookieMiddleware, handlers.GetFileHandle)
 